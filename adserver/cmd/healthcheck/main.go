@@ -0,0 +1,53 @@
+// Commande healthcheck : interroge le service grpc.health.v1 et sort en erreur
+// si le service n'est pas SERVING. Utilisée par le healthcheck Docker.
+//
+// Exemple :
+//
+//	./healthcheck -addr localhost:50051
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"log/slog"
+	"time"
+
+	"adserver/internal/adapters/tlsconfig"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:50051", "adresse gRPC de l'adserver")
+	service := flag.String("service", "", "service à vérifier, serveur entier par défaut")
+	timeout := flag.Duration("timeout", 3*time.Second, "délai maximal de la vérification")
+	var tlsCfg tlsconfig.Config
+	flag.StringVar(&tlsCfg.CAFile, "tls-ca", "", "autorité du serveur (active TLS)")
+	flag.StringVar(&tlsCfg.CertFile, "tls-cert", "", "certificat client pour le mTLS")
+	flag.StringVar(&tlsCfg.KeyFile, "tls-key", "", "clé du certificat client")
+	flag.StringVar(&tlsCfg.ServerName, "tls-server-name", "", "nom attendu dans le certificat du serveur")
+	flag.Parse()
+
+	creds, err := tlsconfig.ClientCredentials(tlsCfg, slog.Default())
+	if err != nil {
+		log.Fatalf("Failed to load TLS files: %v", err)
+	}
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		log.Fatalf("Failed to connect to %s: %v", *addr, err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: *service})
+	if err != nil {
+		log.Fatalf("Health check failed: %v", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		log.Fatalf("Service is %s", resp.GetStatus())
+	}
+}
@@ -2,132 +2,468 @@ package main
 
 import (
 	"adserver/generated/ad_service"
-	"adserver/generated/impression_service"
+	"adserver/internal/adapters/cache"
+	"adserver/internal/adapters/clock"
+	"adserver/internal/adapters/gateway"
+	"adserver/internal/adapters/grpc/auth"
 	"adserver/internal/adapters/grpc/handler"
+	"adserver/internal/adapters/healthcheck"
+	"adserver/internal/adapters/jwks"
+	"adserver/internal/adapters/logging"
+	"adserver/internal/adapters/memory"
+	"adserver/internal/adapters/metrics"
 	"adserver/internal/adapters/mongodb"
+	"adserver/internal/adapters/ratelimit"
+	"adserver/internal/adapters/tlsconfig"
+	"adserver/internal/adapters/tracing"
+	"adserver/internal/adapters/tracker"
 	"adserver/internal/application"
+	"adserver/internal/config"
+	"adserver/internal/domain"
+	"adserver/internal/ports/out"
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"runtime"
+	"shared/dragonflyclient"
+	"shared/generated/impression_service"
+	"shared/mongomigrate"
 	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
-// loadEnv charge les variables d'environnement depuis le fichier .env
-func loadEnv() {
-	if err := godotenv.Load("/app/.env"); err != nil {
-		log.Printf("Warning: .env file not found or error loading it: %v", err)
-	} else {
-		log.Printf("Environment variables loaded successfully from .env file")
-	}
+// fatal journalise une erreur de démarrage et arrête le processus
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
 
-// getEnvOrDefault récupère une variable d'environnement ou retourne une valeur par défaut
-func getEnvOrDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		log.Printf("Config: %s=%s", key, value)
-		return value
+// newDragonflyClient crée le client Dragonfly décrit par c : mode, authentification, pool
+// et TLS optionnel (racines du système si aucune autorité n'est fournie), dont les
+// certificats sont rechargés à chaud. closeClient ferme le client puis arrête le rechargement.
+func newDragonflyClient(c config.DragonflyConfig, reloadInterval time.Duration, logger *slog.Logger) (client redis.UniversalClient, closeClient func(), err error) {
+	opts := dragonflyclient.Options{
+		Mode:             c.Mode,
+		Addrs:            c.Addrs,
+		MasterName:       c.MasterName,
+		Username:         c.Username,
+		Password:         c.Password,
+		SentinelUsername: c.SentinelUsername,
+		SentinelPassword: c.SentinelPassword,
+		DB:               c.DB,
+		PoolSize:         c.Pool.Size,
+		MinIdleConns:     c.Pool.MinIdleConns,
+		MaxRetries:       c.Pool.MaxRetries,
+		DialTimeout:      c.Pool.DialTimeout,
+		ReadTimeout:      c.Pool.ReadTimeout,
+		WriteTimeout:     c.Pool.WriteTimeout,
+		PoolTimeout:      c.Pool.Timeout,
+	}
+	stopReload := func() {}
+	if dragonflyTLS := tlsconfig.Config(c.TLS); c.TLSEnabled || dragonflyTLS.Enabled() {
+		reloader, err := tlsconfig.NewReloader(dragonflyTLS, reloadInterval, logger)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load Dragonfly TLS certificates: %w", err)
+		}
+		stopReload = reloader.Stop
+		opts.TLS = reloader.ClientConfig()
+	}
+	client, err = dragonflyclient.NewClient(opts)
+	if err != nil {
+		stopReload()
+		return nil, nil, err
 	}
-	log.Printf("Config: %s=%s (default)", key, defaultValue)
-	return defaultValue
+	return client, func() { client.Close(); stopReload() }, nil
 }
 
 func main() {
 	startTime := time.Now()
-	log.Printf("Starting Ad Server application...")
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "fichier de configuration YAML, surchargé par les variables d'environnement")
+	envFile := flag.String("env-file", ".env", "fichier .env chargé dans l'environnement s'il existe")
+	printConfig := flag.Bool("print-config", false, "afficher la configuration effective, secrets masqués, puis quitter")
+	flag.Parse()
 
-	log.Printf("System info: Go version=%s, OS=%s, Arch=%s, CPUs=%d",
-		runtime.Version(), runtime.GOOS, runtime.GOARCH, runtime.NumCPU())
+	// Configuration : valeurs par défaut, fichier YAML puis variables d'environnement.
+	// Le .env est journalisé une fois le logger configuré, LOG_LEVEL pouvant en provenir.
+	envErr := godotenv.Load(*envFile)
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		fatal("Failed to load configuration", "error", err)
+	}
+	if *printConfig {
+		if err := config.Print(os.Stdout, cfg); err != nil {
+			fatal("Failed to print configuration", "error", err)
+		}
+		if err := cfg.Validate(); err != nil {
+			fatal("Invalid configuration", "error", err)
+		}
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		fatal("Invalid configuration", "error", err)
+	}
 
-	loadEnv()
+	// Logger JSON structuré, également utilisé par le package log standard
+	logger, err := logging.New(os.Stdout, cfg.Log.Level)
+	if err != nil {
+		log.Fatalf("Invalid LOG_LEVEL: %v", err)
+	}
+	slog.SetDefault(logger)
 
-	grpcHost := getEnvOrDefault("GRPC_HOST", "0.0.0.0")
-	grpcPort := getEnvOrDefault("GRPC_PORT", "50051")
-	mongoURI := getEnvOrDefault("MONGODB_URI", "mongodb://mongodb:27017")
-	mongoDatabase := getEnvOrDefault("MONGODB_DATABASE", "adserver")
-	serviceName := getEnvOrDefault("SERVICE_NAME", "adserver")
-	environment := getEnvOrDefault("ENVIRONMENT", "development")
+	logger.Info("Starting Ad Server application")
+	logger.Info("System info", "go_version", runtime.Version(), "os", runtime.GOOS, "arch", runtime.GOARCH, "cpus", runtime.NumCPU())
+	if envErr != nil {
+		logger.Warn(".env file not found or error loading it", "path", *envFile, "error", envErr)
+	} else {
+		logger.Info("Environment variables loaded from .env file", "path", *envFile)
+	}
+	logger.Info("Configuration loaded", "file", *configFile)
 
-	address := fmt.Sprintf("%s:%s", grpcHost, grpcPort)
-	log.Printf("Listening on %s...", address)
+	// Traces OpenTelemetry
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName:  cfg.Service.Name,
+		Environment:  cfg.Service.Environment,
+		Exporter:     cfg.Tracing.Exporter,
+		OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+		FilePath:     cfg.Tracing.File,
+		SampleRatio:  cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		fatal("Failed to set up tracing", "error", err)
+	}
+	defer func() {
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer flushCancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			logger.Error("Error flushing traces", "error", err)
+		}
+	}()
+
+	// Stockage : MongoDB, ou mémoire du processus pour le développement (STORAGE=memory)
+	var (
+		repo       out.AdRepository
+		apiKeyRepo out.APIKeyRepository
+		mongoPing  healthcheck.Check
+		// Tampon des impressions, écrit une dernière fois à l'arrêt
+		impressionBuffer *mongodb.ImpressionBuffer
+	)
+	if cfg.Storage.Memory() {
+		logger.Warn("In-memory storage: ads and API keys are lost on shutdown and not shared between replicas")
+		repo, apiKeyRepo = memory.NewAdRepository(clock.System, cfg.MongoDB.ArchiveRetention), memory.NewAPIKeyRepository()
+	} else {
+		// Connexion MongoDB
+		logger.Info("Connecting to MongoDB", "database", cfg.MongoDB.Database)
+		mongoCtx, mongoCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer mongoCancel()
+		client, err := mongo.Connect(mongoCtx, options.Client().ApplyURI(cfg.MongoDB.URI))
+		if err != nil {
+			fatal("Failed to connect to MongoDB", "error", err)
+		}
+		pingCtx, pingCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer pingCancel()
+		if err := client.Ping(pingCtx, nil); err != nil {
+			fatal("Failed to ping MongoDB", "error", err)
+		}
+		defer func() {
+			disconnectCtx, disconnectCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer disconnectCancel()
+			if err := client.Disconnect(disconnectCtx); err != nil {
+				logger.Error("Error disconnecting MongoDB", "error", err)
+			}
+		}()
+
+		// Migrations du schéma (index) et rétention de l'archive. Une migration en échec arrête
+		// le service : le code suppose le schéma à jour (clés d'API uniques). La rétention est
+		// un réglage et non une version du schéma : elle est appliquée à chaque démarrage,
+		// migrations désactivées comprises, et son échec arrête aussi le service plutôt que de
+		// conserver l'archive au-delà de la durée configurée.
+		db := client.Database(cfg.MongoDB.Database)
+		migrateCtx, migrateCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if cfg.MongoDB.Migrate {
+			if _, err := mongomigrate.Migrate(migrateCtx, db, mongodb.Migrations(cfg.MongoDB.AdsCollection), logger); err != nil {
+				fatal("Failed to migrate MongoDB schema", "error", err)
+			}
+		}
+		if err := mongodb.EnsureArchiveRetention(migrateCtx, db, cfg.MongoDB.ArchiveCollection, cfg.MongoDB.ArchiveRetention); err != nil {
+			fatal("Failed to set archive retention", "collection", cfg.MongoDB.ArchiveCollection, "error", err)
+		}
+		migrateCancel()
+
+		repo = mongodb.NewMongoRepository(db, cfg.MongoDB.AdsCollection, cfg.MongoDB.ArchiveCollection, clock.System, logger)
+		// Impressions comptées en mémoire et écrites par lots, plutôt qu'un $inc par ServeAd
+		if cfg.MongoDB.ImpressionFlushInterval > 0 {
+			impressionBuffer = mongodb.NewImpressionBuffer(repo, db, cfg.MongoDB.AdsCollection, cfg.MongoDB.ArchiveCollection, cfg.MongoDB.ImpressionFlushInterval, clock.System, logger)
+			impressionBuffer.Start()
+			repo = impressionBuffer
+			logger.Info("Impression buffer enabled", "flush_interval", cfg.MongoDB.ImpressionFlushInterval)
+		}
+		apiKeyRepo = mongodb.NewAPIKeyRepository(db, logger)
+		mongoPing = func(ctx context.Context) error { return client.Ping(ctx, nil) }
+	}
+
+	// Cache des lectures de publicités : LRU local, puis Dragonfly s'il est configuré.
+	// Dragonfly injoignable n'empêche pas le démarrage : le cache se replie sur le repository.
+	if cfg.AdCache.Enabled {
+		cacheOpts := cache.Options{Size: cfg.AdCache.Size, TTL: cfg.AdCache.TTL}
+		if dragonflyCfg := cfg.AdCache.Dragonfly; len(dragonflyCfg.Addrs) > 0 {
+			cacheClient, closeCacheClient, err := newDragonflyClient(dragonflyCfg, cfg.TLS.ReloadInterval, logger)
+			if err != nil {
+				fatal("Failed to configure the Dragonfly ad cache", "error", err)
+			}
+			pingCtx, pingCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := cacheClient.Ping(pingCtx).Err(); err != nil {
+				logger.Warn("Dragonfly ad cache unreachable, falling back to repository", "mode", dragonflyCfg.Mode, "addresses", dragonflyCfg.Addrs, "error", err)
+			}
+			pingCancel()
+			defer closeCacheClient()
+			cacheOpts.Client = cacheClient
+		}
+		repo = cache.NewAdRepository(repo, cacheOpts, clock.System, logger)
+		logger.Info("Ad cache enabled", "size", cfg.AdCache.Size, "ttl", cfg.AdCache.TTL, "dragonfly", cfg.AdCache.Dragonfly.Addrs)
+	}
+
+	address := cfg.GRPC.Address()
+	// Seules les requêtes du chemin critique sont échantillonnées
+	logSampler := logging.NewSampler(cfg.Log.SampleEvery, ad_service.AdService_ServeAd_FullMethodName)
+
+	// Authentification par clé d'API : les clés sont stockées hachées dans MongoDB
+	apiKeys := application.NewAPIKeyService(apiKeyRepo, clock.System, cfg.Auth.CacheTTL, logger)
+	if cfg.Auth.BootstrapAdminKey != "" {
+		if err := apiKeys.EnsureKey(context.Background(), "bootstrap-admin", "", domain.RoleAdmin, cfg.Auth.BootstrapAdminKey); err != nil {
+			fatal("Failed to register AUTH_BOOTSTRAP_ADMIN_KEY", "error", err)
+		}
+	}
+	unaryInterceptors := []grpc.UnaryServerInterceptor{logging.UnaryServerInterceptor(logSampler), metrics.UnaryServerInterceptor()}
+	streamInterceptors := []grpc.StreamServerInterceptor{logging.StreamServerInterceptor(logSampler), metrics.StreamServerInterceptor()}
+	if cfg.Auth.Enabled {
+		authenticator := auth.NewAuthenticator(apiKeys, auth.AdServicePolicy(), logger, ad_service.AdService_ServiceDesc.ServiceName)
+
+		// Jetons porteurs du portail, vérifiés avec le JWKS (fichier local ou URL)
+		if cfg.JWT.JWKS != "" {
+			keySet, err := jwks.NewKeySet(cfg.JWT.JWKS, cfg.JWT.JWKSRefresh, logger)
+			if err != nil {
+				fatal("Failed to load JWKS", "source", cfg.JWT.JWKS, "error", err)
+			}
+			defer keySet.Stop()
+			authenticator.WithTokens(auth.NewJWTVerifier(keySet, auth.JWTConfig{
+				Issuer:      cfg.JWT.Issuer,
+				Audience:    cfg.JWT.Audience,
+				TenantClaim: cfg.JWT.TenantClaim,
+				RoleClaim:   cfg.JWT.RoleClaim,
+				Leeway:      cfg.JWT.Leeway,
+			}))
+			logger.Info("Bearer tokens enabled", "jwks", cfg.JWT.JWKS, "issuer", cfg.JWT.Issuer, "audience", cfg.JWT.Audience)
+		}
+		unaryInterceptors = append(unaryInterceptors, authenticator.UnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, authenticator.StreamServerInterceptor())
+	} else {
+		logger.Warn("Authentication disabled: AdService is open to any client")
+	}
+
+	// Limitation de débit et quotas de ServeAd, par clé d'API et par IP, partagés via Dragonfly.
+	// Placée après l'authentification, qui identifie la clé de l'appelant.
+	if cfg.RateLimit.Enabled {
+		keyLimit := ratelimit.Limit(cfg.RateLimit.Key)
+		ipLimit := ratelimit.Limit(cfg.RateLimit.IP)
+		dragonflyCfg := cfg.RateLimit.Dragonfly
+		rateLimitClient, closeRateLimitClient, err := newDragonflyClient(dragonflyCfg, cfg.TLS.ReloadInterval, logger)
+		if err != nil {
+			fatal("Failed to configure Dragonfly for rate limiting", "error", err)
+		}
+		pingCtx, pingCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := rateLimitClient.Ping(pingCtx).Err(); err != nil {
+			fatal("Failed to connect to Dragonfly for rate limiting", "mode", dragonflyCfg.Mode, "addresses", dragonflyCfg.Addrs, "error", err)
+		}
+		pingCancel()
+		defer closeRateLimitClient()
+
+		limiter := ratelimit.NewLimiter(rateLimitClient, "ratelimit:adserver", clock.System, logger)
+		unaryInterceptors = append(unaryInterceptors, limiter.UnaryServerInterceptor(
+			[]string{ad_service.AdService_ServeAd_FullMethodName},
+			ratelimit.Rule{Name: "key", Key: ratelimit.ByPrincipal(), Limit: keyLimit},
+			ratelimit.Rule{Name: "ip", Key: ratelimit.ByPeerIP(cfg.RateLimit.TrustForwarded), Limit: ipLimit},
+		))
+		logger.Info("Rate limiting enabled", "dragonfly", dragonflyCfg.Addrs, "key_limit", keyLimit, "ip_limit", ipLimit)
+	}
+
+	logger.Info("Listening", "address", address)
 	lis, err := net.Listen("tcp", address)
 	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
+		fatal("Failed to listen", "address", address, "error", err)
+	}
+
+	serverOpts := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
 	}
 
-	grpcServer := grpc.NewServer()
+	// TLS optionnel, mTLS si une autorité des clients est fournie ; les certificats sont rechargés à chaud
+	if cfg.TLS.Enabled() {
+		tlsReloader, err := tlsconfig.NewReloader(tlsconfig.Config{
+			CertFile: cfg.TLS.CertFile,
+			KeyFile:  cfg.TLS.KeyFile,
+			CAFile:   cfg.TLS.ClientCAFile,
+		}, cfg.TLS.ReloadInterval, logger)
+		if err != nil {
+			fatal("Failed to load TLS certificates", "error", err)
+		}
+		defer tlsReloader.Stop()
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsReloader.ServerConfig())))
+		logger.Info("TLS enabled", "mutual", cfg.TLS.ClientCAFile != "")
+	}
+	grpcServer := grpc.NewServer(serverOpts...)
 	// Activer la réflexion pour grpcurl
 	reflection.Register(grpcServer)
 
 	// Connexion gRPC au microservice impression-tracker
-	imprAddr := getEnvOrDefault("IMPRESSION_GRPC_ADDR", "impression-tracker:50052")
-	impressionConn, err := grpc.NewClient(imprAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	imprAddr := cfg.Tracker.Addr
+	imprCreds := insecure.NewCredentials()
+	imprTLS := tlsconfig.Config(cfg.Tracker.TLS)
+	if imprTLS.Enabled() {
+		imprReloader, err := tlsconfig.NewReloader(imprTLS, cfg.TLS.ReloadInterval, logger)
+		if err != nil {
+			fatal("Failed to load impression-tracker TLS certificates", "error", err)
+		}
+		defer imprReloader.Stop()
+		imprCreds = credentials.NewTLS(imprReloader.ClientConfig())
+		logger.Info("TLS enabled towards impression-tracker", "mutual", imprTLS.CertFile != "")
+	}
+	impressionConn, err := grpc.NewClient(imprAddr,
+		grpc.WithTransportCredentials(imprCreds),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()), // Propage le trace-context W3C vers le tracker
+		grpc.WithChainUnaryInterceptor(logging.UnaryClientInterceptor()),
+	)
 	if err != nil {
-		log.Fatalf("Failed to connect to impression-tracker %s: %v", imprAddr, err)
+		fatal("Failed to connect to impression-tracker", "address", imprAddr, "error", err)
 	}
 	defer impressionConn.Close()
 
-	impressionClient := impression_service.NewImpressionServiceClient(impressionConn)
-	log.Printf("Connected to ImpressionService at %s", imprAddr)
+	// Délais, nouvelles tentatives et disjoncteur autour des appels au tracker
+	impressionClient := tracker.NewResilientClient(impression_service.NewImpressionServiceClient(impressionConn), tracker.ResilienceConfig{
+		CallTimeout:      cfg.Tracker.CallTimeout,
+		RetryAttempts:    cfg.Tracker.RetryAttempts,
+		RetryBackoff:     cfg.Tracker.RetryBackoff,
+		BreakerThreshold: cfg.Tracker.BreakerFailures,
+		BreakerCooldown:  cfg.Tracker.BreakerCooldown,
+	}, clock.System, logger)
+	logger.Info("Connected to ImpressionService", "address", imprAddr)
 
-	// Connexion MongoDB
-	log.Printf("Connecting to MongoDB at %s...", mongoURI)
-	mongoCtx, mongoCancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer mongoCancel()
-	client, err := mongo.Connect(mongoCtx, options.Client().ApplyURI(mongoURI))
-	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
-	}
-	pingCtx, pingCancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer pingCancel()
-	if err := client.Ping(pingCtx, nil); err != nil {
-		log.Fatalf("Failed to ping MongoDB: %v", err)
-	}
-	defer func() {
-		disconnectCtx, disconnectCancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer disconnectCancel()
-		if err := client.Disconnect(disconnectCtx); err != nil {
-			log.Printf("Error disconnecting MongoDB: %v", err)
-		}
-	}()
+	adService := application.NewAdService(repo, clock.System, logger)
+	reconciler := application.NewReconciler(repo, tracker.NewImpressionTracker(impressionClient, logger), logger)
 
-	repo := mongodb.NewMongoRepository(client.Database(mongoDatabase))
-	adService := application.NewAdService(repo)
+	// Santé : grpc.health.v1 suit le ping MongoDB et l'état du impression-tracker
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	checker := healthcheck.NewChecker(healthServer, cfg.Health.Interval, cfg.Health.Timeout, logger, ad_service.AdService_ServiceDesc.ServiceName)
+	if mongoPing != nil {
+		checker.Register("mongodb", mongoPing)
+	}
+	checker.Register("impression-tracker", tracker.HealthCheck(impressionConn, impressionClient))
+	checker.Start()
 
-	ad_service.RegisterAdServiceServer(grpcServer, handler.NewAdHandler(adService, impressionClient))
-	log.Printf("AdService handler registered")
+	ad_service.RegisterAdServiceServer(grpcServer, handler.NewAdHandler(adService, reconciler, impressionClient, checker, apiKeys, cfg.RateLimit.TrustForwarded, clock.System, logger))
+	logger.Info("AdService handler registered")
 
-	// Nettoyage des publicités expirées
+	// Archivage des publicités expirées, interrompu à l'arrêt du serveur
+	cleanupCtx, cleanupCancel := context.WithCancel(context.Background())
+	cleanupDone := make(chan struct{})
 	go func() {
-		ticker := time.NewTicker(1 * time.Minute)
+		defer close(cleanupDone)
+		ticker := clock.System.NewTicker(1 * time.Minute)
 		defer ticker.Stop()
-		for range ticker.C {
-			count, err := adService.DeleteExpired(context.Background())
+		for {
+			select {
+			case <-cleanupCtx.Done():
+				return
+			case <-ticker.C():
+			}
+			count, err := adService.DeleteExpired(cleanupCtx)
 			if err != nil {
-				log.Printf("[CleanupExpired] error: %v", err)
+				if cleanupCtx.Err() == nil {
+					logger.Error("CleanupExpired failed", "error", err)
+				}
 			} else {
-				log.Printf("[CleanupExpired] deleted %d ads", count)
+				logger.Info("CleanupExpired completed", "archived", count)
 			}
 		}
 	}()
 
+	// Réconciliation périodique des compteurs d'impressions avec le tracker (désactivée si 0),
+	// interrompue à l'arrêt du serveur
+	reconcileCtx, reconcileCancel := context.WithCancel(context.Background())
+	reconcileDone := make(chan struct{})
+	go func() {
+		defer close(reconcileDone)
+		if cfg.Reconcile.Interval <= 0 {
+			return
+		}
+		ticker := clock.System.NewTicker(cfg.Reconcile.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-reconcileCtx.Done():
+				return
+			case <-ticker.C():
+			}
+			// Toutes les publicités non archivées
+			if _, err := reconciler.Reconcile(reconcileCtx, time.Time{}, clock.System.Now(), cfg.Reconcile.Repair); err != nil {
+				if reconcileCtx.Err() == nil {
+					logger.Error("Reconcile failed", "error", err)
+				}
+			}
+		}
+	}()
+
+	// Endpoint Prometheus
+	metricsServer := metrics.Serve(cfg.Metrics.Addr, logger)
+	logger.Info("Metrics available", "address", cfg.Metrics.Addr, "path", "/metrics")
+
+	// Passerelle REST/JSON et spécification OpenAPI, relayées au serveur gRPC local
+	gatewayCtx, gatewayCancel := context.WithCancel(context.Background())
+	defer gatewayCancel()
+	var gatewayServer *http.Server
+	if cfg.Gateway.Enabled {
+		gatewayCreds := insecure.NewCredentials()
+		gatewayTLS := tlsconfig.Config(cfg.Gateway.TLS)
+		if gatewayTLS.Enabled() {
+			gatewayReloader, err := tlsconfig.NewReloader(gatewayTLS, cfg.TLS.ReloadInterval, logger)
+			if err != nil {
+				fatal("Failed to load gateway TLS certificates", "error", err)
+			}
+			defer gatewayReloader.Stop()
+			gatewayCreds = credentials.NewTLS(gatewayReloader.ClientConfig())
+		}
+		gatewayServer, err = gateway.Serve(gatewayCtx, cfg.Gateway.Addr, cfg.Gateway.GRPCAddr, gatewayCreds, logger)
+		if err != nil {
+			fatal("Failed to start REST gateway", "error", err)
+		}
+		logger.Info("REST gateway available", "address", cfg.Gateway.Addr, "grpc_address", cfg.Gateway.GRPCAddr, "openapi", gateway.OpenAPIPath)
+	}
+
 	// Démarrer le serveur gRPC
 	go func() {
-		log.Printf("%s gRPC server running on %s (%s)",
-			serviceName, address, environment)
+		logger.Info("gRPC server running", "service", cfg.Service.Name, "address", address, "environment", cfg.Service.Environment)
 		if err := grpcServer.Serve(lis); err != nil {
-			log.Fatalf("Failed to serve: %v", err)
+			fatal("Failed to serve", "error", err)
 		}
 	}()
 
@@ -135,7 +471,29 @@ func main() {
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 	<-stop
-	log.Println("Shutting down gRPC server...")
+	logger.Info("Shutting down gRPC server")
+	checker.Stop() // Passe le service à NOT_SERVING avant l'arrêt
+	cleanupCancel()
+	reconcileCancel()
+	<-cleanupDone
+	<-reconcileDone
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Error shutting down metrics server", "error", err)
+	}
+	if gatewayServer != nil {
+		if err := gatewayServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Error shutting down REST gateway", "error", err)
+		}
+	}
 	grpcServer.GracefulStop()
-	log.Printf("Server stopped. Total uptime: %v", time.Since(startTime))
+	if impressionBuffer != nil {
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := impressionBuffer.Stop(flushCtx); err != nil {
+			logger.Error("Failed to flush buffered impressions", "error", err)
+		}
+		flushCancel()
+	}
+	logger.Info("Server stopped", "uptime", time.Since(startTime))
 }
@@ -9,8 +9,10 @@
 package ad_service
 
 import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
@@ -24,6 +26,59 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Rôle d'une clé d'API
+type Role int32
+
+const (
+	Role_ROLE_UNSPECIFIED Role = 0
+	Role_ROLE_ADMIN       Role = 1 // Toutes les opérations, dont la gestion des clés
+	Role_ROLE_ADVERTISER  Role = 2 // Création et lecture des publicités
+	Role_ROLE_READER      Role = 3 // Lecture et diffusion des publicités
+)
+
+// Enum value maps for Role.
+var (
+	Role_name = map[int32]string{
+		0: "ROLE_UNSPECIFIED",
+		1: "ROLE_ADMIN",
+		2: "ROLE_ADVERTISER",
+		3: "ROLE_READER",
+	}
+	Role_value = map[string]int32{
+		"ROLE_UNSPECIFIED": 0,
+		"ROLE_ADMIN":       1,
+		"ROLE_ADVERTISER":  2,
+		"ROLE_READER":      3,
+	}
+)
+
+func (x Role) Enum() *Role {
+	p := new(Role)
+	*p = x
+	return p
+}
+
+func (x Role) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Role) Descriptor() protoreflect.EnumDescriptor {
+	return file_ad_service_proto_enumTypes[0].Descriptor()
+}
+
+func (Role) Type() protoreflect.EnumType {
+	return &file_ad_service_proto_enumTypes[0]
+}
+
+func (x Role) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Role.Descriptor instead.
+func (Role) EnumDescriptor() ([]byte, []int) {
+	return file_ad_service_proto_rawDescGZIP(), []int{0}
+}
+
 // Requête pour créer une nouvelle publicité
 type CreateAdRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -94,6 +149,7 @@ type AdResponse struct {
 	Url           string                 `protobuf:"bytes,4,opt,name=url,proto3" json:"url,omitempty"`
 	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
 	Impressions   int64                  `protobuf:"varint,6,opt,name=impressions,proto3" json:"impressions,omitempty"`
+	Tenant        string                 `protobuf:"bytes,7,opt,name=tenant,proto3" json:"tenant,omitempty"` // Locataire propriétaire, vide pour le locataire par défaut
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -170,6 +226,13 @@ func (x *AdResponse) GetImpressions() int64 {
 	return 0
 }
 
+func (x *AdResponse) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
 // Requête pour récupérer une publicité par son ID
 type GetAdRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -263,8 +326,9 @@ func (x *ServeAdRequest) GetId() string {
 // Réponse de diffusion : URL avec tracking + compteur d'impressions
 type ServeAdResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`                  // URL de la publicité avec tracking intégré
-	Impressions   int64                  `protobuf:"varint,2,opt,name=impressions,proto3" json:"impressions,omitempty"` // Nombre d'impressions après incrément
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`                                       // URL de la publicité avec tracking intégré
+	Impressions   int64                  `protobuf:"varint,2,opt,name=impressions,proto3" json:"impressions,omitempty"`                      // Nombre d'impressions après incrément
+	ImpressionId  string                 `protobuf:"bytes,3,opt,name=impression_id,json=impressionId,proto3" json:"impression_id,omitempty"` // Identifiant de l'impression transmise au tracker, pour corréler ses événements
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -313,6 +377,13 @@ func (x *ServeAdResponse) GetImpressions() int64 {
 	return 0
 }
 
+func (x *ServeAdResponse) GetImpressionId() string {
+	if x != nil {
+		return x.ImpressionId
+	}
+	return ""
+}
+
 // Requête pour obtenir le nombre d'impressions d'une publicité
 type GetImpressionCountRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -771,65 +842,978 @@ func (x *ListAdsResponse) GetAds() []*AdResponse {
 	return nil
 }
 
-var File_ad_service_proto protoreflect.FileDescriptor
+// Requête pour réconcilier les compteurs d'impressions avec le tracker
+type ReconcileImpressionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`      // Début de la période (inclus), origine par défaut
+	To            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`          // Fin de la période (exclue), maintenant par défaut
+	Repair        bool                   `protobuf:"varint,3,opt,name=repair,proto3" json:"repair,omitempty"` // Aligner le compteur de l'adserver sur celui du tracker
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_ad_service_proto_rawDesc = "" +
-	"\n" +
-	"\x10ad_service.proto\x12\x05ad.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x84\x01\n" +
-	"\x0fCreateAdRequest\x12\x14\n" +
-	"\x05title\x18\x01 \x01(\tR\x05title\x12 \n" +
-	"\vdescription\x18\x02 \x01(\tR\vdescription\x129\n" +
-	"\n" +
-	"expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"\xc3\x01\n" +
-	"\n" +
-	"AdResponse\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
-	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
-	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x10\n" +
-	"\x03url\x18\x04 \x01(\tR\x03url\x129\n" +
-	"\n" +
-	"expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12 \n" +
-	"\vimpressions\x18\x06 \x01(\x03R\vimpressions\"\x1e\n" +
-	"\fGetAdRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\" \n" +
-	"\x0eServeAdRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"E\n" +
-	"\x0fServeAdResponse\x12\x10\n" +
-	"\x03url\x18\x01 \x01(\tR\x03url\x12 \n" +
-	"\vimpressions\x18\x02 \x01(\x03R\vimpressions\"0\n" +
-	"\x19GetImpressionCountRequest\x12\x13\n" +
-	"\x05ad_id\x18\x01 \x01(\tR\x04adId\">\n" +
-	"\x1aGetImpressionCountResponse\x12 \n" +
-	"\vimpressions\x18\x01 \x01(\x03R\vimpressions\"2\n" +
-	"\x1bIncrementImpressionsRequest\x12\x13\n" +
-	"\x05ad_id\x18\x01 \x01(\tR\x04adId\"@\n" +
-	"\x1cIncrementImpressionsResponse\x12 \n" +
-	"\vimpressions\x18\x01 \x01(\x03R\vimpressions\".\n" +
-	"\x17ResetImpressionsRequest\x12\x13\n" +
-	"\x05ad_id\x18\x01 \x01(\tR\x04adId\"<\n" +
-	"\x18ResetImpressionsResponse\x12 \n" +
-	"\vimpressions\x18\x01 \x01(\x03R\vimpressions\"\x16\n" +
-	"\x14DeleteExpiredRequest\"<\n" +
-	"\x15DeleteExpiredResponse\x12#\n" +
-	"\rdeleted_count\x18\x01 \x01(\x03R\fdeletedCount\"\xb4\x01\n" +
-	"\x0eListAdsRequest\x129\n" +
-	"\x06filter\x18\x01 \x03(\v2!.ad.v1.ListAdsRequest.FilterEntryR\x06filter\x12\x16\n" +
-	"\x06offset\x18\x02 \x01(\x03R\x06offset\x12\x14\n" +
-	"\x05limit\x18\x03 \x01(\x03R\x05limit\x1a9\n" +
-	"\vFilterEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"6\n" +
-	"\x0fListAdsResponse\x12#\n" +
-	"\x03ads\x18\x01 \x03(\v2\x11.ad.v1.AdResponseR\x03ads2\xc4\x04\n" +
-	"\tAdService\x125\n" +
-	"\bCreateAd\x12\x16.ad.v1.CreateAdRequest\x1a\x11.ad.v1.AdResponse\x12/\n" +
-	"\x05GetAd\x12\x13.ad.v1.GetAdRequest\x1a\x11.ad.v1.AdResponse\x128\n" +
-	"\aServeAd\x12\x15.ad.v1.ServeAdRequest\x1a\x16.ad.v1.ServeAdResponse\x12Y\n" +
-	"\x12GetImpressionCount\x12 .ad.v1.GetImpressionCountRequest\x1a!.ad.v1.GetImpressionCountResponse\x12_\n" +
-	"\x14IncrementImpressions\x12\".ad.v1.IncrementImpressionsRequest\x1a#.ad.v1.IncrementImpressionsResponse\x12S\n" +
-	"\x10ResetImpressions\x12\x1e.ad.v1.ResetImpressionsRequest\x1a\x1f.ad.v1.ResetImpressionsResponse\x12J\n" +
-	"\rDeleteExpired\x12\x1b.ad.v1.DeleteExpiredRequest\x1a\x1c.ad.v1.DeleteExpiredResponse\x128\n" +
-	"\aListAds\x12\x15.ad.v1.ListAdsRequest\x1a\x16.ad.v1.ListAdsResponseB\x16Z\x14generated/ad_serviceb\x06proto3"
+func (x *ReconcileImpressionsRequest) Reset() {
+	*x = ReconcileImpressionsRequest{}
+	mi := &file_ad_service_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconcileImpressionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconcileImpressionsRequest) ProtoMessage() {}
+
+func (x *ReconcileImpressionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ad_service_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconcileImpressionsRequest.ProtoReflect.Descriptor instead.
+func (*ReconcileImpressionsRequest) Descriptor() ([]byte, []int) {
+	return file_ad_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ReconcileImpressionsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ReconcileImpressionsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *ReconcileImpressionsRequest) GetRepair() bool {
+	if x != nil {
+		return x.Repair
+	}
+	return false
+}
+
+// Écart entre les compteurs d'impressions d'une publicité
+type ImpressionDrift struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	AdId                string                 `protobuf:"bytes,1,opt,name=ad_id,json=adId,proto3" json:"ad_id,omitempty"`
+	AdserverImpressions int64                  `protobuf:"varint,2,opt,name=adserver_impressions,json=adserverImpressions,proto3" json:"adserver_impressions,omitempty"`
+	TrackerImpressions  int64                  `protobuf:"varint,3,opt,name=tracker_impressions,json=trackerImpressions,proto3" json:"tracker_impressions,omitempty"`
+	Drift               int64                  `protobuf:"varint,4,opt,name=drift,proto3" json:"drift,omitempty"` // adserver - tracker
+	Repaired            bool                   `protobuf:"varint,5,opt,name=repaired,proto3" json:"repaired,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *ImpressionDrift) Reset() {
+	*x = ImpressionDrift{}
+	mi := &file_ad_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImpressionDrift) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImpressionDrift) ProtoMessage() {}
+
+func (x *ImpressionDrift) ProtoReflect() protoreflect.Message {
+	mi := &file_ad_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImpressionDrift.ProtoReflect.Descriptor instead.
+func (*ImpressionDrift) Descriptor() ([]byte, []int) {
+	return file_ad_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ImpressionDrift) GetAdId() string {
+	if x != nil {
+		return x.AdId
+	}
+	return ""
+}
+
+func (x *ImpressionDrift) GetAdserverImpressions() int64 {
+	if x != nil {
+		return x.AdserverImpressions
+	}
+	return 0
+}
+
+func (x *ImpressionDrift) GetTrackerImpressions() int64 {
+	if x != nil {
+		return x.TrackerImpressions
+	}
+	return 0
+}
+
+func (x *ImpressionDrift) GetDrift() int64 {
+	if x != nil {
+		return x.Drift
+	}
+	return 0
+}
+
+func (x *ImpressionDrift) GetRepaired() bool {
+	if x != nil {
+		return x.Repaired
+	}
+	return false
+}
+
+// Réponse de réconciliation : publicités dont les compteurs divergent
+type ReconcileImpressionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Checked       int64                  `protobuf:"varint,1,opt,name=checked,proto3" json:"checked,omitempty"`
+	Drifts        []*ImpressionDrift     `protobuf:"bytes,2,rep,name=drifts,proto3" json:"drifts,omitempty"`
+	Repaired      int64                  `protobuf:"varint,3,opt,name=repaired,proto3" json:"repaired,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReconcileImpressionsResponse) Reset() {
+	*x = ReconcileImpressionsResponse{}
+	mi := &file_ad_service_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconcileImpressionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconcileImpressionsResponse) ProtoMessage() {}
+
+func (x *ReconcileImpressionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ad_service_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconcileImpressionsResponse.ProtoReflect.Descriptor instead.
+func (*ReconcileImpressionsResponse) Descriptor() ([]byte, []int) {
+	return file_ad_service_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ReconcileImpressionsResponse) GetChecked() int64 {
+	if x != nil {
+		return x.Checked
+	}
+	return 0
+}
+
+func (x *ReconcileImpressionsResponse) GetDrifts() []*ImpressionDrift {
+	if x != nil {
+		return x.Drifts
+	}
+	return nil
+}
+
+func (x *ReconcileImpressionsResponse) GetRepaired() int64 {
+	if x != nil {
+		return x.Repaired
+	}
+	return 0
+}
+
+// Requête de vérification détaillée de la santé du service
+type DeepHealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeepHealthRequest) Reset() {
+	*x = DeepHealthRequest{}
+	mi := &file_ad_service_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeepHealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeepHealthRequest) ProtoMessage() {}
+
+func (x *DeepHealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ad_service_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeepHealthRequest.ProtoReflect.Descriptor instead.
+func (*DeepHealthRequest) Descriptor() ([]byte, []int) {
+	return file_ad_service_proto_rawDescGZIP(), []int{18}
+}
+
+// État d'une dépendance lors de la vérification
+type DependencyHealth struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Healthy       bool                   `protobuf:"varint,2,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`     // Cause de l'échec, vide si la dépendance répond
+	Latency       *durationpb.Duration   `protobuf:"bytes,4,opt,name=latency,proto3" json:"latency,omitempty"` // Durée de la vérification
+	CheckedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=checked_at,json=checkedAt,proto3" json:"checked_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DependencyHealth) Reset() {
+	*x = DependencyHealth{}
+	mi := &file_ad_service_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DependencyHealth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DependencyHealth) ProtoMessage() {}
+
+func (x *DependencyHealth) ProtoReflect() protoreflect.Message {
+	mi := &file_ad_service_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DependencyHealth.ProtoReflect.Descriptor instead.
+func (*DependencyHealth) Descriptor() ([]byte, []int) {
+	return file_ad_service_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *DependencyHealth) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DependencyHealth) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *DependencyHealth) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *DependencyHealth) GetLatency() *durationpb.Duration {
+	if x != nil {
+		return x.Latency
+	}
+	return nil
+}
+
+func (x *DependencyHealth) GetCheckedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CheckedAt
+	}
+	return nil
+}
+
+// Réponse avec l'état global et le détail par dépendance
+type DeepHealthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Serving       bool                   `protobuf:"varint,1,opt,name=serving,proto3" json:"serving,omitempty"` // Vrai si toutes les dépendances répondent
+	Dependencies  []*DependencyHealth    `protobuf:"bytes,2,rep,name=dependencies,proto3" json:"dependencies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeepHealthResponse) Reset() {
+	*x = DeepHealthResponse{}
+	mi := &file_ad_service_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeepHealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeepHealthResponse) ProtoMessage() {}
+
+func (x *DeepHealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ad_service_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeepHealthResponse.ProtoReflect.Descriptor instead.
+func (*DeepHealthResponse) Descriptor() ([]byte, []int) {
+	return file_ad_service_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *DeepHealthResponse) GetServing() bool {
+	if x != nil {
+		return x.Serving
+	}
+	return false
+}
+
+func (x *DeepHealthResponse) GetDependencies() []*DependencyHealth {
+	if x != nil {
+		return x.Dependencies
+	}
+	return nil
+}
+
+// Clé d'API, sans son secret
+type APIKey struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Tenant        string                 `protobuf:"bytes,3,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	Role          Role                   `protobuf:"varint,4,opt,name=role,proto3,enum=ad.v1.Role" json:"role,omitempty"`
+	Prefix        string                 `protobuf:"bytes,5,opt,name=prefix,proto3" json:"prefix,omitempty"` // Début du secret, pour identifier la clé
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	RevokedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=revoked_at,json=revokedAt,proto3" json:"revoked_at,omitempty"` // Absent tant que la clé est active
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *APIKey) Reset() {
+	*x = APIKey{}
+	mi := &file_ad_service_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *APIKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*APIKey) ProtoMessage() {}
+
+func (x *APIKey) ProtoReflect() protoreflect.Message {
+	mi := &file_ad_service_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use APIKey.ProtoReflect.Descriptor instead.
+func (*APIKey) Descriptor() ([]byte, []int) {
+	return file_ad_service_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *APIKey) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *APIKey) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *APIKey) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+func (x *APIKey) GetRole() Role {
+	if x != nil {
+		return x.Role
+	}
+	return Role_ROLE_UNSPECIFIED
+}
+
+func (x *APIKey) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *APIKey) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *APIKey) GetRevokedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RevokedAt
+	}
+	return nil
+}
+
+// Requête de création d'une clé d'API
+type CreateAPIKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Tenant        string                 `protobuf:"bytes,2,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	Role          Role                   `protobuf:"varint,3,opt,name=role,proto3,enum=ad.v1.Role" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAPIKeyRequest) Reset() {
+	*x = CreateAPIKeyRequest{}
+	mi := &file_ad_service_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAPIKeyRequest) ProtoMessage() {}
+
+func (x *CreateAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ad_service_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*CreateAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_ad_service_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CreateAPIKeyRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateAPIKeyRequest) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+func (x *CreateAPIKeyRequest) GetRole() Role {
+	if x != nil {
+		return x.Role
+	}
+	return Role_ROLE_UNSPECIFIED
+}
+
+// Réponse de création : le secret n'est communiqué qu'une fois
+type CreateAPIKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           *APIKey                `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Secret        string                 `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAPIKeyResponse) Reset() {
+	*x = CreateAPIKeyResponse{}
+	mi := &file_ad_service_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAPIKeyResponse) ProtoMessage() {}
+
+func (x *CreateAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ad_service_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*CreateAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_ad_service_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CreateAPIKeyResponse) GetKey() *APIKey {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *CreateAPIKeyResponse) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+// Requête de rotation : une nouvelle clé remplace la clé donnée, qui est révoquée
+type RotateAPIKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RotateAPIKeyRequest) Reset() {
+	*x = RotateAPIKeyRequest{}
+	mi := &file_ad_service_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RotateAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RotateAPIKeyRequest) ProtoMessage() {}
+
+func (x *RotateAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ad_service_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RotateAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*RotateAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_ad_service_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *RotateAPIKeyRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Réponse de rotation avec le secret de la nouvelle clé
+type RotateAPIKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           *APIKey                `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Secret        string                 `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RotateAPIKeyResponse) Reset() {
+	*x = RotateAPIKeyResponse{}
+	mi := &file_ad_service_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RotateAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RotateAPIKeyResponse) ProtoMessage() {}
+
+func (x *RotateAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ad_service_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RotateAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*RotateAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_ad_service_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *RotateAPIKeyResponse) GetKey() *APIKey {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *RotateAPIKeyResponse) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+// Requête de révocation d'une clé d'API
+type RevokeAPIKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAPIKeyRequest) Reset() {
+	*x = RevokeAPIKeyRequest{}
+	mi := &file_ad_service_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAPIKeyRequest) ProtoMessage() {}
+
+func (x *RevokeAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ad_service_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*RevokeAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_ad_service_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *RevokeAPIKeyRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Réponse de révocation
+type RevokeAPIKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           *APIKey                `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAPIKeyResponse) Reset() {
+	*x = RevokeAPIKeyResponse{}
+	mi := &file_ad_service_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAPIKeyResponse) ProtoMessage() {}
+
+func (x *RevokeAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ad_service_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*RevokeAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_ad_service_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *RevokeAPIKeyResponse) GetKey() *APIKey {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+// Requête de liste des clés d'API
+type ListAPIKeysRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAPIKeysRequest) Reset() {
+	*x = ListAPIKeysRequest{}
+	mi := &file_ad_service_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAPIKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAPIKeysRequest) ProtoMessage() {}
+
+func (x *ListAPIKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ad_service_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAPIKeysRequest.ProtoReflect.Descriptor instead.
+func (*ListAPIKeysRequest) Descriptor() ([]byte, []int) {
+	return file_ad_service_proto_rawDescGZIP(), []int{28}
+}
+
+// Réponse de liste des clés d'API, révoquées comprises
+type ListAPIKeysResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []*APIKey              `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAPIKeysResponse) Reset() {
+	*x = ListAPIKeysResponse{}
+	mi := &file_ad_service_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAPIKeysResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAPIKeysResponse) ProtoMessage() {}
+
+func (x *ListAPIKeysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ad_service_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAPIKeysResponse.ProtoReflect.Descriptor instead.
+func (*ListAPIKeysResponse) Descriptor() ([]byte, []int) {
+	return file_ad_service_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ListAPIKeysResponse) GetKeys() []*APIKey {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+var File_ad_service_proto protoreflect.FileDescriptor
+
+const file_ad_service_proto_rawDesc = "" +
+	"\n" +
+	"\x10ad_service.proto\x12\x05ad.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x84\x01\n" +
+	"\x0fCreateAdRequest\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x129\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"\xdb\x01\n" +
+	"\n" +
+	"AdResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x10\n" +
+	"\x03url\x18\x04 \x01(\tR\x03url\x129\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12 \n" +
+	"\vimpressions\x18\x06 \x01(\x03R\vimpressions\x12\x16\n" +
+	"\x06tenant\x18\a \x01(\tR\x06tenant\"\x1e\n" +
+	"\fGetAdRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\" \n" +
+	"\x0eServeAdRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"j\n" +
+	"\x0fServeAdResponse\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12 \n" +
+	"\vimpressions\x18\x02 \x01(\x03R\vimpressions\x12#\n" +
+	"\rimpression_id\x18\x03 \x01(\tR\fimpressionId\"0\n" +
+	"\x19GetImpressionCountRequest\x12\x13\n" +
+	"\x05ad_id\x18\x01 \x01(\tR\x04adId\">\n" +
+	"\x1aGetImpressionCountResponse\x12 \n" +
+	"\vimpressions\x18\x01 \x01(\x03R\vimpressions\"2\n" +
+	"\x1bIncrementImpressionsRequest\x12\x13\n" +
+	"\x05ad_id\x18\x01 \x01(\tR\x04adId\"@\n" +
+	"\x1cIncrementImpressionsResponse\x12 \n" +
+	"\vimpressions\x18\x01 \x01(\x03R\vimpressions\".\n" +
+	"\x17ResetImpressionsRequest\x12\x13\n" +
+	"\x05ad_id\x18\x01 \x01(\tR\x04adId\"<\n" +
+	"\x18ResetImpressionsResponse\x12 \n" +
+	"\vimpressions\x18\x01 \x01(\x03R\vimpressions\"\x16\n" +
+	"\x14DeleteExpiredRequest\"<\n" +
+	"\x15DeleteExpiredResponse\x12#\n" +
+	"\rdeleted_count\x18\x01 \x01(\x03R\fdeletedCount\"\xb4\x01\n" +
+	"\x0eListAdsRequest\x129\n" +
+	"\x06filter\x18\x01 \x03(\v2!.ad.v1.ListAdsRequest.FilterEntryR\x06filter\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x03R\x06offset\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x03R\x05limit\x1a9\n" +
+	"\vFilterEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"6\n" +
+	"\x0fListAdsResponse\x12#\n" +
+	"\x03ads\x18\x01 \x03(\v2\x11.ad.v1.AdResponseR\x03ads\"\x91\x01\n" +
+	"\x1bReconcileImpressionsRequest\x12.\n" +
+	"\x04from\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x16\n" +
+	"\x06repair\x18\x03 \x01(\bR\x06repair\"\xbc\x01\n" +
+	"\x0fImpressionDrift\x12\x13\n" +
+	"\x05ad_id\x18\x01 \x01(\tR\x04adId\x121\n" +
+	"\x14adserver_impressions\x18\x02 \x01(\x03R\x13adserverImpressions\x12/\n" +
+	"\x13tracker_impressions\x18\x03 \x01(\x03R\x12trackerImpressions\x12\x14\n" +
+	"\x05drift\x18\x04 \x01(\x03R\x05drift\x12\x1a\n" +
+	"\brepaired\x18\x05 \x01(\bR\brepaired\"\x84\x01\n" +
+	"\x1cReconcileImpressionsResponse\x12\x18\n" +
+	"\achecked\x18\x01 \x01(\x03R\achecked\x12.\n" +
+	"\x06drifts\x18\x02 \x03(\v2\x16.ad.v1.ImpressionDriftR\x06drifts\x12\x1a\n" +
+	"\brepaired\x18\x03 \x01(\x03R\brepaired\"\x13\n" +
+	"\x11DeepHealthRequest\"\xc6\x01\n" +
+	"\x10DependencyHealth\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\ahealthy\x18\x02 \x01(\bR\ahealthy\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x123\n" +
+	"\alatency\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\alatency\x129\n" +
+	"\n" +
+	"checked_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcheckedAt\"k\n" +
+	"\x12DeepHealthResponse\x12\x18\n" +
+	"\aserving\x18\x01 \x01(\bR\aserving\x12;\n" +
+	"\fdependencies\x18\x02 \x03(\v2\x17.ad.v1.DependencyHealthR\fdependencies\"\xf3\x01\n" +
+	"\x06APIKey\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06tenant\x18\x03 \x01(\tR\x06tenant\x12\x1f\n" +
+	"\x04role\x18\x04 \x01(\x0e2\v.ad.v1.RoleR\x04role\x12\x16\n" +
+	"\x06prefix\x18\x05 \x01(\tR\x06prefix\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"revoked_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\trevokedAt\"b\n" +
+	"\x13CreateAPIKeyRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06tenant\x18\x02 \x01(\tR\x06tenant\x12\x1f\n" +
+	"\x04role\x18\x03 \x01(\x0e2\v.ad.v1.RoleR\x04role\"O\n" +
+	"\x14CreateAPIKeyResponse\x12\x1f\n" +
+	"\x03key\x18\x01 \x01(\v2\r.ad.v1.APIKeyR\x03key\x12\x16\n" +
+	"\x06secret\x18\x02 \x01(\tR\x06secret\"%\n" +
+	"\x13RotateAPIKeyRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"O\n" +
+	"\x14RotateAPIKeyResponse\x12\x1f\n" +
+	"\x03key\x18\x01 \x01(\v2\r.ad.v1.APIKeyR\x03key\x12\x16\n" +
+	"\x06secret\x18\x02 \x01(\tR\x06secret\"%\n" +
+	"\x13RevokeAPIKeyRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"7\n" +
+	"\x14RevokeAPIKeyResponse\x12\x1f\n" +
+	"\x03key\x18\x01 \x01(\v2\r.ad.v1.APIKeyR\x03key\"\x14\n" +
+	"\x12ListAPIKeysRequest\"8\n" +
+	"\x13ListAPIKeysResponse\x12!\n" +
+	"\x04keys\x18\x01 \x03(\v2\r.ad.v1.APIKeyR\x04keys*R\n" +
+	"\x04Role\x12\x14\n" +
+	"\x10ROLE_UNSPECIFIED\x10\x00\x12\x0e\n" +
+	"\n" +
+	"ROLE_ADMIN\x10\x01\x12\x13\n" +
+	"\x0fROLE_ADVERTISER\x10\x02\x12\x0f\n" +
+	"\vROLE_READER\x10\x032\xad\v\n" +
+	"\tAdService\x12I\n" +
+	"\bCreateAd\x12\x16.ad.v1.CreateAdRequest\x1a\x11.ad.v1.AdResponse\"\x12\x82\xd3\xe4\x93\x02\f:\x01*\"\a/v1/ads\x12E\n" +
+	"\x05GetAd\x12\x13.ad.v1.GetAdRequest\x1a\x11.ad.v1.AdResponse\"\x14\x82\xd3\xe4\x93\x02\x0e\x12\f/v1/ads/{id}\x12T\n" +
+	"\aServeAd\x12\x15.ad.v1.ServeAdRequest\x1a\x16.ad.v1.ServeAdResponse\"\x1a\x82\xd3\xe4\x93\x02\x14\"\x12/v1/ads/{id}:serve\x12~\n" +
+	"\x12GetImpressionCount\x12 .ad.v1.GetImpressionCountRequest\x1a!.ad.v1.GetImpressionCountResponse\"#\x82\xd3\xe4\x93\x02\x1d\x12\x1b/v1/ads/{ad_id}/impressions\x12\x8e\x01\n" +
+	"\x14IncrementImpressions\x12\".ad.v1.IncrementImpressionsRequest\x1a#.ad.v1.IncrementImpressionsResponse\"-\x82\xd3\xe4\x93\x02'\"%/v1/ads/{ad_id}/impressions:increment\x12~\n" +
+	"\x10ResetImpressions\x12\x1e.ad.v1.ResetImpressionsRequest\x1a\x1f.ad.v1.ResetImpressionsResponse\")\x82\xd3\xe4\x93\x02#\"!/v1/ads/{ad_id}/impressions:reset\x12i\n" +
+	"\rDeleteExpired\x12\x1b.ad.v1.DeleteExpiredRequest\x1a\x1c.ad.v1.DeleteExpiredResponse\"\x1d\x82\xd3\xe4\x93\x02\x17\"\x15/v1/ads:deleteExpired\x12I\n" +
+	"\aListAds\x12\x15.ad.v1.ListAdsRequest\x1a\x16.ad.v1.ListAdsResponse\"\x0f\x82\xd3\xe4\x93\x02\t\x12\a/v1/ads\x12\x85\x01\n" +
+	"\x14ReconcileImpressions\x12\".ad.v1.ReconcileImpressionsRequest\x1a#.ad.v1.ReconcileImpressionsResponse\"$\x82\xd3\xe4\x93\x02\x1e:\x01*\"\x19/v1/impressions:reconcile\x12U\n" +
+	"\n" +
+	"DeepHealth\x12\x18.ad.v1.DeepHealthRequest\x1a\x19.ad.v1.DeepHealthResponse\"\x12\x82\xd3\xe4\x93\x02\f\x12\n" +
+	"/v1/health\x12`\n" +
+	"\fCreateAPIKey\x12\x1a.ad.v1.CreateAPIKeyRequest\x1a\x1b.ad.v1.CreateAPIKeyResponse\"\x17\x82\xd3\xe4\x93\x02\x11:\x01*\"\f/v1/api-keys\x12i\n" +
+	"\fRotateAPIKey\x12\x1a.ad.v1.RotateAPIKeyRequest\x1a\x1b.ad.v1.RotateAPIKeyResponse\" \x82\xd3\xe4\x93\x02\x1a\"\x18/v1/api-keys/{id}:rotate\x12i\n" +
+	"\fRevokeAPIKey\x12\x1a.ad.v1.RevokeAPIKeyRequest\x1a\x1b.ad.v1.RevokeAPIKeyResponse\" \x82\xd3\xe4\x93\x02\x1a\"\x18/v1/api-keys/{id}:revoke\x12Z\n" +
+	"\vListAPIKeys\x12\x19.ad.v1.ListAPIKeysRequest\x1a\x1a.ad.v1.ListAPIKeysResponse\"\x14\x82\xd3\xe4\x93\x02\x0e\x12\f/v1/api-keysB\x16Z\x14generated/ad_serviceb\x06proto3"
 
 var (
 	file_ad_service_proto_rawDescOnce sync.Once
@@ -843,52 +1827,96 @@ func file_ad_service_proto_rawDescGZIP() []byte {
 	return file_ad_service_proto_rawDescData
 }
 
-var file_ad_service_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_ad_service_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_ad_service_proto_msgTypes = make([]protoimpl.MessageInfo, 31)
 var file_ad_service_proto_goTypes = []any{
-	(*CreateAdRequest)(nil),              // 0: ad.v1.CreateAdRequest
-	(*AdResponse)(nil),                   // 1: ad.v1.AdResponse
-	(*GetAdRequest)(nil),                 // 2: ad.v1.GetAdRequest
-	(*ServeAdRequest)(nil),               // 3: ad.v1.ServeAdRequest
-	(*ServeAdResponse)(nil),              // 4: ad.v1.ServeAdResponse
-	(*GetImpressionCountRequest)(nil),    // 5: ad.v1.GetImpressionCountRequest
-	(*GetImpressionCountResponse)(nil),   // 6: ad.v1.GetImpressionCountResponse
-	(*IncrementImpressionsRequest)(nil),  // 7: ad.v1.IncrementImpressionsRequest
-	(*IncrementImpressionsResponse)(nil), // 8: ad.v1.IncrementImpressionsResponse
-	(*ResetImpressionsRequest)(nil),      // 9: ad.v1.ResetImpressionsRequest
-	(*ResetImpressionsResponse)(nil),     // 10: ad.v1.ResetImpressionsResponse
-	(*DeleteExpiredRequest)(nil),         // 11: ad.v1.DeleteExpiredRequest
-	(*DeleteExpiredResponse)(nil),        // 12: ad.v1.DeleteExpiredResponse
-	(*ListAdsRequest)(nil),               // 13: ad.v1.ListAdsRequest
-	(*ListAdsResponse)(nil),              // 14: ad.v1.ListAdsResponse
-	nil,                                  // 15: ad.v1.ListAdsRequest.FilterEntry
-	(*timestamppb.Timestamp)(nil),        // 16: google.protobuf.Timestamp
+	(Role)(0),                            // 0: ad.v1.Role
+	(*CreateAdRequest)(nil),              // 1: ad.v1.CreateAdRequest
+	(*AdResponse)(nil),                   // 2: ad.v1.AdResponse
+	(*GetAdRequest)(nil),                 // 3: ad.v1.GetAdRequest
+	(*ServeAdRequest)(nil),               // 4: ad.v1.ServeAdRequest
+	(*ServeAdResponse)(nil),              // 5: ad.v1.ServeAdResponse
+	(*GetImpressionCountRequest)(nil),    // 6: ad.v1.GetImpressionCountRequest
+	(*GetImpressionCountResponse)(nil),   // 7: ad.v1.GetImpressionCountResponse
+	(*IncrementImpressionsRequest)(nil),  // 8: ad.v1.IncrementImpressionsRequest
+	(*IncrementImpressionsResponse)(nil), // 9: ad.v1.IncrementImpressionsResponse
+	(*ResetImpressionsRequest)(nil),      // 10: ad.v1.ResetImpressionsRequest
+	(*ResetImpressionsResponse)(nil),     // 11: ad.v1.ResetImpressionsResponse
+	(*DeleteExpiredRequest)(nil),         // 12: ad.v1.DeleteExpiredRequest
+	(*DeleteExpiredResponse)(nil),        // 13: ad.v1.DeleteExpiredResponse
+	(*ListAdsRequest)(nil),               // 14: ad.v1.ListAdsRequest
+	(*ListAdsResponse)(nil),              // 15: ad.v1.ListAdsResponse
+	(*ReconcileImpressionsRequest)(nil),  // 16: ad.v1.ReconcileImpressionsRequest
+	(*ImpressionDrift)(nil),              // 17: ad.v1.ImpressionDrift
+	(*ReconcileImpressionsResponse)(nil), // 18: ad.v1.ReconcileImpressionsResponse
+	(*DeepHealthRequest)(nil),            // 19: ad.v1.DeepHealthRequest
+	(*DependencyHealth)(nil),             // 20: ad.v1.DependencyHealth
+	(*DeepHealthResponse)(nil),           // 21: ad.v1.DeepHealthResponse
+	(*APIKey)(nil),                       // 22: ad.v1.APIKey
+	(*CreateAPIKeyRequest)(nil),          // 23: ad.v1.CreateAPIKeyRequest
+	(*CreateAPIKeyResponse)(nil),         // 24: ad.v1.CreateAPIKeyResponse
+	(*RotateAPIKeyRequest)(nil),          // 25: ad.v1.RotateAPIKeyRequest
+	(*RotateAPIKeyResponse)(nil),         // 26: ad.v1.RotateAPIKeyResponse
+	(*RevokeAPIKeyRequest)(nil),          // 27: ad.v1.RevokeAPIKeyRequest
+	(*RevokeAPIKeyResponse)(nil),         // 28: ad.v1.RevokeAPIKeyResponse
+	(*ListAPIKeysRequest)(nil),           // 29: ad.v1.ListAPIKeysRequest
+	(*ListAPIKeysResponse)(nil),          // 30: ad.v1.ListAPIKeysResponse
+	nil,                                  // 31: ad.v1.ListAdsRequest.FilterEntry
+	(*timestamppb.Timestamp)(nil),        // 32: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),          // 33: google.protobuf.Duration
 }
 var file_ad_service_proto_depIdxs = []int32{
-	16, // 0: ad.v1.CreateAdRequest.expires_at:type_name -> google.protobuf.Timestamp
-	16, // 1: ad.v1.AdResponse.expires_at:type_name -> google.protobuf.Timestamp
-	15, // 2: ad.v1.ListAdsRequest.filter:type_name -> ad.v1.ListAdsRequest.FilterEntry
-	1,  // 3: ad.v1.ListAdsResponse.ads:type_name -> ad.v1.AdResponse
-	0,  // 4: ad.v1.AdService.CreateAd:input_type -> ad.v1.CreateAdRequest
-	2,  // 5: ad.v1.AdService.GetAd:input_type -> ad.v1.GetAdRequest
-	3,  // 6: ad.v1.AdService.ServeAd:input_type -> ad.v1.ServeAdRequest
-	5,  // 7: ad.v1.AdService.GetImpressionCount:input_type -> ad.v1.GetImpressionCountRequest
-	7,  // 8: ad.v1.AdService.IncrementImpressions:input_type -> ad.v1.IncrementImpressionsRequest
-	9,  // 9: ad.v1.AdService.ResetImpressions:input_type -> ad.v1.ResetImpressionsRequest
-	11, // 10: ad.v1.AdService.DeleteExpired:input_type -> ad.v1.DeleteExpiredRequest
-	13, // 11: ad.v1.AdService.ListAds:input_type -> ad.v1.ListAdsRequest
-	1,  // 12: ad.v1.AdService.CreateAd:output_type -> ad.v1.AdResponse
-	1,  // 13: ad.v1.AdService.GetAd:output_type -> ad.v1.AdResponse
-	4,  // 14: ad.v1.AdService.ServeAd:output_type -> ad.v1.ServeAdResponse
-	6,  // 15: ad.v1.AdService.GetImpressionCount:output_type -> ad.v1.GetImpressionCountResponse
-	8,  // 16: ad.v1.AdService.IncrementImpressions:output_type -> ad.v1.IncrementImpressionsResponse
-	10, // 17: ad.v1.AdService.ResetImpressions:output_type -> ad.v1.ResetImpressionsResponse
-	12, // 18: ad.v1.AdService.DeleteExpired:output_type -> ad.v1.DeleteExpiredResponse
-	14, // 19: ad.v1.AdService.ListAds:output_type -> ad.v1.ListAdsResponse
-	12, // [12:20] is the sub-list for method output_type
-	4,  // [4:12] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+	32, // 0: ad.v1.CreateAdRequest.expires_at:type_name -> google.protobuf.Timestamp
+	32, // 1: ad.v1.AdResponse.expires_at:type_name -> google.protobuf.Timestamp
+	31, // 2: ad.v1.ListAdsRequest.filter:type_name -> ad.v1.ListAdsRequest.FilterEntry
+	2,  // 3: ad.v1.ListAdsResponse.ads:type_name -> ad.v1.AdResponse
+	32, // 4: ad.v1.ReconcileImpressionsRequest.from:type_name -> google.protobuf.Timestamp
+	32, // 5: ad.v1.ReconcileImpressionsRequest.to:type_name -> google.protobuf.Timestamp
+	17, // 6: ad.v1.ReconcileImpressionsResponse.drifts:type_name -> ad.v1.ImpressionDrift
+	33, // 7: ad.v1.DependencyHealth.latency:type_name -> google.protobuf.Duration
+	32, // 8: ad.v1.DependencyHealth.checked_at:type_name -> google.protobuf.Timestamp
+	20, // 9: ad.v1.DeepHealthResponse.dependencies:type_name -> ad.v1.DependencyHealth
+	0,  // 10: ad.v1.APIKey.role:type_name -> ad.v1.Role
+	32, // 11: ad.v1.APIKey.created_at:type_name -> google.protobuf.Timestamp
+	32, // 12: ad.v1.APIKey.revoked_at:type_name -> google.protobuf.Timestamp
+	0,  // 13: ad.v1.CreateAPIKeyRequest.role:type_name -> ad.v1.Role
+	22, // 14: ad.v1.CreateAPIKeyResponse.key:type_name -> ad.v1.APIKey
+	22, // 15: ad.v1.RotateAPIKeyResponse.key:type_name -> ad.v1.APIKey
+	22, // 16: ad.v1.RevokeAPIKeyResponse.key:type_name -> ad.v1.APIKey
+	22, // 17: ad.v1.ListAPIKeysResponse.keys:type_name -> ad.v1.APIKey
+	1,  // 18: ad.v1.AdService.CreateAd:input_type -> ad.v1.CreateAdRequest
+	3,  // 19: ad.v1.AdService.GetAd:input_type -> ad.v1.GetAdRequest
+	4,  // 20: ad.v1.AdService.ServeAd:input_type -> ad.v1.ServeAdRequest
+	6,  // 21: ad.v1.AdService.GetImpressionCount:input_type -> ad.v1.GetImpressionCountRequest
+	8,  // 22: ad.v1.AdService.IncrementImpressions:input_type -> ad.v1.IncrementImpressionsRequest
+	10, // 23: ad.v1.AdService.ResetImpressions:input_type -> ad.v1.ResetImpressionsRequest
+	12, // 24: ad.v1.AdService.DeleteExpired:input_type -> ad.v1.DeleteExpiredRequest
+	14, // 25: ad.v1.AdService.ListAds:input_type -> ad.v1.ListAdsRequest
+	16, // 26: ad.v1.AdService.ReconcileImpressions:input_type -> ad.v1.ReconcileImpressionsRequest
+	19, // 27: ad.v1.AdService.DeepHealth:input_type -> ad.v1.DeepHealthRequest
+	23, // 28: ad.v1.AdService.CreateAPIKey:input_type -> ad.v1.CreateAPIKeyRequest
+	25, // 29: ad.v1.AdService.RotateAPIKey:input_type -> ad.v1.RotateAPIKeyRequest
+	27, // 30: ad.v1.AdService.RevokeAPIKey:input_type -> ad.v1.RevokeAPIKeyRequest
+	29, // 31: ad.v1.AdService.ListAPIKeys:input_type -> ad.v1.ListAPIKeysRequest
+	2,  // 32: ad.v1.AdService.CreateAd:output_type -> ad.v1.AdResponse
+	2,  // 33: ad.v1.AdService.GetAd:output_type -> ad.v1.AdResponse
+	5,  // 34: ad.v1.AdService.ServeAd:output_type -> ad.v1.ServeAdResponse
+	7,  // 35: ad.v1.AdService.GetImpressionCount:output_type -> ad.v1.GetImpressionCountResponse
+	9,  // 36: ad.v1.AdService.IncrementImpressions:output_type -> ad.v1.IncrementImpressionsResponse
+	11, // 37: ad.v1.AdService.ResetImpressions:output_type -> ad.v1.ResetImpressionsResponse
+	13, // 38: ad.v1.AdService.DeleteExpired:output_type -> ad.v1.DeleteExpiredResponse
+	15, // 39: ad.v1.AdService.ListAds:output_type -> ad.v1.ListAdsResponse
+	18, // 40: ad.v1.AdService.ReconcileImpressions:output_type -> ad.v1.ReconcileImpressionsResponse
+	21, // 41: ad.v1.AdService.DeepHealth:output_type -> ad.v1.DeepHealthResponse
+	24, // 42: ad.v1.AdService.CreateAPIKey:output_type -> ad.v1.CreateAPIKeyResponse
+	26, // 43: ad.v1.AdService.RotateAPIKey:output_type -> ad.v1.RotateAPIKeyResponse
+	28, // 44: ad.v1.AdService.RevokeAPIKey:output_type -> ad.v1.RevokeAPIKeyResponse
+	30, // 45: ad.v1.AdService.ListAPIKeys:output_type -> ad.v1.ListAPIKeysResponse
+	32, // [32:46] is the sub-list for method output_type
+	18, // [18:32] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
 }
 
 func init() { file_ad_service_proto_init() }
@@ -901,13 +1929,14 @@ func file_ad_service_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ad_service_proto_rawDesc), len(file_ad_service_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   16,
+			NumEnums:      1,
+			NumMessages:   31,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_ad_service_proto_goTypes,
 		DependencyIndexes: file_ad_service_proto_depIdxs,
+		EnumInfos:         file_ad_service_proto_enumTypes,
 		MessageInfos:      file_ad_service_proto_msgTypes,
 	}.Build()
 	File_ad_service_proto = out.File
@@ -29,13 +29,20 @@ const (
 	AdService_ResetImpressions_FullMethodName     = "/ad.v1.AdService/ResetImpressions"
 	AdService_DeleteExpired_FullMethodName        = "/ad.v1.AdService/DeleteExpired"
 	AdService_ListAds_FullMethodName              = "/ad.v1.AdService/ListAds"
+	AdService_ReconcileImpressions_FullMethodName = "/ad.v1.AdService/ReconcileImpressions"
+	AdService_DeepHealth_FullMethodName           = "/ad.v1.AdService/DeepHealth"
+	AdService_CreateAPIKey_FullMethodName         = "/ad.v1.AdService/CreateAPIKey"
+	AdService_RotateAPIKey_FullMethodName         = "/ad.v1.AdService/RotateAPIKey"
+	AdService_RevokeAPIKey_FullMethodName         = "/ad.v1.AdService/RevokeAPIKey"
+	AdService_ListAPIKeys_FullMethodName          = "/ad.v1.AdService/ListAPIKeys"
 )
 
 // AdServiceClient is the client API for AdService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 //
-// Service principal de gestion des publicités
+// Service principal de gestion des publicités.
+// Les options google.api.http exposent chaque méthode en REST/JSON via la passerelle.
 type AdServiceClient interface {
 	CreateAd(ctx context.Context, in *CreateAdRequest, opts ...grpc.CallOption) (*AdResponse, error)
 	GetAd(ctx context.Context, in *GetAdRequest, opts ...grpc.CallOption) (*AdResponse, error)
@@ -44,7 +51,14 @@ type AdServiceClient interface {
 	IncrementImpressions(ctx context.Context, in *IncrementImpressionsRequest, opts ...grpc.CallOption) (*IncrementImpressionsResponse, error)
 	ResetImpressions(ctx context.Context, in *ResetImpressionsRequest, opts ...grpc.CallOption) (*ResetImpressionsResponse, error)
 	DeleteExpired(ctx context.Context, in *DeleteExpiredRequest, opts ...grpc.CallOption) (*DeleteExpiredResponse, error)
+	// Les filtres sont passés en paramètres de requête : ?filter[title]=...&offset=0&limit=10
 	ListAds(ctx context.Context, in *ListAdsRequest, opts ...grpc.CallOption) (*ListAdsResponse, error)
+	ReconcileImpressions(ctx context.Context, in *ReconcileImpressionsRequest, opts ...grpc.CallOption) (*ReconcileImpressionsResponse, error)
+	DeepHealth(ctx context.Context, in *DeepHealthRequest, opts ...grpc.CallOption) (*DeepHealthResponse, error)
+	CreateAPIKey(ctx context.Context, in *CreateAPIKeyRequest, opts ...grpc.CallOption) (*CreateAPIKeyResponse, error)
+	RotateAPIKey(ctx context.Context, in *RotateAPIKeyRequest, opts ...grpc.CallOption) (*RotateAPIKeyResponse, error)
+	RevokeAPIKey(ctx context.Context, in *RevokeAPIKeyRequest, opts ...grpc.CallOption) (*RevokeAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context, in *ListAPIKeysRequest, opts ...grpc.CallOption) (*ListAPIKeysResponse, error)
 }
 
 type adServiceClient struct {
@@ -135,11 +149,72 @@ func (c *adServiceClient) ListAds(ctx context.Context, in *ListAdsRequest, opts
 	return out, nil
 }
 
+func (c *adServiceClient) ReconcileImpressions(ctx context.Context, in *ReconcileImpressionsRequest, opts ...grpc.CallOption) (*ReconcileImpressionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReconcileImpressionsResponse)
+	err := c.cc.Invoke(ctx, AdService_ReconcileImpressions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) DeepHealth(ctx context.Context, in *DeepHealthRequest, opts ...grpc.CallOption) (*DeepHealthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeepHealthResponse)
+	err := c.cc.Invoke(ctx, AdService_DeepHealth_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) CreateAPIKey(ctx context.Context, in *CreateAPIKeyRequest, opts ...grpc.CallOption) (*CreateAPIKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateAPIKeyResponse)
+	err := c.cc.Invoke(ctx, AdService_CreateAPIKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) RotateAPIKey(ctx context.Context, in *RotateAPIKeyRequest, opts ...grpc.CallOption) (*RotateAPIKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RotateAPIKeyResponse)
+	err := c.cc.Invoke(ctx, AdService_RotateAPIKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) RevokeAPIKey(ctx context.Context, in *RevokeAPIKeyRequest, opts ...grpc.CallOption) (*RevokeAPIKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeAPIKeyResponse)
+	err := c.cc.Invoke(ctx, AdService_RevokeAPIKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ListAPIKeys(ctx context.Context, in *ListAPIKeysRequest, opts ...grpc.CallOption) (*ListAPIKeysResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAPIKeysResponse)
+	err := c.cc.Invoke(ctx, AdService_ListAPIKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AdServiceServer is the server API for AdService service.
 // All implementations must embed UnimplementedAdServiceServer
 // for forward compatibility.
 //
-// Service principal de gestion des publicités
+// Service principal de gestion des publicités.
+// Les options google.api.http exposent chaque méthode en REST/JSON via la passerelle.
 type AdServiceServer interface {
 	CreateAd(context.Context, *CreateAdRequest) (*AdResponse, error)
 	GetAd(context.Context, *GetAdRequest) (*AdResponse, error)
@@ -148,7 +223,14 @@ type AdServiceServer interface {
 	IncrementImpressions(context.Context, *IncrementImpressionsRequest) (*IncrementImpressionsResponse, error)
 	ResetImpressions(context.Context, *ResetImpressionsRequest) (*ResetImpressionsResponse, error)
 	DeleteExpired(context.Context, *DeleteExpiredRequest) (*DeleteExpiredResponse, error)
+	// Les filtres sont passés en paramètres de requête : ?filter[title]=...&offset=0&limit=10
 	ListAds(context.Context, *ListAdsRequest) (*ListAdsResponse, error)
+	ReconcileImpressions(context.Context, *ReconcileImpressionsRequest) (*ReconcileImpressionsResponse, error)
+	DeepHealth(context.Context, *DeepHealthRequest) (*DeepHealthResponse, error)
+	CreateAPIKey(context.Context, *CreateAPIKeyRequest) (*CreateAPIKeyResponse, error)
+	RotateAPIKey(context.Context, *RotateAPIKeyRequest) (*RotateAPIKeyResponse, error)
+	RevokeAPIKey(context.Context, *RevokeAPIKeyRequest) (*RevokeAPIKeyResponse, error)
+	ListAPIKeys(context.Context, *ListAPIKeysRequest) (*ListAPIKeysResponse, error)
 	mustEmbedUnimplementedAdServiceServer()
 }
 
@@ -183,6 +265,24 @@ func (UnimplementedAdServiceServer) DeleteExpired(context.Context, *DeleteExpire
 func (UnimplementedAdServiceServer) ListAds(context.Context, *ListAdsRequest) (*ListAdsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListAds not implemented")
 }
+func (UnimplementedAdServiceServer) ReconcileImpressions(context.Context, *ReconcileImpressionsRequest) (*ReconcileImpressionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReconcileImpressions not implemented")
+}
+func (UnimplementedAdServiceServer) DeepHealth(context.Context, *DeepHealthRequest) (*DeepHealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeepHealth not implemented")
+}
+func (UnimplementedAdServiceServer) CreateAPIKey(context.Context, *CreateAPIKeyRequest) (*CreateAPIKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAPIKey not implemented")
+}
+func (UnimplementedAdServiceServer) RotateAPIKey(context.Context, *RotateAPIKeyRequest) (*RotateAPIKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RotateAPIKey not implemented")
+}
+func (UnimplementedAdServiceServer) RevokeAPIKey(context.Context, *RevokeAPIKeyRequest) (*RevokeAPIKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeAPIKey not implemented")
+}
+func (UnimplementedAdServiceServer) ListAPIKeys(context.Context, *ListAPIKeysRequest) (*ListAPIKeysResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAPIKeys not implemented")
+}
 func (UnimplementedAdServiceServer) mustEmbedUnimplementedAdServiceServer() {}
 func (UnimplementedAdServiceServer) testEmbeddedByValue()                   {}
 
@@ -348,6 +448,114 @@ func _AdService_ListAds_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AdService_ReconcileImpressions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReconcileImpressionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).ReconcileImpressions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_ReconcileImpressions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).ReconcileImpressions(ctx, req.(*ReconcileImpressionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_DeepHealth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeepHealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).DeepHealth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_DeepHealth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).DeepHealth(ctx, req.(*DeepHealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_CreateAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAPIKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).CreateAPIKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_CreateAPIKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).CreateAPIKey(ctx, req.(*CreateAPIKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_RotateAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateAPIKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).RotateAPIKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_RotateAPIKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).RotateAPIKey(ctx, req.(*RotateAPIKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_RevokeAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeAPIKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).RevokeAPIKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_RevokeAPIKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).RevokeAPIKey(ctx, req.(*RevokeAPIKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_ListAPIKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAPIKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).ListAPIKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_ListAPIKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).ListAPIKeys(ctx, req.(*ListAPIKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AdService_ServiceDesc is the grpc.ServiceDesc for AdService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -387,6 +595,30 @@ var AdService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListAds",
 			Handler:    _AdService_ListAds_Handler,
 		},
+		{
+			MethodName: "ReconcileImpressions",
+			Handler:    _AdService_ReconcileImpressions_Handler,
+		},
+		{
+			MethodName: "DeepHealth",
+			Handler:    _AdService_DeepHealth_Handler,
+		},
+		{
+			MethodName: "CreateAPIKey",
+			Handler:    _AdService_CreateAPIKey_Handler,
+		},
+		{
+			MethodName: "RotateAPIKey",
+			Handler:    _AdService_RotateAPIKey_Handler,
+		},
+		{
+			MethodName: "RevokeAPIKey",
+			Handler:    _AdService_RevokeAPIKey_Handler,
+		},
+		{
+			MethodName: "ListAPIKeys",
+			Handler:    _AdService_ListAPIKeys_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "ad_service.proto",
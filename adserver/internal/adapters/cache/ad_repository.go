@@ -0,0 +1,195 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"adserver/internal/adapters/metrics"
+	"adserver/internal/domain"
+	"adserver/internal/ports/out"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// keyPrefix préfixe les clés des publicités dans Dragonfly
+const keyPrefix = "adcache:"
+
+// Options règle le cache des publicités
+type Options struct {
+	Size   int                   // Nombre maximal de publicités en mémoire
+	TTL    time.Duration         // Durée de vie d'une entrée, bornée par l'expiration de la publicité
+	Client redis.UniversalClient // Second niveau partagé entre réplicas, nil pour le désactiver
+}
+
+// adRepository décore un AdRepository d'un cache en lecture (read-through) de GetByID :
+// un LRU en mémoire, puis Dragonfly s'il est configuré, puis le repository décoré.
+// Les lectures concurrentes d'une même publicité absente des deux niveaux n'interrogent
+// le repository qu'une fois (singleflight).
+//
+// Cohérence : le compteur d'impressions d'une entrée est mis à jour par IncrementImpressions
+// dans le cache local de la réplica, mais peut retarder d'au plus TTL ailleurs (autres
+// réplicas, Dragonfly). ResetImpressions et AdjustImpressions invalident les deux niveaux, et
+// aucune entrée ne survit à l'expiration de sa publicité.
+type adRepository struct {
+	out.AdRepository
+	local  *lru
+	client redis.UniversalClient
+	ttl    time.Duration
+	group  singleflight.Group
+	clock  out.Clock
+	logger *slog.Logger
+}
+
+// NewAdRepository retourne repo décoré d'un cache de ses lectures par ID
+func NewAdRepository(repo out.AdRepository, opts Options, clock out.Clock, logger *slog.Logger) out.AdRepository {
+	return &adRepository{
+		AdRepository: repo,
+		local:        newLRU(opts.Size),
+		client:       opts.Client,
+		ttl:          opts.TTL,
+		clock:        clock,
+		logger:       logger.With("component", "AdCache"),
+	}
+}
+
+// GetByID retourne la publicité depuis le cache, ou la charge et la met en cache.
+// Comme le repository décoré, elle retourne nil,nil pour une publicité absente ou
+// appartenant à un autre locataire ; les absences ne sont pas mises en cache.
+func (r *adRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Pub, error) {
+	if ad, ok := r.local.get(id, r.clock.Now()); ok {
+		metrics.AdCacheLookup("local", "hit")
+		return visible(ctx, ad), nil
+	}
+	metrics.AdCacheLookup("local", "miss")
+
+	// Le locataire fait partie de la clé : le chargement est restreint à celui de l'appelant
+	key := domain.TenantFromContext(ctx) + "/" + id.String()
+	v, err, _ := r.group.Do(key, func() (interface{}, error) {
+		// Un appelant qui abandonne n'interrompt pas le chargement partagé
+		return r.load(context.WithoutCancel(ctx), id)
+	})
+	// load retourne un *domain.Pub nil pour une absence : v n'est alors pas une interface nil
+	ad, _ := v.(*domain.Pub)
+	if err != nil || ad == nil {
+		return nil, err
+	}
+	return clone(ad), nil
+}
+
+// load lit la publicité dans Dragonfly puis dans le repository, et remplit les niveaux manquants
+func (r *adRepository) load(ctx context.Context, id uuid.UUID) (*domain.Pub, error) {
+	if r.client != nil {
+		data, err := r.client.Get(ctx, keyPrefix+id.String()).Bytes()
+		switch {
+		case err == nil:
+			var ad domain.Pub
+			if err := json.Unmarshal(data, &ad); err == nil {
+				metrics.AdCacheLookup("dragonfly", "hit")
+				r.local.add(*clone(&ad), r.expiry(&ad))
+				return visible(ctx, ad), nil
+			}
+			r.logger.WarnContext(ctx, "Ignoring unreadable cached ad", "id", id, "error", err)
+		case errors.Is(err, redis.Nil):
+			metrics.AdCacheLookup("dragonfly", "miss")
+		default:
+			r.logger.WarnContext(ctx, "Ad cache lookup failed, reading repository", "id", id, "error", err)
+		}
+	}
+
+	ad, err := r.AdRepository.GetByID(ctx, id)
+	if err != nil || ad == nil {
+		return ad, err
+	}
+	expires := r.expiry(ad)
+	r.local.add(*clone(ad), expires)
+	if r.client != nil {
+		if ttl := expires.Sub(r.clock.Now()); ttl > 0 {
+			data, err := json.Marshal(ad)
+			if err == nil {
+				err = r.client.Set(ctx, keyPrefix+id.String(), data, ttl).Err()
+			}
+			if err != nil {
+				r.logger.WarnContext(ctx, "Failed to store ad in cache", "id", id, "error", err)
+			}
+		}
+	}
+	return ad, nil
+}
+
+// IncrementImpressions incrémente le compteur et le reporte dans le cache local
+func (r *adRepository) IncrementImpressions(ctx context.Context, id uuid.UUID) (int64, error) {
+	count, err := r.AdRepository.IncrementImpressions(ctx, id)
+	if err == nil {
+		r.local.setImpressions(id, count)
+	}
+	return count, err
+}
+
+// ResetImpressions réinitialise le compteur et invalide la publicité en cache
+func (r *adRepository) ResetImpressions(ctx context.Context, id uuid.UUID) (int64, error) {
+	count, err := r.AdRepository.ResetImpressions(ctx, id)
+	r.invalidate(ctx, id)
+	return count, err
+}
+
+// AdjustImpressions corrige le compteur et invalide la publicité en cache
+func (r *adRepository) AdjustImpressions(ctx context.Context, id uuid.UUID, delta int64) (int64, error) {
+	count, err := r.AdRepository.AdjustImpressions(ctx, id, delta)
+	r.invalidate(ctx, id)
+	return count, err
+}
+
+// DeleteExpired retire les publicités expirées, du repository comme du cache local ;
+// celles de Dragonfly expirent d'elles-mêmes avec la publicité
+func (r *adRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	count, err := r.AdRepository.DeleteExpired(ctx)
+	r.local.purgeExpired(r.clock.Now())
+	return count, err
+}
+
+// invalidate retire la publicité des deux niveaux de cache
+func (r *adRepository) invalidate(ctx context.Context, id uuid.UUID) {
+	r.local.delete(id)
+	if r.client == nil {
+		return
+	}
+	if err := r.client.Del(ctx, keyPrefix+id.String()).Err(); err != nil {
+		r.logger.WarnContext(ctx, "Failed to invalidate cached ad", "id", id, "error", err)
+	}
+}
+
+// expiry retourne la date d'expiration d'une entrée : après TTL, ou à l'expiration de la publicité
+func (r *adRepository) expiry(ad *domain.Pub) time.Time {
+	expires := r.clock.Now().Add(r.ttl)
+	if ad.ExpiresAt.Before(expires) {
+		return ad.ExpiresAt
+	}
+	return expires
+}
+
+// visible retourne une copie de la publicité si le locataire du contexte peut la lire,
+// nil sinon, comme les requêtes restreintes du repository
+func visible(ctx context.Context, ad domain.Pub) *domain.Pub {
+	if tenant := domain.TenantFromContext(ctx); tenant != "" && ad.Tenant != tenant {
+		return nil
+	}
+	return clone(&ad)
+}
+
+// clone copie une publicité, description comprise
+func clone(ad *domain.Pub) *domain.Pub {
+	c := *ad
+	if ad.Description != nil {
+		description := *ad.Description
+		c.Description = &description
+	}
+	return &c
+}
+
+// Ensure adRepository implements the AdRepository interface
+var _ out.AdRepository = (*adRepository)(nil)
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"adserver/internal/adapters/clock"
+	"adserver/internal/adapters/memory"
+	"adserver/internal/domain"
+	"adserver/internal/ports/out"
+	"adserver/internal/ports/out/contract"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// discard est le logger des tests
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+var testStart = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+var (
+	acme   = domain.WithTenant(context.Background(), "acme")
+	globex = domain.WithTenant(context.Background(), "globex")
+)
+
+// newClient connecte un client au serveur miniredis, fermé à la fin du test
+func newClient(t testing.TB, mr *miniredis.Miniredis) redis.UniversalClient {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// tiers retourne les configurations testées : LRU seul, puis LRU et Dragonfly (miniredis)
+func tiers() map[string]func(t *testing.T) redis.UniversalClient {
+	return map[string]func(t *testing.T) redis.UniversalClient{
+		"local":     func(*testing.T) redis.UniversalClient { return nil },
+		"dragonfly": func(t *testing.T) redis.UniversalClient { return newClient(t, miniredis.RunT(t)) },
+	}
+}
+
+func TestAdRepositoryContract(t *testing.T) {
+	for name, client := range tiers() {
+		t.Run(name, func(t *testing.T) {
+			contract.AdRepository(t, func(t *testing.T, clock out.Clock) out.AdRepository {
+				return NewAdRepository(memory.NewAdRepository(clock, 0), Options{Size: 100, TTL: time.Minute, Client: client(t)}, clock, discard)
+			})
+		})
+	}
+}
+
+func TestGetByIDMissingAd(t *testing.T) {
+	for name, client := range tiers() {
+		t.Run(name, func(t *testing.T) {
+			fake := clock.NewFake(testStart)
+			repo := NewAdRepository(memory.NewAdRepository(fake, 0), Options{Size: 100, TTL: time.Minute, Client: client(t)}, fake, discard)
+
+			// Deux lectures : l'absence n'est pas mise en cache
+			for i := 0; i < 2; i++ {
+				if got, err := repo.GetByID(acme, uuid.New()); err != nil || got != nil {
+					t.Fatalf("GetByID(unknown) = %v, %v, want nil, nil", got, err)
+				}
+			}
+		})
+	}
+}
+
+func TestGetByIDOtherTenantFromDragonfly(t *testing.T) {
+	fake := clock.NewFake(testStart)
+	backing := memory.NewAdRepository(fake, 0)
+	mr := miniredis.RunT(t)
+	ad := domain.Pub{ID: uuid.New(), Tenant: "acme", Title: "Ma publicité", ExpiresAt: testStart.Add(time.Hour)}
+	if _, err := backing.Create(acme, &ad); err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+
+	// Une réplica lit la publicité pour acme et la partage dans Dragonfly
+	first := NewAdRepository(backing, Options{Size: 100, TTL: time.Minute, Client: newClient(t, mr)}, fake, discard)
+	if got, err := first.GetByID(acme, ad.ID); err != nil || got == nil {
+		t.Fatalf("GetByID(acme) = %v, %v, want the ad", got, err)
+	}
+
+	// Une autre réplica la trouve dans Dragonfly, mais pas pour un autre locataire
+	second := NewAdRepository(backing, Options{Size: 100, TTL: time.Minute, Client: newClient(t, mr)}, fake, discard)
+	if got, err := second.GetByID(globex, ad.ID); err != nil || got != nil {
+		t.Errorf("GetByID(globex) = %v, %v, want nil, nil", got, err)
+	}
+	if got, err := second.GetByID(acme, ad.ID); err != nil || got == nil || got.Title != ad.Title {
+		t.Errorf("GetByID(acme) = %v, %v, want the ad", got, err)
+	}
+	// Le LRU de la seconde réplica, rempli depuis Dragonfly, filtre aussi le locataire
+	if got, err := second.GetByID(globex, ad.ID); err != nil || got != nil {
+		t.Errorf("GetByID(globex) from the local cache = %v, %v, want nil, nil", got, err)
+	}
+}
+
+// roundTrip simule la latence d'une lecture MongoDB
+const roundTrip = 200 * time.Microsecond
+
+// slowRepository ajoute roundTrip à chaque GetByID du repository décoré
+type slowRepository struct {
+	out.AdRepository
+}
+
+func (r slowRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Pub, error) {
+	time.Sleep(roundTrip)
+	return r.AdRepository.GetByID(ctx, id)
+}
+
+// BenchmarkGetByID compare la lecture d'une publicité sans cache, depuis Dragonfly
+// (LRU de taille nulle) et depuis le LRU
+func BenchmarkGetByID(b *testing.B) {
+	fake := clock.NewFake(testStart)
+	backing := slowRepository{memory.NewAdRepository(fake, 0)}
+	ad := domain.Pub{ID: uuid.New(), Tenant: "acme", Title: "Ma publicité", ExpiresAt: testStart.Add(time.Hour)}
+	if _, err := backing.Create(acme, &ad); err != nil {
+		b.Fatalf("Create() error: %v", err)
+	}
+
+	for _, bb := range []struct {
+		name string
+		repo func(b *testing.B) out.AdRepository
+	}{
+		{name: "repository", repo: func(*testing.B) out.AdRepository { return backing }},
+		{name: "dragonfly", repo: func(b *testing.B) out.AdRepository {
+			return NewAdRepository(backing, Options{TTL: time.Minute, Client: newClient(b, miniredis.RunT(b))}, fake, discard)
+		}},
+		{name: "local", repo: func(*testing.B) out.AdRepository {
+			return NewAdRepository(backing, Options{Size: 100, TTL: time.Minute}, fake, discard)
+		}},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			repo := bb.repo(b)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if got, err := repo.GetByID(acme, ad.ID); err != nil || got == nil {
+					b.Fatalf("GetByID() = %v, %v", got, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"adserver/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// lru est un cache de publicités borné en nombre d'entrées, évincées de la moins
+// récemment lue à la plus récente. Chaque entrée porte sa propre date d'expiration.
+type lru struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // Entrées, de la plus récente à la plus ancienne
+	entries map[uuid.UUID]*list.Element
+}
+
+// lruEntry est une publicité en cache et sa date d'expiration
+type lruEntry struct {
+	ad      domain.Pub
+	expires time.Time
+}
+
+// newLRU crée un cache de size entrées au plus
+func newLRU(size int) *lru {
+	return &lru{size: size, order: list.New(), entries: make(map[uuid.UUID]*list.Element)}
+}
+
+// get retourne une copie de la publicité id si elle est en cache et non expirée à now
+func (c *lru) get(id uuid.UUID, now time.Time) (domain.Pub, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[id]
+	if !ok {
+		return domain.Pub{}, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if !now.Before(entry.expires) {
+		c.remove(elem)
+		return domain.Pub{}, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.ad, true
+}
+
+// add met en cache la publicité jusqu'à expires, en évinçant au besoin la plus ancienne
+func (c *lru) add(ad domain.Pub, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[ad.ID]; ok {
+		elem.Value = &lruEntry{ad: ad, expires: expires}
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[ad.ID] = c.order.PushFront(&lruEntry{ad: ad, expires: expires})
+	if c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+// setImpressions met à jour le compteur de la publicité id si elle est en cache
+func (c *lru) setImpressions(id uuid.UUID, impressions int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[id]; ok {
+		elem.Value.(*lruEntry).ad.Impressions = impressions
+	}
+}
+
+// delete retire la publicité id du cache
+func (c *lru) delete(id uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[id]; ok {
+		c.remove(elem)
+	}
+}
+
+// purgeExpired retire les entrées expirées à now
+func (c *lru) purgeExpired(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for elem := c.order.Front(); elem != nil; {
+		next := elem.Next()
+		if !now.Before(elem.Value.(*lruEntry).expires) {
+			c.remove(elem)
+		}
+		elem = next
+	}
+}
+
+// remove retire une entrée ; le verrou doit être détenu
+func (c *lru) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*lruEntry).ad.ID)
+}
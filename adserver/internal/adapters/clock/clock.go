@@ -0,0 +1,33 @@
+package clock
+
+import (
+	"time"
+
+	"adserver/internal/ports/out"
+)
+
+// System est l'horloge murale du système
+var System out.Clock = systemClock{}
+
+// systemClock implémente out.Clock avec le paquet time
+type systemClock struct{}
+
+// Now retourne time.Now()
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTicker retourne un time.Ticker
+func (systemClock) NewTicker(d time.Duration) out.Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+// systemTicker adapte time.Ticker à l'interface out.Ticker
+type systemTicker struct {
+	*time.Ticker
+}
+
+// C retourne le canal du time.Ticker
+func (t systemTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
@@ -0,0 +1,100 @@
+package gateway
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"adserver/generated/ad_service"
+	"adserver/internal/adapters/grpc/auth"
+	"adserver/internal/adapters/ratelimit"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// OpenAPIPath est le chemin de la spécification OpenAPI générée depuis les protos
+const OpenAPIPath = "/openapi.json"
+
+// Métadonnées portant les en-têtes User-Agent et Referer du client HTTP : la requête gRPC
+// relayée porte le User-Agent de la passerelle elle-même
+const (
+	UserAgentHeader = runtime.MetadataPrefix + "user-agent"
+	RefererHeader   = runtime.MetadataPrefix + "referer"
+)
+
+//go:embed openapi/ad_service.swagger.json
+var openAPISpec []byte
+
+// Serve démarre la passerelle REST/JSON sur addr. Chaque requête est relayée au serveur
+// gRPC grpcAddr, dont elle traverse les intercepteurs (authentification, limitation de débit).
+// La connexion au serveur est fermée à l'annulation de ctx.
+func Serve(ctx context.Context, addr, grpcAddr string, creds credentials.TransportCredentials, logger *slog.Logger) (*http.Server, error) {
+	logger = logger.With("component", "Gateway")
+
+	handler, err := newHandler(ctx, grpcAddr, creds)
+	if err != nil {
+		return nil, err
+	}
+	server := &http.Server{Addr: addr, Handler: handler, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Gateway server failed", "addr", addr, "error", err)
+		}
+	}()
+	return server, nil
+}
+
+// newHandler construit les routes de la passerelle : l'API REST relayée à grpcAddr et la
+// spécification OpenAPI
+func newHandler(ctx context.Context, grpcAddr string, creds credentials.TransportCredentials) (http.Handler, error) {
+	gwMux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(incomingHeader),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeader),
+	)
+	err := ad_service.RegisterAdServiceHandlerFromEndpoint(ctx, gwMux, grpcAddr, []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(OpenAPIPath, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(openAPISpec)
+	})
+	mux.Handle("/", gwMux)
+	return mux, nil
+}
+
+// incomingHeader transmet en métadonnée gRPC la clé d'API, le User-Agent et le Referer du
+// client en plus des en-têtes standard (Authorization, en-têtes préfixés Grpc-Metadata-)
+func incomingHeader(key string) (string, bool) {
+	switch {
+	case strings.EqualFold(key, auth.APIKeyHeader):
+		return auth.APIKeyHeader, true
+	case strings.EqualFold(key, "User-Agent"):
+		return UserAgentHeader, true
+	case strings.EqualFold(key, "Referer"):
+		return RefererHeader, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// outgoingHeader expose la métadonnée retry-after en en-tête HTTP Retry-After ; les autres
+// métadonnées gardent le préfixe Grpc-Metadata-
+func outgoingHeader(key string) (string, bool) {
+	if key == ratelimit.RetryAfterHeader {
+		return "Retry-After", true
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
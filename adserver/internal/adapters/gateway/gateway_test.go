@@ -0,0 +1,155 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"adserver/generated/ad_service"
+	"adserver/internal/adapters/clock"
+	"adserver/internal/adapters/grpc/auth"
+	"adserver/internal/adapters/grpc/handler"
+	"adserver/internal/adapters/memory"
+	"adserver/internal/application"
+	"adserver/internal/domain"
+	"shared/generated/impression_service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// discard est le logger des tests
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+var testStart = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// recordingTracker retient les impressions transmises au tracker
+type recordingTracker struct {
+	impression_service.ImpressionServiceClient
+
+	mu          sync.Mutex
+	impressions []*impression_service.TrackImpressionRequest
+}
+
+func (t *recordingTracker) TrackImpression(_ context.Context, req *impression_service.TrackImpressionRequest, _ ...grpc.CallOption) (*impression_service.TrackImpressionResponse, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.impressions = append(t.impressions, req)
+	return &impression_service.TrackImpressionResponse{Success: true}, nil
+}
+
+// newGateway démarre un Ad Server en mémoire et la passerelle qui le sert en REST ; la clé
+// d'API retournée est celle d'un annonceur du locataire acme
+func newGateway(t *testing.T) (*httptest.Server, *recordingTracker, string) {
+	t.Helper()
+	fake := clock.NewFake(testStart)
+	keys := application.NewAPIKeyService(memory.NewAPIKeyRepository(), fake, 0, discard)
+	tracker := &recordingTracker{}
+	adService := application.NewAdService(memory.NewAdRepository(fake, 0), fake, discard)
+
+	authenticator := auth.NewAuthenticator(keys, auth.AdServicePolicy(), discard, ad_service.AdService_ServiceDesc.ServiceName)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(authenticator.UnaryServerInterceptor()))
+	ad_service.RegisterAdServiceServer(server, handler.NewAdHandler(adService, nil, tracker, nil, keys, false, fake, discard))
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	h, err := newHandler(ctx, lis.Addr().String(), insecure.NewCredentials())
+	if err != nil {
+		t.Fatalf("newHandler() error: %v", err)
+	}
+	gw := httptest.NewServer(h)
+	t.Cleanup(gw.Close)
+
+	_, secret, err := keys.CreateKey(context.Background(), "acme-advertiser", "acme", domain.RoleAdvertiser)
+	if err != nil {
+		t.Fatalf("CreateKey() error: %v", err)
+	}
+	return gw, tracker, secret
+}
+
+// post envoie une requête REST authentifiée et décode la réponse JSON dans out
+func post(t *testing.T, gw *httptest.Server, apiKey, path, body string, headers map[string]string, out any) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, gw.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Api-Key", apiKey)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := gw.Client().Do(req)
+	if err != nil {
+		t.Fatalf("POST %s error: %v", path, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("POST %s = %d %s", path, resp.StatusCode, data)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		t.Fatalf("decode %s: %v", data, err)
+	}
+}
+
+func TestServeAdForwardsBrowserHeaders(t *testing.T) {
+	gw, tracker, apiKey := newGateway(t)
+
+	var ad struct{ ID string }
+	post(t, gw, apiKey, "/v1/ads", `{"title":"acme ad","expiresAt":"2026-01-01T13:00:00Z"}`, nil, &ad)
+
+	const userAgent = "Mozilla/5.0 (X11; Linux x86_64) Firefox/140.0"
+	var served struct{ ImpressionID string }
+	post(t, gw, apiKey, "/v1/ads/"+ad.ID+":serve", "", map[string]string{
+		"User-Agent": userAgent,
+		"Referer":    "https://news.example/article",
+	}, &served)
+
+	// Le tracker reçoit le navigateur du client, pas le client gRPC de la passerelle
+	if len(tracker.impressions) != 1 {
+		t.Fatalf("tracked %d impressions, want 1", len(tracker.impressions))
+	}
+	got := tracker.impressions[0]
+	if got.GetUserAgent() != userAgent {
+		t.Errorf("tracked user agent = %q, want %q", got.GetUserAgent(), userAgent)
+	}
+	if got.GetReferrer() != "https://news.example/article" {
+		t.Errorf("tracked referrer = %q, want the Referer header", got.GetReferrer())
+	}
+	if got.GetImpressionId() != served.ImpressionID {
+		t.Errorf("tracked impression id = %q, served %q", got.GetImpressionId(), served.ImpressionID)
+	}
+}
+
+func TestIncomingHeader(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+		ok     bool
+	}{
+		{header: "X-Api-Key", want: auth.APIKeyHeader, ok: true},
+		{header: "User-Agent", want: UserAgentHeader, ok: true},
+		{header: "Referer", want: RefererHeader, ok: true},
+		{header: "Grpc-Metadata-X-Request-Id", want: "X-Request-Id", ok: true},
+		{header: "X-Unknown", ok: false},
+	}
+	for _, tt := range tests {
+		if got, ok := incomingHeader(tt.header); ok != tt.ok || (ok && !strings.EqualFold(got, tt.want)) {
+			t.Errorf("incomingHeader(%q) = %q, %v, want %q, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"adserver/generated/ad_service"
+	"adserver/internal/domain"
+	"adserver/internal/ports/in"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// APIKeyHeader est la clé de métadonnée gRPC portant le secret de la clé d'API
+	APIKeyHeader = "x-api-key"
+	// AuthorizationHeader porte un jeton "Bearer <jwt>"
+	AuthorizationHeader = "authorization"
+)
+
+type contextKey struct{}
+
+// WithPrincipal associe l'identité authentifiée au contexte
+func WithPrincipal(ctx context.Context, p *domain.Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// PrincipalFromContext retourne l'identité authentifiée de l'appel, ou nil
+func PrincipalFromContext(ctx context.Context) *domain.Principal {
+	p, _ := ctx.Value(contextKey{}).(*domain.Principal)
+	return p
+}
+
+// Policy associe à chaque méthode gRPC (nom complet) les rôles autorisés à l'appeler.
+type Policy map[string][]domain.Role
+
+var (
+	allRoles    = []domain.Role{domain.RoleAdmin, domain.RoleAdvertiser, domain.RoleReader}
+	writerRoles = []domain.Role{domain.RoleAdmin, domain.RoleAdvertiser}
+	adminRoles  = []domain.Role{domain.RoleAdmin}
+)
+
+// AdServicePolicy retourne les rôles autorisés pour chaque RPC de l'AdService
+func AdServicePolicy() Policy {
+	return Policy{
+		ad_service.AdService_GetAd_FullMethodName:                allRoles,
+		ad_service.AdService_ServeAd_FullMethodName:              allRoles,
+		ad_service.AdService_GetImpressionCount_FullMethodName:   allRoles,
+		ad_service.AdService_ListAds_FullMethodName:              allRoles,
+		ad_service.AdService_DeepHealth_FullMethodName:           allRoles,
+		ad_service.AdService_CreateAd_FullMethodName:             writerRoles,
+		ad_service.AdService_IncrementImpressions_FullMethodName: writerRoles,
+		ad_service.AdService_ResetImpressions_FullMethodName:     adminRoles,
+		ad_service.AdService_DeleteExpired_FullMethodName:        adminRoles,
+		ad_service.AdService_ReconcileImpressions_FullMethodName: adminRoles,
+		ad_service.AdService_CreateAPIKey_FullMethodName:         adminRoles,
+		ad_service.AdService_RotateAPIKey_FullMethodName:         adminRoles,
+		ad_service.AdService_RevokeAPIKey_FullMethodName:         adminRoles,
+		ad_service.AdService_ListAPIKeys_FullMethodName:          adminRoles,
+	}
+}
+
+// allows indique si le rôle peut appeler la méthode. Une méthode absente de la politique est refusée.
+func (p Policy) allows(method string, role domain.Role) bool {
+	for _, r := range p[method] {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator authentifie les appels des services protégés par clé d'API ou, si un
+// vérificateur est configuré, par jeton porteur, puis applique la politique de rôles.
+// Les autres services (santé, réflexion) restent publics.
+type Authenticator struct {
+	keys      in.APIKeyService
+	tokens    TokenVerifier // nil : jetons porteurs refusés
+	policy    Policy
+	protected []string // Préfixes "/<service>/" des services protégés
+	logger    *slog.Logger
+}
+
+// NewAuthenticator crée un authentificateur protégeant les services gRPC donnés
+func NewAuthenticator(keys in.APIKeyService, policy Policy, logger *slog.Logger, services ...string) *Authenticator {
+	a := &Authenticator{keys: keys, policy: policy, logger: logger.With("component", "Authenticator")}
+	for _, service := range services {
+		a.protected = append(a.protected, "/"+service+"/")
+	}
+	return a
+}
+
+// WithTokens accepte, en plus des clés d'API, les jetons porteurs vérifiés par tokens
+func (a *Authenticator) WithTokens(tokens TokenVerifier) *Authenticator {
+	a.tokens = tokens
+	return a
+}
+
+// UnaryServerInterceptor authentifie chaque appel unaire
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := a.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor authentifie chaque appel en flux
+func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &principalStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authorize identifie l'appelant et vérifie le rôle requis par la méthode
+func (a *Authenticator) authorize(ctx context.Context, method string) (context.Context, error) {
+	if !a.isProtected(method) {
+		return ctx, nil
+	}
+
+	principal, err := a.authenticate(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+
+	if !a.policy.allows(method, principal.Role) {
+		a.logger.WarnContext(ctx, "Permission denied", "method", method, "principal", principal.Name, "key_id", principal.KeyID, "role", principal.Role)
+		return nil, status.Errorf(codes.PermissionDenied, "role %s is not allowed to call %s", principal.Role, method)
+	}
+	// Les requêtes des repositories sont restreintes au locataire de l'appelant
+	ctx = domain.WithTenant(ctx, principal.Tenant)
+	return WithPrincipal(ctx, principal), nil
+}
+
+// authenticate identifie l'appelant par son jeton porteur ou sa clé d'API
+func (a *Authenticator) authenticate(ctx context.Context, method string) (*domain.Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	if authorization := first(AuthorizationHeader); authorization != "" && a.tokens != nil {
+		scheme, token, ok := strings.Cut(authorization, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			return nil, status.Error(codes.Unauthenticated, "unsupported authorization scheme")
+		}
+		principal, err := a.tokens.Verify(ctx, strings.TrimSpace(token))
+		if err != nil {
+			a.logger.WarnContext(ctx, "Rejected bearer token", "method", method, "error", err)
+			return nil, status.Error(codes.Unauthenticated, "invalid bearer token")
+		}
+		return principal, nil
+	}
+
+	secret := first(APIKeyHeader)
+	if secret == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing API key or bearer token")
+	}
+	principal, err := a.keys.Authenticate(ctx, secret)
+	if errors.Is(err, domain.ErrAPIKeyNotFound) {
+		a.logger.WarnContext(ctx, "Rejected unknown or revoked API key", "method", method)
+		return nil, status.Error(codes.Unauthenticated, "invalid API key")
+	}
+	if err != nil {
+		a.logger.ErrorContext(ctx, "API key verification failed", "method", method, "error", err)
+		return nil, status.Error(codes.Unavailable, "API key verification failed")
+	}
+	return principal, nil
+}
+
+// isProtected indique si la méthode appartient à un service protégé
+func (a *Authenticator) isProtected(method string) bool {
+	for _, prefix := range a.protected {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// principalStream substitue le contexte authentifié à celui du flux
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *principalStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"time"
+
+	"adserver/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeySource fournit la clé publique de vérification associée à un kid
+type KeySource interface {
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// TokenVerifier authentifie un jeton porteur et retourne l'identité correspondante
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (*domain.Principal, error)
+}
+
+// JWTConfig décrit les jetons acceptés et la correspondance de leurs claims
+type JWTConfig struct {
+	Issuer      string        // Claim iss attendu, non vérifié si vide
+	Audience    string        // Valeur attendue dans aud, non vérifiée si vide
+	TenantClaim string        // Claim portant le locataire
+	RoleClaim   string        // Claim portant le rôle (chaîne ou liste de chaînes)
+	Leeway      time.Duration // Tolérance de décalage d'horloge sur exp, nbf et iat
+}
+
+// rolePriority ordonne les rôles, du plus au moins privilégié
+var rolePriority = []domain.Role{domain.RoleAdmin, domain.RoleAdvertiser, domain.RoleReader}
+
+// JWTVerifier vérifie les jetons JWT signés par une clé du JWKS et en déduit
+// le locataire et le rôle de l'appelant.
+type JWTVerifier struct {
+	keys   KeySource
+	cfg    JWTConfig
+	parser *jwt.Parser
+}
+
+// NewJWTVerifier crée un vérificateur de jetons. Seuls les algorithmes asymétriques
+// sont acceptés, ce qui écarte les jetons "none" et la confusion RS256/HS256.
+func NewJWTVerifier(keys KeySource, cfg JWTConfig) *JWTVerifier {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	return &JWTVerifier{keys: keys, cfg: cfg, parser: jwt.NewParser(opts...)}
+}
+
+// Verify vérifie la signature et la validité du jeton puis construit l'identité
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*domain.Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token has no kid header")
+		}
+		return v.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subject, _ := claims.GetSubject()
+	tenant, _ := claims[v.cfg.TenantClaim].(string)
+	role, err := v.role(claims)
+	if err != nil {
+		return nil, err
+	}
+	if role != domain.RoleAdmin && tenant == "" {
+		return nil, fmt.Errorf("claim %q is required for role %s", v.cfg.TenantClaim, role)
+	}
+	if err := domain.ValidateTenant(tenant); err != nil {
+		return nil, fmt.Errorf("claim %q: %w", v.cfg.TenantClaim, err)
+	}
+	return &domain.Principal{Name: subject, Tenant: tenant, Role: role}, nil
+}
+
+// role retourne le rôle le plus privilégié parmi les valeurs du claim de rôle
+func (v *JWTVerifier) role(claims jwt.MapClaims) (domain.Role, error) {
+	var values []string
+	switch raw := claims[v.cfg.RoleClaim].(type) {
+	case string:
+		values = []string{raw}
+	case []any:
+		for _, item := range raw {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	for _, candidate := range rolePriority {
+		for _, value := range values {
+			if domain.Role(value) == candidate {
+				return candidate, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("claim %q carries no known role", v.cfg.RoleClaim)
+}
@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"adserver/generated/ad_service"
+	"adserver/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// discard est le logger des tests
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// staticKeys est un JWKS en mémoire
+type staticKeys map[string]crypto.PublicKey
+
+func (k staticKeys) Key(_ context.Context, kid string) (crypto.PublicKey, error) {
+	key, ok := k[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// signer signe des jetons avec une clé générée pour le test
+type signer struct {
+	kid    string
+	method jwt.SigningMethod
+	key    crypto.Signer
+}
+
+func (s signer) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(s.method, claims)
+	token.Header["kid"] = s.kid
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func newSigners(t *testing.T) (ec, rs, ed signer, keys staticKeys) {
+	t.Helper()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec = signer{kid: "ec", method: jwt.SigningMethodES256, key: ecKey}
+	rs = signer{kid: "rsa", method: jwt.SigningMethodRS256, key: rsaKey}
+	ed = signer{kid: "ed", method: jwt.SigningMethodEdDSA, key: edKey}
+	keys = staticKeys{"ec": ecKey.Public(), "rsa": rsaKey.Public(), "ed": edKey.Public()}
+	return ec, rs, ed, keys
+}
+
+var testJWTConfig = JWTConfig{
+	Issuer:      "https://portal.example.com",
+	Audience:    "adserver",
+	TenantClaim: "tenant",
+	RoleClaim:   "roles",
+	Leeway:      30 * time.Second,
+}
+
+// portalClaims retourne les claims d'un jeton valide du portail, modifiés par overrides
+func portalClaims(overrides jwt.MapClaims) jwt.MapClaims {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":    testJWTConfig.Issuer,
+		"aud":    []string{"adserver", "reporting"},
+		"sub":    "alice",
+		"iat":    now.Unix(),
+		"exp":    now.Add(time.Hour).Unix(),
+		"tenant": "acme",
+		"roles":  []string{"reader", "advertiser"},
+	}
+	for name, value := range overrides {
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+	}
+	return claims
+}
+
+func TestJWTVerifierAcceptsSignedTokens(t *testing.T) {
+	ec, rs, ed, keys := newSigners(t)
+	v := NewJWTVerifier(keys, testJWTConfig)
+
+	for _, s := range []signer{ec, rs, ed} {
+		t.Run(s.method.Alg(), func(t *testing.T) {
+			principal, err := v.Verify(context.Background(), s.sign(t, portalClaims(nil)))
+			if err != nil {
+				t.Fatalf("Verify() error: %v", err)
+			}
+			want := domain.Principal{Name: "alice", Tenant: "acme", Role: domain.RoleAdvertiser}
+			if *principal != want {
+				t.Errorf("Verify() = %+v, want %+v", *principal, want)
+			}
+		})
+	}
+
+	// Un administrateur n'a pas besoin de locataire
+	principal, err := v.Verify(context.Background(), ec.sign(t, portalClaims(jwt.MapClaims{"tenant": nil, "roles": "admin"})))
+	if err != nil || principal.Role != domain.RoleAdmin || principal.Tenant != "" {
+		t.Errorf("Verify(admin) = %+v, %v, want an admin without tenant", principal, err)
+	}
+}
+
+func TestJWTVerifierRejectsInvalidTokens(t *testing.T) {
+	ec, _, _, keys := newSigners(t)
+	v := NewJWTVerifier(keys, testJWTConfig)
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := signer{kid: "ec", method: jwt.SigningMethodES256, key: otherKey}
+
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, portalClaims(nil))
+	hmac.Header["kid"] = "ec"
+	hmacToken, err := hmac.SignedString([]byte("shared-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	none := jwt.NewWithClaims(jwt.SigningMethodNone, portalClaims(nil))
+	none.Header["kid"] = "ec"
+	noneToken, err := none.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "expired", token: ec.sign(t, portalClaims(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}))},
+		{name: "no expiry", token: ec.sign(t, portalClaims(jwt.MapClaims{"exp": nil}))},
+		{name: "issued in the future", token: ec.sign(t, portalClaims(jwt.MapClaims{"iat": time.Now().Add(time.Hour).Unix()}))},
+		{name: "wrong issuer", token: ec.sign(t, portalClaims(jwt.MapClaims{"iss": "https://evil.example.com"}))},
+		{name: "wrong audience", token: ec.sign(t, portalClaims(jwt.MapClaims{"aud": "billing"}))},
+		{name: "no known role", token: ec.sign(t, portalClaims(jwt.MapClaims{"roles": []string{"owner"}}))},
+		{name: "advertiser without tenant", token: ec.sign(t, portalClaims(jwt.MapClaims{"tenant": nil}))},
+		{name: "invalid tenant", token: ec.sign(t, portalClaims(jwt.MapClaims{"tenant": "acme:globex"}))},
+		{name: "forged signature", token: forged.sign(t, portalClaims(nil))},
+		{name: "unknown kid", token: signer{kid: "rotated", method: jwt.SigningMethodES256, key: otherKey}.sign(t, portalClaims(nil))},
+		{name: "missing kid", token: signer{method: jwt.SigningMethodES256, key: otherKey}.sign(t, portalClaims(nil))},
+		{name: "symmetric algorithm", token: hmacToken},
+		{name: "unsigned", token: noneToken},
+		{name: "malformed", token: "not.a.jwt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if principal, err := v.Verify(context.Background(), tt.token); err == nil {
+				t.Errorf("Verify() = %+v, want an error", principal)
+			}
+		})
+	}
+}
+
+func TestJWTVerifierLeeway(t *testing.T) {
+	ec, _, _, keys := newSigners(t)
+	v := NewJWTVerifier(keys, testJWTConfig)
+
+	token := ec.sign(t, portalClaims(jwt.MapClaims{"exp": time.Now().Add(-10 * time.Second).Unix()}))
+	if _, err := v.Verify(context.Background(), token); err != nil {
+		t.Errorf("Verify() of a token expired within the leeway error: %v", err)
+	}
+}
+
+func TestAuthenticatorBearerToken(t *testing.T) {
+	ec, _, _, keys := newSigners(t)
+	a := NewAuthenticator(nil, AdServicePolicy(), discard, ad_service.AdService_ServiceDesc.ServiceName).
+		WithTokens(NewJWTVerifier(keys, testJWTConfig))
+	reader := ec.sign(t, portalClaims(jwt.MapClaims{"roles": "reader"}))
+
+	tests := []struct {
+		name          string
+		authorization string
+		method        string
+		wantCode      codes.Code
+	}{
+		{name: "allowed role", authorization: "Bearer " + reader, method: ad_service.AdService_ListAds_FullMethodName, wantCode: codes.OK},
+		{name: "case-insensitive scheme", authorization: "bearer " + reader, method: ad_service.AdService_ListAds_FullMethodName, wantCode: codes.OK},
+		{name: "denied role", authorization: "Bearer " + reader, method: ad_service.AdService_CreateAd_FullMethodName, wantCode: codes.PermissionDenied},
+		{name: "invalid token", authorization: "Bearer " + reader + "x", method: ad_service.AdService_ListAds_FullMethodName, wantCode: codes.Unauthenticated},
+		{name: "unsupported scheme", authorization: "Basic YWxpY2U6c2VjcmV0", method: ad_service.AdService_ListAds_FullMethodName, wantCode: codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(AuthorizationHeader, tt.authorization))
+			var tenant string
+			_, err := a.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, func(ctx context.Context, _ any) (any, error) {
+				tenant = domain.TenantFromContext(ctx)
+				return nil, nil
+			})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("error = %v, want %v", err, tt.wantCode)
+			}
+			if err == nil && tenant != "acme" {
+				t.Errorf("handler tenant = %q, want the token tenant %q", tenant, "acme")
+			}
+		})
+	}
+}
@@ -1,40 +1,61 @@
 package handler
 
 import (
+	"cmp"
 	"context"
-	"log"
+	"errors"
+	"log/slog"
 	"time"
 
 	"adserver/generated/ad_service"
-	"adserver/generated/impression_service"
+	"adserver/internal/adapters/healthcheck"
+	"adserver/internal/adapters/metrics"
+	"adserver/internal/adapters/ratelimit"
 	"adserver/internal/domain"
 	"adserver/internal/ports/in"
+	"adserver/internal/ports/out"
+	"shared/generated/impression_service"
 
 	"github.com/google/uuid"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // AdHandler implémente le service gRPC AdService
 type AdHandler struct {
 	adService        in.AdService
+	reconciler       in.ImpressionReconciler                    // Réconciliation des compteurs avec le tracker
 	impressionClient impression_service.ImpressionServiceClient // Client pour le service d'impression
+	health           *healthcheck.Checker                       // Vérification des dépendances pour DeepHealth
+	apiKeys          in.APIKeyService                           // Gestion des clés d'API
+	trustForwarded   bool                                       // IP du client lue dans X-Forwarded-For
+	clock            out.Clock                                  // Fin par défaut des périodes de réconciliation
+	logger           *slog.Logger
 	ad_service.UnimplementedAdServiceServer
 }
 
 // NewAdHandler crée une nouvelle instance du handler
-func NewAdHandler(adService in.AdService, impressionClient impression_service.ImpressionServiceClient) *AdHandler {
+func NewAdHandler(adService in.AdService, reconciler in.ImpressionReconciler, impressionClient impression_service.ImpressionServiceClient, health *healthcheck.Checker, apiKeys in.APIKeyService, trustForwarded bool, clock out.Clock, logger *slog.Logger) *AdHandler {
 	return &AdHandler{
 		adService:        adService,
+		reconciler:       reconciler,
 		impressionClient: impressionClient,
+		health:           health,
+		apiKeys:          apiKeys,
+		trustForwarded:   trustForwarded,
+		clock:            clock,
+		logger:           logger.With("component", "AdHandler"),
 	}
 }
 
 // CreateAd implémente la création d'une publicité
 func (h *AdHandler) CreateAd(ctx context.Context, req *ad_service.CreateAdRequest) (*ad_service.AdResponse, error) {
 	start := time.Now()
-	log.Printf("[CreateAd] start: title=%q expiresAt=%v", req.Title, req.ExpiresAt)
+	h.logger.DebugContext(ctx, "CreateAd start", "title", req.Title, "expires_at", req.ExpiresAt)
 
 	// Validation des entrées
 	if req.Title == "" {
@@ -59,7 +80,7 @@ func (h *AdHandler) CreateAd(ctx context.Context, req *ad_service.CreateAdReques
 	// Appel au service
 	createdAd, err := h.adService.CreateAd(ctx, ad)
 	if err != nil {
-		log.Printf("[CreateAd] service error: %v", err)
+		h.logger.ErrorContext(ctx, "CreateAd service failed", "error", err)
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
@@ -70,15 +91,16 @@ func (h *AdHandler) CreateAd(ctx context.Context, req *ad_service.CreateAdReques
 		Description: *createdAd.Description,
 		Url:         createdAd.URL,
 		ExpiresAt:   timestamppb.New(createdAd.ExpiresAt),
+		Tenant:      createdAd.Tenant,
 	}
-	log.Printf("[CreateAd] completed in %v id=%s", time.Since(start), createdAd.ID)
+	h.logger.InfoContext(ctx, "CreateAd completed", "duration", time.Since(start), "id", createdAd.ID)
 	return resp, nil
 }
 
 // GetAd implémente la récupération d'une publicité
 func (h *AdHandler) GetAd(ctx context.Context, req *ad_service.GetAdRequest) (*ad_service.AdResponse, error) {
 	start := time.Now()
-	log.Printf("[GetAd] start: id=%q", req.Id)
+	h.logger.DebugContext(ctx, "GetAd start", "id", req.Id)
 
 	// Validation des entrées
 	if req.Id == "" {
@@ -87,8 +109,11 @@ func (h *AdHandler) GetAd(ctx context.Context, req *ad_service.GetAdRequest) (*a
 
 	// Appel au service
 	ad, err := h.adService.GetAd(ctx, req.Id)
+	if errors.Is(err, domain.ErrAdNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
 	if err != nil {
-		log.Printf("[GetAd] service error: %v", err)
+		h.logger.ErrorContext(ctx, "GetAd service failed", "error", err)
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
@@ -98,18 +123,19 @@ func (h *AdHandler) GetAd(ctx context.Context, req *ad_service.GetAdRequest) (*a
 		Title:     ad.Title,
 		Url:       ad.URL,
 		ExpiresAt: timestamppb.New(ad.ExpiresAt),
+		Tenant:    ad.Tenant,
 	}
 	if ad.Description != nil {
 		response.Description = *ad.Description
 	}
-	log.Printf("[GetAd] completed in %v id=%s", time.Since(start), req.Id)
+	h.logger.InfoContext(ctx, "GetAd completed", "duration", time.Since(start), "id", req.Id)
 	return response, nil
 }
 
 // ServeAd implémente la diffusion d'une publicité
 func (h *AdHandler) ServeAd(ctx context.Context, req *ad_service.ServeAdRequest) (*ad_service.ServeAdResponse, error) {
 	start := time.Now()
-	log.Printf("[ServeAd] start: id=%q", req.Id)
+	h.logger.DebugContext(ctx, "ServeAd start", "id", req.Id)
 
 	// Validation des entrées
 	if req.Id == "" {
@@ -122,40 +148,47 @@ func (h *AdHandler) ServeAd(ctx context.Context, req *ad_service.ServeAdRequest)
 	}
 
 	// Appel au service local
-	url, impressions, err := h.adService.ServeAd(ctx, id)
+	ad, err := h.adService.ServeAd(ctx, id)
+	if errors.Is(err, domain.ErrAdNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
 	if err != nil {
-		log.Printf("[ServeAd] service error: %v", err)
+		h.logger.ErrorContext(ctx, "ServeAd service failed", "error", err)
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	// Appel au service d'impression via gRPC
+	// Appel au service d'impression via gRPC, sous le locataire de l'annonce
 	impressionID := uuid.New().String()
 	trackReq := &impression_service.TrackImpressionRequest{
 		AdId:         req.Id,
 		ImpressionId: impressionID,
+		Tenant:       ad.Tenant,
 	}
+	fillImpressionContext(ctx, trackReq, h.trustForwarded)
 
 	_, err = h.impressionClient.TrackImpression(ctx, trackReq)
 	if err != nil {
 		// On log l'erreur mais on continue pour retourner l'URL
-		log.Printf("[ServeAd] impression tracking error: %v", err)
+		h.logger.WarnContext(ctx, "ServeAd impression tracking failed", "id", req.Id, "error", err)
+		metrics.ImpressionTrackingFailed()
 	} else {
-		log.Printf("[ServeAd] impression tracked successfully: adId=%s impressionId=%s", req.Id, impressionID)
+		h.logger.DebugContext(ctx, "ServeAd impression tracked", "ad_id", req.Id, "impression_id", impressionID)
 	}
 
 	// Transformation en réponse
 	resp := &ad_service.ServeAdResponse{
-		Url:         url,
-		Impressions: impressions,
+		Url:          ad.URL,
+		Impressions:  ad.Impressions,
+		ImpressionId: impressionID,
 	}
-	log.Printf("[ServeAd] completed in %v id=%s impressions=%d", time.Since(start), req.Id, impressions)
+	h.logger.InfoContext(ctx, "ServeAd completed", "duration", time.Since(start), "id", req.Id, "impressions", ad.Impressions)
 	return resp, nil
 }
 
 // GetImpressionCount implémente la récupération du nombre d'impressions d'une publicité
 func (h *AdHandler) GetImpressionCount(ctx context.Context, req *ad_service.GetImpressionCountRequest) (*ad_service.GetImpressionCountResponse, error) {
 	start := time.Now()
-	log.Printf("[GetImpressionCount] start: adId=%q", req.AdId)
+	h.logger.DebugContext(ctx, "GetImpressionCount start", "ad_id", req.AdId)
 
 	// Validation des entrées
 	if req.AdId == "" {
@@ -169,52 +202,139 @@ func (h *AdHandler) GetImpressionCount(ctx context.Context, req *ad_service.GetI
 
 	// Appel au service
 	impr, err := h.adService.GetAdImpressions(ctx, id)
+	if errors.Is(err, domain.ErrAdNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
 	if err != nil {
-		log.Printf("[GetImpressionCount] service error: %v", err)
+		h.logger.ErrorContext(ctx, "GetImpressionCount service failed", "error", err)
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	// Transformation en réponse
 	resp := &ad_service.GetImpressionCountResponse{Impressions: impr}
-	log.Printf("[GetImpressionCount] completed in %v adId=%s impressions=%d", time.Since(start), req.AdId, impr)
+	h.logger.InfoContext(ctx, "GetImpressionCount completed", "duration", time.Since(start), "ad_id", req.AdId, "impressions", impr)
 	return resp, nil
 }
 
 // IncrementImpressions implémente l'incrémentation du compteur d'impressions
 func (h *AdHandler) IncrementImpressions(ctx context.Context, req *ad_service.IncrementImpressionsRequest) (*ad_service.IncrementImpressionsResponse, error) {
 	start := time.Now()
-	log.Printf("[IncrementImpressions] start: adId=%q", req.AdId)
+	h.logger.DebugContext(ctx, "IncrementImpressions start", "ad_id", req.AdId)
 
 	if req.AdId == "" {
-		log.Printf("[IncrementImpressions] invalid argument: adId empty")
+		h.logger.DebugContext(ctx, "IncrementImpressions rejected: empty ad_id")
 		return nil, status.Error(codes.InvalidArgument, "ad_id is required")
 	}
 
 	impr, err := h.adService.IncrementImpressions(ctx, req.AdId)
+	if errors.Is(err, domain.ErrAdNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
 	if err != nil {
-		log.Printf("[IncrementImpressions] service error: %v", err)
+		h.logger.ErrorContext(ctx, "IncrementImpressions service failed", "error", err)
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	resp := &ad_service.IncrementImpressionsResponse{Impressions: impr}
-	log.Printf("[IncrementImpressions] completed in %v adId=%s impressions=%d", time.Since(start), req.AdId, impr)
+	h.logger.InfoContext(ctx, "IncrementImpressions completed", "duration", time.Since(start), "ad_id", req.AdId, "impressions", impr)
 	return resp, nil
 }
 
-// DeleteExpired implémente la suppression des annonces expirées
+// DeleteExpired implémente le retrait (archivage) des annonces expirées
 func (h *AdHandler) DeleteExpired(ctx context.Context, req *ad_service.DeleteExpiredRequest) (*ad_service.DeleteExpiredResponse, error) {
 	start := time.Now()
-	log.Printf("[DeleteExpired] start")
+	h.logger.DebugContext(ctx, "DeleteExpired start")
 
 	// Appel au service
 	count, err := h.adService.DeleteExpired(ctx)
 	if err != nil {
-		log.Printf("[DeleteExpired] service error: %v", err)
+		h.logger.ErrorContext(ctx, "DeleteExpired service failed", "error", err)
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	// Transformation en réponse
 	resp := &ad_service.DeleteExpiredResponse{DeletedCount: count}
-	log.Printf("[DeleteExpired] completed in %v deletedCount=%d", time.Since(start), count)
+	h.logger.InfoContext(ctx, "DeleteExpired completed", "duration", time.Since(start), "deleted_count", count)
 	return resp, nil
 }
+
+// ReconcileImpressions compare les compteurs d'impressions avec ceux du tracker
+func (h *AdHandler) ReconcileImpressions(ctx context.Context, req *ad_service.ReconcileImpressionsRequest) (*ad_service.ReconcileImpressionsResponse, error) {
+	start := time.Now()
+	h.logger.DebugContext(ctx, "ReconcileImpressions start", "from", req.GetFrom().AsTime(), "to", req.GetTo().AsTime(), "repair", req.Repair)
+
+	var from time.Time
+	if req.From != nil {
+		from = req.From.AsTime()
+	}
+	to := h.clock.Now()
+	if req.To != nil {
+		to = req.To.AsTime()
+	}
+	if !from.Before(to) {
+		return nil, status.Error(codes.InvalidArgument, "from must be before to")
+	}
+
+	// Appel au service
+	report, err := h.reconciler.Reconcile(ctx, from, to, req.Repair)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "ReconcileImpressions service failed", "error", err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// Transformation en réponse
+	resp := &ad_service.ReconcileImpressionsResponse{
+		Checked:  int64(report.Checked),
+		Repaired: int64(report.Repaired),
+	}
+	for _, d := range report.Drifts {
+		resp.Drifts = append(resp.Drifts, &ad_service.ImpressionDrift{
+			AdId:                d.AdID.String(),
+			AdserverImpressions: d.AdServer,
+			TrackerImpressions:  d.Tracker,
+			Drift:               d.Drift(),
+			Repaired:            d.Repaired,
+		})
+	}
+	h.logger.InfoContext(ctx, "ReconcileImpressions completed", "duration", time.Since(start), "checked", report.Checked, "drifts", len(report.Drifts))
+	return resp, nil
+}
+
+// DeepHealth vérifie immédiatement chaque dépendance (MongoDB, impression-tracker) et retourne le détail.
+// Le statut grpc.health.v1 est mis à jour avec le résultat.
+func (h *AdHandler) DeepHealth(ctx context.Context, req *ad_service.DeepHealthRequest) (*ad_service.DeepHealthResponse, error) {
+	results, serving := h.health.Run(ctx)
+
+	resp := &ad_service.DeepHealthResponse{Serving: serving}
+	for _, r := range results {
+		resp.Dependencies = append(resp.Dependencies, &ad_service.DependencyHealth{
+			Name:      r.Name,
+			Healthy:   r.Healthy,
+			Error:     r.Error,
+			Latency:   durationpb.New(r.Latency),
+			CheckedAt: timestamppb.New(r.CheckedAt),
+		})
+	}
+
+	h.logger.InfoContext(ctx, "DeepHealth completed", "serving", serving)
+	return resp, nil
+}
+
+// fillImpressionContext renseigne le contexte client (User-Agent, IP, referer) de l'impression
+// à partir des métadonnées de la requête entrante. L'IP, contrôlée par le filtre de trafic
+// invalide du tracker, ne vient de X-Forwarded-For qu'avec trustForwarded.
+func fillImpressionContext(ctx context.Context, req *impression_service.TrackImpressionRequest, trustForwarded bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	// Via la passerelle REST, les en-têtes du navigateur arrivent préfixés (grpcgateway-user-agent) :
+	// user-agent est alors celui du client gRPC de la passerelle
+	req.UserAgent = cmp.Or(first(runtime.MetadataPrefix+"user-agent"), first("user-agent"))
+	req.Referrer = cmp.Or(first(runtime.MetadataPrefix+"referer"), first("referer"))
+	req.IpAddress = ratelimit.ClientIP(ctx, trustForwarded)
+}
@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"adserver/generated/ad_service"
+	"adserver/internal/adapters/clock"
+	"adserver/internal/adapters/grpc/auth"
+	"adserver/internal/adapters/memory"
+	"adserver/internal/application"
+	"adserver/internal/domain"
+	"adserver/internal/ports/in"
+	"shared/generated/impression_service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// discard est le logger des tests
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// recordingTracker retient le locataire et l'identifiant des impressions transmises au tracker
+type recordingTracker struct {
+	impression_service.ImpressionServiceClient
+
+	mu            sync.Mutex
+	tenants       []string
+	impressionIDs []string
+}
+
+func (t *recordingTracker) TrackImpression(_ context.Context, req *impression_service.TrackImpressionRequest, _ ...grpc.CallOption) (*impression_service.TrackImpressionResponse, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tenants = append(t.tenants, req.GetTenant())
+	t.impressionIDs = append(t.impressionIDs, req.GetImpressionId())
+	return &impression_service.TrackImpressionResponse{Success: true}, nil
+}
+
+func TestFillImpressionContextClientIP(t *testing.T) {
+	tests := []struct {
+		name           string
+		forwarded      []string
+		trustForwarded bool
+		want           string
+	}{
+		{name: "peer address", want: "198.51.100.7"},
+		{name: "spoofed header ignored", forwarded: []string{"203.0.113.9"}, want: "198.51.100.7"},
+		{name: "trusted proxy hop", forwarded: []string{"203.0.113.9, 192.0.2.10"}, trustForwarded: true, want: "192.0.2.10"},
+		{name: "trusted single hop", forwarded: []string{"192.0.2.10"}, trustForwarded: true, want: "192.0.2.10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("198.51.100.7"), Port: 41000}})
+			md := metadata.Pairs("user-agent", "Mozilla/5.0", "referer", "https://example.com/")
+			for _, f := range tt.forwarded {
+				md.Append("x-forwarded-for", f)
+			}
+			ctx = metadata.NewIncomingContext(ctx, md)
+
+			req := &impression_service.TrackImpressionRequest{}
+			fillImpressionContext(ctx, req, tt.trustForwarded)
+			if req.IpAddress != tt.want {
+				t.Errorf("IpAddress = %q, want %q", req.IpAddress, tt.want)
+			}
+			if req.UserAgent != "Mozilla/5.0" || req.Referrer != "https://example.com/" {
+				t.Errorf("UserAgent, Referrer = %q, %q", req.UserAgent, req.Referrer)
+			}
+		})
+	}
+}
+
+// isolatedServer sert l'AdService en mémoire derrière l'authentification par clé d'API
+type isolatedServer struct {
+	client  ad_service.AdServiceClient
+	keys    in.APIKeyService
+	tracker *recordingTracker
+	clock   *clock.Fake
+}
+
+func newIsolatedServer(t *testing.T) *isolatedServer {
+	t.Helper()
+	fake := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	keys := application.NewAPIKeyService(memory.NewAPIKeyRepository(), fake, 0, discard)
+	tracker := &recordingTracker{}
+	adService := application.NewAdService(memory.NewAdRepository(fake, 0), fake, discard)
+
+	authenticator := auth.NewAuthenticator(keys, auth.AdServicePolicy(), discard, ad_service.AdService_ServiceDesc.ServiceName)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(authenticator.UnaryServerInterceptor()))
+	ad_service.RegisterAdServiceServer(server, NewAdHandler(adService, nil, tracker, nil, keys, false, fake, discard))
+	lis := bufconn.Listen(1 << 20)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///adserver",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &isolatedServer{client: ad_service.NewAdServiceClient(conn), keys: keys, tracker: tracker, clock: fake}
+}
+
+// as retourne un contexte authentifié par une nouvelle clé de tenant et role
+func (s *isolatedServer) as(t *testing.T, tenant string, role domain.Role) context.Context {
+	t.Helper()
+	_, secret, err := s.keys.CreateKey(context.Background(), tenant+"-"+string(role), tenant, role)
+	if err != nil {
+		t.Fatalf("CreateKey() error: %v", err)
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), auth.APIKeyHeader, secret)
+}
+
+func TestServeAdReturnsTrackedImpressionID(t *testing.T) {
+	s := newIsolatedServer(t)
+	acme := s.as(t, "acme", domain.RoleAdvertiser)
+	ad, err := s.client.CreateAd(acme, &ad_service.CreateAdRequest{Title: "acme ad", ExpiresAt: timestamppb.New(s.clock.Now().Add(time.Hour))})
+	if err != nil {
+		t.Fatalf("CreateAd() error: %v", err)
+	}
+
+	var served []string
+	for i := 0; i < 2; i++ {
+		resp, err := s.client.ServeAd(acme, &ad_service.ServeAdRequest{Id: ad.Id})
+		if err != nil {
+			t.Fatalf("ServeAd() error: %v", err)
+		}
+		served = append(served, resp.GetImpressionId())
+	}
+
+	// Chaque diffusion retourne l'identifiant transmis au tracker, distinct d'une diffusion à l'autre
+	if len(s.tracker.impressionIDs) != 2 || served[0] != s.tracker.impressionIDs[0] || served[1] != s.tracker.impressionIDs[1] {
+		t.Errorf("served impression ids = %q, tracked %q, want the same", served, s.tracker.impressionIDs)
+	}
+	if served[0] == "" || served[0] == served[1] {
+		t.Errorf("served impression ids = %q, want two distinct ids", served)
+	}
+}
+
+func TestTenantIsolation(t *testing.T) {
+	s := newIsolatedServer(t)
+	acme, globex := s.as(t, "acme", domain.RoleAdvertiser), s.as(t, "globex", domain.RoleAdvertiser)
+	globexAdmin := s.as(t, "globex", domain.RoleAdmin)
+
+	expiresAt := timestamppb.New(s.clock.Now().Add(time.Hour))
+	ad, err := s.client.CreateAd(acme, &ad_service.CreateAdRequest{Title: "acme ad", ExpiresAt: expiresAt})
+	if err != nil {
+		t.Fatalf("CreateAd() error: %v", err)
+	}
+
+	// Une publicité d'un autre locataire n'existe pas pour l'appelant
+	tests := []struct {
+		name string
+		call func(ctx context.Context) error
+	}{
+		{name: "GetAd", call: func(ctx context.Context) error {
+			_, err := s.client.GetAd(ctx, &ad_service.GetAdRequest{Id: ad.Id})
+			return err
+		}},
+		{name: "ServeAd", call: func(ctx context.Context) error {
+			_, err := s.client.ServeAd(ctx, &ad_service.ServeAdRequest{Id: ad.Id})
+			return err
+		}},
+		{name: "GetImpressionCount", call: func(ctx context.Context) error {
+			_, err := s.client.GetImpressionCount(ctx, &ad_service.GetImpressionCountRequest{AdId: ad.Id})
+			return err
+		}},
+		{name: "IncrementImpressions", call: func(ctx context.Context) error {
+			_, err := s.client.IncrementImpressions(ctx, &ad_service.IncrementImpressionsRequest{AdId: ad.Id})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(globex); status.Code(err) != codes.NotFound {
+				t.Errorf("%s as globex error = %v, want NotFound", tt.name, err)
+			}
+			if err := tt.call(acme); err != nil {
+				t.Errorf("%s as acme error: %v", tt.name, err)
+			}
+		})
+	}
+
+	// Les impressions sont transmises au tracker sous le locataire de la publicité
+	if len(s.tracker.tenants) != 1 || s.tracker.tenants[0] != "acme" {
+		t.Errorf("tracked tenants = %v, want [acme]", s.tracker.tenants)
+	}
+
+	// L'administrateur d'un locataire n'archive pas les publicités des autres
+	s.clock.Advance(2 * time.Hour)
+	deleted, err := s.client.DeleteExpired(globexAdmin, &ad_service.DeleteExpiredRequest{})
+	if err != nil || deleted.GetDeletedCount() != 0 {
+		t.Fatalf("DeleteExpired() as globex admin = %v, %v, want 0 deleted", deleted, err)
+	}
+	if count, err := s.client.GetImpressionCount(acme, &ad_service.GetImpressionCountRequest{AdId: ad.Id}); err != nil || count.GetImpressions() != 2 {
+		t.Errorf("GetImpressionCount() as acme = %v, %v, want 2 impressions kept", count, err)
+	}
+}
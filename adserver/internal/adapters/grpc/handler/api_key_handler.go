@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"adserver/generated/ad_service"
+	"adserver/internal/domain"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var rolesToProto = map[domain.Role]ad_service.Role{
+	domain.RoleAdmin:      ad_service.Role_ROLE_ADMIN,
+	domain.RoleAdvertiser: ad_service.Role_ROLE_ADVERTISER,
+	domain.RoleReader:     ad_service.Role_ROLE_READER,
+}
+
+var rolesFromProto = map[ad_service.Role]domain.Role{
+	ad_service.Role_ROLE_ADMIN:      domain.RoleAdmin,
+	ad_service.Role_ROLE_ADVERTISER: domain.RoleAdvertiser,
+	ad_service.Role_ROLE_READER:     domain.RoleReader,
+}
+
+// CreateAPIKey crée une clé d'API et retourne son secret
+func (h *AdHandler) CreateAPIKey(ctx context.Context, req *ad_service.CreateAPIKeyRequest) (*ad_service.CreateAPIKeyResponse, error) {
+	start := time.Now()
+	h.logger.DebugContext(ctx, "CreateAPIKey start", "name", req.Name, "tenant", req.Tenant, "role", req.Role)
+
+	// Validation des entrées
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	role, ok := rolesFromProto[req.Role]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "role is required")
+	}
+	if role != domain.RoleAdmin && req.Tenant == "" {
+		return nil, status.Error(codes.InvalidArgument, "tenant is required for non-admin keys")
+	}
+	if err := domain.ValidateTenant(req.Tenant); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	// Un administrateur rattaché à un locataire ne crée de clés que pour celui-ci
+	if caller := domain.TenantFromContext(ctx); caller != "" && req.Tenant != caller {
+		return nil, status.Errorf(codes.PermissionDenied, "cannot create a key outside of tenant %q", caller)
+	}
+
+	// Appel au service
+	key, secret, err := h.apiKeys.CreateKey(ctx, req.Name, req.Tenant, role)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "CreateAPIKey service failed", "error", err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	h.logger.InfoContext(ctx, "CreateAPIKey completed", "duration", time.Since(start), "key_id", key.ID)
+	return &ad_service.CreateAPIKeyResponse{Key: apiKeyToProto(key), Secret: secret}, nil
+}
+
+// RotateAPIKey remplace une clé d'API par une nouvelle et révoque l'ancienne
+func (h *AdHandler) RotateAPIKey(ctx context.Context, req *ad_service.RotateAPIKeyRequest) (*ad_service.RotateAPIKeyResponse, error) {
+	start := time.Now()
+	h.logger.DebugContext(ctx, "RotateAPIKey start", "id", req.Id)
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id format")
+	}
+
+	// Appel au service
+	key, secret, err := h.apiKeys.RotateKey(ctx, id)
+	if err != nil {
+		return nil, h.apiKeyError(ctx, "RotateAPIKey", err)
+	}
+
+	h.logger.InfoContext(ctx, "RotateAPIKey completed", "duration", time.Since(start), "old_key_id", id, "key_id", key.ID)
+	return &ad_service.RotateAPIKeyResponse{Key: apiKeyToProto(key), Secret: secret}, nil
+}
+
+// RevokeAPIKey révoque une clé d'API
+func (h *AdHandler) RevokeAPIKey(ctx context.Context, req *ad_service.RevokeAPIKeyRequest) (*ad_service.RevokeAPIKeyResponse, error) {
+	start := time.Now()
+	h.logger.DebugContext(ctx, "RevokeAPIKey start", "id", req.Id)
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id format")
+	}
+
+	// Appel au service
+	key, err := h.apiKeys.RevokeKey(ctx, id)
+	if err != nil {
+		return nil, h.apiKeyError(ctx, "RevokeAPIKey", err)
+	}
+
+	h.logger.InfoContext(ctx, "RevokeAPIKey completed", "duration", time.Since(start), "key_id", id)
+	return &ad_service.RevokeAPIKeyResponse{Key: apiKeyToProto(key)}, nil
+}
+
+// ListAPIKeys liste les clés d'API, sans leurs secrets
+func (h *AdHandler) ListAPIKeys(ctx context.Context, req *ad_service.ListAPIKeysRequest) (*ad_service.ListAPIKeysResponse, error) {
+	start := time.Now()
+
+	// Appel au service
+	keys, err := h.apiKeys.ListKeys(ctx)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "ListAPIKeys service failed", "error", err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// Transformation en réponse
+	resp := &ad_service.ListAPIKeysResponse{}
+	for _, key := range keys {
+		resp.Keys = append(resp.Keys, apiKeyToProto(key))
+	}
+	h.logger.InfoContext(ctx, "ListAPIKeys completed", "duration", time.Since(start), "returned", len(keys))
+	return resp, nil
+}
+
+// apiKeyError convertit une erreur du service de clés en statut gRPC
+func (h *AdHandler) apiKeyError(ctx context.Context, method string, err error) error {
+	if errors.Is(err, domain.ErrAPIKeyNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	h.logger.ErrorContext(ctx, method+" service failed", "error", err)
+	return status.Error(codes.Internal, err.Error())
+}
+
+// apiKeyToProto convertit une clé d'API en message, sans empreinte
+func apiKeyToProto(key *domain.APIKey) *ad_service.APIKey {
+	msg := &ad_service.APIKey{
+		Id:        key.ID.String(),
+		Name:      key.Name,
+		Tenant:    key.Tenant,
+		Role:      rolesToProto[key.Role],
+		Prefix:    key.Prefix,
+		CreatedAt: timestamppb.New(key.CreatedAt),
+	}
+	if key.RevokedAt != nil {
+		msg.RevokedAt = timestamppb.New(*key.RevokedAt)
+	}
+	return msg
+}
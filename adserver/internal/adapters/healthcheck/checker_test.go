@@ -0,0 +1,108 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// discard est le logger des tests
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+const service = "adserver.AdService"
+
+// statusOf lit le statut publié pour service ("" = serveur entier)
+func statusOf(t *testing.T, server *health.Server, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	resp, err := server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		t.Fatalf("Check(%q) error: %v", service, err)
+	}
+	return resp.GetStatus()
+}
+
+// expect vérifie le statut du serveur entier et du service suivi
+func expect(t *testing.T, server *health.Server, want healthpb.HealthCheckResponse_ServingStatus) {
+	t.Helper()
+	for _, s := range []string{"", service} {
+		if got := statusOf(t, server, s); got != want {
+			t.Errorf("status of %q = %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestCheckerStatusFollowsDependencies(t *testing.T) {
+	server := health.NewServer()
+	checker := NewChecker(server, time.Hour, time.Second, discard, service)
+
+	// Avant la première vérification, le service ne reçoit pas de trafic
+	expect(t, server, healthpb.HealthCheckResponse_NOT_SERVING)
+
+	var down atomic.Bool
+	checker.Register("mongodb", func(context.Context) error { return nil })
+	checker.Register("tracker", func(context.Context) error {
+		if down.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+
+	if _, serving := checker.Run(context.Background()); !serving {
+		t.Fatal("Run() with healthy dependencies is not serving")
+	}
+	expect(t, server, healthpb.HealthCheckResponse_SERVING)
+
+	down.Store(true)
+	results, serving := checker.Run(context.Background())
+	if serving {
+		t.Fatal("Run() with a lost dependency is serving")
+	}
+	expect(t, server, healthpb.HealthCheckResponse_NOT_SERVING)
+	for _, r := range results {
+		if healthy := r.Name != "tracker"; r.Healthy != healthy {
+			t.Errorf("result %s healthy = %v, want %v", r.Name, r.Healthy, healthy)
+		}
+		if r.Name == "tracker" && r.Error != "connection refused" {
+			t.Errorf("result tracker error = %q, want the check error", r.Error)
+		}
+	}
+	if got := failing(results); len(got) != 1 || got[0] != "tracker" {
+		t.Errorf("failing() = %v, want [tracker]", got)
+	}
+
+	down.Store(false)
+	checker.Run(context.Background())
+	expect(t, server, healthpb.HealthCheckResponse_SERVING)
+}
+
+func TestCheckerTimesOutSlowDependencies(t *testing.T) {
+	server := health.NewServer()
+	checker := NewChecker(server, time.Hour, 10*time.Millisecond, discard)
+	checker.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	results, serving := checker.Run(context.Background())
+	if serving || results[0].Healthy {
+		t.Errorf("Run() = %+v, %v, want the slow dependency unhealthy", results, serving)
+	}
+}
+
+func TestCheckerStopStopsServing(t *testing.T) {
+	server := health.NewServer()
+	checker := NewChecker(server, time.Hour, time.Second, discard, service)
+	checker.Register("mongodb", func(context.Context) error { return nil })
+
+	checker.Start()
+	expect(t, server, healthpb.HealthCheckResponse_SERVING)
+	checker.Stop()
+	expect(t, server, healthpb.HealthCheckResponse_NOT_SERVING)
+}
@@ -0,0 +1,239 @@
+package jwks
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minRefetchInterval limite les rechargements déclenchés par un kid inconnu,
+// pour qu'un jeton forgé ne fasse pas interroger la source à chaque appel
+const minRefetchInterval = time.Minute
+
+// jwk est une clé publique au format JSON Web Key (RFC 7517)
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// KeySet garde en mémoire les clés publiques d'un JWKS lu depuis un fichier local
+// ou une URL, rechargé périodiquement et lorsqu'un jeton référence un kid inconnu.
+// Si un rechargement échoue, les clés précédentes restent utilisées.
+type KeySet struct {
+	source string
+	client *http.Client
+	logger *slog.Logger
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	lastFetched time.Time
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewKeySet charge le JWKS de source (chemin de fichier ou URL http(s)) et, si
+// refresh > 0, le recharge à cet intervalle.
+func NewKeySet(source string, refresh time.Duration, logger *slog.Logger) (*KeySet, error) {
+	ks := &KeySet{
+		source:   source,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		logger:   logger.With("component", "JWKS"),
+		stopChan: make(chan struct{}),
+	}
+	if err := ks.refresh(context.Background()); err != nil {
+		return nil, err
+	}
+
+	if refresh > 0 {
+		ks.wg.Add(1)
+		go func() {
+			defer ks.wg.Done()
+			ticker := time.NewTicker(refresh)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					if err := ks.refresh(context.Background()); err != nil {
+						ks.logger.Error("JWKS refresh failed, keeping current keys", "source", ks.source, "error", err)
+					}
+				case <-ks.stopChan:
+					return
+				}
+			}
+		}()
+	}
+	return ks, nil
+}
+
+// Stop arrête le rechargement périodique
+func (ks *KeySet) Stop() {
+	close(ks.stopChan)
+	ks.wg.Wait()
+}
+
+// Key retourne la clé publique de kid, en rechargeant le JWKS si elle est inconnue
+func (ks *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	ks.mu.RLock()
+	key, ok := ks.keys[kid]
+	stale := time.Since(ks.lastFetched) >= minRefetchInterval
+	ks.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	// Rotation côté émetteur : la nouvelle clé n'est peut-être pas encore chargée
+	if stale {
+		if err := ks.refresh(ctx); err != nil {
+			ks.logger.ErrorContext(ctx, "JWKS refresh failed, keeping current keys", "source", ks.source, "error", err)
+		}
+		ks.mu.RLock()
+		key, ok = ks.keys[kid]
+		ks.mu.RUnlock()
+		if ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// refresh relit la source et remplace les clés
+func (ks *KeySet) refresh(ctx context.Context) error {
+	data, err := ks.read(ctx)
+
+	ks.mu.Lock()
+	ks.lastFetched = time.Now()
+	ks.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			ks.logger.WarnContext(ctx, "Skipping unsupported JWKS key", "kid", k.Kid, "error", err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("no usable signing key in JWKS %s", ks.source)
+	}
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.mu.Unlock()
+	ks.logger.DebugContext(ctx, "JWKS loaded", "source", ks.source, "keys", len(keys))
+	return nil
+}
+
+// read retourne le contenu du JWKS depuis le fichier ou l'URL
+func (ks *KeySet) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(ks.source, "http://") && !strings.HasPrefix(ks.source, "https://") {
+		return os.ReadFile(ks.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch JWKS %s: %s", ks.source, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// publicKey convertit la JWK en clé publique (RSA, EC P-256/P-384/P-521, Ed25519)
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// decodeBigInt décode un entier encodé en base64url sans remplissage
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
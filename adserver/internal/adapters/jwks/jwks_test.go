@@ -0,0 +1,143 @@
+package jwks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// discard est le logger des tests
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) jwk {
+	return jwk{Kid: kid, Kty: "RSA", Use: "sig", N: b64(key.N.Bytes()), E: b64(big.NewInt(int64(key.E)).Bytes())}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) jwk {
+	return jwk{Kid: kid, Kty: "EC", Crv: key.Curve.Params().Name, X: b64(key.X.Bytes()), Y: b64(key.Y.Bytes())}
+}
+
+// writeJWKS écrit le jeu de clés au format JWKS dans path
+func writeJWKS(t *testing.T, path string, keys ...jwk) {
+	t.Helper()
+	data, err := json.Marshal(map[string][]jwk{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeySetLoadsSupportedKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path,
+		rsaJWK("rsa", &rsaKey.PublicKey),
+		ecJWK("ec", &ecKey.PublicKey),
+		jwk{Kid: "ed", Kty: "OKP", Crv: "Ed25519", X: b64(edKey)},
+		jwk{Kid: "enc", Kty: "RSA", Use: "enc", N: b64(rsaKey.N.Bytes()), E: "AQAB"},
+		jwk{Kid: "hmac", Kty: "oct"},
+		jwk{Kid: "off-curve", Kty: "EC", Crv: "P-256", X: b64([]byte{1}), Y: b64([]byte{2})},
+	)
+	ks, err := NewKeySet(path, 0, discard)
+	if err != nil {
+		t.Fatalf("NewKeySet() error: %v", err)
+	}
+	defer ks.Stop()
+
+	ctx := context.Background()
+	if key, err := ks.Key(ctx, "rsa"); err != nil || !rsaKey.PublicKey.Equal(key) {
+		t.Errorf("Key(rsa) = %v, %v, want the RSA public key", key, err)
+	}
+	if key, err := ks.Key(ctx, "ec"); err != nil || !ecKey.PublicKey.Equal(key) {
+		t.Errorf("Key(ec) = %v, %v, want the EC public key", key, err)
+	}
+	if key, err := ks.Key(ctx, "ed"); err != nil || !edKey.Equal(key) {
+		t.Errorf("Key(ed) = %v, %v, want the Ed25519 public key", key, err)
+	}
+	for _, kid := range []string{"enc", "hmac", "off-curve", "missing"} {
+		if _, err := ks.Key(ctx, kid); err == nil {
+			t.Errorf("Key(%s) succeeded, want an unknown key error", kid)
+		}
+	}
+}
+
+func TestKeySetRefetchesUnknownKid(t *testing.T) {
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, ecJWK("old", &oldKey.PublicKey))
+	ks, err := NewKeySet(path, 0, discard)
+	if err != nil {
+		t.Fatalf("NewKeySet() error: %v", err)
+	}
+	defer ks.Stop()
+
+	// Rotation chez l'émetteur : la nouvelle clé n'est lue qu'une fois le délai minimal écoulé
+	writeJWKS(t, path, ecJWK("new", &newKey.PublicKey))
+	if _, err := ks.Key(context.Background(), "new"); err == nil {
+		t.Fatal("Key(new) succeeded right after loading, want the refetch to be rate limited")
+	}
+	ks.lastFetched = time.Now().Add(-minRefetchInterval)
+	if key, err := ks.Key(context.Background(), "new"); err != nil || !newKey.PublicKey.Equal(key) {
+		t.Fatalf("Key(new) = %v, %v after the interval, want the rotated key", key, err)
+	}
+
+	// Un JWKS devenu illisible conserve les clés chargées
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ks.lastFetched = time.Now().Add(-minRefetchInterval)
+	if _, err := ks.Key(context.Background(), "unknown"); err == nil {
+		t.Error("Key(unknown) succeeded")
+	}
+	if _, err := ks.Key(context.Background(), "new"); err != nil {
+		t.Errorf("Key(new) after a failed refresh error: %v", err)
+	}
+}
+
+func TestNewKeySetRequiresUsableKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, jwk{Kid: "hmac", Kty: "oct"})
+	if _, err := NewKeySet(path, 0, discard); err == nil {
+		t.Error("NewKeySet() accepted a JWKS without signing keys")
+	}
+	if _, err := NewKeySet(filepath.Join(t.TempDir(), "missing.json"), 0, discard); err == nil {
+		t.Error("NewKeySet() accepted a missing file")
+	}
+}
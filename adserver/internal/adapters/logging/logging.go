@@ -0,0 +1,157 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDHeader est la clé de métadonnée gRPC portant l'identifiant de requête entre services
+const RequestIDHeader = "x-request-id"
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	sampledKey
+)
+
+// New crée un logger JSON filtré au niveau demandé (debug, info, warn ou error).
+// Chaque ligne porte l'identifiant de requête et l'identifiant de trace du contexte.
+func New(w io.Writer, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(strings.TrimSpace(level))); err != nil {
+		return nil, fmt.Errorf("invalid log level %q (expected debug, info, warn or error)", level)
+	}
+	inner := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: lvl})
+	return slog.New(&contextHandler{Handler: inner}), nil
+}
+
+// WithRequestID associe un identifiant de requête au contexte
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID retourne l'identifiant de requête du contexte, ou une chaîne vide
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// sampled indique si les lignes inférieures à warn doivent être écrites pour ce contexte.
+// Hors requête (tâches de fond), tout est écrit.
+func sampled(ctx context.Context) bool {
+	s, ok := ctx.Value(sampledKey).(bool)
+	return !ok || s
+}
+
+// contextHandler enrichit chaque enregistrement avec les identifiants du contexte
+// et écarte les lignes des requêtes non échantillonnées. Les avertissements et
+// les erreurs sont toujours écrits.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelWarn && !sampled(ctx) {
+		return nil
+	}
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// Sampler retient une requête sur every parmi celles des méthodes du chemin critique.
+// Les autres méthodes sont toujours journalisées.
+type Sampler struct {
+	every   uint64
+	methods map[string]bool
+	n       atomic.Uint64
+}
+
+// NewSampler crée un échantillonneur pour les méthodes gRPC (nom complet) données.
+// every = 1 désactive l'échantillonnage.
+func NewSampler(every int, methods ...string) *Sampler {
+	s := &Sampler{every: uint64(max(every, 1)), methods: make(map[string]bool, len(methods))}
+	for _, m := range methods {
+		s.methods[m] = true
+	}
+	return s
+}
+
+func (s *Sampler) keep(method string) bool {
+	if s.every == 1 || !s.methods[method] {
+		return true
+	}
+	return s.n.Add(1)%s.every == 1
+}
+
+// requestContext attribue un identifiant de requête (repris de l'appelant s'il en fournit un)
+// et décide de l'échantillonnage des logs de l'appel
+func requestContext(ctx context.Context, method string, s *Sampler) context.Context {
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDHeader); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	if id == "" {
+		id = uuid.NewString()
+	}
+	ctx = WithRequestID(ctx, id)
+	return context.WithValue(ctx, sampledKey, s.keep(method))
+}
+
+// UnaryServerInterceptor prépare le contexte de log de chaque appel unaire.
+func UnaryServerInterceptor(s *Sampler) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(requestContext(ctx, info.FullMethod, s), req)
+	}
+}
+
+// StreamServerInterceptor prépare le contexte de log de chaque appel en flux.
+func StreamServerInterceptor(s *Sampler) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: requestContext(ss.Context(), info.FullMethod, s)})
+	}
+}
+
+// contextStream substitue le contexte enrichi à celui du flux
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// UnaryClientInterceptor transmet l'identifiant de requête au service appelé.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if id := RequestID(ctx); id != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, RequestIDHeader, id)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -0,0 +1,133 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// lines décode les lignes JSON écrites par le logger
+func lines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func TestNewRejectsUnknownLevel(t *testing.T) {
+	if _, err := New(&bytes.Buffer{}, "verbose"); err == nil {
+		t.Error("New(verbose) succeeded")
+	}
+}
+
+func TestLoggerAddsContextIdentifiers(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, " debug ")
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(WithRequestID(context.Background(), "req-1"), sc)
+	logger.With("component", "test").DebugContext(ctx, "hello")
+
+	got := lines(t, &buf)
+	if len(got) != 1 {
+		t.Fatalf("got %d lines, want 1", len(got))
+	}
+	for key, want := range map[string]string{
+		"request_id": "req-1",
+		"trace_id":   sc.TraceID().String(),
+		"span_id":    sc.SpanID().String(),
+		"component":  "test",
+	} {
+		if got[0][key] != want {
+			t.Errorf("%s = %v, want %s", key, got[0][key], want)
+		}
+	}
+}
+
+func TestSamplerKeepsOneRequestInEvery(t *testing.T) {
+	const serveAd = "/adserver.AdService/ServeAd"
+	s := NewSampler(3, serveAd)
+	kept := 0
+	for i := 0; i < 9; i++ {
+		if s.keep(serveAd) {
+			kept++
+		}
+	}
+	if kept != 3 {
+		t.Errorf("kept %d ServeAd calls out of 9, want 3", kept)
+	}
+	if !s.keep("/adserver.AdService/GetAd") {
+		t.Error("methods outside the critical path must always be logged")
+	}
+	if !NewSampler(0, serveAd).keep(serveAd) {
+		t.Error("every < 1 must disable sampling")
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	logger, _ := New(&buf, "info")
+	const method = "/adserver.AdService/ServeAd"
+	interceptor := UnaryServerInterceptor(NewSampler(2, method))
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+
+	var ids []string
+	handler := func(ctx context.Context, _ any) (any, error) {
+		ids = append(ids, RequestID(ctx))
+		logger.InfoContext(ctx, "served")
+		logger.WarnContext(ctx, "slow")
+		return nil, nil
+	}
+	// L'identifiant de l'appelant est repris, sinon un identifiant est généré
+	incoming := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDHeader, "from-caller"))
+	interceptor(incoming, nil, info, handler)
+	interceptor(context.Background(), nil, info, handler)
+
+	if ids[0] != "from-caller" || ids[1] == "" || ids[1] == ids[0] {
+		t.Errorf("request ids = %q, want the caller's then a generated one", ids)
+	}
+	// Seconde requête non échantillonnée : seul l'avertissement est écrit
+	var messages []string
+	for _, l := range lines(t, &buf) {
+		messages = append(messages, l["msg"].(string)+"@"+l["request_id"].(string))
+	}
+	want := []string{"served@from-caller", "slow@from-caller", "slow@" + ids[1]}
+	if strings.Join(messages, ",") != strings.Join(want, ",") {
+		t.Errorf("messages = %v, want %v", messages, want)
+	}
+}
+
+func TestUnaryClientInterceptorForwardsRequestID(t *testing.T) {
+	var got []string
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		got = md.Get(RequestIDHeader)
+		return nil
+	}
+	UnaryClientInterceptor()(WithRequestID(context.Background(), "req-1"), "/m", nil, nil, nil, invoker)
+	if len(got) != 1 || got[0] != "req-1" {
+		t.Errorf("forwarded %s = %v, want [req-1]", RequestIDHeader, got)
+	}
+}
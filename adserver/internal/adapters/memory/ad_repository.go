@@ -0,0 +1,266 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"adserver/internal/adapters/metrics"
+	"adserver/internal/adapters/tracing"
+	"adserver/internal/domain"
+	"adserver/internal/ports/out"
+
+	"github.com/google/uuid"
+)
+
+// adRepository implémente l'interface AdRepository en mémoire, pour les tests et le mode
+// développement (STORAGE=memory). Comme avec MongoDB, les requêtes sont restreintes au
+// locataire du contexte et les publicités sont listées dans leur ordre d'insertion.
+type adRepository struct {
+	mu        sync.RWMutex
+	ads       map[uuid.UUID]*storedAd
+	next      int64                    // Rang de la prochaine insertion
+	archive   map[uuid.UUID]archivedAd // Publicités expirées
+	retention time.Duration            // Durée de conservation de l'archive, 0 = illimitée
+	clock     out.Clock
+}
+
+// storedAd est une publicité et son rang d'insertion
+type storedAd struct {
+	ad  domain.Pub
+	seq int64
+}
+
+// archivedAd est une publicité expirée et sa date d'archivage
+type archivedAd struct {
+	ad domain.Pub
+	at time.Time
+}
+
+// NewAdRepository crée un repository de publicités vide. DeleteExpired archive les
+// publicités expirées à l'heure de clock et, comme l'index TTL de MongoDB, purge
+// l'archive au-delà de retention (0 = jamais).
+func NewAdRepository(clock out.Clock, retention time.Duration) out.AdRepository {
+	return &adRepository{
+		ads:       make(map[uuid.UUID]*storedAd),
+		archive:   make(map[uuid.UUID]archivedAd),
+		retention: retention,
+		clock:     clock,
+	}
+}
+
+// Create enregistre une copie de la publicité et retourne son ID
+func (r *adRepository) Create(ctx context.Context, ad *domain.Pub) (string, error) {
+	defer metrics.ObserveRepository("memory", "Create", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "Create")
+	defer span.End()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.ads[ad.ID]; exists {
+		return "", fmt.Errorf("ad %s already exists", ad.ID)
+	}
+	r.ads[ad.ID] = &storedAd{ad: copyAd(ad), seq: r.next}
+	r.next++
+	return ad.ID.String(), nil
+}
+
+// GetByID retourne une copie de la publicité, nil si elle est absente ou d'un autre locataire
+func (r *adRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Pub, error) {
+	defer metrics.ObserveRepository("memory", "GetByID", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "GetByID")
+	defer span.End()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	stored := r.find(ctx, id)
+	if stored == nil {
+		return nil, nil
+	}
+	ad := copyAd(&stored.ad)
+	return &ad, nil
+}
+
+// Exists vérifie si la publicité existe pour le locataire du contexte
+func (r *adRepository) Exists(ctx context.Context, id uuid.UUID) (bool, error) {
+	defer metrics.ObserveRepository("memory", "Exists", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "Exists")
+	defer span.End()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.find(ctx, id) != nil, nil
+}
+
+// IncrementImpressions incrémente le compteur et retourne le nouveau total
+func (r *adRepository) IncrementImpressions(ctx context.Context, id uuid.UUID) (int64, error) {
+	defer metrics.ObserveRepository("memory", "IncrementImpressions", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "IncrementImpressions")
+	defer span.End()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := r.find(ctx, id)
+	if stored == nil {
+		return 0, domain.ErrAdNotFound
+	}
+	stored.ad.Impressions++
+	return stored.ad.Impressions, nil
+}
+
+// ResetImpressions remet le compteur à zéro et retourne l'ancien total
+func (r *adRepository) ResetImpressions(ctx context.Context, id uuid.UUID) (int64, error) {
+	defer metrics.ObserveRepository("memory", "ResetImpressions", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "ResetImpressions")
+	defer span.End()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := r.find(ctx, id)
+	if stored == nil {
+		return 0, domain.ErrAdNotFound
+	}
+	old := stored.ad.Impressions
+	stored.ad.Impressions = 0
+	return old, nil
+}
+
+// AdjustImpressions ajoute delta au compteur d'impressions et retourne le nouveau total
+func (r *adRepository) AdjustImpressions(ctx context.Context, id uuid.UUID, delta int64) (int64, error) {
+	defer metrics.ObserveRepository("memory", "AdjustImpressions", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "AdjustImpressions")
+	defer span.End()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := r.find(ctx, id)
+	if stored == nil {
+		return 0, domain.ErrAdNotFound
+	}
+	stored.ad.Impressions += delta
+	return stored.ad.Impressions, nil
+}
+
+// DeleteExpired archive les publicités expirées du locataire du contexte, compteur final
+// compris, puis purge les archives plus anciennes que la rétention
+func (r *adRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	defer metrics.ObserveRepository("memory", "DeleteExpired", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "DeleteExpired")
+	defer span.End()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.clock.Now()
+	var archived int64
+	for id, stored := range r.ads {
+		if visible(ctx, &stored.ad) && stored.ad.ExpiresAt.Before(now) {
+			r.archive[id] = archivedAd{ad: stored.ad, at: now}
+			delete(r.ads, id)
+			archived++
+		}
+	}
+	if r.retention > 0 {
+		for id, old := range r.archive {
+			if now.Sub(old.at) >= r.retention {
+				delete(r.archive, id)
+			}
+		}
+	}
+	return archived, nil
+}
+
+// List retourne une page des publicités correspondant au filtre, par ordre d'insertion.
+// Le filtre compare par égalité les champs title, description, url et tenant (noms BSON) ;
+// un locataire dans le contexte remplace celui du filtre.
+func (r *adRepository) List(ctx context.Context, filter map[string]interface{}, offset, limit int64) ([]*domain.Pub, error) {
+	defer metrics.ObserveRepository("memory", "List", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "List")
+	defer span.End()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := filter["tenant"]; ok && domain.TenantFromContext(ctx) != "" {
+		filter = maps.Clone(filter)
+		delete(filter, "tenant")
+	}
+
+	var matched []*storedAd
+	for _, stored := range r.ads {
+		ok, err := matches(&stored.ad, filter)
+		if err != nil {
+			return nil, err
+		}
+		if ok && visible(ctx, &stored.ad) {
+			matched = append(matched, stored)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].seq < matched[j].seq })
+
+	var ads []*domain.Pub
+	for i := offset; i < int64(len(matched)) && (limit <= 0 || i < offset+limit); i++ {
+		ad := copyAd(&matched[i].ad)
+		ads = append(ads, &ad)
+	}
+	return ads, nil
+}
+
+// GetImpressions retourne le compteur d'impressions de la publicité
+func (r *adRepository) GetImpressions(ctx context.Context, id uuid.UUID) (int64, error) {
+	defer metrics.ObserveRepository("memory", "GetImpressions", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "GetImpressions")
+	defer span.End()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	stored := r.find(ctx, id)
+	if stored == nil {
+		return 0, domain.ErrAdNotFound
+	}
+	return stored.ad.Impressions, nil
+}
+
+// find retourne la publicité si elle est visible du locataire du contexte
+func (r *adRepository) find(ctx context.Context, id uuid.UUID) *storedAd {
+	stored, ok := r.ads[id]
+	if !ok || !visible(ctx, &stored.ad) {
+		return nil
+	}
+	return stored
+}
+
+// visible indique si la publicité appartient au locataire du contexte ; sans locataire,
+// toutes les publicités sont visibles
+func visible(ctx context.Context, ad *domain.Pub) bool {
+	tenant := domain.TenantFromContext(ctx)
+	return tenant == "" || ad.Tenant == tenant
+}
+
+// matches compare la publicité aux critères d'égalité du filtre
+func matches(ad *domain.Pub, filter map[string]interface{}) (bool, error) {
+	for field, want := range filter {
+		var got string
+		switch field {
+		case "title":
+			got = ad.Title
+		case "description":
+			if ad.Description != nil {
+				got = *ad.Description
+			}
+		case "url":
+			got = ad.URL
+		case "tenant":
+			got = ad.Tenant
+		default:
+			return false, fmt.Errorf("unsupported filter field %q", field)
+		}
+		if fmt.Sprint(want) != got {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// copyAd copie la publicité, description comprise, pour isoler le stockage des appelants
+func copyAd(ad *domain.Pub) domain.Pub {
+	c := *ad
+	if ad.Description != nil {
+		description := *ad.Description
+		c.Description = &description
+	}
+	return c
+}
@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"adserver/internal/adapters/metrics"
+	"adserver/internal/adapters/tracing"
+	"adserver/internal/domain"
+	"adserver/internal/ports/out"
+
+	"github.com/google/uuid"
+)
+
+// apiKeyRepository implémente APIKeyRepository en mémoire. Comme la collection MongoDB,
+// il refuse deux clés de même ID ou de même empreinte.
+type apiKeyRepository struct {
+	mu   sync.RWMutex
+	keys map[uuid.UUID]*domain.APIKey
+}
+
+// NewAPIKeyRepository crée un repository de clés d'API vide
+func NewAPIKeyRepository() out.APIKeyRepository {
+	return &apiKeyRepository{keys: make(map[uuid.UUID]*domain.APIKey)}
+}
+
+// Create enregistre une copie de la clé
+func (r *apiKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	defer metrics.ObserveRepository("memory", "CreateAPIKey", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "CreateAPIKey")
+	defer span.End()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.keys[key.ID]; exists {
+		return fmt.Errorf("api key %s already exists", key.ID)
+	}
+	for _, existing := range r.keys {
+		if existing.Hash == key.Hash {
+			return fmt.Errorf("api key hash already registered")
+		}
+	}
+	c := copyKey(key)
+	r.keys[key.ID] = &c
+	return nil
+}
+
+// GetByID récupère une clé du locataire du contexte par son ID
+func (r *apiKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	defer metrics.ObserveRepository("memory", "GetAPIKeyByID", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "GetAPIKeyByID")
+	defer span.End()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	key, ok := r.keys[id]
+	if !ok || !keyVisible(ctx, key) {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	c := copyKey(key)
+	return &c, nil
+}
+
+// GetByHash récupère une clé par l'empreinte de son secret, tous locataires confondus
+func (r *apiKeyRepository) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	defer metrics.ObserveRepository("memory", "GetAPIKeyByHash", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "GetAPIKeyByHash")
+	defer span.End()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, key := range r.keys {
+		if key.Hash == hash {
+			c := copyKey(key)
+			return &c, nil
+		}
+	}
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+// List retourne les clés du locataire du contexte, ou toutes les clés sans locataire,
+// par date de création
+func (r *apiKeyRepository) List(ctx context.Context) ([]*domain.APIKey, error) {
+	defer metrics.ObserveRepository("memory", "ListAPIKeys", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "ListAPIKeys")
+	defer span.End()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var keys []*domain.APIKey
+	for _, key := range r.keys {
+		if keyVisible(ctx, key) {
+			c := copyKey(key)
+			keys = append(keys, &c)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+// Revoke marque la clé comme révoquée ; une clé déjà révoquée garde sa date de révocation
+func (r *apiKeyRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	defer metrics.ObserveRepository("memory", "RevokeAPIKey", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "RevokeAPIKey")
+	defer span.End()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, ok := r.keys[id]
+	if !ok || !keyVisible(ctx, key) {
+		return domain.ErrAPIKeyNotFound
+	}
+	if key.RevokedAt == nil {
+		key.RevokedAt = &at
+	}
+	return nil
+}
+
+// keyVisible indique si la clé appartient au locataire du contexte ; sans locataire,
+// toutes les clés sont visibles
+func keyVisible(ctx context.Context, key *domain.APIKey) bool {
+	tenant := domain.TenantFromContext(ctx)
+	return tenant == "" || key.Tenant == tenant
+}
+
+// copyKey copie la clé, date de révocation comprise
+func copyKey(key *domain.APIKey) domain.APIKey {
+	c := *key
+	if key.RevokedAt != nil {
+		revokedAt := *key.RevokedAt
+		c.RevokedAt = &revokedAt
+	}
+	return c
+}
@@ -0,0 +1,16 @@
+package memory
+
+import (
+	"testing"
+
+	"adserver/internal/ports/out"
+	"adserver/internal/ports/out/contract"
+)
+
+func TestAdRepositoryContract(t *testing.T) {
+	contract.AdRepository(t, func(_ *testing.T, clock out.Clock) out.AdRepository { return NewAdRepository(clock, 0) })
+}
+
+func TestAPIKeyRepositoryContract(t *testing.T) {
+	contract.APIKeyRepository(t, func(*testing.T) out.APIKeyRepository { return NewAPIKeyRepository() })
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// family retourne la métrique name du registre par défaut
+func family(t *testing.T, name string) *dto.MetricFamily {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error: %v", err)
+	}
+	for _, f := range families {
+		if f.GetName() == name {
+			return f
+		}
+	}
+	t.Fatalf("metric %s is not registered", name)
+	return nil
+}
+
+// sample retourne la série de la métrique name portant les labels donnés
+func sample(t *testing.T, name string, labels map[string]string) *dto.Metric {
+	t.Helper()
+	for _, m := range family(t, name).GetMetric() {
+		matched := 0
+		for _, l := range m.GetLabel() {
+			if labels[l.GetName()] == l.GetValue() {
+				matched++
+			}
+		}
+		if matched == len(labels) {
+			return m
+		}
+	}
+	t.Fatalf("metric %s has no series %v", name, labels)
+	return nil
+}
+
+func TestMetricsAreRegistered(t *testing.T) {
+	ObserveRepository("memory", "GetByID", time.Now())
+	ImpressionTrackingFailed()
+	TrackerCircuitState(2)
+	TrackerCall("TrackImpression", "retry")
+	RateLimited("/adserver.AdService/ServeAd", "ip", "quota")
+	AdCacheLookup("local", "hit")
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		check  func(m *dto.Metric) bool
+	}{
+		{"adserver_repository_operation_seconds", map[string]string{"repository": "memory", "operation": "GetByID"}, func(m *dto.Metric) bool { return m.GetHistogram().GetSampleCount() >= 1 }},
+		{"adserver_impression_tracking_errors_total", nil, func(m *dto.Metric) bool { return m.GetCounter().GetValue() >= 1 }},
+		{"adserver_tracker_circuit_state", nil, func(m *dto.Metric) bool { return m.GetGauge().GetValue() == 2 }},
+		{"adserver_tracker_calls_total", map[string]string{"method": "TrackImpression", "result": "retry"}, func(m *dto.Metric) bool { return m.GetCounter().GetValue() >= 1 }},
+		{"adserver_rate_limited_total", map[string]string{"method": "/adserver.AdService/ServeAd", "rule": "ip", "reason": "quota"}, func(m *dto.Metric) bool { return m.GetCounter().GetValue() >= 1 }},
+		{"adserver_ad_cache_lookups_total", map[string]string{"tier": "local", "result": "hit"}, func(m *dto.Metric) bool { return m.GetCounter().GetValue() >= 1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if m := sample(t, tt.name, tt.labels); !tt.check(m) {
+				t.Errorf("%s%v = %v", tt.name, tt.labels, m)
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptorLabelsStatusCode(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/adserver.AdService/GetAd"}
+	interceptor := UnaryServerInterceptor()
+	for _, err := range []error{nil, status.Error(codes.NotFound, "not found")} {
+		interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) { return nil, err })
+	}
+
+	for _, code := range []string{"OK", "NotFound"} {
+		m := sample(t, "adserver_grpc_server_handling_seconds", map[string]string{"method": info.FullMethod, "code": code})
+		if m.GetHistogram().GetSampleCount() < 1 {
+			t.Errorf("no %s call observed", code)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"adserver/internal/adapters/metrics"
+	"adserver/internal/adapters/tracing"
+	"adserver/internal/domain"
+	"adserver/internal/ports/out"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// apiKeyRepository implémente APIKeyRepository sur la collection "api_keys"
+type apiKeyRepository struct {
+	collection *mongo.Collection
+	logger     *slog.Logger
+}
+
+// NewAPIKeyRepository crée le repository des clés d'API
+func NewAPIKeyRepository(db *mongo.Database, logger *slog.Logger) out.APIKeyRepository {
+	return &apiKeyRepository{collection: db.Collection("api_keys"), logger: logger.With("component", "APIKeyRepository")}
+}
+
+// Create insère une nouvelle clé
+func (r *apiKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	defer metrics.ObserveRepository("mongodb", "CreateAPIKey", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "mongodb", "CreateAPIKey")
+	defer span.End()
+	if _, err := r.collection.InsertOne(ctx, key); err != nil {
+		r.logger.ErrorContext(ctx, "Create failed", "key_id", key.ID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// GetByID récupère une clé par son ID
+func (r *apiKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	defer metrics.ObserveRepository("mongodb", "GetAPIKeyByID", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "mongodb", "GetAPIKeyByID")
+	defer span.End()
+	return r.findOne(ctx, scoped(ctx, bson.M{"_id": id}))
+}
+
+// GetByHash récupère une clé par l'empreinte de son secret. La requête n'est pas restreinte
+// au locataire : elle sert à authentifier l'appelant, dont le locataire n'est pas encore connu.
+func (r *apiKeyRepository) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	defer metrics.ObserveRepository("mongodb", "GetAPIKeyByHash", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "mongodb", "GetAPIKeyByHash")
+	defer span.End()
+	return r.findOne(ctx, bson.M{"hash": hash})
+}
+
+// List retourne les clés du locataire du contexte, ou toutes les clés sans locataire
+func (r *apiKeyRepository) List(ctx context.Context) ([]*domain.APIKey, error) {
+	defer metrics.ObserveRepository("mongodb", "ListAPIKeys", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "mongodb", "ListAPIKeys")
+	defer span.End()
+	cursor, err := r.collection.Find(ctx, scoped(ctx, bson.M{}))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "List failed", "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var keys []*domain.APIKey
+	if err := cursor.All(ctx, &keys); err != nil {
+		r.logger.ErrorContext(ctx, "List decode failed", "error", err)
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Revoke marque la clé comme révoquée ; une clé déjà révoquée garde sa date de révocation.
+// Une clé d'un autre locataire que celui du contexte est considérée comme absente.
+func (r *apiKeyRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	defer metrics.ObserveRepository("mongodb", "RevokeAPIKey", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "mongodb", "RevokeAPIKey")
+	defer span.End()
+	result, err := r.collection.UpdateOne(ctx,
+		scoped(ctx, bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}}),
+		bson.M{"$set": bson.M{"revoked_at": at}},
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Revoke failed", "key_id", id, "error", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		// Clé absente, ou déjà révoquée
+		if _, err := r.findOne(ctx, scoped(ctx, bson.M{"_id": id})); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findOne récupère la clé correspondant au filtre
+func (r *apiKeyRepository) findOne(ctx context.Context, filter bson.M) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.collection.FindOne(ctx, filter).Decode(&key)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		r.logger.ErrorContext(ctx, "FindOne failed", "error", err)
+		return nil, err
+	}
+	return &key, nil
+}
@@ -0,0 +1,58 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// archiveTTLIndex est l'index TTL qui purge les annonces archivées
+const archiveTTLIndex = "archived_at_ttl"
+
+// EnsureArchiveRetention aligne l'index TTL de la collection d'archive sur la durée de
+// rétention : MongoDB supprime alors de lui-même les annonces archivées depuis plus de
+// retention. L'index est créé, sa durée modifiée sur place (collMod) si elle a changé,
+// ou supprimé si retention vaut 0 (conservation illimitée).
+// Contrairement aux migrations, l'opération est rejouée à chaque démarrage, la rétention
+// étant un réglage et non une version du schéma.
+// La durée d'un index TTL est un nombre entier de secondes sur 32 bits.
+func EnsureArchiveRetention(ctx context.Context, db *mongo.Database, collection string, retention time.Duration) error {
+	if retention > 0 && (retention < time.Second || retention/time.Second > math.MaxInt32) {
+		return fmt.Errorf("archive retention %s must be between 1s and %d seconds", retention, math.MaxInt32)
+	}
+	indexes := db.Collection(collection).Indexes()
+	specs, err := indexes.ListSpecifications(ctx)
+	if err != nil {
+		return err
+	}
+	var current *mongo.IndexSpecification
+	for _, spec := range specs {
+		if spec.Name == archiveTTLIndex {
+			current = spec
+		}
+	}
+
+	seconds := int32(retention / time.Second)
+	switch {
+	case retention <= 0:
+		if current != nil {
+			_, err = indexes.DropOne(ctx, archiveTTLIndex)
+		}
+	case current == nil:
+		_, err = indexes.CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: "archived_at", Value: 1}},
+			Options: options.Index().SetName(archiveTTLIndex).SetExpireAfterSeconds(seconds),
+		})
+	case current.ExpireAfterSeconds == nil || *current.ExpireAfterSeconds != seconds:
+		err = db.RunCommand(ctx, bson.D{
+			{Key: "collMod", Value: collection},
+			{Key: "index", Value: bson.D{{Key: "name", Value: archiveTTLIndex}, {Key: "expireAfterSeconds", Value: seconds}}},
+		}).Err()
+	}
+	return err
+}
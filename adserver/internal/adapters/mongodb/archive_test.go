@@ -0,0 +1,95 @@
+package mongodb
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"adserver/internal/adapters/clock"
+	"adserver/internal/domain"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestEnsureArchiveRetentionRejectsInvalidDurations(t *testing.T) {
+	// La durée est refusée avant tout accès à la base
+	for _, retention := range []time.Duration{time.Millisecond, (math.MaxInt32 + 1) * time.Second} {
+		if err := EnsureArchiveRetention(context.Background(), nil, "ads_archive", retention); err == nil {
+			t.Errorf("EnsureArchiveRetention(%s) succeeded, want an error", retention)
+		}
+	}
+}
+
+func TestEnsureArchiveRetention(t *testing.T) {
+	db := newDatabase(t)
+	ctx := context.Background()
+
+	// expireAfter retourne la durée de l'index TTL de l'archive, nil s'il n'existe pas
+	expireAfter := func() *int32 {
+		t.Helper()
+		specs, err := db.Collection("ads_archive").Indexes().ListSpecifications(ctx)
+		if err != nil {
+			t.Fatalf("ListSpecifications() error: %v", err)
+		}
+		for _, spec := range specs {
+			if spec.Name == archiveTTLIndex {
+				return spec.ExpireAfterSeconds
+			}
+		}
+		return nil
+	}
+
+	for _, retention := range []time.Duration{24 * time.Hour, 48 * time.Hour} {
+		if err := EnsureArchiveRetention(ctx, db, "ads_archive", retention); err != nil {
+			t.Fatalf("EnsureArchiveRetention(%s) error: %v", retention, err)
+		}
+		if got := expireAfter(); got == nil || *got != int32(retention/time.Second) {
+			t.Errorf("expireAfterSeconds after EnsureArchiveRetention(%s) = %v", retention, got)
+		}
+	}
+	if err := EnsureArchiveRetention(ctx, db, "ads_archive", 0); err != nil {
+		t.Fatalf("EnsureArchiveRetention(0) error: %v", err)
+	}
+	if got := expireAfter(); got != nil {
+		t.Errorf("TTL index still present with retention 0, expireAfterSeconds = %d", *got)
+	}
+}
+
+func TestDeleteExpiredReplacesOnlyLowerArchiveCopies(t *testing.T) {
+	db := newDatabase(t)
+	fake := clock.NewFake(testStart)
+	repo := NewMongoRepository(db, "ads", "ads_archive", fake, discard)
+	ctx := context.Background()
+
+	// Copie périmée d'une exécution interrompue, et copie complétée par des impressions tardives
+	stale := domain.Pub{ID: uuid.New(), Tenant: "acme", Title: "stale", ExpiresAt: testStart.Add(time.Minute), Impressions: 3}
+	late := domain.Pub{ID: uuid.New(), Tenant: "acme", Title: "late", ExpiresAt: testStart.Add(time.Minute), Impressions: 3}
+	for _, ad := range []domain.Pub{stale, late} {
+		if _, err := db.Collection("ads").InsertOne(ctx, ad); err != nil {
+			t.Fatal(err)
+		}
+	}
+	archived := map[uuid.UUID]int64{stale.ID: 1, late.ID: 9}
+	for id, impressions := range archived {
+		if _, err := db.Collection("ads_archive").InsertOne(ctx, bson.M{"_id": id, "impressions": impressions}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fake.Advance(time.Hour)
+	if got, err := repo.DeleteExpired(ctx); err != nil || got != 2 {
+		t.Fatalf("DeleteExpired() = %d, %v, want 2", got, err)
+	}
+	want := map[uuid.UUID]int64{stale.ID: 3, late.ID: 9}
+	for id, impressions := range want {
+		var doc domain.Pub
+		if err := db.Collection("ads_archive").FindOne(ctx, bson.M{"_id": id}).Decode(&doc); err != nil {
+			t.Fatalf("archived ad %s: %v", id, err)
+		}
+		if doc.Impressions != impressions {
+			t.Errorf("archived impressions of %s = %d, want %d", id, doc.Impressions, impressions)
+		}
+	}
+}
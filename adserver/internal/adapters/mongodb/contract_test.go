@@ -0,0 +1,63 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"adserver/internal/ports/out"
+	"adserver/internal/ports/out/contract"
+	"shared/mongomigrate"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// discard est le logger des tests
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// newDatabase retourne une base migrée propre au test, supprimée à la fin du test.
+// Sans MONGO_TEST_URI, le test est ignoré.
+func newDatabase(t *testing.T) *mongo.Database {
+	t.Helper()
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI not set")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("mongo.Connect() error: %v", err)
+	}
+	db := client.Database(fmt.Sprintf("contract_%d", time.Now().UnixNano()))
+	t.Cleanup(func() {
+		_ = db.Drop(context.Background())
+		_ = client.Disconnect(context.Background())
+	})
+	if _, err := mongomigrate.Migrate(ctx, db, Migrations("ads"), discard); err != nil {
+		t.Fatalf("Migrate() error: %v", err)
+	}
+	return db
+}
+
+func TestAdRepositoryContract(t *testing.T) {
+	contract.AdRepository(t, func(t *testing.T, clock out.Clock) out.AdRepository {
+		return NewMongoRepository(newDatabase(t), "ads", "ads_archive", clock, discard)
+	})
+}
+
+func TestImpressionBufferContract(t *testing.T) {
+	contract.AdRepository(t, func(t *testing.T, clock out.Clock) out.AdRepository {
+		db := newDatabase(t)
+		return NewImpressionBuffer(NewMongoRepository(db, "ads", "ads_archive", clock, discard), db, "ads", "ads_archive", time.Minute, clock, discard)
+	})
+}
+
+func TestAPIKeyRepositoryContract(t *testing.T) {
+	contract.APIKeyRepository(t, func(t *testing.T) out.APIKeyRepository { return NewAPIKeyRepository(newDatabase(t), discard) })
+}
@@ -0,0 +1,503 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"adserver/internal/adapters/metrics"
+	"adserver/internal/adapters/tracing"
+	"adserver/internal/domain"
+	"adserver/internal/ports/out"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	impressionShards       = 32               // Partitions des compteurs, pour limiter la contention des verrous
+	impressionFlushTimeout = 10 * time.Second // Délai maximal d'une écriture périodique
+)
+
+// ImpressionBuffer décore un AdRepository MongoDB d'un tampon des impressions :
+// IncrementImpressions ne fait plus de $inc par impression sur le document de la
+// publicité, qui devient un point chaud d'écriture sous charge, mais incrémente un
+// compteur en mémoire, écrit toutes les interval en un seul BulkWrite.
+//
+// Le total retourné est le dernier total lu dans MongoDB plus les impressions en
+// attente ; il est relu après chaque écriture, ce qui y ajoute celles des autres
+// réplicas. GetImpressions, GetByID et List ajoutent les impressions en attente au
+// total persisté. ResetImpressions remplace le total, impressions en attente comprises,
+// AdjustImpressions le corrige, et DeleteExpired écrit le tampon avant d'archiver. Les
+// impressions d'une publicité archivée depuis, par cette réplica ou une autre, sont
+// ajoutées à son total dans l'archive.
+//
+// Une écriture fige les impressions en attente sous les verrous des partitions, puis
+// écrit hors de tout verrou : les incréments continuent pendant l'écriture. Une lecture
+// qui la chevauche recommence une fois l'écriture terminée, pour ne pas compter deux fois
+// les impressions en cours d'écriture ; les lectures hors écriture ne sont jamais bloquées.
+//
+// Un arrêt sans Stop perd au plus interval d'impressions ; une écriture en échec est
+// retentée à l'écriture suivante.
+type ImpressionBuffer struct {
+	out.AdRepository
+	collection impressionCollection
+	archive    impressionCollection
+	shards     [impressionShards]impressionShard
+	writeMu    sync.Mutex    // Une écriture, un reset ou une correction du total à la fois
+	stateMu    sync.Mutex    // Protège generation et writing
+	generation uint64        // Nombre d'écritures commencées
+	writing    chan struct{} // Fermé à la fin de l'écriture en cours, nil hors écriture
+	interval   time.Duration
+	clock      out.Clock
+	logger     *slog.Logger
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+// impressionCollection est la partie de *mongo.Collection utilisée par le tampon
+type impressionCollection interface {
+	BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error)
+	Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error)
+}
+
+// impressionShard est une partition des compteurs
+type impressionShard struct {
+	mu       sync.Mutex
+	counters map[uuid.UUID]*impressionCounter
+}
+
+// impressionCounter est le compteur d'une publicité ; il est protégé par le verrou de sa partition
+type impressionCounter struct {
+	tenant    string // Locataire de la publicité, pour restreindre les incréments comme le repository
+	persisted int64  // Dernier total lu dans MongoDB
+	inflight  int64  // Impressions en cours d'écriture
+	pending   int64  // Impressions pas encore écrites
+	touched   bool   // Incrémenté depuis la dernière écriture
+}
+
+// NewImpressionBuffer retourne repo décoré d'un tampon des impressions écrit dans
+// collection toutes les interval, ou dans archiveCollection pour les publicités archivées,
+// à démarrer avec Start
+func NewImpressionBuffer(repo out.AdRepository, db *mongo.Database, collection, archiveCollection string, interval time.Duration, clock out.Clock, logger *slog.Logger) *ImpressionBuffer {
+	return newImpressionBuffer(repo, db.Collection(collection), db.Collection(archiveCollection), interval, clock, logger)
+}
+
+// newImpressionBuffer implémente NewImpressionBuffer sur des collections quelconques
+func newImpressionBuffer(repo out.AdRepository, collection, archive impressionCollection, interval time.Duration, clock out.Clock, logger *slog.Logger) *ImpressionBuffer {
+	b := &ImpressionBuffer{
+		AdRepository: repo,
+		collection:   collection,
+		archive:      archive,
+		interval:     interval,
+		clock:        clock,
+		logger:       logger.With("component", "ImpressionBuffer"),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	for i := range b.shards {
+		b.shards[i].counters = make(map[uuid.UUID]*impressionCounter)
+	}
+	return b
+}
+
+// Start lance l'écriture périodique du tampon
+func (b *ImpressionBuffer) Start() {
+	ticker := b.clock.NewTicker(b.interval)
+	go func() {
+		defer close(b.done)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-b.stop:
+				return
+			case <-ticker.C():
+				flushCtx, flushCancel := context.WithTimeout(context.Background(), impressionFlushTimeout)
+				if err := b.Flush(flushCtx); err != nil {
+					b.logger.Error("Failed to flush impressions, retrying next interval", "error", err)
+				}
+				flushCancel()
+			}
+		}
+	}()
+}
+
+// Stop arrête l'écriture périodique puis écrit les impressions en attente
+func (b *ImpressionBuffer) Stop(ctx context.Context) error {
+	close(b.stop)
+	<-b.done
+	return b.Flush(ctx)
+}
+
+// IncrementImpressions incrémente le compteur en mémoire et retourne le nouveau total.
+// Le total persisté d'une publicité est lu à sa première impression (ou après une
+// période sans impression), ce qui vérifie aussi son existence et son locataire.
+func (b *ImpressionBuffer) IncrementImpressions(ctx context.Context, id uuid.UUID) (int64, error) {
+	shard := b.shard(id)
+	shard.mu.Lock()
+	if c, ok := shard.counters[id]; ok {
+		defer shard.mu.Unlock()
+		if !visibleTo(ctx, c.tenant) {
+			return 0, domain.ErrAdNotFound
+		}
+		return c.increment(), nil
+	}
+	shard.mu.Unlock()
+
+	ad, err := b.AdRepository.GetByID(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if ad == nil {
+		return 0, domain.ErrAdNotFound
+	}
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	c, ok := shard.counters[id]
+	if !ok {
+		c = &impressionCounter{tenant: ad.Tenant, persisted: ad.Impressions}
+		shard.counters[id] = c
+	}
+	return c.increment(), nil
+}
+
+// ResetImpressions réinitialise le total et retourne l'ancien, impressions en attente comprises
+func (b *ImpressionBuffer) ResetImpressions(ctx context.Context, id uuid.UUID) (int64, error) {
+	b.writeMu.Lock()
+	defer b.writeMu.Unlock()
+	defer b.endWrite(b.beginWrite())
+	count, err := b.AdRepository.ResetImpressions(ctx, id)
+	if err != nil {
+		return count, err
+	}
+	shard := b.shard(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if c, ok := shard.counters[id]; ok {
+		count += c.pending
+		c.persisted, c.pending = 0, 0
+	}
+	return count, nil
+}
+
+// AdjustImpressions corrige le total persisté et retourne le nouveau total, impressions
+// en attente comprises
+func (b *ImpressionBuffer) AdjustImpressions(ctx context.Context, id uuid.UUID, delta int64) (int64, error) {
+	b.writeMu.Lock()
+	defer b.writeMu.Unlock()
+	defer b.endWrite(b.beginWrite())
+	count, err := b.AdRepository.AdjustImpressions(ctx, id, delta)
+	if err != nil {
+		return count, err
+	}
+	shard := b.shard(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if c, ok := shard.counters[id]; ok {
+		c.persisted = count
+		count += c.pending
+	}
+	return count, nil
+}
+
+// GetImpressions retourne le total persisté plus les impressions en attente
+func (b *ImpressionBuffer) GetImpressions(ctx context.Context, id uuid.UUID) (int64, error) {
+	var count int64
+	err := b.read(ctx, func() (err error) {
+		count, err = b.AdRepository.GetImpressions(ctx, id)
+		return err
+	}, func() {
+		count += b.pending(id)
+	})
+	return count, err
+}
+
+// GetByID retourne la publicité, impressions en attente comprises
+func (b *ImpressionBuffer) GetByID(ctx context.Context, id uuid.UUID) (*domain.Pub, error) {
+	var ad *domain.Pub
+	err := b.read(ctx, func() (err error) {
+		ad, err = b.AdRepository.GetByID(ctx, id)
+		return err
+	}, func() {
+		if ad != nil {
+			ad.Impressions += b.pending(id)
+		}
+	})
+	return ad, err
+}
+
+// List liste les publicités, impressions en attente comprises
+func (b *ImpressionBuffer) List(ctx context.Context, filter map[string]interface{}, offset, limit int64) ([]*domain.Pub, error) {
+	var ads []*domain.Pub
+	err := b.read(ctx, func() (err error) {
+		ads, err = b.AdRepository.List(ctx, filter, offset, limit)
+		return err
+	}, func() {
+		for _, ad := range ads {
+			ad.Impressions += b.pending(ad.ID)
+		}
+	})
+	return ads, err
+}
+
+// DeleteExpired écrit le tampon, pour archiver les totaux définitifs, puis retire les
+// publicités expirées, sans retenir les écritures suivantes : le repository ne supprime
+// pas une publicité dont le total a changé depuis sa copie dans l'archive. Les impressions
+// comptées pendant l'archivage, ou restées en attente après une écriture en échec, sont
+// ajoutées à l'archive à l'écriture suivante.
+func (b *ImpressionBuffer) DeleteExpired(ctx context.Context) (int64, error) {
+	if err := b.Flush(ctx); err != nil {
+		b.logger.WarnContext(ctx, "Failed to flush impressions before archiving", "error", err)
+	}
+	return b.AdRepository.DeleteExpired(ctx)
+}
+
+// Flush écrit les impressions en attente en un BulkWrite de $inc, puis relit les totaux
+// des publicités écrites. Les impressions d'une écriture en échec restent en attente.
+// Celles d'une publicité disparue entre-temps sont ajoutées à son total archivé.
+// Les compteurs inactifs depuis l'écriture précédente, ou dont la publicité a disparu,
+// sont retirés.
+func (b *ImpressionBuffer) Flush(ctx context.Context) error {
+	b.writeMu.Lock()
+	defer b.writeMu.Unlock()
+	defer metrics.ObserveRepository("mongodb", "FlushImpressions", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "mongodb", "FlushImpressions")
+	defer span.End()
+	start := time.Now()
+	defer b.endWrite(b.beginWrite())
+
+	var (
+		ids    []uuid.UUID
+		deltas []int64
+	)
+	for i := range b.shards {
+		shard := &b.shards[i]
+		shard.mu.Lock()
+		for id, c := range shard.counters {
+			switch {
+			case c.pending > 0:
+				ids = append(ids, id)
+				deltas = append(deltas, c.pending)
+				c.inflight, c.pending = c.pending, 0
+			case !c.touched:
+				delete(shard.counters, id)
+			}
+			c.touched = false
+		}
+		shard.mu.Unlock()
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	failed, err := increment(ctx, b.collection, ids, deltas)
+	var (
+		totals     map[uuid.UUID]int64
+		refreshErr error
+	)
+	if allFailed(failed) {
+		refreshErr = err
+	} else {
+		totals, refreshErr = b.totals(ctx, ids)
+		if refreshErr != nil {
+			b.logger.WarnContext(ctx, "Failed to refresh impression totals", "error", refreshErr)
+		}
+	}
+
+	// Une publicité écrite mais introuvable a été archivée depuis ses derniers incréments,
+	// que son $inc n'a pas atteints : ils complètent son total archivé
+	var gone []int
+	if refreshErr == nil {
+		for i, id := range ids {
+			if _, found := totals[id]; !found && !failed[i] {
+				gone = append(gone, i)
+			}
+		}
+	}
+	archiveFailed := make(map[uuid.UUID]bool)
+	if len(gone) > 0 {
+		goneIDs, goneDeltas := make([]uuid.UUID, len(gone)), make([]int64, len(gone))
+		for j, i := range gone {
+			goneIDs[j], goneDeltas[j] = ids[i], deltas[i]
+		}
+		failedArchive, archiveErr := increment(ctx, b.archive, goneIDs, goneDeltas)
+		if archiveErr != nil {
+			b.logger.WarnContext(ctx, "Failed to add impressions to archived ads, retrying next interval", "error", archiveErr)
+		}
+		for j, id := range goneIDs {
+			archiveFailed[id] = failedArchive[j]
+		}
+	}
+
+	for i, id := range ids {
+		shard := b.shard(id)
+		shard.mu.Lock()
+		c := shard.counters[id]
+		c.inflight = 0
+		total, found := totals[id]
+		switch {
+		case failed[i], archiveFailed[id]:
+			c.pending += deltas[i]
+		case refreshErr != nil:
+			c.persisted += deltas[i]
+		case found:
+			c.persisted = total
+		default:
+			delete(shard.counters, id)
+		}
+		shard.mu.Unlock()
+	}
+	if err != nil {
+		b.logger.ErrorContext(ctx, "FlushImpressions failed", "ads", len(ids), "error", err)
+		return err
+	}
+	b.logger.DebugContext(ctx, "FlushImpressions completed", "duration", time.Since(start), "ads", len(ids))
+	return nil
+}
+
+// totals lit les totaux persistés des publicités ids
+func (b *ImpressionBuffer) totals(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]int64, error) {
+	cursor, err := b.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}},
+		options.Find().SetProjection(bson.M{"impressions": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var docs []struct {
+		ID          uuid.UUID `bson:"_id"`
+		Impressions int64     `bson:"impressions"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	totals := make(map[uuid.UUID]int64, len(docs))
+	for _, doc := range docs {
+		totals[doc.ID] = doc.Impressions
+	}
+	return totals, nil
+}
+
+// beginWrite signale le début d'une écriture aux lectures et retourne le canal à passer à
+// endWrite ; writeMu doit être détenu
+func (b *ImpressionBuffer) beginWrite() chan struct{} {
+	b.stateMu.Lock()
+	defer b.stateMu.Unlock()
+	b.generation++
+	b.writing = make(chan struct{})
+	return b.writing
+}
+
+// endWrite signale la fin de l'écriture aux lectures qui l'attendent
+func (b *ImpressionBuffer) endWrite(writing chan struct{}) {
+	b.stateMu.Lock()
+	defer b.stateMu.Unlock()
+	b.writing = nil
+	close(writing)
+}
+
+// read exécute read, la lecture des totaux persistés, hors de toute écriture, puis
+// addPending, l'ajout des impressions en attente. Une lecture commencée pendant une
+// écriture attend sa fin ; une lecture qu'une écriture a chevauchée est recommencée.
+// addPending s'exécute avant toute nouvelle écriture : aucune impression n'est alors à
+// la fois persistée et comptée en attente.
+func (b *ImpressionBuffer) read(ctx context.Context, read func() error, addPending func()) error {
+	for {
+		b.stateMu.Lock()
+		writing, generation := b.writing, b.generation
+		b.stateMu.Unlock()
+		if writing != nil {
+			select {
+			case <-writing:
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if err := read(); err != nil {
+			return err
+		}
+		b.stateMu.Lock()
+		if b.generation == generation {
+			addPending()
+			b.stateMu.Unlock()
+			return nil
+		}
+		b.stateMu.Unlock()
+	}
+}
+
+// pending retourne les impressions de la publicité id pas encore écrites, en cours
+// d'écriture comprises
+func (b *ImpressionBuffer) pending(id uuid.UUID) int64 {
+	shard := b.shard(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if c, ok := shard.counters[id]; ok {
+		return c.inflight + c.pending
+	}
+	return 0
+}
+
+// increment ajoute deltas[i] au compteur d'impressions du document ids[i] de collection, en
+// un BulkWrite non ordonné. failed indique les opérations à reprendre ; un document absent
+// n'est pas une erreur.
+func increment(ctx context.Context, collection impressionCollection, ids []uuid.UUID, deltas []int64) (failed []bool, err error) {
+	models := make([]mongo.WriteModel, len(ids))
+	for i, id := range ids {
+		models[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": id}).
+			SetUpdate(bson.M{"$inc": bson.M{"impressions": deltas[i]}})
+	}
+	_, err = collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	failed = make([]bool, len(ids))
+	var bulkErr mongo.BulkWriteException
+	switch {
+	case err == nil:
+	case errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil:
+		// Écriture non ordonnée : seules les opérations en erreur sont à reprendre
+		for _, we := range bulkErr.WriteErrors {
+			failed[we.Index] = true
+		}
+	default:
+		for i := range failed {
+			failed[i] = true
+		}
+	}
+	return failed, err
+}
+
+// allFailed indique si toutes les opérations ont échoué
+func allFailed(failed []bool) bool {
+	for _, f := range failed {
+		if !f {
+			return false
+		}
+	}
+	return true
+}
+
+// shard retourne la partition du compteur de la publicité id
+func (b *ImpressionBuffer) shard(id uuid.UUID) *impressionShard {
+	return &b.shards[int(id[0])%impressionShards]
+}
+
+// increment compte une impression et retourne le nouveau total ; le verrou doit être détenu
+func (c *impressionCounter) increment() int64 {
+	c.pending++
+	c.touched = true
+	return c.persisted + c.inflight + c.pending
+}
+
+// visibleTo indique si le locataire du contexte peut compter les impressions d'une
+// publicité de tenant, comme les requêtes restreintes du repository
+func visibleTo(ctx context.Context, tenant string) bool {
+	t := domain.TenantFromContext(ctx)
+	return t == "" || t == tenant
+}
+
+// Ensure ImpressionBuffer implements the AdRepository interface
+var _ out.AdRepository = (*ImpressionBuffer)(nil)
@@ -0,0 +1,266 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"adserver/internal/adapters/clock"
+	"adserver/internal/adapters/memory"
+	"adserver/internal/domain"
+	"adserver/internal/ports/out"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var testStart = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+var acme = domain.WithTenant(context.Background(), "acme")
+
+// fakeCollection simule les $inc et la relecture des totaux du tampon sur un repository
+// en mémoire, sans base MongoDB
+type fakeCollection struct {
+	repo out.AdRepository
+	err  error         // Erreur retournée par BulkWrite, sans rien écrire
+	held chan struct{} // Si non nil, BulkWrite le signale puis attend release
+	hold chan struct{}
+}
+
+func (f *fakeCollection) BulkWrite(ctx context.Context, models []mongo.WriteModel, _ ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	if f.held != nil {
+		f.held <- struct{}{}
+		<-f.hold
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	for _, model := range models {
+		update := model.(*mongo.UpdateOneModel)
+		id := update.Filter.(bson.M)["_id"].(uuid.UUID)
+		delta := update.Update.(bson.M)["$inc"].(bson.M)["impressions"].(int64)
+		// Un document absent n'est pas une erreur
+		if _, err := f.repo.AdjustImpressions(context.Background(), id, delta); err != nil && !errors.Is(err, domain.ErrAdNotFound) {
+			return nil, err
+		}
+	}
+	return &mongo.BulkWriteResult{}, nil
+}
+
+func (f *fakeCollection) Find(ctx context.Context, filter interface{}, _ ...*options.FindOptions) (*mongo.Cursor, error) {
+	var docs []interface{}
+	for _, id := range filter.(bson.M)["_id"].(bson.M)["$in"].([]uuid.UUID) {
+		impressions, err := f.repo.GetImpressions(context.Background(), id)
+		if errors.Is(err, domain.ErrAdNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, bson.M{"_id": id, "impressions": impressions})
+	}
+	return mongo.NewCursorFromDocuments(docs, nil, nil)
+}
+
+// newFakeBuffer retourne un tampon décorant un repository en mémoire, dont l'archive est
+// un second repository en mémoire
+func newFakeBuffer(t *testing.T) (buffer *ImpressionBuffer, ads, archive *fakeCollection, fake *clock.Fake) {
+	t.Helper()
+	fake = clock.NewFake(testStart)
+	ads = &fakeCollection{repo: memory.NewAdRepository(fake, 0)}
+	archive = &fakeCollection{repo: memory.NewAdRepository(fake, 0)}
+	return newImpressionBuffer(ads.repo, ads, archive, time.Minute, fake, discard), ads, archive, fake
+}
+
+func TestImpressionBufferFlush(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(t *testing.T, ads, archive *fakeCollection, ad domain.Pub, fake *clock.Fake)
+		wantErr     bool
+		wantAds     int64 // Total persisté de la publicité, -1 si elle a été archivée
+		wantArchive int64 // Total archivé de la publicité
+		wantPending int64
+	}{
+		{name: "written", wantAds: 3},
+		{name: "failed write stays pending", setup: func(_ *testing.T, ads, _ *fakeCollection, _ domain.Pub, _ *clock.Fake) {
+			ads.err = errors.New("connection reset")
+		}, wantErr: true, wantAds: 0, wantPending: 3},
+		{name: "archived ad", setup: func(t *testing.T, ads, archive *fakeCollection, ad domain.Pub, fake *clock.Fake) {
+			archived := ad
+			archived.Impressions = 2
+			if _, err := archive.repo.Create(acme, &archived); err != nil {
+				t.Fatalf("Create() in the archive error: %v", err)
+			}
+			fake.Advance(2 * time.Hour)
+			if got, err := ads.repo.DeleteExpired(context.Background()); err != nil || got != 1 {
+				t.Fatalf("DeleteExpired() = %d, %v, want 1", got, err)
+			}
+		}, wantAds: -1, wantArchive: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer, ads, archive, fake := newFakeBuffer(t)
+			ad := domain.Pub{ID: uuid.New(), Tenant: "acme", Title: "Ma publicité", ExpiresAt: testStart.Add(time.Hour)}
+			if _, err := ads.repo.Create(acme, &ad); err != nil {
+				t.Fatalf("Create() error: %v", err)
+			}
+			for i := 0; i < 3; i++ {
+				if _, err := buffer.IncrementImpressions(acme, ad.ID); err != nil {
+					t.Fatalf("IncrementImpressions() error: %v", err)
+				}
+			}
+			if tt.setup != nil {
+				tt.setup(t, ads, archive, ad, fake)
+			}
+
+			if err := buffer.Flush(context.Background()); (err != nil) != tt.wantErr {
+				t.Fatalf("Flush() error = %v, want error %t", err, tt.wantErr)
+			}
+			got, err := ads.repo.GetImpressions(acme, ad.ID)
+			if tt.wantAds < 0 {
+				if !errors.Is(err, domain.ErrAdNotFound) {
+					t.Errorf("GetImpressions() of an archived ad = %d, %v, want ErrAdNotFound", got, err)
+				}
+			} else if err != nil || got != tt.wantAds {
+				t.Errorf("persisted impressions = %d, %v, want %d", got, err, tt.wantAds)
+			}
+			if tt.wantArchive > 0 {
+				if got, err := archive.repo.GetImpressions(acme, ad.ID); err != nil || got != tt.wantArchive {
+					t.Errorf("archived impressions = %d, %v, want %d", got, err, tt.wantArchive)
+				}
+			}
+			if got := buffer.pending(ad.ID); got != tt.wantPending {
+				t.Errorf("pending() after Flush = %d, want %d", got, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestImpressionBufferCountsImpressionsBeingWritten(t *testing.T) {
+	buffer, ads, _, _ := newFakeBuffer(t)
+	ad := domain.Pub{ID: uuid.New(), Tenant: "acme", Title: "Ma publicité", ExpiresAt: testStart.Add(time.Hour)}
+	other := domain.Pub{ID: uuid.New(), Tenant: "acme", Title: "Autre publicité", ExpiresAt: testStart.Add(time.Hour)}
+	for _, a := range []*domain.Pub{&ad, &other} {
+		if _, err := ads.repo.Create(acme, a); err != nil {
+			t.Fatalf("Create() error: %v", err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := buffer.IncrementImpressions(acme, ad.ID); err != nil {
+			t.Fatalf("IncrementImpressions() error: %v", err)
+		}
+	}
+	reads := func(t *testing.T, want int64) {
+		t.Helper()
+		if got, err := buffer.GetByID(acme, ad.ID); err != nil || got == nil || got.Impressions != want {
+			t.Errorf("GetByID() = %+v, %v, want %d impressions", got, err, want)
+		}
+		if got, err := buffer.List(acme, map[string]interface{}{"title": ad.Title}, 0, 0); err != nil || len(got) != 1 || got[0].Impressions != want {
+			t.Errorf("List() = %v, %v, want 1 ad with %d impressions", got, err, want)
+		}
+		if got, err := buffer.GetImpressions(acme, ad.ID); err != nil || got != want {
+			t.Errorf("GetImpressions() = %d, %v, want %d", got, err, want)
+		}
+	}
+	reads(t, 3)
+
+	// L'écriture est retenue dans BulkWrite : les impressions sont en cours d'écriture
+	ads.held, ads.hold = make(chan struct{}), make(chan struct{})
+	flushed := make(chan error)
+	go func() { flushed <- buffer.Flush(context.Background()) }()
+	<-ads.held
+
+	// Les incréments ne sont pas bloqués par l'écriture
+	if got, err := buffer.IncrementImpressions(acme, ad.ID); err != nil || got != 4 {
+		t.Errorf("IncrementImpressions() during a flush = %d, %v, want 4", got, err)
+	}
+	if got, err := buffer.IncrementImpressions(acme, other.ID); err != nil || got != 1 {
+		t.Errorf("IncrementImpressions() of another ad during a flush = %d, %v, want 1", got, err)
+	}
+	if got := buffer.pending(ad.ID); got != 4 {
+		t.Errorf("pending() during a flush = %d, want 4", got)
+	}
+
+	// Les lectures attendent la fin de l'écriture, qui persiste les impressions
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reads(t, 4)
+	}()
+	select {
+	case <-done:
+		t.Fatal("reads completed during a flush")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(ads.hold)
+	if err := <-flushed; err != nil {
+		t.Fatalf("Flush() error: %v", err)
+	}
+	<-done
+	if got, err := ads.repo.GetImpressions(acme, ad.ID); err != nil || got != 3 {
+		t.Errorf("persisted impressions = %d, %v, want 3", got, err)
+	}
+}
+
+func TestImpressionBufferArchivesLateImpressions(t *testing.T) {
+	tests := []struct {
+		name    string
+		archive func(t *testing.T, buffer *ImpressionBuffer, repo out.AdRepository)
+	}{
+		{name: "archived by the buffer", archive: func(t *testing.T, buffer *ImpressionBuffer, _ out.AdRepository) {
+			if got, err := buffer.DeleteExpired(context.Background()); err != nil || got != 1 {
+				t.Fatalf("DeleteExpired() = %d, %v, want 1", got, err)
+			}
+		}},
+		{name: "archived by another replica", archive: func(t *testing.T, buffer *ImpressionBuffer, repo out.AdRepository) {
+			if got, err := repo.DeleteExpired(context.Background()); err != nil || got != 1 {
+				t.Fatalf("DeleteExpired() = %d, %v, want 1", got, err)
+			}
+			if err := buffer.Flush(context.Background()); err != nil {
+				t.Fatalf("Flush() error: %v", err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDatabase(t)
+			fake := clock.NewFake(testStart)
+			repo := NewMongoRepository(db, "ads", "ads_archive", fake, discard)
+			buffer := NewImpressionBuffer(repo, db, "ads", "ads_archive", time.Minute, fake, discard)
+			ad := domain.Pub{ID: uuid.New(), Tenant: "acme", Title: "Ma publicité", ExpiresAt: testStart.Add(time.Minute)}
+			if _, err := repo.Create(acme, &ad); err != nil {
+				t.Fatalf("Create() error: %v", err)
+			}
+
+			// Deux impressions écrites, une en attente lors de l'archivage
+			increment := func() {
+				t.Helper()
+				if _, err := buffer.IncrementImpressions(acme, ad.ID); err != nil {
+					t.Fatalf("IncrementImpressions() error: %v", err)
+				}
+			}
+			increment()
+			increment()
+			if err := buffer.Flush(context.Background()); err != nil {
+				t.Fatalf("Flush() error: %v", err)
+			}
+			increment()
+			fake.Advance(2 * time.Minute)
+			tt.archive(t, buffer, repo)
+
+			var archived domain.Pub
+			if err := db.Collection("ads_archive").FindOne(context.Background(), bson.M{"_id": ad.ID}).Decode(&archived); err != nil {
+				t.Fatalf("archived ad lookup error: %v", err)
+			}
+			if archived.Impressions != 3 {
+				t.Errorf("archived impressions = %d, want 3", archived.Impressions)
+			}
+			if got := buffer.pending(ad.ID); got != 0 {
+				t.Errorf("pending() after archiving = %d, want 0", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package mongodb
+
+import (
+	"shared/mongomigrate"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migrations retourne les migrations du schéma de l'adserver, appliquées par
+// mongomigrate.Migrate. Chaque étape est consignée par collection : une collection
+// renommée dans la configuration reçoit toutes ses étapes.
+// Une version publiée ne doit plus changer : toute évolution passe par une nouvelle version.
+func Migrations(adsCollection string) []mongomigrate.Migration {
+	return []mongomigrate.Migration{
+		{
+			Collection:  adsCollection,
+			Version:     1,
+			Description: "ads: index by tenant and expiry",
+			Up: mongomigrate.CreateIndexes(mongo.IndexModel{
+				Keys: bson.D{{Key: "tenant", Value: 1}, {Key: "expires_at", Value: 1}},
+			}),
+		},
+		{
+			// Le nettoyage périodique n'est pas restreint à un locataire
+			Collection:  adsCollection,
+			Version:     2,
+			Description: "ads: index by expiry for the cleanup",
+			Up: mongomigrate.CreateIndexes(mongo.IndexModel{
+				Keys: bson.D{{Key: "expires_at", Value: 1}},
+			}),
+		},
+		{
+			Collection:  "api_keys",
+			Version:     1,
+			Description: "api_keys: unique hash, index by tenant",
+			Up: mongomigrate.CreateIndexes(
+				mongo.IndexModel{Keys: bson.D{{Key: "hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+				mongo.IndexModel{Keys: bson.D{{Key: "tenant", Value: 1}}},
+			),
+		},
+	}
+}
@@ -2,9 +2,12 @@ package mongodb
 
 import (
 	"context"
-	"log"
+	"errors"
+	"log/slog"
 	"time"
 
+	"adserver/internal/adapters/metrics"
+	"adserver/internal/adapters/tracing"
 	"adserver/internal/domain"
 	"adserver/internal/ports/out"
 
@@ -14,154 +17,309 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// archiveBatchSize est le nombre maximal d'annonces archivées par aller-retour
+const archiveBatchSize = 500
+
 // mongoRepository implémente l'interface AdRepository en utilisant MongoDB comme backend
 type mongoRepository struct {
 	collection *mongo.Collection
+	archive    *mongo.Collection // Annonces expirées
+	clock      out.Clock
+	logger     *slog.Logger
 }
 
 // NewMongoRepository crée une nouvelle instance du repository MongoDB
-// pour la collection "ads" dans la base de données spécifiée
-func NewMongoRepository(db *mongo.Database) out.AdRepository {
-	return &mongoRepository{collection: db.Collection("ads")}
+// pour la collection des publicités (collection) dans la base de données spécifiée.
+// Toutes les requêtes sont restreintes au locataire du contexte (domain.TenantFromContext) ;
+// DeleteExpired déplace les annonces expirées à l'heure de clock vers archiveCollection.
+func NewMongoRepository(db *mongo.Database, collection, archiveCollection string, clock out.Clock, logger *slog.Logger) out.AdRepository {
+	return &mongoRepository{
+		collection: db.Collection(collection),
+		archive:    db.Collection(archiveCollection),
+		clock:      clock,
+		logger:     logger.With("component", "MongoRepository"),
+	}
 }
 
 // Create insère une nouvelle annonce dans la collection MongoDB et retourne son ID
 func (r *mongoRepository) Create(ctx context.Context, ad *domain.Pub) (string, error) {
+	defer metrics.ObserveRepository("mongodb", "Create", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "mongodb", "Create")
+	defer span.End()
 	start := time.Now()
-	log.Printf("[MongoRepository.Create] start id=%s title=%q", ad.ID, ad.Title)
+	r.logger.DebugContext(ctx, "Create start", "id", ad.ID, "title", ad.Title)
 	_, err := r.collection.InsertOne(ctx, ad)
 	if err != nil {
-		log.Printf("[MongoRepository.Create] error: %v", err)
+		r.logger.ErrorContext(ctx, "Create failed", "error", err)
 		return "", err
 	}
-	log.Printf("[MongoRepository.Create] completed in %v id=%s", time.Since(start), ad.ID)
+	r.logger.DebugContext(ctx, "Create completed", "duration", time.Since(start), "id", ad.ID)
 	return ad.ID.String(), nil
 }
 
-// GetByID récupère une annonce par son ID UUID
+// GetByID récupère une annonce par son ID UUID ; celle d'un autre locataire est ignorée
 func (r *mongoRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Pub, error) {
+	defer metrics.ObserveRepository("mongodb", "GetByID", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "mongodb", "GetByID")
+	defer span.End()
 	start := time.Now()
-	log.Printf("[MongoRepository.GetByID] start id=%s", id)
+	r.logger.DebugContext(ctx, "GetByID start", "id", id)
 	var ad domain.Pub
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&ad)
+	err := r.collection.FindOne(ctx, scoped(ctx, bson.M{"_id": id})).Decode(&ad)
 	if err == mongo.ErrNoDocuments {
-		log.Printf("[MongoRepository.GetByID] not found id=%s", id)
+		r.logger.DebugContext(ctx, "GetByID not found", "id", id)
 		return nil, nil
 	}
 	if err != nil {
-		log.Printf("[MongoRepository.GetByID] error: %v", err)
+		r.logger.ErrorContext(ctx, "GetByID failed", "error", err)
 		return nil, err
 	}
-	log.Printf("[MongoRepository.GetByID] completed in %v id=%s", time.Since(start), id)
+	r.logger.DebugContext(ctx, "GetByID completed", "duration", time.Since(start), "id", id)
 	return &ad, nil
 }
 
 // Exists vérifie si une annonce existe dans la collection par son ID UUID
 func (r *mongoRepository) Exists(ctx context.Context, id uuid.UUID) (bool, error) {
-	log.Printf("Vérification de l'existence de l'annonce avec ID: %s", id.String())
+	defer metrics.ObserveRepository("mongodb", "Exists", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "mongodb", "Exists")
+	defer span.End()
+	r.logger.DebugContext(ctx, "Exists start", "id", id)
 
-	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": id})
+	count, err := r.collection.CountDocuments(ctx, scoped(ctx, bson.M{"_id": id}))
 	if err != nil {
-		log.Printf("Erreur lors de la vérification de l'existence de l'annonce %s: %v", id.String(), err)
+		r.logger.ErrorContext(ctx, "Exists failed", "id", id, "error", err)
 		return false, err
 	}
 
 	exists := count > 0
-	log.Printf("Résultat de la vérification pour l'annonce %s: existe = %t", id.String(), exists)
+	r.logger.DebugContext(ctx, "Exists completed", "id", id, "exists", exists)
 
 	return exists, nil
 }
 
 // IncrementImpressions incrémente le compteur d'impressions et retourne le nouveau total
 func (r *mongoRepository) IncrementImpressions(ctx context.Context, id uuid.UUID) (int64, error) {
+	defer metrics.ObserveRepository("mongodb", "IncrementImpressions", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "mongodb", "IncrementImpressions")
+	defer span.End()
 	start := time.Now()
-	log.Printf("[MongoRepository.IncrementImpressions] start id=%s", id)
+	r.logger.DebugContext(ctx, "IncrementImpressions start", "id", id)
 	result := r.collection.FindOneAndUpdate(
 		ctx,
-		bson.M{"_id": id},
+		scoped(ctx, bson.M{"_id": id}),
 		bson.M{"$inc": bson.M{"impressions": 1}},
 		options.FindOneAndUpdate().SetReturnDocument(options.After),
 	)
+	if errors.Is(result.Err(), mongo.ErrNoDocuments) {
+		return 0, domain.ErrAdNotFound
+	}
 	if result.Err() != nil {
-		log.Printf("[MongoRepository.IncrementImpressions] error: %v", result.Err())
+		r.logger.ErrorContext(ctx, "IncrementImpressions failed", "error", result.Err())
 		return 0, result.Err()
 	}
 	var ad domain.Pub
 	if err := result.Decode(&ad); err != nil {
-		log.Printf("[MongoRepository.IncrementImpressions] decode error: %v", err)
+		r.logger.ErrorContext(ctx, "IncrementImpressions decode failed", "error", err)
 		return 0, err
 	}
-	log.Printf("[MongoRepository.IncrementImpressions] completed in %v id=%s impressions=%d", time.Since(start), id, ad.Impressions)
+	r.logger.DebugContext(ctx, "IncrementImpressions completed", "duration", time.Since(start), "id", id, "impressions", ad.Impressions)
 	return ad.Impressions, nil
 }
 
 // ResetImpressions réinitialise le compteur d'impressions et retourne l'ancien total
 func (r *mongoRepository) ResetImpressions(ctx context.Context, id uuid.UUID) (int64, error) {
+	defer metrics.ObserveRepository("mongodb", "ResetImpressions", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "mongodb", "ResetImpressions")
+	defer span.End()
 	start := time.Now()
-	log.Printf("[MongoRepository.ResetImpressions] start id=%s", id)
+	r.logger.DebugContext(ctx, "ResetImpressions start", "id", id)
 	result := r.collection.FindOneAndUpdate(
 		ctx,
-		bson.M{"_id": id},
+		scoped(ctx, bson.M{"_id": id}),
 		bson.M{"$set": bson.M{"impressions": 0}},
 		options.FindOneAndUpdate().SetReturnDocument(options.Before),
 	)
+	if errors.Is(result.Err(), mongo.ErrNoDocuments) {
+		return 0, domain.ErrAdNotFound
+	}
 	if result.Err() != nil {
-		log.Printf("[MongoRepository.ResetImpressions] error: %v", result.Err())
+		r.logger.ErrorContext(ctx, "ResetImpressions failed", "error", result.Err())
 		return 0, result.Err()
 	}
 	var ad domain.Pub
 	if err := result.Decode(&ad); err != nil {
-		log.Printf("[MongoRepository.ResetImpressions] decode error: %v", err)
+		r.logger.ErrorContext(ctx, "ResetImpressions decode failed", "error", err)
 		return 0, err
 	}
-	log.Printf("[MongoRepository.ResetImpressions] completed in %v id=%s oldImpressions=%d", time.Since(start), id, ad.Impressions)
+	r.logger.DebugContext(ctx, "ResetImpressions completed", "duration", time.Since(start), "id", id, "old_impressions", ad.Impressions)
 	return ad.Impressions, nil
 }
 
-// DeleteExpired supprime toutes les annonces expirées et retourne le nombre supprimé
-func (r *mongoRepository) DeleteExpired(ctx context.Context) (int64, error) {
+// AdjustImpressions ajoute delta au compteur d'impressions ($inc) et retourne le nouveau total
+func (r *mongoRepository) AdjustImpressions(ctx context.Context, id uuid.UUID, delta int64) (int64, error) {
+	defer metrics.ObserveRepository("mongodb", "AdjustImpressions", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "mongodb", "AdjustImpressions")
+	defer span.End()
 	start := time.Now()
-	log.Printf("[MongoRepository.DeleteExpired] start")
-	result, err := r.collection.DeleteMany(ctx, bson.M{"expires_at": bson.M{"$lt": time.Now()}})
-	if err != nil {
-		log.Printf("[MongoRepository.DeleteExpired] error: %v", err)
+	r.logger.DebugContext(ctx, "AdjustImpressions start", "id", id, "delta", delta)
+	result := r.collection.FindOneAndUpdate(
+		ctx,
+		scoped(ctx, bson.M{"_id": id}),
+		bson.M{"$inc": bson.M{"impressions": delta}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+	if errors.Is(result.Err(), mongo.ErrNoDocuments) {
+		r.logger.DebugContext(ctx, "AdjustImpressions not found", "id", id)
+		return 0, domain.ErrAdNotFound
+	}
+	if result.Err() != nil {
+		r.logger.ErrorContext(ctx, "AdjustImpressions failed", "error", result.Err())
+		return 0, result.Err()
+	}
+	var ad domain.Pub
+	if err := result.Decode(&ad); err != nil {
+		r.logger.ErrorContext(ctx, "AdjustImpressions decode failed", "error", err)
 		return 0, err
 	}
-	log.Printf("[MongoRepository.DeleteExpired] completed in %v deletedCount=%d", time.Since(start), result.DeletedCount)
-	return result.DeletedCount, nil
+	r.logger.DebugContext(ctx, "AdjustImpressions completed", "duration", time.Since(start), "id", id, "impressions", ad.Impressions)
+	return ad.Impressions, nil
+}
+
+// DeleteExpired archive les annonces expirées : chacune est copiée, compteur d'impressions
+// final compris, dans la collection d'archive avec sa date d'archivage (archived_at), puis
+// retirée de la collection des annonces. Retourne le nombre d'annonces archivées.
+//
+// Une annonce n'est retirée que si son compteur est encore celui de la copie : une
+// impression comptée ($inc) entre la copie et la suppression la laisse en place, et elle
+// est copiée de nouveau au passage suivant. La copie remplace celle d'une exécution
+// interrompue ou d'un passage précédent, mais jamais une copie au compteur plus élevé :
+// une autre réplica l'a archivée après ces impressions, ou des impressions tardives ont
+// été ajoutées à l'archive depuis.
+func (r *mongoRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	defer metrics.ObserveRepository("mongodb", "DeleteExpired", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "mongodb", "DeleteExpired")
+	defer span.End()
+	start := time.Now()
+	r.logger.DebugContext(ctx, "DeleteExpired start")
+	now := r.clock.Now()
+	expired := bson.M{"$lt": now}
+
+	var archived int64
+	for {
+		cursor, err := r.collection.Find(ctx, scoped(ctx, bson.M{"expires_at": expired}), options.Find().SetLimit(archiveBatchSize))
+		if err != nil {
+			r.logger.ErrorContext(ctx, "DeleteExpired failed to find expired ads", "error", err)
+			return archived, err
+		}
+		var docs []bson.M
+		if err := cursor.All(ctx, &docs); err != nil {
+			r.logger.ErrorContext(ctx, "DeleteExpired failed to read expired ads", "error", err)
+			return archived, err
+		}
+		if len(docs) == 0 {
+			break
+		}
+
+		copies := make([]mongo.WriteModel, len(docs))
+		deletes := make([]mongo.WriteModel, len(docs))
+		for i, doc := range docs {
+			deletes[i] = mongo.NewDeleteOneModel().SetFilter(bson.M{"_id": doc["_id"], "impressions": doc["impressions"], "expires_at": expired})
+			doc["archived_at"] = now
+			copies[i] = mongo.NewReplaceOneModel().
+				SetFilter(bson.M{"_id": doc["_id"], "impressions": bson.M{"$lte": doc["impressions"]}}).
+				SetReplacement(doc).
+				SetUpsert(true)
+		}
+		// Une copie au compteur plus élevé fait échouer l'upsert sur l'identifiant : elle est conservée
+		if _, err := r.archive.BulkWrite(ctx, copies, options.BulkWrite().SetOrdered(false)); err != nil && !onlyDuplicateKeys(err) {
+			r.logger.ErrorContext(ctx, "DeleteExpired failed to archive ads", "error", err)
+			return archived, err
+		}
+		result, err := r.collection.BulkWrite(ctx, deletes, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			r.logger.ErrorContext(ctx, "DeleteExpired failed to remove archived ads", "error", err)
+			return archived, err
+		}
+		archived += result.DeletedCount
+		// Les annonces modifiées depuis leur copie sont reprises au passage suivant ; si aucune
+		// n'a pu être retirée, elles le seront à la prochaine exécution
+		if result.DeletedCount == 0 || (len(docs) < archiveBatchSize && result.DeletedCount == int64(len(docs))) {
+			break
+		}
+	}
+	r.logger.DebugContext(ctx, "DeleteExpired completed", "duration", time.Since(start), "archived_count", archived)
+	return archived, nil
+}
+
+// onlyDuplicateKeys indique si err ne signale que des documents déjà présents
+func onlyDuplicateKeys(err error) bool {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil || len(bulkErr.WriteErrors) == 0 {
+		return false
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if !mongo.IsDuplicateKeyError(writeErr) {
+			return false
+		}
+	}
+	return true
 }
 
 // List récupère une liste paginée d'annonces selon les critères de filtrage
 func (r *mongoRepository) List(ctx context.Context, filter map[string]interface{}, offset, limit int64) ([]*domain.Pub, error) {
+	defer metrics.ObserveRepository("mongodb", "List", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "mongodb", "List")
+	defer span.End()
 	start := time.Now()
-	log.Printf("[MongoRepository.List] start filter=%v offset=%d limit=%d", filter, offset, limit)
+	r.logger.DebugContext(ctx, "List start", "filter", filter, "offset", offset, "limit", limit)
 	opts := options.Find().SetSkip(offset).SetLimit(limit)
-	cursor, err := r.collection.Find(ctx, filter, opts)
+	cursor, err := r.collection.Find(ctx, scoped(ctx, bson.M(filter)), opts)
 	if err != nil {
-		log.Printf("[MongoRepository.List] error find: %v", err)
+		r.logger.ErrorContext(ctx, "List find failed", "error", err)
 		return nil, err
 	}
 	defer cursor.Close(ctx)
 
 	var ads []*domain.Pub
 	if err := cursor.All(ctx, &ads); err != nil {
-		log.Printf("[MongoRepository.List] error decode all: %v", err)
+		r.logger.ErrorContext(ctx, "List decode failed", "error", err)
 		return nil, err
 	}
-	log.Printf("[MongoRepository.List] completed in %v returned=%d", time.Since(start), len(ads))
+	r.logger.DebugContext(ctx, "List completed", "duration", time.Since(start), "returned", len(ads))
 	return ads, nil
 }
 
 // GetImpressions récupère le nombre d'impressions d'une annonce
 func (r *mongoRepository) GetImpressions(ctx context.Context, id uuid.UUID) (int64, error) {
+	defer metrics.ObserveRepository("mongodb", "GetImpressions", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "mongodb", "GetImpressions")
+	defer span.End()
 	start := time.Now()
-	log.Printf("[MongoRepository.GetImpressions] start id=%s", id)
+	r.logger.DebugContext(ctx, "GetImpressions start", "id", id)
 	var ad domain.Pub
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&ad)
+	err := r.collection.FindOne(ctx, scoped(ctx, bson.M{"_id": id})).Decode(&ad)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, domain.ErrAdNotFound
+	}
 	if err != nil {
-		log.Printf("[MongoRepository.GetImpressions] error: %v", err)
+		r.logger.ErrorContext(ctx, "GetImpressions failed", "error", err)
 		return 0, err
 	}
-	log.Printf("[MongoRepository.GetImpressions] completed in %v id=%s impressions=%d", time.Since(start), id, ad.Impressions)
+	r.logger.DebugContext(ctx, "GetImpressions completed", "duration", time.Since(start), "id", id, "impressions", ad.Impressions)
 	return ad.Impressions, nil
 }
+
+// scoped restreint le filtre au locataire du contexte. Le filtre fourni est copié :
+// une clé "tenant" qu'il contiendrait est remplacée par celle du contexte.
+func scoped(ctx context.Context, filter bson.M) bson.M {
+	tenant := domain.TenantFromContext(ctx)
+	if tenant == "" {
+		return filter
+	}
+	out := make(bson.M, len(filter)+1)
+	for k, v := range filter {
+		out[k] = v
+	}
+	out["tenant"] = tenant
+	return out
+}
@@ -0,0 +1,245 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"adserver/internal/adapters/grpc/auth"
+	"adserver/internal/adapters/metrics"
+	"adserver/internal/ports/out"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RetryAfterHeader est la métadonnée indiquant, en secondes, quand un appel limité peut être retenté
+const RetryAfterHeader = "retry-after"
+
+// Raisons d'un refus
+const (
+	ReasonRate  = "rate"  // Seau de jetons vide
+	ReasonQuota = "quota" // Quota journalier atteint
+)
+
+// allowScript applique atomiquement le quota journalier puis le seau de jetons d'un client.
+// KEYS[1] : seau (hash tokens/ts), KEYS[2] : compteur du jour.
+// ARGV : débit (jetons/s, 0 = illimité), capacité, maintenant (ms), quota (0 = aucun), ms avant minuit UTC.
+// Retourne {0, 0} si l'appel est accepté, {1, attente_ms} si le débit est dépassé,
+// {2, attente_ms} si le quota est atteint.
+var allowScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local daily = tonumber(ARGV[4])
+local until_reset = tonumber(ARGV[5])
+
+if daily > 0 then
+  local used = tonumber(redis.call('GET', KEYS[2]) or '0')
+  if used >= daily then
+    return {2, until_reset}
+  end
+end
+
+if rate > 0 then
+  local tokens = burst
+  local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+  if state[1] then
+    local elapsed = math.max(0, now - tonumber(state[2]))
+    tokens = math.min(burst, tonumber(state[1]) + elapsed * rate / 1000)
+  end
+  local ttl = math.ceil(burst * 1000 / rate) + 1000
+  if tokens < 1 then
+    redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+    redis.call('PEXPIRE', KEYS[1], ttl)
+    return {1, math.ceil((1 - tokens) * 1000 / rate)}
+  end
+  redis.call('HSET', KEYS[1], 'tokens', tostring(tokens - 1), 'ts', now)
+  redis.call('PEXPIRE', KEYS[1], ttl)
+end
+
+if daily > 0 then
+  redis.call('INCR', KEYS[2])
+  redis.call('PEXPIRE', KEYS[2], until_reset + 3600000)
+end
+return {0, 0}
+`)
+
+// Limit décrit la limite appliquée à chaque client d'une règle.
+type Limit struct {
+	Rate  float64 // Jetons rechargés par seconde, 0 pour ne pas limiter le débit
+	Burst int     // Capacité du seau, Rate arrondi au supérieur par défaut
+	Daily int64   // Appels acceptés par jour UTC, 0 pour aucun quota
+}
+
+// Enabled indique si la limite restreint les appels
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 || l.Daily > 0
+}
+
+// Decision est le résultat de la vérification d'un appel.
+type Decision struct {
+	Allowed    bool
+	Reason     string        // ReasonRate ou ReasonQuota lorsque l'appel est refusé
+	RetryAfter time.Duration // Attente avant qu'un nouvel appel puisse être accepté
+}
+
+// KeyFunc identifie le client d'un appel ; une chaîne vide exempte l'appel de la règle.
+type KeyFunc func(ctx context.Context, req any) string
+
+// Rule limite les appels de chaque client identifié par Key.
+type Rule struct {
+	Name  string // Segment de clé Dragonfly et libellé des métriques ("key", "ip")
+	Key   KeyFunc
+	Limit Limit
+}
+
+// Limiter applique des seaux de jetons et des quotas journaliers stockés dans Dragonfly,
+// partagés par toutes les instances du service.
+type Limiter struct {
+	client redis.UniversalClient
+	prefix string
+	clock  out.Clock
+	logger *slog.Logger
+}
+
+// NewLimiter crée un limiteur dont les clés Dragonfly commencent par prefix. Les seaux se
+// rechargent et les quotas se renouvellent selon l'heure de clock.
+func NewLimiter(client redis.UniversalClient, prefix string, clock out.Clock, logger *slog.Logger) *Limiter {
+	return &Limiter{client: client, prefix: prefix, clock: clock, logger: logger.With("component", "RateLimiter")}
+}
+
+// Allow consomme un jeton du client id pour la règle rule
+func (l *Limiter) Allow(ctx context.Context, rule, id string, limit Limit) (Decision, error) {
+	if !limit.Enabled() {
+		return Decision{Allowed: true}, nil
+	}
+
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = int(math.Ceil(limit.Rate))
+	}
+	now := l.clock.Now().UTC()
+	midnight := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+	// Entre accolades, le seau et le compteur du jour partagent un slot en mode cluster
+	base := fmt.Sprintf("{%s:%s:%s}", l.prefix, rule, id)
+
+	res, err := allowScript.Run(ctx, l.client,
+		[]string{base, base + ":" + now.Format("20060102")},
+		limit.Rate, burst, now.UnixMilli(), limit.Daily, midnight.Sub(now).Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return Decision{Allowed: true}, err
+	}
+	if len(res) != 2 {
+		return Decision{Allowed: true}, fmt.Errorf("unexpected rate limit script result %v", res)
+	}
+
+	retryAfter := time.Duration(res[1]) * time.Millisecond
+	switch res[0] {
+	case 1:
+		return Decision{Reason: ReasonRate, RetryAfter: retryAfter}, nil
+	case 2:
+		return Decision{Reason: ReasonQuota, RetryAfter: retryAfter}, nil
+	}
+	return Decision{Allowed: true}, nil
+}
+
+// UnaryServerInterceptor applique les règles aux méthodes listées (noms complets).
+// Un appel refusé reçoit ResourceExhausted et la métadonnée retry-after. Si Dragonfly
+// ne répond pas, l'appel est accepté : la limitation ne doit pas interrompre la diffusion.
+func (l *Limiter) UnaryServerInterceptor(methods []string, rules ...Rule) grpc.UnaryServerInterceptor {
+	limited := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		limited[m] = true
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !limited[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		for _, rule := range rules {
+			id := rule.Key(ctx, req)
+			if id == "" {
+				continue
+			}
+			decision, err := l.Allow(ctx, rule.Name, id, rule.Limit)
+			if err != nil {
+				l.logger.WarnContext(ctx, "Rate limit check failed, allowing call", "method", info.FullMethod, "rule", rule.Name, "error", err)
+				continue
+			}
+			if decision.Allowed {
+				continue
+			}
+
+			seconds := int64(math.Ceil(decision.RetryAfter.Seconds()))
+			if seconds < 1 {
+				seconds = 1
+			}
+			_ = grpc.SetHeader(ctx, metadata.Pairs(RetryAfterHeader, strconv.FormatInt(seconds, 10)))
+			metrics.RateLimited(info.FullMethod, rule.Name, decision.Reason)
+			l.logger.DebugContext(ctx, "Call rate limited", "method", info.FullMethod, "rule", rule.Name, "client", id, "reason", decision.Reason, "retry_after", decision.RetryAfter)
+			return nil, status.Errorf(codes.ResourceExhausted, "%s limit exceeded for %s, retry after %ds", decision.Reason, rule.Name, seconds)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ByPeerIP identifie le client par son adresse IP, lue par ClientIP
+func ByPeerIP(trustForwarded bool) KeyFunc {
+	return func(ctx context.Context, _ any) string {
+		return ClientIP(ctx, trustForwarded)
+	}
+}
+
+// ClientIP retourne l'adresse IP du client de l'appel. Avec trustForwarded, la dernière adresse
+// de X-Forwarded-For est utilisée, celle qu'a ajoutée le proxy de confiance (la passerelle REST
+// ajoute l'adresse de son appelant à l'en-tête reçu) ; les précédentes, fournies par le client,
+// sont ignorées. Sans trustForwarded, l'en-tête est ignoré et l'adresse du pair est retenue.
+func ClientIP(ctx context.Context, trustForwarded bool) string {
+	if trustForwarded {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get("x-forwarded-for"); len(values) > 0 {
+			hops := strings.Split(values[len(values)-1], ",")
+			if ip := strings.TrimSpace(hops[len(hops)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// ByPrincipal identifie le client par sa clé d'API, ou par le sujet de son jeton porteur.
+// Les appels non authentifiés (authentification désactivée) ne sont pas concernés.
+func ByPrincipal() KeyFunc {
+	return func(ctx context.Context, _ any) string {
+		p := auth.PrincipalFromContext(ctx)
+		switch {
+		case p == nil:
+			return ""
+		case p.KeyID != uuid.Nil:
+			return p.KeyID.String()
+		default:
+			return "sub:" + p.Name
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"adserver/internal/adapters/clock"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// discard est le logger des tests
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// newLimiter crée un limiteur sur un serveur miniredis, à l'heure de l'horloge retournée
+func newLimiter(t *testing.T) (*Limiter, *clock.Fake, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	fake := clock.NewFake(time.Date(2026, 1, 1, 23, 0, 0, 0, time.UTC))
+	return NewLimiter(client, "ratelimit:adserver", fake, discard), fake, mr
+}
+
+// hashTag retourne la partie de la clé qui détermine son slot en mode cluster
+func hashTag(key string) string {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			return key[start+1 : start+1+end]
+		}
+	}
+	return key
+}
+
+func TestAllowKeysShareClusterSlot(t *testing.T) {
+	l, _, mr := newLimiter(t)
+
+	limit := Limit{Rate: 0.001, Burst: 2, Daily: 10}
+	for i := 0; i < 2; i++ {
+		if d, err := l.Allow(context.Background(), "key", "abc", limit); err != nil || !d.Allowed {
+			t.Fatalf("Allow() #%d = %+v, %v, want allowed", i+1, d, err)
+		}
+	}
+	if d, err := l.Allow(context.Background(), "key", "abc", limit); err != nil || d.Allowed || d.Reason != ReasonRate {
+		t.Fatalf("Allow() with an empty bucket = %+v, %v, want a rate refusal", d, err)
+	}
+
+	// Le seau et le compteur du jour doivent partager un slot pour le script en mode cluster
+	keys := mr.Keys()
+	if len(keys) != 2 {
+		t.Fatalf("keys = %v, want the bucket and the daily counter", keys)
+	}
+	for _, key := range keys {
+		if tag := hashTag(key); tag != "ratelimit:adserver:key:abc" {
+			t.Errorf("hash tag of %q = %q, want ratelimit:adserver:key:abc", key, tag)
+		}
+	}
+}
+
+func TestAllowFollowsClock(t *testing.T) {
+	l, fake, _ := newLimiter(t)
+	allow := func(limit Limit) Decision {
+		t.Helper()
+		d, err := l.Allow(context.Background(), "key", "abc", limit)
+		if err != nil {
+			t.Fatalf("Allow() error: %v", err)
+		}
+		return d
+	}
+
+	// Un jeton par seconde : le seau vide se recharge quand l'horloge avance
+	rate := Limit{Rate: 1, Burst: 1}
+	if d := allow(rate); !d.Allowed {
+		t.Fatalf("first Allow() = %+v, want allowed", d)
+	}
+	if d := allow(rate); d.Allowed || d.RetryAfter != time.Second {
+		t.Fatalf("Allow() with an empty bucket = %+v, want a refusal for 1s", d)
+	}
+	fake.Advance(time.Second)
+	if d := allow(rate); !d.Allowed {
+		t.Errorf("Allow() after the refill = %+v, want allowed", d)
+	}
+
+	// Le quota journalier se renouvelle à minuit UTC
+	quota := Limit{Daily: 1}
+	if d := allow(quota); !d.Allowed {
+		t.Fatalf("first Allow() of the day = %+v, want allowed", d)
+	}
+	want := time.Hour - time.Second
+	if d := allow(quota); d.Allowed || d.Reason != ReasonQuota || d.RetryAfter != want {
+		t.Fatalf("Allow() over quota = %+v, want a quota refusal for %v", d, want)
+	}
+	fake.Advance(time.Hour)
+	if d := allow(quota); !d.Allowed {
+		t.Errorf("Allow() the next day = %+v, want allowed", d)
+	}
+}
@@ -0,0 +1,112 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifie l'instrumentation manuelle du service
+const tracerName = "adserver"
+
+// Exporteurs supportés
+const (
+	ExporterNone   = "none"   // Traces désactivées
+	ExporterOTLP   = "otlp"   // Envoi à un collecteur OpenTelemetry via OTLP/gRPC
+	ExporterStdout = "stdout" // Écriture JSON sur la sortie standard
+	ExporterFile   = "file"   // Écriture JSON dans un fichier, pour une analyse hors ligne
+)
+
+// Config regroupe les paramètres d'export des traces.
+type Config struct {
+	ServiceName  string  // Nom du service dans les traces
+	Environment  string  // Environnement de déploiement
+	Exporter     string  // none, otlp, stdout ou file
+	OTLPEndpoint string  // Adresse host:port du collecteur OTLP/gRPC
+	FilePath     string  // Fichier de sortie de l'exporteur file
+	SampleRatio  float64 // Proportion des traces racines échantillonnées (0 à 1)
+}
+
+// Setup installe le TracerProvider global et la propagation W3C trace-context.
+// La fonction retournée vide les spans en attente et doit être appelée à l'arrêt.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	// La propagation est installée même sans export, afin de relayer le contexte des appelants
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	var (
+		exporter sdktrace.SpanExporter
+		closer   func() error
+		err      error
+	)
+	switch cfg.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exporter, err = otlptracegrpc.New(ctx,
+			otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint),
+			otlptracegrpc.WithInsecure(),
+		)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New()
+	case ExporterFile:
+		var f *os.File
+		if f, err = os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to open trace file %s: %w", cfg.FilePath, err)
+		}
+		closer = f.Close
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q (expected none, otlp, stdout or file)", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", cfg.Exporter, err)
+	}
+
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.DeploymentEnvironment(cfg.Environment),
+	)
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			if cerr := closer(); err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}, nil
+}
+
+// StartRepository ouvre un span autour d'une opération de repository.
+// S'utilise en début de méthode :
+//
+//	ctx, span := tracing.StartRepository(ctx, "mongodb", "GetByID")
+//	defer span.End()
+func StartRepository(ctx context.Context, system, operation string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, system+"."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", system),
+			attribute.String("db.operation", operation),
+		),
+	)
+}
@@ -0,0 +1,118 @@
+package tracker
+
+import (
+	"sync"
+	"time"
+
+	"adserver/internal/ports/out"
+)
+
+// BreakerState est l'état du disjoncteur vers le impression-tracker
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // Appels autorisés
+	BreakerHalfOpen                     // Un seul appel de test autorisé après la pause
+	BreakerOpen                         // Appels rejetés immédiatement jusqu'à la fin de la pause
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	}
+	return "unknown"
+}
+
+// breaker s'ouvre après threshold échecs consécutifs et rejette les appels pendant cooldown.
+// À l'issue de la pause, un appel de test décide de la fermeture ou d'une nouvelle ouverture.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	clock     out.Clock
+	onChange  func(BreakerState)
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool // Appel de test en cours en semi-ouvert
+}
+
+func newBreaker(threshold int, cooldown time.Duration, clock out.Clock, onChange func(BreakerState)) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown, clock: clock, onChange: onChange}
+}
+
+// State retourne l'état courant du disjoncteur
+func (b *breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// allow indique si un appel peut être tenté
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.clock.Now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.setState(BreakerHalfOpen)
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+// success enregistre un appel réussi : le disjoncteur se referme
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	if b.state != BreakerClosed {
+		b.setState(BreakerClosed)
+	}
+}
+
+// failure enregistre un échec : le disjoncteur s'ouvre au seuil, ou dès l'échec de l'appel de test
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.openedAt = b.clock.Now()
+		if b.state != BreakerOpen {
+			b.setState(BreakerOpen)
+		}
+	}
+}
+
+// release libère l'appel de test sans conclure (appel annulé par l'appelant)
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// setState change l'état. Doit être appelée avec b.mu verrouillé.
+func (b *breaker) setState(state BreakerState) {
+	b.state = state
+	b.onChange(state)
+}
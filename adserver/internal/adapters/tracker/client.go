@@ -0,0 +1,60 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"adserver/internal/adapters/metrics"
+	"adserver/internal/ports/out"
+	"shared/generated/impression_service"
+
+	"github.com/google/uuid"
+)
+
+// grpcTracker implémente l'interface ImpressionTracker en interrogeant le microservice
+// impression-tracker via gRPC
+type grpcTracker struct {
+	client impression_service.ImpressionServiceClient
+	logger *slog.Logger
+}
+
+// NewImpressionTracker crée un adaptateur vers le microservice impression-tracker
+func NewImpressionTracker(client impression_service.ImpressionServiceClient, logger *slog.Logger) out.ImpressionTracker {
+	return &grpcTracker{client: client, logger: logger.With("component", "ImpressionTracker")}
+}
+
+// GetTotals récupère le trafic de toute la vie des publicités du locataire et additionne
+// valide et invalide. Sans bornes, le tracker compte aussi les impressions en attente.
+func (t *grpcTracker) GetTotals(ctx context.Context, tenant string, ids []uuid.UUID) (map[uuid.UUID]int64, error) {
+	defer metrics.ObserveRepository("impression-tracker", "GetTotals", time.Now())
+	start := time.Now()
+	t.logger.DebugContext(ctx, "GetTotals start", "tenant", tenant, "ads", len(ids))
+
+	req := &impression_service.GetImpressionTotalsRequest{
+		AdIds:  make([]string, len(ids)),
+		Tenant: tenant,
+	}
+	for i, id := range ids {
+		req.AdIds[i] = id.String()
+	}
+
+	resp, err := t.client.GetImpressionTotals(ctx, req)
+	if err != nil {
+		t.logger.ErrorContext(ctx, "GetTotals failed", "error", err)
+		return nil, err
+	}
+
+	totals := make(map[uuid.UUID]int64, len(resp.GetTotals()))
+	for _, total := range resp.GetTotals() {
+		id, err := uuid.Parse(total.GetAdId())
+		if err != nil {
+			return nil, fmt.Errorf("invalid ad id %q returned by impression-tracker: %v", total.GetAdId(), err)
+		}
+		totals[id] = total.GetValid() + total.GetInvalid()
+	}
+
+	t.logger.DebugContext(ctx, "GetTotals completed", "duration", time.Since(start), "ads", len(totals))
+	return totals, nil
+}
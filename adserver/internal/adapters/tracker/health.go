@@ -0,0 +1,29 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// HealthCheck retourne une vérification de la connexion au impression-tracker :
+// le tracker doit répondre SERVING sur grpc.health.v1, donc avoir lui-même ses dépendances,
+// et le disjoncteur du client ne doit pas être ouvert.
+func HealthCheck(conn *grpc.ClientConn, resilient *ResilientClient) func(ctx context.Context) error {
+	client := healthpb.NewHealthClient(conn)
+	return func(ctx context.Context) error {
+		if state := resilient.BreakerState(); state == BreakerOpen {
+			return fmt.Errorf("circuit breaker is %s", state)
+		}
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			return fmt.Errorf("connection %s: %w", conn.GetState(), err)
+		}
+		if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("impression-tracker is %s", resp.GetStatus())
+		}
+		return nil
+	}
+}
@@ -0,0 +1,220 @@
+package tracker
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"adserver/internal/adapters/metrics"
+	"adserver/internal/ports/out"
+	"shared/generated/impression_service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ResilienceConfig regroupe les protections des appels au impression-tracker.
+type ResilienceConfig struct {
+	CallTimeout      time.Duration // Délai maximal de chaque tentative
+	RetryAttempts    int           // Nombre maximal de tentatives des appels idempotents
+	RetryBackoff     time.Duration // Attente avant la première nouvelle tentative, doublée ensuite
+	BreakerThreshold int           // Échecs consécutifs ouvrant le disjoncteur
+	BreakerCooldown  time.Duration // Durée d'ouverture du disjoncteur avant un appel de test
+}
+
+// errCircuitOpen est retournée sans appel réseau tant que le disjoncteur est ouvert
+var errCircuitOpen = status.Error(codes.Unavailable, "impression-tracker circuit breaker is open")
+
+// ResilientClient enveloppe le client gRPC du impression-tracker : chaque tentative a son
+// propre délai, les appels idempotents sont retentés avec une attente aléatoire, et un
+// disjoncteur rejette immédiatement les appels lorsque le tracker est indisponible.
+// Les écritures (TrackImpression, TrackEvent) ne sont jamais retentées pour ne pas
+// compter deux fois la même impression.
+type ResilientClient struct {
+	impression_service.ImpressionServiceClient
+	cfg     ResilienceConfig
+	breaker *breaker
+	logger  *slog.Logger
+}
+
+// NewResilientClient enveloppe client avec les protections de cfg ; la pause du disjoncteur
+// est mesurée selon clock.
+func NewResilientClient(client impression_service.ImpressionServiceClient, cfg ResilienceConfig, clock out.Clock, logger *slog.Logger) *ResilientClient {
+	logger = logger.With("component", "ResilientImpressionClient")
+	metrics.TrackerCircuitState(int(BreakerClosed))
+	return &ResilientClient{
+		ImpressionServiceClient: client,
+		cfg:                     cfg,
+		logger:                  logger,
+		breaker: newBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown, clock, func(state BreakerState) {
+			metrics.TrackerCircuitState(int(state))
+			logger.Warn("Circuit breaker state changed", "state", state.String())
+		}),
+	}
+}
+
+// BreakerState retourne l'état courant du disjoncteur
+func (c *ResilientClient) BreakerState() BreakerState {
+	return c.breaker.State()
+}
+
+func (c *ResilientClient) TrackImpression(ctx context.Context, in *impression_service.TrackImpressionRequest, opts ...grpc.CallOption) (*impression_service.TrackImpressionResponse, error) {
+	var resp *impression_service.TrackImpressionResponse
+	err := c.invoke(ctx, "TrackImpression", false, func(ctx context.Context) (err error) {
+		resp, err = c.ImpressionServiceClient.TrackImpression(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+func (c *ResilientClient) GetImpressionCount(ctx context.Context, in *impression_service.GetImpressionCountRequest, opts ...grpc.CallOption) (*impression_service.GetImpressionCountResponse, error) {
+	var resp *impression_service.GetImpressionCountResponse
+	err := c.invoke(ctx, "GetImpressionCount", true, func(ctx context.Context) (err error) {
+		resp, err = c.ImpressionServiceClient.GetImpressionCount(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+func (c *ResilientClient) TrackEvent(ctx context.Context, in *impression_service.TrackEventRequest, opts ...grpc.CallOption) (*impression_service.TrackEventResponse, error) {
+	var resp *impression_service.TrackEventResponse
+	err := c.invoke(ctx, "TrackEvent", false, func(ctx context.Context) (err error) {
+		resp, err = c.ImpressionServiceClient.TrackEvent(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+func (c *ResilientClient) GetViewabilityReport(ctx context.Context, in *impression_service.GetViewabilityReportRequest, opts ...grpc.CallOption) (*impression_service.GetViewabilityReportResponse, error) {
+	var resp *impression_service.GetViewabilityReportResponse
+	err := c.invoke(ctx, "GetViewabilityReport", true, func(ctx context.Context) (err error) {
+		resp, err = c.ImpressionServiceClient.GetViewabilityReport(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+func (c *ResilientClient) GetTrafficReport(ctx context.Context, in *impression_service.GetTrafficReportRequest, opts ...grpc.CallOption) (*impression_service.GetTrafficReportResponse, error) {
+	var resp *impression_service.GetTrafficReportResponse
+	err := c.invoke(ctx, "GetTrafficReport", true, func(ctx context.Context) (err error) {
+		resp, err = c.ImpressionServiceClient.GetTrafficReport(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+func (c *ResilientClient) GetImpressionTotals(ctx context.Context, in *impression_service.GetImpressionTotalsRequest, opts ...grpc.CallOption) (*impression_service.GetImpressionTotalsResponse, error) {
+	var resp *impression_service.GetImpressionTotalsResponse
+	err := c.invoke(ctx, "GetImpressionTotals", true, func(ctx context.Context) (err error) {
+		resp, err = c.ImpressionServiceClient.GetImpressionTotals(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// ExportImpressions n'est protégé que par le disjoncteur : la durée d'un export
+// dépend du volume et le flux ne peut pas être rejoué.
+func (c *ResilientClient) ExportImpressions(ctx context.Context, in *impression_service.ExportImpressionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[impression_service.ExportImpressionsChunk], error) {
+	if !c.breaker.allow() {
+		metrics.TrackerCall("ExportImpressions", "rejected")
+		return nil, errCircuitOpen
+	}
+	stream, err := c.ImpressionServiceClient.ExportImpressions(ctx, in, opts...)
+	c.record(ctx, "ExportImpressions", err)
+	return stream, err
+}
+
+// DeepHealth n'est pas soumis au disjoncteur afin de toujours refléter l'état réel du tracker.
+func (c *ResilientClient) DeepHealth(ctx context.Context, in *impression_service.DeepHealthRequest, opts ...grpc.CallOption) (*impression_service.DeepHealthResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.CallTimeout)
+	defer cancel()
+	return c.ImpressionServiceClient.DeepHealth(ctx, in, opts...)
+}
+
+// invoke exécute call avec un délai par tentative, sous le contrôle du disjoncteur.
+// Les appels idempotents sont retentés sur erreur transitoire, avec une attente
+// exponentielle dont la moitié est aléatoire pour étaler les reprises des instances.
+func (c *ResilientClient) invoke(ctx context.Context, method string, idempotent bool, call func(ctx context.Context) error) error {
+	attempts := 1
+	if idempotent {
+		attempts = max(c.cfg.RetryAttempts, 1)
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if !c.breaker.allow() {
+			metrics.TrackerCall(method, "rejected")
+			if err != nil {
+				// Le disjoncteur s'est ouvert entre deux tentatives : la dernière erreur est plus parlante
+				return err
+			}
+			return errCircuitOpen
+		}
+
+		callCtx, cancel := context.WithTimeout(ctx, c.cfg.CallTimeout)
+		err = call(callCtx)
+		cancel()
+		c.record(ctx, method, err)
+
+		if err == nil || attempt >= attempts || !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		backoff := c.cfg.RetryBackoff << (attempt - 1)
+		wait := backoff/2 + rand.N(backoff/2+1)
+		metrics.TrackerCall(method, "retry")
+		c.logger.DebugContext(ctx, "Retrying impression-tracker call", "method", method, "attempt", attempt, "wait", wait, "error", err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// record reporte le résultat d'une tentative au disjoncteur et aux métriques.
+// Une tentative interrompue par l'appelant n'est imputée ni au succès ni à l'échec.
+func (c *ResilientClient) record(ctx context.Context, method string, err error) {
+	switch {
+	case err == nil:
+		metrics.TrackerCall(method, "ok")
+		c.breaker.success()
+	case ctx.Err() != nil:
+		metrics.TrackerCall(method, "error")
+		c.breaker.release()
+	case isFailure(err):
+		metrics.TrackerCall(method, "error")
+		c.breaker.failure()
+	default:
+		// Erreur applicative (argument invalide, précondition) : le tracker répond correctement
+		metrics.TrackerCall(method, "error")
+		c.breaker.success()
+	}
+}
+
+// isFailure indique si l'erreur traduit une indisponibilité du tracker. ResourceExhausted
+// signale une limitation de débit d'un locataire : le tracker répond, le disjoncteur reste fermé.
+func isFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown:
+		return true
+	}
+	return false
+}
+
+// retryable indique si une nouvelle tentative peut réussir
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// Ensure ResilientClient implements the ImpressionServiceClient interface
+var _ impression_service.ImpressionServiceClient = (*ResilientClient)(nil)
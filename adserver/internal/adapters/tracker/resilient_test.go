@@ -0,0 +1,266 @@
+package tracker
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"adserver/internal/adapters/clock"
+	"adserver/internal/ports/out"
+	"shared/generated/impression_service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// discard est le logger des tests
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// testStart est l'heure de départ des horloges factices
+var testStart = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// fault est une panne injectée dans la réponse du faux tracker
+type fault func(ctx context.Context) error
+
+// unavailable fait échouer l'appel comme un tracker arrêté
+func unavailable(context.Context) error {
+	return status.Error(codes.Unavailable, "tracker is down")
+}
+
+// hang bloque jusqu'à l'expiration du délai de l'appel
+func hang(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// rejected fait échouer l'appel comme une requête invalide
+func rejected(context.Context) error {
+	return status.Error(codes.InvalidArgument, "bad request")
+}
+
+// faultyServer est un faux impression-tracker qui applique, appel après appel, les pannes
+// programmées puis répond normalement
+type faultyServer struct {
+	impression_service.UnimplementedImpressionServiceServer
+
+	mu     sync.Mutex
+	faults []fault
+	calls  int
+}
+
+// inject programme les pannes des prochains appels
+func (s *faultyServer) inject(faults ...fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, faults...)
+}
+
+// Calls retourne le nombre d'appels reçus
+func (s *faultyServer) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func (s *faultyServer) next(ctx context.Context) error {
+	s.mu.Lock()
+	s.calls++
+	var f fault
+	if len(s.faults) > 0 {
+		f, s.faults = s.faults[0], s.faults[1:]
+	}
+	s.mu.Unlock()
+	if f == nil {
+		return nil
+	}
+	return f(ctx)
+}
+
+func (s *faultyServer) TrackImpression(ctx context.Context, _ *impression_service.TrackImpressionRequest) (*impression_service.TrackImpressionResponse, error) {
+	if err := s.next(ctx); err != nil {
+		return nil, err
+	}
+	return &impression_service.TrackImpressionResponse{Success: true}, nil
+}
+
+func (s *faultyServer) GetImpressionCount(ctx context.Context, _ *impression_service.GetImpressionCountRequest) (*impression_service.GetImpressionCountResponse, error) {
+	if err := s.next(ctx); err != nil {
+		return nil, err
+	}
+	return &impression_service.GetImpressionCountResponse{Count: 42}, nil
+}
+
+// newResilientClient démarre le faux tracker, en bonne santé, sur une connexion en mémoire
+// et retourne le client protégé par cfg
+func newResilientClient(t *testing.T, cfg ResilienceConfig) (*ResilientClient, *faultyServer) {
+	client, fake, _ := newResilientConn(t, cfg, clock.NewFake(testStart))
+	return client, fake
+}
+
+// newResilientConn retourne aussi la connexion ; la pause du disjoncteur suit clk
+func newResilientConn(t *testing.T, cfg ResilienceConfig, clk out.Clock) (*ResilientClient, *faultyServer, *grpc.ClientConn) {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	fake := &faultyServer{}
+	server := grpc.NewServer()
+	impression_service.RegisterImpressionServiceServer(server, fake)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///tracker",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewResilientClient(impression_service.NewImpressionServiceClient(conn), cfg, clk, discard), fake, conn
+}
+
+// testConfig retente trois fois sans attente notable et ouvre le disjoncteur au bout de dix échecs
+func testConfig() ResilienceConfig {
+	return ResilienceConfig{
+		CallTimeout:      50 * time.Millisecond,
+		RetryAttempts:    3,
+		RetryBackoff:     time.Millisecond,
+		BreakerThreshold: 10,
+		BreakerCooldown:  time.Minute,
+	}
+}
+
+func getCount(c *ResilientClient) error {
+	_, err := c.GetImpressionCount(context.Background(), &impression_service.GetImpressionCountRequest{AdId: "ad"})
+	return err
+}
+
+func trackImpression(c *ResilientClient) error {
+	_, err := c.TrackImpression(context.Background(), &impression_service.TrackImpressionRequest{AdId: "ad"})
+	return err
+}
+
+func TestResilientClientCallTimeout(t *testing.T) {
+	cfg := testConfig()
+	cfg.RetryAttempts = 1
+	client, fake := newResilientClient(t, cfg)
+	fake.inject(hang)
+
+	start := time.Now()
+	err := getCount(client)
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("GetImpressionCount() error = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("call took %v, want about the %v call timeout", elapsed, cfg.CallTimeout)
+	}
+}
+
+func TestResilientClientRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		call      func(*ResilientClient) error
+		faults    []fault
+		wantCode  codes.Code
+		wantCalls int
+	}{
+		{name: "idempotent call recovers", call: getCount, faults: []fault{unavailable, hang}, wantCode: codes.OK, wantCalls: 3},
+		{name: "idempotent call gives up", call: getCount, faults: []fault{unavailable, unavailable, unavailable, unavailable}, wantCode: codes.Unavailable, wantCalls: 3},
+		{name: "application error not retried", call: getCount, faults: []fault{rejected}, wantCode: codes.InvalidArgument, wantCalls: 1},
+		{name: "write never retried", call: trackImpression, faults: []fault{unavailable}, wantCode: codes.Unavailable, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, fake := newResilientClient(t, testConfig())
+			fake.inject(tt.faults...)
+			if err := tt.call(client); status.Code(err) != tt.wantCode {
+				t.Errorf("error = %v, want %v", err, tt.wantCode)
+			}
+			if got := fake.Calls(); got != tt.wantCalls {
+				t.Errorf("server calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestResilientClientCircuitBreaker(t *testing.T) {
+	cfg := testConfig()
+	cfg.RetryAttempts = 1
+	cfg.BreakerThreshold = 2
+	cfg.BreakerCooldown = time.Minute
+	clk := clock.NewFake(testStart)
+	client, fake, conn := newResilientConn(t, cfg, clk)
+	check := HealthCheck(conn, client)
+	if err := check(context.Background()); err != nil {
+		t.Fatalf("HealthCheck() = %v with a closed breaker, want healthy", err)
+	}
+
+	// Une erreur applicative ne compte pas comme un échec du tracker
+	fake.inject(rejected, unavailable, unavailable)
+	for i := 0; i < 3; i++ {
+		trackImpression(client)
+	}
+	if state := client.BreakerState(); state != BreakerOpen {
+		t.Fatalf("state after 2 consecutive failures = %v, want open", state)
+	}
+	if err := check(context.Background()); err == nil {
+		t.Error("HealthCheck() = nil with an open breaker, want an error")
+	}
+
+	// Disjoncteur ouvert : échec immédiat sans appel réseau
+	if err := trackImpression(client); err != errCircuitOpen {
+		t.Errorf("error while open = %v, want %v", err, errCircuitOpen)
+	}
+	if got := fake.Calls(); got != 3 {
+		t.Errorf("server calls = %d, want 3 (rejected call not sent)", got)
+	}
+
+	// Le disjoncteur reste ouvert pendant toute la pause
+	clk.Advance(cfg.BreakerCooldown - time.Second)
+	if err := trackImpression(client); err != errCircuitOpen {
+		t.Errorf("error before the end of the cooldown = %v, want %v", err, errCircuitOpen)
+	}
+
+	// L'appel de test qui échoue rouvre le disjoncteur
+	clk.Advance(time.Second)
+	fake.inject(unavailable)
+	if err := getCount(client); status.Code(err) != codes.Unavailable {
+		t.Fatalf("probe error = %v, want Unavailable", err)
+	}
+	if state := client.BreakerState(); state != BreakerOpen {
+		t.Fatalf("state after a failed probe = %v, want open", state)
+	}
+
+	// L'appel de test qui réussit le referme
+	clk.Advance(cfg.BreakerCooldown)
+	if err := getCount(client); err != nil {
+		t.Fatalf("probe error = %v, want success", err)
+	}
+	if state := client.BreakerState(); state != BreakerClosed {
+		t.Errorf("state after a successful probe = %v, want closed", state)
+	}
+}
+
+func TestBreakerAllowsOneProbe(t *testing.T) {
+	b := newBreaker(1, 0, clock.NewFake(testStart), func(BreakerState) {})
+	b.failure()
+	if !b.allow() {
+		t.Fatal("allow() = false after the cooldown, want a probe")
+	}
+	if b.allow() {
+		t.Error("allow() = true while the probe is in flight")
+	}
+	b.release()
+	if !b.allow() {
+		t.Error("allow() = false after the probe was released")
+	}
+}
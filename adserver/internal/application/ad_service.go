@@ -3,7 +3,7 @@ package application
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"adserver/internal/domain"
@@ -16,53 +16,59 @@ import (
 // AdServiceImpl implémente l'interface AdService
 // Cette implémentation gère la logique métier des annonces
 type AdServiceImpl struct {
-	repo out.AdRepository
+	repo   out.AdRepository
+	clock  out.Clock
+	logger *slog.Logger
 }
 
-// NewAdService crée une nouvelle instance du service d'annonces
-func NewAdService(repo out.AdRepository) in.AdService {
-	return &AdServiceImpl{repo: repo}
+// NewAdService crée une nouvelle instance du service d'annonces.
+// Les dates d'expiration sont évaluées selon clock.
+func NewAdService(repo out.AdRepository, clock out.Clock, logger *slog.Logger) in.AdService {
+	return &AdServiceImpl{repo: repo, clock: clock, logger: logger.With("component", "AdService")}
 }
 
-// CreateAd crée une nouvelle annonce
+// CreateAd crée une nouvelle annonce pour le locataire de l'appelant
 func (s *AdServiceImpl) CreateAd(ctx context.Context, ad *domain.Pub) (*domain.Pub, error) {
 	start := time.Now()
-	log.Printf("[AdService CreateAd] start: Title=%s", ad.Title)
+	s.logger.DebugContext(ctx, "CreateAd start", "title", ad.Title)
 
 	// Génération d'un ID unique
 	ad.ID = uuid.New()
 
+	// Rattachement au locataire de l'appelant
+	ad.Tenant = domain.TenantFromContext(ctx)
+
 	// Construction de l'URL de tracking
 	ad.URL = fmt.Sprintf("https://%s/ads/%s", "localhost:8080", ad.ID.String())
 
 	// Si pas de date d'expiration, on met une date par défaut (24h)
 	if ad.ExpiresAt.IsZero() {
-		ad.ExpiresAt = time.Now().Add(24 * time.Hour)
+		ad.ExpiresAt = s.clock.Now().Add(24 * time.Hour)
 	}
 
 	// Initialisation du compteur d'impressions
 	ad.Impressions = 0
 
 	// Validation de la date d'expiration
-	if !ad.ExpiresAt.After(time.Now()) {
+	if !ad.ExpiresAt.After(s.clock.Now()) {
 		return nil, fmt.Errorf("expiration date must be in the future")
 	}
 
 	// Création dans le repository
 	_, err := s.repo.Create(ctx, ad)
 	if err != nil {
-		log.Printf("[AdService CreateAd] error: %v", err)
+		s.logger.ErrorContext(ctx, "CreateAd failed", "error", err)
 		return nil, err
 	}
 
-	log.Printf("[AdService CreateAd] completed in %v id=%s", time.Since(start), ad.ID)
+	s.logger.DebugContext(ctx, "CreateAd completed", "duration", time.Since(start), "id", ad.ID)
 	return ad, nil
 }
 
 // GetAd récupère une annonce par son ID
 func (s *AdServiceImpl) GetAd(ctx context.Context, id string) (*domain.Pub, error) {
 	start := time.Now()
-	log.Printf("[AdService GetAd] start: id=%s", id)
+	s.logger.DebugContext(ctx, "GetAd start", "id", id)
 
 	// Validation de l'ID
 	uuid, err := uuid.Parse(id)
@@ -73,55 +79,63 @@ func (s *AdServiceImpl) GetAd(ctx context.Context, id string) (*domain.Pub, erro
 	// Récupération depuis le repository
 	ad, err := s.repo.GetByID(ctx, uuid)
 	if err != nil {
-		log.Printf("[AdService GetAd] error: %v", err)
+		s.logger.ErrorContext(ctx, "GetAd failed", "id", id, "error", err)
 		return nil, err
 	}
+	if ad == nil {
+		return nil, domain.ErrAdNotFound
+	}
 
-	log.Printf("[AdService GetAd] completed in %v id=%s", time.Since(start), id)
+	s.logger.DebugContext(ctx, "GetAd completed", "duration", time.Since(start), "id", id)
 	return ad, nil
 }
 
-// ServeAd sert une annonce et incrémente son compteur d'impressions
-func (s *AdServiceImpl) ServeAd(ctx context.Context, id uuid.UUID) (string, int64, error) {
+// ServeAd sert une annonce et incrémente son compteur d'impressions.
+// L'annonce retournée porte le compteur après incrémentation.
+func (s *AdServiceImpl) ServeAd(ctx context.Context, id uuid.UUID) (*domain.Pub, error) {
 	start := time.Now()
-	log.Printf("[AdService ServeAd] start: id=%s", id)
+	s.logger.DebugContext(ctx, "ServeAd start", "id", id)
 
 	// Récupération de l'annonce
 	ad, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		log.Printf("[AdService ServeAd] error getting ad: %v", err)
-		return "", 0, err
+		s.logger.ErrorContext(ctx, "ServeAd failed to get ad", "id", id, "error", err)
+		return nil, err
+	}
+	if ad == nil {
+		return nil, domain.ErrAdNotFound
 	}
 
 	// Vérification de l'expiration
-	if !ad.ExpiresAt.After(time.Now()) {
-		return "", 0, fmt.Errorf("ad has expired")
+	if !ad.ExpiresAt.After(s.clock.Now()) {
+		return nil, fmt.Errorf("ad has expired")
 	}
 
 	// Incrémentation du compteur d'impressions
 	impressions, err := s.repo.IncrementImpressions(ctx, id)
 	if err != nil {
-		log.Printf("[AdService ServeAd] error incrementing impressions: %v", err)
-		return "", 0, err
+		s.logger.ErrorContext(ctx, "ServeAd failed to increment impressions", "id", id, "error", err)
+		return nil, err
 	}
+	ad.Impressions = impressions
 
-	log.Printf("[AdService ServeAd] completed in %v id=%s impressions=%d", time.Since(start), id, impressions)
-	return ad.URL, impressions, nil
+	s.logger.DebugContext(ctx, "ServeAd completed", "duration", time.Since(start), "id", id, "impressions", impressions)
+	return ad, nil
 }
 
 // GetAdImpressions récupère le nombre d'impressions d'une annonce
 func (s *AdServiceImpl) GetAdImpressions(ctx context.Context, id uuid.UUID) (int64, error) {
 	start := time.Now()
-	log.Printf("[AdService GetAdImpressions] start: id=%s", id)
+	s.logger.DebugContext(ctx, "GetAdImpressions start", "id", id)
 
 	// Récupération du compteur d'impressions
 	impressions, err := s.repo.GetImpressions(ctx, id)
 	if err != nil {
-		log.Printf("[AdService GetAdImpressions] error: %v", err)
+		s.logger.ErrorContext(ctx, "GetAdImpressions failed", "id", id, "error", err)
 		return 0, err
 	}
 
-	log.Printf("[AdService GetAdImpressions] completed in %v id=%s impressions=%d", time.Since(start), id, impressions)
+	s.logger.DebugContext(ctx, "GetAdImpressions completed", "duration", time.Since(start), "id", id, "impressions", impressions)
 	return impressions, nil
 }
 
@@ -134,7 +148,7 @@ func (s *AdServiceImpl) CleanupExpired(ctx context.Context) error {
 // IncrementImpressions incrémente le compteur d'impressions d'une annonce
 func (s *AdServiceImpl) IncrementImpressions(ctx context.Context, id string) (int64, error) {
 	start := time.Now()
-	log.Printf("[AdService IncrementImpressions] start: id=%s", id)
+	s.logger.DebugContext(ctx, "IncrementImpressions start", "id", id)
 
 	// Validation de l'ID
 	uuid, err := uuid.Parse(id)
@@ -145,26 +159,26 @@ func (s *AdServiceImpl) IncrementImpressions(ctx context.Context, id string) (in
 	// Incrémentation du compteur
 	impressions, err := s.repo.IncrementImpressions(ctx, uuid)
 	if err != nil {
-		log.Printf("[AdService IncrementImpressions] error: %v", err)
+		s.logger.ErrorContext(ctx, "IncrementImpressions failed", "id", id, "error", err)
 		return 0, err
 	}
 
-	log.Printf("[AdService IncrementImpressions] completed in %v id=%s impressions=%d", time.Since(start), id, impressions)
+	s.logger.DebugContext(ctx, "IncrementImpressions completed", "duration", time.Since(start), "id", id, "impressions", impressions)
 	return impressions, nil
 }
 
-// DeleteExpired supprime les annonces expirées
+// DeleteExpired retire les annonces expirées ; le repository les archive
 func (s *AdServiceImpl) DeleteExpired(ctx context.Context) (int64, error) {
 	start := time.Now()
-	log.Printf("[AdService DeleteExpired] start")
+	s.logger.DebugContext(ctx, "DeleteExpired start")
 
-	// Suppression des annonces expirées
+	// Archivage des annonces expirées
 	count, err := s.repo.DeleteExpired(ctx)
 	if err != nil {
-		log.Printf("[AdService DeleteExpired] error: %v", err)
+		s.logger.ErrorContext(ctx, "DeleteExpired failed", "error", err)
 		return 0, err
 	}
 
-	log.Printf("[AdService DeleteExpired] completed in %v deleted=%d", time.Since(start), count)
+	s.logger.DebugContext(ctx, "DeleteExpired completed", "duration", time.Since(start), "deleted", count)
 	return count, nil
 }
@@ -0,0 +1,200 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"adserver/internal/domain"
+	"adserver/internal/ports/in"
+	"adserver/internal/ports/out"
+
+	"github.com/google/uuid"
+)
+
+const (
+	apiKeySecretPrefix = "ak_" // Préfixe des secrets, repérable par les scanners de secrets
+	apiKeyPrefixLength = 10    // Nombre de caractères du secret conservés en clair pour identifier la clé
+)
+
+// cachedPrincipal est une authentification réussie gardée en mémoire
+type cachedPrincipal struct {
+	principal *domain.Principal
+	expiresAt time.Time
+}
+
+// APIKeyServiceImpl gère les clés d'API. Les authentifications réussies sont gardées
+// en mémoire pendant cacheTTL pour ne pas interroger MongoDB à chaque appel : une clé
+// révoquée sur une autre instance reste acceptée au plus cacheTTL sur celle-ci.
+type APIKeyServiceImpl struct {
+	repo     out.APIKeyRepository
+	clock    out.Clock
+	cacheTTL time.Duration
+	logger   *slog.Logger
+
+	mu    sync.Mutex
+	cache map[string]cachedPrincipal // Par empreinte du secret
+}
+
+// NewAPIKeyService crée le service de gestion des clés d'API ; les dates de création et de
+// révocation, comme l'expiration du cache, suivent clock
+func NewAPIKeyService(repo out.APIKeyRepository, clock out.Clock, cacheTTL time.Duration, logger *slog.Logger) in.APIKeyService {
+	return &APIKeyServiceImpl{
+		repo:     repo,
+		clock:    clock,
+		cacheTTL: cacheTTL,
+		logger:   logger.With("component", "APIKeyService"),
+		cache:    make(map[string]cachedPrincipal),
+	}
+}
+
+// CreateKey crée une clé et retourne son secret
+func (s *APIKeyServiceImpl) CreateKey(ctx context.Context, name, tenant string, role domain.Role) (*domain.APIKey, string, error) {
+	if !role.Valid() {
+		return nil, "", fmt.Errorf("invalid role %q", role)
+	}
+	secret, err := newAPIKeySecret()
+	if err != nil {
+		return nil, "", err
+	}
+	key, err := s.store(ctx, name, tenant, role, secret)
+	if err != nil {
+		return nil, "", err
+	}
+	s.logger.InfoContext(ctx, "API key created", "key_id", key.ID, "name", name, "tenant", tenant, "role", role)
+	return key, secret, nil
+}
+
+// RotateKey crée une clé remplaçant id puis révoque cette dernière
+func (s *APIKeyServiceImpl) RotateKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, string, error) {
+	old, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if old.Revoked() {
+		return nil, "", fmt.Errorf("api key %s is revoked", id)
+	}
+
+	secret, err := newAPIKeySecret()
+	if err != nil {
+		return nil, "", err
+	}
+	key, err := s.store(ctx, old.Name, old.Tenant, old.Role, secret)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := s.RevokeKey(ctx, id); err != nil {
+		return nil, "", err
+	}
+	s.logger.InfoContext(ctx, "API key rotated", "old_key_id", id, "key_id", key.ID)
+	return key, secret, nil
+}
+
+// RevokeKey révoque une clé et l'écarte du cache de cette instance
+func (s *APIKeyServiceImpl) RevokeKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	if err := s.repo.Revoke(ctx, id, s.clock.Now()); err != nil {
+		return nil, err
+	}
+	key, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	delete(s.cache, key.Hash)
+	s.mu.Unlock()
+
+	s.logger.InfoContext(ctx, "API key revoked", "key_id", id)
+	return key, nil
+}
+
+// ListKeys retourne toutes les clés
+func (s *APIKeyServiceImpl) ListKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	return s.repo.List(ctx)
+}
+
+// Authenticate retourne l'identité associée au secret
+func (s *APIKeyServiceImpl) Authenticate(ctx context.Context, secret string) (*domain.Principal, error) {
+	hash := hashAPIKeySecret(secret)
+
+	s.mu.Lock()
+	cached, ok := s.cache[hash]
+	s.mu.Unlock()
+	if ok && s.clock.Now().Before(cached.expiresAt) {
+		return cached.principal, nil
+	}
+
+	key, err := s.repo.GetByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if key.Revoked() {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+
+	principal := &domain.Principal{KeyID: key.ID, Name: key.Name, Tenant: key.Tenant, Role: key.Role}
+	if s.cacheTTL > 0 {
+		s.mu.Lock()
+		s.cache[hash] = cachedPrincipal{principal: principal, expiresAt: s.clock.Now().Add(s.cacheTTL)}
+		s.mu.Unlock()
+	}
+	return principal, nil
+}
+
+// EnsureKey enregistre le secret s'il n'existe pas encore
+func (s *APIKeyServiceImpl) EnsureKey(ctx context.Context, name, tenant string, role domain.Role, secret string) error {
+	_, err := s.repo.GetByHash(ctx, hashAPIKeySecret(secret))
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		return err
+	}
+	key, err := s.store(ctx, name, tenant, role, secret)
+	if err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "Bootstrap API key registered", "key_id", key.ID, "name", name, "role", role)
+	return nil
+}
+
+// store enregistre une clé pour le secret donné
+func (s *APIKeyServiceImpl) store(ctx context.Context, name, tenant string, role domain.Role, secret string) (*domain.APIKey, error) {
+	key := &domain.APIKey{
+		ID:        uuid.New(),
+		Name:      name,
+		Tenant:    tenant,
+		Role:      role,
+		Prefix:    secret[:min(apiKeyPrefixLength, len(secret))],
+		Hash:      hashAPIKeySecret(secret),
+		CreatedAt: s.clock.Now(),
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		s.logger.ErrorContext(ctx, "API key creation failed", "error", err)
+		return nil, err
+	}
+	return key, nil
+}
+
+// newAPIKeySecret génère un secret aléatoire de 256 bits
+func newAPIKeySecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiKeySecretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashAPIKeySecret retourne l'empreinte SHA-256 du secret. Les secrets étant aléatoires
+// et longs, une fonction de dérivation lente n'apporte rien et ralentirait chaque appel.
+func hashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
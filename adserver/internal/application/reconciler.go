@@ -0,0 +1,113 @@
+package application
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"adserver/internal/domain"
+	"adserver/internal/ports/in"
+	"adserver/internal/ports/out"
+
+	"github.com/google/uuid"
+)
+
+// reconcileBatchSize est le nombre de publicités comparées par appel au tracker
+const reconcileBatchSize = 100
+
+// Reconciler compare les compteurs d'impressions de l'adserver à ceux du tracker.
+// Les deux sources divergent lorsque l'appel au tracker échoue dans ServeAd.
+type Reconciler struct {
+	repo    out.AdRepository
+	tracker out.ImpressionTracker
+	logger  *slog.Logger
+}
+
+// NewReconciler crée le service de réconciliation des impressions
+func NewReconciler(repo out.AdRepository, tracker out.ImpressionTracker, logger *slog.Logger) in.ImpressionReconciler {
+	return &Reconciler{repo: repo, tracker: tracker, logger: logger.With("component", "Reconciler")}
+}
+
+// Reconcile compare, pour chaque publicité active pendant [from, to), son compteur d'impressions
+// au total du tracker. Les deux compteurs couvrent toute la vie de la publicité : la période
+// ne fait que choisir les publicités comparées. Avec repair, l'écart est appliqué au compteur
+// de l'adserver sous forme d'incrément, sans écraser les impressions servies entre-temps.
+func (r *Reconciler) Reconcile(ctx context.Context, from, to time.Time, repair bool) (*domain.ReconciliationReport, error) {
+	start := time.Now()
+	r.logger.DebugContext(ctx, "Reconcile start", "from", from, "to", to, "repair", repair)
+
+	report := &domain.ReconciliationReport{}
+	for offset := int64(0); ; offset += reconcileBatchSize {
+		ads, err := r.repo.List(ctx, map[string]interface{}{}, offset, reconcileBatchSize)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Reconcile failed to list ads", "error", err)
+			return nil, err
+		}
+
+		if err := r.reconcileBatch(ctx, ads, from, repair, report); err != nil {
+			return nil, err
+		}
+
+		if len(ads) < reconcileBatchSize {
+			break
+		}
+	}
+
+	r.logger.InfoContext(ctx, "Reconcile completed", "duration", time.Since(start),
+		"checked", report.Checked, "drifts", len(report.Drifts), "repaired", report.Repaired)
+	return report, nil
+}
+
+// reconcileBatch compare un lot de publicités et complète le rapport.
+// Le tracker comptant par locataire, ses totaux sont demandés locataire par locataire.
+func (r *Reconciler) reconcileBatch(ctx context.Context, ads []*domain.Pub, from time.Time, repair bool, report *domain.ReconciliationReport) error {
+	// Les publicités expirées avant le début de la période ne sont pas concernées
+	byTenant := make(map[string][]*domain.Pub)
+	for _, ad := range ads {
+		if ad.ExpiresAt.Before(from) {
+			continue
+		}
+		byTenant[ad.Tenant] = append(byTenant[ad.Tenant], ad)
+	}
+
+	for tenant, active := range byTenant {
+		ids := make([]uuid.UUID, len(active))
+		for i, ad := range active {
+			ids[i] = ad.ID
+		}
+		totals, err := r.tracker.GetTotals(ctx, tenant, ids)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Reconcile failed to get tracker totals", "tenant", tenant, "error", err)
+			return err
+		}
+		r.compare(ctx, active, totals, repair, report)
+	}
+	return nil
+}
+
+// compare confronte les compteurs des publicités aux totaux du tracker
+func (r *Reconciler) compare(ctx context.Context, active []*domain.Pub, totals map[uuid.UUID]int64, repair bool, report *domain.ReconciliationReport) {
+	for _, ad := range active {
+		report.Checked++
+		drift := domain.ImpressionDrift{
+			AdID:     ad.ID,
+			AdServer: ad.Impressions,
+			Tracker:  totals[ad.ID],
+		}
+		if drift.Drift() == 0 {
+			continue
+		}
+
+		if repair {
+			if _, err := r.repo.AdjustImpressions(ctx, ad.ID, -drift.Drift()); err != nil {
+				r.logger.ErrorContext(ctx, "Reconcile failed to repair ad", "id", ad.ID, "error", err)
+			} else {
+				drift.Repaired = true
+				report.Repaired++
+			}
+		}
+		r.logger.WarnContext(ctx, "Impression drift", "id", ad.ID,
+			"adserver", drift.AdServer, "tracker", drift.Tracker, "repaired", drift.Repaired)
+		report.Drifts = append(report.Drifts, drift)
+	}
+}
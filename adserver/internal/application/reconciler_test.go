@@ -0,0 +1,104 @@
+package application
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"adserver/internal/adapters/clock"
+	"adserver/internal/adapters/memory"
+	"adserver/internal/domain"
+	"adserver/internal/ports/out"
+
+	"github.com/google/uuid"
+)
+
+var testStart = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// discard est le logger des tests
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// fakeTracker retourne des totaux fixes ; onTotals s'exécute avant la réponse pour
+// simuler les impressions servies pendant la réconciliation
+type fakeTracker struct {
+	totals   map[uuid.UUID]int64
+	onTotals func()
+}
+
+func (t *fakeTracker) GetTotals(_ context.Context, _ string, ids []uuid.UUID) (map[uuid.UUID]int64, error) {
+	if t.onTotals != nil {
+		t.onTotals()
+	}
+	totals := make(map[uuid.UUID]int64, len(ids))
+	for _, id := range ids {
+		totals[id] = t.totals[id]
+	}
+	return totals, nil
+}
+
+// createAd enregistre une publicité de tenant expirant à expiresAt avec impressions impressions
+func createAd(t *testing.T, repo out.AdRepository, tenant string, expiresAt time.Time, impressions int64) uuid.UUID {
+	t.Helper()
+	ad := &domain.Pub{ID: uuid.New(), Tenant: tenant, Title: "ad", URL: "https://example.com/", ExpiresAt: expiresAt, Impressions: impressions}
+	if _, err := repo.Create(context.Background(), ad); err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	return ad.ID
+}
+
+func impressions(t *testing.T, repo out.AdRepository, id uuid.UUID) int64 {
+	t.Helper()
+	count, err := repo.GetImpressions(context.Background(), id)
+	if err != nil {
+		t.Fatalf("GetImpressions() error: %v", err)
+	}
+	return count
+}
+
+func TestReconcileReportsDrifts(t *testing.T) {
+	repo := memory.NewAdRepository(clock.NewFake(testStart), 0)
+	inSync := createAd(t, repo, "acme", testStart.Add(time.Hour), 5)
+	behind := createAd(t, repo, "globex", testStart.Add(time.Hour), 3)
+	expired := createAd(t, repo, "acme", testStart.Add(-time.Hour), 1)
+	tracker := &fakeTracker{totals: map[uuid.UUID]int64{inSync: 5, behind: 7, expired: 9}}
+
+	report, err := NewReconciler(repo, tracker, discard).Reconcile(context.Background(), testStart, testStart.Add(time.Minute), false)
+	if err != nil {
+		t.Fatalf("Reconcile() error: %v", err)
+	}
+	if report.Checked != 2 || report.Repaired != 0 {
+		t.Errorf("Checked, Repaired = %d, %d, want 2, 0 (expired ad skipped)", report.Checked, report.Repaired)
+	}
+	if len(report.Drifts) != 1 || report.Drifts[0].AdID != behind || report.Drifts[0].Drift() != -4 {
+		t.Fatalf("Drifts = %+v, want one drift of -4 for %s", report.Drifts, behind)
+	}
+	if got := impressions(t, repo, behind); got != 3 {
+		t.Errorf("impressions = %d without repair, want 3", got)
+	}
+}
+
+func TestReconcileRepairKeepsConcurrentImpressions(t *testing.T) {
+	repo := memory.NewAdRepository(clock.NewFake(testStart), 0)
+	id := createAd(t, repo, "acme", testStart.Add(time.Hour), 3)
+	// Une impression est servie, et comptée des deux côtés, entre la lecture des publicités
+	// et la réparation : elle ne doit pas être écrasée
+	tracker := &fakeTracker{totals: map[uuid.UUID]int64{id: 7}}
+	tracker.onTotals = func() {
+		if _, err := repo.IncrementImpressions(context.Background(), id); err != nil {
+			t.Fatalf("IncrementImpressions() error: %v", err)
+		}
+	}
+
+	report, err := NewReconciler(repo, tracker, discard).Reconcile(context.Background(), time.Time{}, testStart, true)
+	if err != nil {
+		t.Fatalf("Reconcile() error: %v", err)
+	}
+	if report.Repaired != 1 || len(report.Drifts) != 1 || !report.Drifts[0].Repaired {
+		t.Fatalf("report = %+v, want one repaired drift", report)
+	}
+	if got := impressions(t, repo, id); got != 8 {
+		t.Errorf("impressions = %d after repair, want 8 (tracker total plus the concurrent impression)", got)
+	}
+}
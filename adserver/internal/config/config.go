@@ -0,0 +1,376 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+)
+
+// Config regroupe toute la configuration de l'adserver. Chaque champ porte sa clé YAML
+// et la variable d'environnement qui le surcharge ; sur une structure imbriquée, le tag env
+// préfixe les variables de ses champs. Les secrets sont masqués à l'affichage.
+type Config struct {
+	Service   ServiceConfig   `yaml:"service"`
+	Storage   StorageConfig   `yaml:"storage"`
+	Log       LogConfig       `yaml:"log"`
+	GRPC      GRPCConfig      `yaml:"grpc"`
+	TLS       TLSConfig       `yaml:"tls"`
+	MongoDB   MongoDBConfig   `yaml:"mongodb"`
+	AdCache   AdCacheConfig   `yaml:"ad_cache"`
+	Tracker   TrackerConfig   `yaml:"tracker"`
+	Auth      AuthConfig      `yaml:"auth"`
+	JWT       JWTConfig       `yaml:"jwt"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Gateway   GatewayConfig   `yaml:"gateway"`
+	Health    HealthConfig    `yaml:"health"`
+	Reconcile ReconcileConfig `yaml:"reconcile"`
+	Metrics   MetricsConfig   `yaml:"metrics"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+}
+
+// ServiceConfig identifie l'instance dans les logs et les traces
+type ServiceConfig struct {
+	Name        string `yaml:"name" env:"SERVICE_NAME"`
+	Environment string `yaml:"environment" env:"ENVIRONMENT"`
+}
+
+// StorageConfig choisit les adaptateurs de stockage
+type StorageConfig struct {
+	Backend string `yaml:"backend" env:"STORAGE"` // external (MongoDB) ou memory
+}
+
+// Memory indique si les publicités et les clés sont gardées en mémoire, sans MongoDB
+func (c StorageConfig) Memory() bool {
+	return c.Backend == "memory"
+}
+
+// LogConfig règle les logs JSON
+type LogConfig struct {
+	Level       string `yaml:"level" env:"LOG_LEVEL"`               // debug, info, warn ou error
+	SampleEvery int    `yaml:"sample_every" env:"LOG_SAMPLE_EVERY"` // Un ServeAd journalisé sur N sous le niveau warn
+}
+
+// GRPCConfig est l'adresse d'écoute du serveur gRPC
+type GRPCConfig struct {
+	Host string `yaml:"host" env:"GRPC_HOST"`
+	Port int    `yaml:"port" env:"GRPC_PORT"`
+}
+
+// Address retourne l'adresse d'écoute host:port
+func (c GRPCConfig) Address() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
+}
+
+// TLSConfig active le TLS du serveur gRPC, et le mTLS si une autorité des clients est fournie
+type TLSConfig struct {
+	CertFile       string        `yaml:"cert_file" env:"TLS_CERT_FILE"`
+	KeyFile        string        `yaml:"key_file" env:"TLS_KEY_FILE"`
+	ClientCAFile   string        `yaml:"client_ca_file" env:"TLS_CLIENT_CA_FILE"`
+	ReloadInterval time.Duration `yaml:"reload_interval" env:"TLS_RELOAD_INTERVAL"` // 0 : jamais
+}
+
+// Enabled indique si le serveur gRPC chiffre ses connexions
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.ClientCAFile != ""
+}
+
+// ClientTLSConfig est le TLS d'une connexion sortante : autorité du serveur, certificat pour le mTLS
+type ClientTLSConfig struct {
+	CertFile   string `yaml:"cert_file" env:"CERT_FILE"`
+	KeyFile    string `yaml:"key_file" env:"KEY_FILE"`
+	CAFile     string `yaml:"ca_file" env:"CA_FILE"`
+	ServerName string `yaml:"server_name" env:"SERVER_NAME"`
+}
+
+// MongoDBConfig est la base des publicités et des clés d'API
+type MongoDBConfig struct {
+	URI               string        `yaml:"uri" env:"MONGODB_URI" secret:"url"`
+	Database          string        `yaml:"database" env:"MONGODB_DATABASE"`
+	AdsCollection     string        `yaml:"ads_collection" env:"MONGODB_COLLECTION"`
+	ArchiveCollection string        `yaml:"archive_collection" env:"MONGODB_ARCHIVE_COLLECTION"` // Annonces expirées
+	ArchiveRetention  time.Duration `yaml:"archive_retention" env:"MONGODB_ARCHIVE_RETENTION"`   // Purge par index TTL, 0 = jamais
+	Migrate           bool          `yaml:"migrate" env:"MONGODB_MIGRATE"`                       // Migrations du schéma au démarrage
+	// Écriture groupée des impressions, 0 = un $inc par impression
+	ImpressionFlushInterval time.Duration `yaml:"impression_flush_interval" env:"MONGODB_IMPRESSION_FLUSH_INTERVAL"`
+}
+
+// DragonflyConfig est une connexion à Dragonfly : un nœud, des sentinelles ou un cluster.
+// Ses variables sont préfixées par l'usage de la connexion (AD_CACHE_DRAGONFLY_MODE...).
+type DragonflyConfig struct {
+	Mode             string          `yaml:"mode" env:"MODE"`  // standalone, sentinel ou cluster
+	Addrs            []string        `yaml:"addrs" env:"ADDR"` // Séparées par des virgules dans la variable
+	MasterName       string          `yaml:"master_name" env:"MASTER_NAME"`
+	Username         string          `yaml:"username" env:"USERNAME"`
+	Password         string          `yaml:"password" env:"PASSWORD" secret:"true"`
+	SentinelUsername string          `yaml:"sentinel_username" env:"SENTINEL_USERNAME"`
+	SentinelPassword string          `yaml:"sentinel_password" env:"SENTINEL_PASSWORD" secret:"true"`
+	DB               int             `yaml:"db" env:"DB"`
+	TLSEnabled       bool            `yaml:"tls_enabled" env:"TLS_ENABLED"` // Implicite si un fichier TLS est fourni
+	TLS              ClientTLSConfig `yaml:"tls" env:"TLS_"`
+	Pool             PoolConfig      `yaml:"pool"`
+}
+
+// PoolConfig règle le pool de connexions et les délais d'un client Dragonfly
+type PoolConfig struct {
+	Size         int           `yaml:"size" env:"POOL_SIZE"` // Connexions par nœud, 0 : 10 par CPU
+	MinIdleConns int           `yaml:"min_idle_conns" env:"MIN_IDLE_CONNS"`
+	MaxRetries   int           `yaml:"max_retries" env:"MAX_RETRIES"` // -1 : aucune nouvelle tentative
+	DialTimeout  time.Duration `yaml:"dial_timeout" env:"DIAL_TIMEOUT"`
+	ReadTimeout  time.Duration `yaml:"read_timeout" env:"READ_TIMEOUT"`
+	WriteTimeout time.Duration `yaml:"write_timeout" env:"WRITE_TIMEOUT"`
+	Timeout      time.Duration `yaml:"timeout" env:"POOL_TIMEOUT"` // Attente d'une connexion libre
+}
+
+// defaultDragonfly retourne une connexion à un nœud unique aux délais par défaut
+func defaultDragonfly(addrs ...string) DragonflyConfig {
+	return DragonflyConfig{
+		Mode:  "standalone",
+		Addrs: addrs,
+		Pool: PoolConfig{
+			MaxRetries:   3,
+			DialTimeout:  5 * time.Second,
+			ReadTimeout:  3 * time.Second,
+			WriteTimeout: 3 * time.Second,
+			Timeout:      4 * time.Second,
+		},
+	}
+}
+
+// AdCacheConfig règle le cache des lectures de publicités (chemin de ServeAd)
+type AdCacheConfig struct {
+	Enabled   bool            `yaml:"enabled" env:"AD_CACHE_ENABLED"`
+	Size      int             `yaml:"size" env:"AD_CACHE_SIZE"` // Publicités gardées en mémoire
+	TTL       time.Duration   `yaml:"ttl" env:"AD_CACHE_TTL"`
+	Dragonfly DragonflyConfig `yaml:"dragonfly" env:"AD_CACHE_DRAGONFLY_"` // Second niveau, sans adresse = aucun
+}
+
+// TrackerConfig est la connexion résiliente à l'impression-tracker
+type TrackerConfig struct {
+	Addr            string          `yaml:"addr" env:"IMPRESSION_GRPC_ADDR"`
+	CallTimeout     time.Duration   `yaml:"call_timeout" env:"TRACKER_CALL_TIMEOUT"`
+	RetryAttempts   int             `yaml:"retry_attempts" env:"TRACKER_RETRY_ATTEMPTS"`
+	RetryBackoff    time.Duration   `yaml:"retry_backoff" env:"TRACKER_RETRY_BACKOFF"`
+	BreakerFailures int             `yaml:"breaker_failures" env:"TRACKER_BREAKER_FAILURES"`
+	BreakerCooldown time.Duration   `yaml:"breaker_cooldown" env:"TRACKER_BREAKER_COOLDOWN"`
+	TLS             ClientTLSConfig `yaml:"tls" env:"IMPRESSION_TLS_"`
+}
+
+// AuthConfig règle l'authentification par clé d'API
+type AuthConfig struct {
+	Enabled           bool          `yaml:"enabled" env:"AUTH_ENABLED"`
+	CacheTTL          time.Duration `yaml:"cache_ttl" env:"AUTH_CACHE_TTL"`
+	BootstrapAdminKey string        `yaml:"bootstrap_admin_key" env:"AUTH_BOOTSTRAP_ADMIN_KEY" secret:"true"`
+}
+
+// JWTConfig règle la vérification des jetons porteurs ; désactivée sans JWKS
+type JWTConfig struct {
+	JWKS        string        `yaml:"jwks" env:"JWT_JWKS"` // Fichier local ou URL
+	JWKSRefresh time.Duration `yaml:"jwks_refresh" env:"JWT_JWKS_REFRESH"`
+	Issuer      string        `yaml:"issuer" env:"JWT_ISSUER"`
+	Audience    string        `yaml:"audience" env:"JWT_AUDIENCE"`
+	TenantClaim string        `yaml:"tenant_claim" env:"JWT_TENANT_CLAIM"`
+	RoleClaim   string        `yaml:"role_claim" env:"JWT_ROLE_CLAIM"`
+	Leeway      time.Duration `yaml:"leeway" env:"JWT_LEEWAY"`
+}
+
+// Limit est une limite de débit : jetons par seconde, capacité du seau et quota journalier
+type Limit struct {
+	Rate  float64 `yaml:"rate" env:"RATE"`   // 0 : débit illimité
+	Burst int     `yaml:"burst" env:"BURST"` // 0 : Rate arrondi au supérieur
+	Daily int64   `yaml:"daily" env:"DAILY"` // 0 : aucun quota
+}
+
+// RateLimitConfig règle la limitation de débit de ServeAd
+type RateLimitConfig struct {
+	Enabled        bool            `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	Dragonfly      DragonflyConfig `yaml:"dragonfly" env:"RATE_LIMIT_DRAGONFLY_"` // Seaux partagés entre réplicas
+	Key            Limit           `yaml:"key" env:"RATE_LIMIT_KEY_"`             // Par clé d'API ou sujet de jeton
+	IP             Limit           `yaml:"ip" env:"RATE_LIMIT_IP_"`
+	TrustForwarded bool            `yaml:"trust_forwarded" env:"RATE_LIMIT_TRUST_FORWARDED"` // IP du client (limites et filtre IVT) lue dans X-Forwarded-For
+}
+
+// GatewayConfig règle la passerelle REST/JSON
+type GatewayConfig struct {
+	Enabled  bool            `yaml:"enabled" env:"GATEWAY_ENABLED"`
+	Addr     string          `yaml:"addr" env:"GATEWAY_ADDR"`
+	GRPCAddr string          `yaml:"grpc_addr" env:"GATEWAY_GRPC_ADDR"`
+	TLS      ClientTLSConfig `yaml:"tls" env:"GATEWAY_TLS_"` // Connexion au serveur gRPC
+}
+
+// HealthConfig règle la vérification périodique des dépendances
+type HealthConfig struct {
+	Interval time.Duration `yaml:"interval" env:"HEALTH_CHECK_INTERVAL"`
+	Timeout  time.Duration `yaml:"timeout" env:"HEALTH_CHECK_TIMEOUT"`
+}
+
+// ReconcileConfig règle la réconciliation périodique avec le tracker
+type ReconcileConfig struct {
+	Interval time.Duration `yaml:"interval" env:"RECONCILE_INTERVAL"` // 0 : désactivée
+	Repair   bool          `yaml:"repair" env:"RECONCILE_REPAIR"`
+}
+
+// MetricsConfig est l'adresse de l'endpoint Prometheus
+type MetricsConfig struct {
+	Addr string `yaml:"addr" env:"METRICS_ADDR"`
+}
+
+// TracingConfig règle l'export des traces OpenTelemetry
+type TracingConfig struct {
+	Exporter     string  `yaml:"exporter" env:"TRACING_EXPORTER"` // none, otlp, stdout ou file
+	OTLPEndpoint string  `yaml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT"`
+	File         string  `yaml:"file" env:"TRACING_FILE"`
+	SampleRatio  float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+// Default retourne la configuration par défaut, celle du déploiement Docker Compose
+func Default() *Config {
+	return &Config{
+		Service: ServiceConfig{Name: "adserver", Environment: "development"},
+		Storage: StorageConfig{Backend: "external"},
+		Log:     LogConfig{Level: "info", SampleEvery: 1},
+		GRPC:    GRPCConfig{Host: "0.0.0.0", Port: 50051},
+		TLS:     TLSConfig{ReloadInterval: 30 * time.Second},
+		MongoDB: MongoDBConfig{
+			URI:               "mongodb://mongodb:27017",
+			Database:          "adserver",
+			AdsCollection:     "ads",
+			ArchiveCollection: "ads_archive",
+			ArchiveRetention:  90 * 24 * time.Hour,
+			Migrate:           true,
+		},
+		Tracker: TrackerConfig{
+			Addr:            "impression-tracker:50052",
+			CallTimeout:     300 * time.Millisecond,
+			RetryAttempts:   3,
+			RetryBackoff:    50 * time.Millisecond,
+			BreakerFailures: 5,
+			BreakerCooldown: 30 * time.Second,
+		},
+		AdCache: AdCacheConfig{Size: 10000, TTL: 30 * time.Second, Dragonfly: defaultDragonfly()},
+		Auth:    AuthConfig{Enabled: true, CacheTTL: 30 * time.Second},
+		JWT:     JWTConfig{JWKSRefresh: 10 * time.Minute, TenantClaim: "tenant", RoleClaim: "role", Leeway: 30 * time.Second},
+		RateLimit: RateLimitConfig{
+			Dragonfly: defaultDragonfly("dragonfly:6379"),
+			Key:       Limit{Rate: 50, Burst: 100},
+			IP:        Limit{Rate: 20, Burst: 40},
+		},
+		Gateway: GatewayConfig{Enabled: true, Addr: ":8080", GRPCAddr: "localhost:50051"},
+		Health:  HealthConfig{Interval: 10 * time.Second, Timeout: 2 * time.Second},
+		Metrics: MetricsConfig{Addr: ":9090"},
+		Tracing: TracingConfig{Exporter: "none", OTLPEndpoint: "otel-collector:4317", File: "/app/traces.json", SampleRatio: 1},
+	}
+}
+
+// Validate vérifie toute la configuration et rapporte chaque valeur invalide
+func (c *Config) Validate() error {
+	var v validator
+
+	var level slog.Level
+	v.check(level.UnmarshalText([]byte(c.Log.Level)) == nil, "LOG_LEVEL must be debug, info, warn or error")
+	v.check(c.Log.SampleEvery >= 1, "LOG_SAMPLE_EVERY must be a positive integer")
+	v.check(c.Service.Name != "", "SERVICE_NAME must not be empty")
+	v.check(c.Storage.Backend == "external" || c.Storage.Memory(), "STORAGE must be external or memory")
+	v.check(!c.Storage.Memory() || !c.RateLimit.Enabled, "RATE_LIMIT_ENABLED requires STORAGE=external: buckets live in Dragonfly")
+	v.check(c.GRPC.Port > 0 && c.GRPC.Port <= 65535, "GRPC_PORT must be between 1 and 65535")
+
+	v.check(c.TLS.ReloadInterval >= 0, "TLS_RELOAD_INTERVAL must be a non-negative duration")
+	v.check(c.TLS.ClientCAFile == "" || c.TLS.CertFile != "", "TLS_CLIENT_CA_FILE requires TLS_CERT_FILE and TLS_KEY_FILE")
+	v.check((c.TLS.CertFile == "") == (c.TLS.KeyFile == ""), "TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	v.check((c.Tracker.TLS.CertFile == "") == (c.Tracker.TLS.KeyFile == ""), "IMPRESSION_TLS_CERT_FILE and IMPRESSION_TLS_KEY_FILE must be set together")
+	v.check((c.Gateway.TLS.CertFile == "") == (c.Gateway.TLS.KeyFile == ""), "GATEWAY_TLS_CERT_FILE and GATEWAY_TLS_KEY_FILE must be set together")
+
+	v.check(c.MongoDB.URI != "", "MONGODB_URI must not be empty")
+	v.check(c.MongoDB.Database != "", "MONGODB_DATABASE must not be empty")
+	v.check(c.MongoDB.AdsCollection != "", "MONGODB_COLLECTION must not be empty")
+	v.check(c.MongoDB.ArchiveCollection != "" && c.MongoDB.ArchiveCollection != c.MongoDB.AdsCollection, "MONGODB_ARCHIVE_COLLECTION must be set and differ from MONGODB_COLLECTION")
+	v.check(c.MongoDB.ArchiveRetention == 0 || (c.MongoDB.ArchiveRetention >= time.Second && c.MongoDB.ArchiveRetention/time.Second <= math.MaxInt32),
+		"MONGODB_ARCHIVE_RETENTION must be 0 (keep forever) or between 1s and about 68 years")
+	v.check(c.MongoDB.ImpressionFlushInterval >= 0, "MONGODB_IMPRESSION_FLUSH_INTERVAL must be a non-negative duration")
+
+	v.check(c.Tracker.Addr != "", "IMPRESSION_GRPC_ADDR must not be empty")
+	v.check(c.Tracker.CallTimeout > 0, "TRACKER_CALL_TIMEOUT must be a positive duration")
+	v.check(c.Tracker.RetryAttempts >= 1, "TRACKER_RETRY_ATTEMPTS must be a positive integer")
+	v.check(c.Tracker.RetryBackoff >= 0, "TRACKER_RETRY_BACKOFF must be a non-negative duration")
+	v.check(c.Tracker.BreakerFailures >= 1, "TRACKER_BREAKER_FAILURES must be a positive integer")
+	v.check(c.Tracker.BreakerCooldown > 0, "TRACKER_BREAKER_COOLDOWN must be a positive duration")
+
+	v.check(c.Auth.CacheTTL >= 0, "AUTH_CACHE_TTL must be a non-negative duration")
+	v.check(c.JWT.JWKSRefresh >= 0, "JWT_JWKS_REFRESH must be a non-negative duration")
+	v.check(c.JWT.Leeway >= 0, "JWT_LEEWAY must be a non-negative duration")
+	v.check(c.JWT.JWKS == "" || (c.JWT.TenantClaim != "" && c.JWT.RoleClaim != ""), "JWT_TENANT_CLAIM and JWT_ROLE_CLAIM must not be empty")
+
+	v.check(!c.AdCache.Enabled || c.AdCache.Size > 0, "AD_CACHE_SIZE must be positive")
+	v.check(!c.AdCache.Enabled || c.AdCache.TTL > 0, "AD_CACHE_TTL must be a positive duration")
+	if c.AdCache.Enabled && len(c.AdCache.Dragonfly.Addrs) > 0 {
+		v.dragonfly("AD_CACHE_DRAGONFLY_", c.AdCache.Dragonfly)
+	}
+
+	v.limit("RATE_LIMIT_KEY", c.RateLimit.Key)
+	v.limit("RATE_LIMIT_IP", c.RateLimit.IP)
+	if c.RateLimit.Enabled {
+		v.dragonfly("RATE_LIMIT_DRAGONFLY_", c.RateLimit.Dragonfly)
+	}
+
+	v.check(!c.Gateway.Enabled || (c.Gateway.Addr != "" && c.Gateway.GRPCAddr != ""), "GATEWAY_ADDR and GATEWAY_GRPC_ADDR must not be empty")
+	v.check(c.Health.Interval > 0, "HEALTH_CHECK_INTERVAL must be a positive duration")
+	v.check(c.Health.Timeout > 0, "HEALTH_CHECK_TIMEOUT must be a positive duration")
+	v.check(c.Reconcile.Interval >= 0, "RECONCILE_INTERVAL must be a non-negative duration")
+	v.check(c.Metrics.Addr != "", "METRICS_ADDR must not be empty")
+
+	switch c.Tracing.Exporter {
+	case "none", "otlp", "stdout", "file":
+	default:
+		v.check(false, "TRACING_EXPORTER must be none, otlp, stdout or file")
+	}
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "TRACING_SAMPLE_RATIO must be between 0 and 1")
+	return v.err()
+}
+
+// validator accumule les erreurs de validation
+type validator struct {
+	errs []error
+}
+
+func (v *validator) check(ok bool, msg string) {
+	if !ok {
+		v.errs = append(v.errs, errors.New(msg))
+	}
+}
+
+// limit vérifie la limite {name}_RATE, {name}_BURST et {name}_DAILY
+func (v *validator) limit(name string, l Limit) {
+	v.check(l.Rate >= 0, name+"_RATE must be a non-negative number")
+	v.check(l.Burst >= 0, name+"_BURST must be a non-negative integer")
+	v.check(l.Daily >= 0, name+"_DAILY must be a non-negative integer")
+}
+
+// dragonfly vérifie la connexion à Dragonfly de variables préfixées par prefix
+func (v *validator) dragonfly(prefix string, c DragonflyConfig) {
+	switch c.Mode {
+	case "standalone":
+		v.check(len(c.Addrs) == 1, prefix+"ADDR must be a single address in standalone mode")
+	case "sentinel":
+		v.check(len(c.Addrs) > 0, prefix+"ADDR must list the sentinel addresses")
+		v.check(c.MasterName != "", prefix+"MASTER_NAME is required in sentinel mode")
+	case "cluster":
+		v.check(len(c.Addrs) > 0, prefix+"ADDR must list the cluster seed addresses")
+		v.check(c.DB == 0, prefix+"DB must be 0 in cluster mode")
+	default:
+		v.check(false, prefix+"MODE must be standalone, sentinel or cluster")
+	}
+	v.check(c.DB >= 0, prefix+"DB must be a non-negative integer")
+	v.check((c.TLS.CertFile == "") == (c.TLS.KeyFile == ""), prefix+"TLS_CERT_FILE and "+prefix+"TLS_KEY_FILE must be set together")
+	v.check(c.Pool.Size >= 0, prefix+"POOL_SIZE must be a non-negative integer")
+	v.check(c.Pool.MinIdleConns >= 0, prefix+"MIN_IDLE_CONNS must be a non-negative integer")
+	v.check(c.Pool.MaxRetries >= -1, prefix+"MAX_RETRIES must be -1 or more")
+	v.check(c.Pool.DialTimeout > 0, prefix+"DIAL_TIMEOUT must be a positive duration")
+	v.check(c.Pool.ReadTimeout > 0, prefix+"READ_TIMEOUT must be a positive duration")
+	v.check(c.Pool.WriteTimeout > 0, prefix+"WRITE_TIMEOUT must be a positive duration")
+	v.check(c.Pool.Timeout > 0, prefix+"POOL_TIMEOUT must be a positive duration")
+}
+
+func (v *validator) err() error {
+	return errors.Join(v.errs...)
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role détermine les RPC accessibles à une clé d'API.
+type Role string
+
+const (
+	RoleAdmin      Role = "admin"      // Toutes les opérations, dont la gestion des clés
+	RoleAdvertiser Role = "advertiser" // Création et lecture des publicités
+	RoleReader     Role = "reader"     // Lecture et diffusion des publicités
+)
+
+// Valid indique si le rôle est connu
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleAdvertiser, RoleReader:
+		return true
+	}
+	return false
+}
+
+// ErrAPIKeyNotFound est retournée lorsqu'une clé est inconnue
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKey est une clé d'accès à l'AdService. Seule l'empreinte SHA-256 du secret
+// est conservée : le secret n'est communiqué qu'à la création ou à la rotation.
+type APIKey struct {
+	ID        uuid.UUID  `bson:"_id" json:"id"`
+	Name      string     `bson:"name" json:"name"`
+	Tenant    string     `bson:"tenant" json:"tenant"`
+	Role      Role       `bson:"role" json:"role"`
+	Prefix    string     `bson:"prefix" json:"prefix"` // Début du secret, pour identifier la clé sans la révéler
+	Hash      string     `bson:"hash" json:"-"`
+	CreatedAt time.Time  `bson:"created_at" json:"created_at"`
+	RevokedAt *time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+}
+
+// Revoked indique si la clé a été révoquée
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// Principal est l'identité authentifiée d'un appel.
+type Principal struct {
+	KeyID  uuid.UUID // Clé d'API présentée, nulle pour un jeton porteur
+	Name   string    // Nom de la clé, ou sujet (sub) du jeton
+	Tenant string
+	Role   Role
+}
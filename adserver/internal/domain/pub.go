@@ -1,13 +1,18 @@
 package domain
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrAdNotFound est retournée lorsqu'une publicité est absente, ou appartient à un autre locataire
+var ErrAdNotFound = errors.New("ad not found")
+
 type Pub struct {
 	ID          uuid.UUID `bson:"_id,omitempty" json:"id"`
+	Tenant      string    `bson:"tenant,omitempty" json:"tenant,omitempty"` // Locataire propriétaire
 	Title       string    `bson:"title" json:"title"`
 	Description *string   `bson:"description,omitempty" json:"description,omitempty"`
 	URL         string    `bson:"url" json:"url"`
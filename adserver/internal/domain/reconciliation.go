@@ -0,0 +1,24 @@
+package domain
+
+import "github.com/google/uuid"
+
+// ImpressionDrift compare, pour une publicité, le compteur d'impressions de l'adserver
+// à celui du microservice impression-tracker.
+type ImpressionDrift struct {
+	AdID     uuid.UUID
+	AdServer int64 // Compteur "impressions" de la publicité dans l'adserver
+	Tracker  int64 // Impressions comptées par le tracker (trafic valide et invalide)
+	Repaired bool  // Le compteur de l'adserver a été aligné sur celui du tracker
+}
+
+// Drift retourne l'écart entre les deux sources (positif si l'adserver compte plus).
+func (d ImpressionDrift) Drift() int64 {
+	return d.AdServer - d.Tracker
+}
+
+// ReconciliationReport résume une réconciliation des compteurs d'impressions.
+type ReconciliationReport struct {
+	Checked  int               // Nombre de publicités comparées
+	Drifts   []ImpressionDrift // Publicités dont les compteurs divergent
+	Repaired int               // Nombre de compteurs réparés
+}
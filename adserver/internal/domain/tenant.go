@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidTenant est retournée pour un identifiant de locataire inutilisable par l'impression-tracker.
+var ErrInvalidTenant = errors.New("tenant must not contain ':'")
+
+// tenantKey est la clé de contexte du locataire
+type tenantKey struct{}
+
+// WithTenant associe le locataire de l'appelant au contexte. Les requêtes des repositories
+// sont alors restreintes à ce locataire ; sans locataire (administration, tâches de fond),
+// elles portent sur tous les locataires.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext retourne le locataire du contexte, ou une chaîne vide
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// ValidateTenant vérifie que le locataire peut être transmis à l'impression-tracker,
+// qui en fait un segment de ses clés de cache
+func ValidateTenant(tenant string) error {
+	if strings.Contains(tenant, ":") {
+		return ErrInvalidTenant
+	}
+	return nil
+}
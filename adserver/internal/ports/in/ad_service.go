@@ -18,17 +18,17 @@ type AdService interface {
 	// Retourne l'annonce ou une erreur si non trouvée
 	GetAd(ctx context.Context, id string) (*domain.Pub, error)
 
-	// ServeAd diffuse la pub, incrémente le compteur, et renvoie :
-	// - l'URL à afficher
+	// ServeAd diffuse la pub, incrémente le compteur, et renvoie l'annonce :
+	// - son URL à afficher et son locataire
 	// - le nombre d'impressions APRÈS incrément
-	ServeAd(ctx context.Context, id uuid.UUID) (string, int64, error)
+	ServeAd(ctx context.Context, id uuid.UUID) (*domain.Pub, error)
 
 	// IncrementImpressions incrémente le compteur d'impressions d'une annonce
 	// Retourne le nouveau nombre total d'impressions
 	IncrementImpressions(ctx context.Context, id string) (int64, error)
 
-	// DeleteExpired supprime toutes les annonces expirées
-	// Retourne le nombre d'annonces supprimées
+	// DeleteExpired retire les annonces expirées, archivées avec leur compteur final
+	// Retourne le nombre d'annonces retirées
 	DeleteExpired(ctx context.Context) (int64, error)
 
 	// GetAdImpressions ne fait QUE lire le compteur, sans toucher au cache ou au track
@@ -0,0 +1,32 @@
+package in
+
+import (
+	"adserver/internal/domain"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyService définit la gestion des clés d'API et l'authentification des appels
+type APIKeyService interface {
+	// CreateKey crée une clé et retourne son secret, qui n'est plus consultable ensuite
+	CreateKey(ctx context.Context, name, tenant string, role domain.Role) (*domain.APIKey, string, error)
+
+	// RotateKey remplace une clé par une nouvelle clé de même nom, locataire et rôle,
+	// révoque l'ancienne et retourne le nouveau secret
+	RotateKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, string, error)
+
+	// RevokeKey révoque une clé ; les appels qui la présentent sont refusés
+	RevokeKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, error)
+
+	// ListKeys retourne toutes les clés, révoquées comprises
+	ListKeys(ctx context.Context) ([]*domain.APIKey, error)
+
+	// Authenticate retourne l'identité associée à un secret valide et non révoqué,
+	// ou domain.ErrAPIKeyNotFound
+	Authenticate(ctx context.Context, secret string) (*domain.Principal, error)
+
+	// EnsureKey enregistre un secret fourni par la configuration s'il n'existe pas encore
+	// (clé d'administration initiale)
+	EnsureKey(ctx context.Context, name, tenant string, role domain.Role, secret string) error
+}
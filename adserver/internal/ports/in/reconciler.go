@@ -0,0 +1,15 @@
+package in
+
+import (
+	"adserver/internal/domain"
+	"context"
+	"time"
+)
+
+// ImpressionReconciler définit la réconciliation des compteurs d'impressions
+// entre l'adserver et le microservice impression-tracker
+type ImpressionReconciler interface {
+	// Reconcile compare les compteurs, sur toute leur vie, des publicités actives pendant
+	// [from, to) et, si repair est vrai, corrige le compteur de l'adserver de l'écart constaté
+	Reconcile(ctx context.Context, from, to time.Time, repair bool) (*domain.ReconciliationReport, error)
+}
@@ -23,20 +23,26 @@ type AdRepository interface {
 	Exists(ctx context.Context, id uuid.UUID) (bool, error)
 
 	// IncrementImpressions incrémente atomiquement le compteur d'impressions
-	// et retourne la nouvelle valeur du compteur.
+	// et retourne la nouvelle valeur du compteur, ou domain.ErrAdNotFound.
 	IncrementImpressions(ctx context.Context, id uuid.UUID) (newCount int64, err error)
 
 	// ResetImpressions réinitialise le compteur en cache (pour batch sync)
-	// et retourne l'ancienne valeur avant reset.
+	// et retourne l'ancienne valeur avant reset, ou domain.ErrAdNotFound.
 	ResetImpressions(ctx context.Context, id uuid.UUID) (oldCount int64, err error)
 
-	// DeleteExpired supprime toutes les publicités expirées.
-	// Retourne le nombre de documents supprimés.
+	// AdjustImpressions ajoute atomiquement delta (positif ou négatif) au compteur
+	// (réparation après réconciliation), sans écraser les incréments concurrents,
+	// et retourne la nouvelle valeur, ou domain.ErrAdNotFound.
+	AdjustImpressions(ctx context.Context, id uuid.UUID, delta int64) (newCount int64, err error)
+
+	// DeleteExpired retire toutes les publicités expirées et les archive,
+	// compteur d'impressions final compris.
+	// Retourne le nombre de publicités retirées.
 	DeleteExpired(ctx context.Context) (deletedCount int64, err error)
 
 	// List permet de lister les publicités selon un filtre et pagination.
 	List(ctx context.Context, filter map[string]interface{}, offset, limit int64) ([]*domain.Pub, error)
 
-	// GetImpressions récupère le nombre d'impressions d'une publicité
+	// GetImpressions récupère le nombre d'impressions d'une publicité, ou domain.ErrAdNotFound.
 	GetImpressions(ctx context.Context, id uuid.UUID) (int64, error)
 }
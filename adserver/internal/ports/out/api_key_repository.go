@@ -0,0 +1,27 @@
+package out
+
+import (
+	"adserver/internal/domain"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyRepository stocke les clés d'API (empreintes uniquement).
+type APIKeyRepository interface {
+	// Create insère une nouvelle clé.
+	Create(ctx context.Context, key *domain.APIKey) error
+
+	// GetByID récupère une clé par son ID, ou domain.ErrAPIKeyNotFound.
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error)
+
+	// GetByHash récupère une clé par l'empreinte de son secret, ou domain.ErrAPIKeyNotFound.
+	GetByHash(ctx context.Context, hash string) (*domain.APIKey, error)
+
+	// List retourne toutes les clés, révoquées comprises.
+	List(ctx context.Context) ([]*domain.APIKey, error)
+
+	// Revoke marque la clé comme révoquée à la date donnée, ou retourne domain.ErrAPIKeyNotFound.
+	Revoke(ctx context.Context, id uuid.UUID, at time.Time) error
+}
@@ -0,0 +1,315 @@
+// Package contract regroupe les suites de tests que chaque adaptateur d'un port de sortie
+// doit passer, quel que soit son stockage : mémoire, MongoDB ou cache.
+package contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"adserver/internal/adapters/clock"
+	"adserver/internal/domain"
+	"adserver/internal/ports/out"
+
+	"github.com/google/uuid"
+)
+
+// start est l'heure de départ des horloges factices des suites, à la milliseconde comme MongoDB
+var start = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+var (
+	acme   = domain.WithTenant(context.Background(), "acme")
+	globex = domain.WithTenant(context.Background(), "globex")
+)
+
+// AdRepository vérifie un out.AdRepository ; newRepo retourne un repository vide dont
+// DeleteExpired compare les expirations à l'heure de clock
+func AdRepository(t *testing.T, newRepo func(t *testing.T, clock out.Clock) out.AdRepository) {
+	t.Run("create and read", func(t *testing.T) {
+		repo := newRepo(t, clock.NewFake(start))
+		description := "Soldes"
+		ad := newAd("acme", "Ma publicité", time.Hour)
+		ad.Description = &description
+		create(t, repo, ad)
+
+		got, err := repo.GetByID(acme, ad.ID)
+		if err != nil || got == nil {
+			t.Fatalf("GetByID() = %v, %v, want the ad", got, err)
+		}
+		if got.ID != ad.ID || got.Tenant != "acme" || got.Title != ad.Title || got.URL != ad.URL ||
+			!got.ExpiresAt.Equal(ad.ExpiresAt) || got.Description == nil || *got.Description != description {
+			t.Errorf("GetByID() = %+v, want %+v", got, ad)
+		}
+		// L'appelant ne modifie pas la publicité stockée
+		got.Title = "modifiée"
+		if again, err := repo.GetByID(acme, ad.ID); err != nil || again.Title != ad.Title {
+			t.Errorf("GetByID() after modifying a read copy = %+v, %v", again, err)
+		}
+
+		if _, err := repo.Create(acme, &ad); err == nil {
+			t.Error("Create() of an existing ID succeeded, want an error")
+		}
+	})
+
+	t.Run("unknown or other tenant's ad", func(t *testing.T) {
+		repo := newRepo(t, clock.NewFake(start))
+		ad := newAd("acme", "Ma publicité", time.Hour)
+		create(t, repo, ad)
+
+		for _, tt := range []struct {
+			name string
+			ctx  context.Context
+			id   uuid.UUID
+		}{
+			{name: "unknown ID", ctx: acme, id: uuid.New()},
+			{name: "other tenant", ctx: globex, id: ad.ID},
+		} {
+			if got, err := repo.GetByID(tt.ctx, tt.id); err != nil || got != nil {
+				t.Errorf("GetByID(%s) = %v, %v, want nil, nil", tt.name, got, err)
+			}
+			if exists, err := repo.Exists(tt.ctx, tt.id); err != nil || exists {
+				t.Errorf("Exists(%s) = %v, %v, want false", tt.name, exists, err)
+			}
+			if _, err := repo.IncrementImpressions(tt.ctx, tt.id); !errors.Is(err, domain.ErrAdNotFound) {
+				t.Errorf("IncrementImpressions(%s) error = %v, want %v", tt.name, err, domain.ErrAdNotFound)
+			}
+			if _, err := repo.ResetImpressions(tt.ctx, tt.id); !errors.Is(err, domain.ErrAdNotFound) {
+				t.Errorf("ResetImpressions(%s) error = %v, want %v", tt.name, err, domain.ErrAdNotFound)
+			}
+			if _, err := repo.AdjustImpressions(tt.ctx, tt.id, 1); !errors.Is(err, domain.ErrAdNotFound) {
+				t.Errorf("AdjustImpressions(%s) error = %v, want %v", tt.name, err, domain.ErrAdNotFound)
+			}
+			if _, err := repo.GetImpressions(tt.ctx, tt.id); !errors.Is(err, domain.ErrAdNotFound) {
+				t.Errorf("GetImpressions(%s) error = %v, want %v", tt.name, err, domain.ErrAdNotFound)
+			}
+		}
+
+		// Sans locataire, toutes les publicités sont visibles
+		if exists, err := repo.Exists(context.Background(), ad.ID); err != nil || !exists {
+			t.Errorf("Exists() without tenant = %v, %v, want true", exists, err)
+		}
+	})
+
+	t.Run("impression counter", func(t *testing.T) {
+		repo := newRepo(t, clock.NewFake(start))
+		ad := newAd("acme", "Ma publicité", time.Hour)
+		create(t, repo, ad)
+
+		for want := int64(1); want <= 3; want++ {
+			if got, err := repo.IncrementImpressions(acme, ad.ID); err != nil || got != want {
+				t.Fatalf("IncrementImpressions() = %d, %v, want %d", got, err, want)
+			}
+		}
+		if got, err := repo.AdjustImpressions(acme, ad.ID, -1); err != nil || got != 2 {
+			t.Errorf("AdjustImpressions(-1) = %d, %v, want 2", got, err)
+		}
+		if got, err := repo.GetImpressions(acme, ad.ID); err != nil || got != 2 {
+			t.Errorf("GetImpressions() = %d, %v, want 2", got, err)
+		}
+		if got, err := repo.GetByID(acme, ad.ID); err != nil || got == nil || got.Impressions != 2 {
+			t.Errorf("GetByID() = %+v, %v, want 2 impressions", got, err)
+		}
+		if got, err := repo.ResetImpressions(acme, ad.ID); err != nil || got != 2 {
+			t.Errorf("ResetImpressions() = %d, %v, want 2", got, err)
+		}
+		if got, err := repo.GetImpressions(acme, ad.ID); err != nil || got != 0 {
+			t.Errorf("GetImpressions() after reset = %d, %v, want 0", got, err)
+		}
+	})
+
+	t.Run("concurrent increments", func(t *testing.T) {
+		repo := newRepo(t, clock.NewFake(start))
+		ad := newAd("acme", "Ma publicité", time.Hour)
+		create(t, repo, ad)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					if _, err := repo.IncrementImpressions(acme, ad.ID); err != nil {
+						t.Errorf("IncrementImpressions() error: %v", err)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		if got, err := repo.GetImpressions(acme, ad.ID); err != nil || got != 200 {
+			t.Errorf("GetImpressions() = %d, %v, want 200", got, err)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		repo := newRepo(t, clock.NewFake(start))
+		var titles []string
+		for i := 0; i < 4; i++ {
+			ad := newAd("acme", fmt.Sprintf("pub %d", i), time.Hour)
+			create(t, repo, ad)
+			titles = append(titles, ad.Title)
+		}
+		create(t, repo, newAd("globex", "pub 0", time.Hour))
+
+		list := func(ctx context.Context, filter map[string]interface{}, offset, limit int64) []string {
+			t.Helper()
+			ads, err := repo.List(ctx, filter, offset, limit)
+			if err != nil {
+				t.Fatalf("List() error: %v", err)
+			}
+			var got []string
+			for _, ad := range ads {
+				got = append(got, ad.Title)
+			}
+			return got
+		}
+		for _, tt := range []struct {
+			name          string
+			ctx           context.Context
+			filter        map[string]interface{}
+			offset, limit int64
+			want          []string
+		}{
+			{name: "tenant's ads in insertion order", ctx: acme, filter: map[string]interface{}{}, want: titles},
+			{name: "page", ctx: acme, filter: map[string]interface{}{}, offset: 1, limit: 2, want: titles[1:3]},
+			{name: "filter", ctx: acme, filter: map[string]interface{}{"title": "pub 2"}, want: titles[2:3]},
+			{name: "other tenant", ctx: globex, filter: map[string]interface{}{}, want: []string{"pub 0"}},
+			{name: "tenant filter overridden by context", ctx: globex, filter: map[string]interface{}{"tenant": "acme"}, want: []string{"pub 0"}},
+		} {
+			if got := list(tt.ctx, tt.filter, tt.offset, tt.limit); !equal(got, tt.want...) {
+				t.Errorf("List(%s) = %v, want %v", tt.name, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("delete expired", func(t *testing.T) {
+		fake := clock.NewFake(start)
+		repo := newRepo(t, fake)
+		expiring, lasting := newAd("acme", "éphémère", time.Minute), newAd("acme", "durable", time.Hour)
+		other := newAd("globex", "éphémère", time.Minute)
+		for _, ad := range []domain.Pub{expiring, lasting, other} {
+			create(t, repo, ad)
+		}
+		if _, err := repo.IncrementImpressions(acme, expiring.ID); err != nil {
+			t.Fatalf("IncrementImpressions() error: %v", err)
+		}
+
+		if got, err := repo.DeleteExpired(acme); err != nil || got != 0 {
+			t.Fatalf("DeleteExpired() before expiry = %d, %v, want 0", got, err)
+		}
+		fake.Advance(2 * time.Minute)
+		if got, err := repo.DeleteExpired(acme); err != nil || got != 1 {
+			t.Fatalf("DeleteExpired() = %d, %v, want 1", got, err)
+		}
+		if got, err := repo.GetByID(acme, expiring.ID); err != nil || got != nil {
+			t.Errorf("GetByID(expired) = %v, %v, want nil, nil", got, err)
+		}
+		if exists, err := repo.Exists(acme, lasting.ID); err != nil || !exists {
+			t.Errorf("Exists(lasting) = %v, %v, want true", exists, err)
+		}
+		// Le nettoyage d'un locataire ne touche pas aux publicités des autres
+		if exists, err := repo.Exists(globex, other.ID); err != nil || !exists {
+			t.Errorf("Exists(other tenant's expired ad) = %v, %v, want true", exists, err)
+		}
+		if got, err := repo.DeleteExpired(context.Background()); err != nil || got != 1 {
+			t.Errorf("DeleteExpired() without tenant = %d, %v, want 1", got, err)
+		}
+	})
+}
+
+// newAd retourne une publicité du locataire tenant expirant après ttl
+func newAd(tenant, title string, ttl time.Duration) domain.Pub {
+	return domain.Pub{ID: uuid.New(), Tenant: tenant, Title: title, URL: "https://example.com", ExpiresAt: start.Add(ttl)}
+}
+
+func create(t *testing.T, repo out.AdRepository, ad domain.Pub) {
+	t.Helper()
+	if _, err := repo.Create(domain.WithTenant(context.Background(), ad.Tenant), &ad); err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+}
+
+// APIKeyRepository vérifie un out.APIKeyRepository ; newRepo retourne un repository vide
+func APIKeyRepository(t *testing.T, newRepo func(t *testing.T) out.APIKeyRepository) {
+	repo := newRepo(t)
+	newKey := func(tenant, hash string, created time.Duration) *domain.APIKey {
+		key := &domain.APIKey{ID: uuid.New(), Name: tenant + "-" + hash, Tenant: tenant, Role: domain.RoleReader, Prefix: hash[:2], Hash: hash, CreatedAt: start.Add(created)}
+		if err := repo.Create(context.Background(), key); err != nil {
+			t.Fatalf("Create(%s) error: %v", key.Name, err)
+		}
+		return key
+	}
+	first, second := newKey("acme", "hash-1", 0), newKey("acme", "hash-2", time.Minute)
+	other := newKey("globex", "hash-3", 2*time.Minute)
+
+	if err := repo.Create(context.Background(), &domain.APIKey{ID: uuid.New(), Tenant: "acme", Hash: "hash-1"}); err == nil {
+		t.Error("Create() with a registered hash succeeded, want an error")
+	}
+
+	if got, err := repo.GetByID(acme, first.ID); err != nil || got.Hash != first.Hash || got.Role != first.Role {
+		t.Errorf("GetByID() = %+v, %v, want %+v", got, err, first)
+	}
+	if _, err := repo.GetByID(globex, first.ID); !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		t.Errorf("GetByID(other tenant) error = %v, want %v", err, domain.ErrAPIKeyNotFound)
+	}
+	if _, err := repo.GetByID(acme, uuid.New()); !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		t.Errorf("GetByID(unknown) error = %v, want %v", err, domain.ErrAPIKeyNotFound)
+	}
+	// L'authentification cherche la clé avant de connaître son locataire
+	if got, err := repo.GetByHash(acme, other.Hash); err != nil || got.ID != other.ID {
+		t.Errorf("GetByHash() = %+v, %v, want %s", got, err, other.ID)
+	}
+	if _, err := repo.GetByHash(acme, "unknown"); !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		t.Errorf("GetByHash(unknown) error = %v, want %v", err, domain.ErrAPIKeyNotFound)
+	}
+
+	ids := func(ctx context.Context) []string {
+		t.Helper()
+		keys, err := repo.List(ctx)
+		if err != nil {
+			t.Fatalf("List() error: %v", err)
+		}
+		var got []string
+		for _, key := range keys {
+			got = append(got, key.ID.String())
+		}
+		return got
+	}
+	if got := ids(acme); !equal(got, first.ID.String(), second.ID.String()) {
+		t.Errorf("List(acme) = %v, want the two acme keys", got)
+	}
+	if got := ids(context.Background()); len(got) != 3 {
+		t.Errorf("List() without tenant = %v, want the three keys", got)
+	}
+
+	// La première révocation fait foi
+	if err := repo.Revoke(globex, first.ID, start); !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		t.Errorf("Revoke(other tenant) error = %v, want %v", err, domain.ErrAPIKeyNotFound)
+	}
+	if err := repo.Revoke(acme, first.ID, start.Add(time.Hour)); err != nil {
+		t.Fatalf("Revoke() error: %v", err)
+	}
+	if err := repo.Revoke(acme, first.ID, start.Add(2*time.Hour)); err != nil {
+		t.Errorf("Revoke() again error: %v", err)
+	}
+	if got, err := repo.GetByID(acme, first.ID); err != nil || got.RevokedAt == nil || !got.RevokedAt.Equal(start.Add(time.Hour)) {
+		t.Errorf("GetByID() after Revoke = %+v, %v, want revoked at %v", got, err, start.Add(time.Hour))
+	}
+	if err := repo.Revoke(acme, uuid.New(), start); !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		t.Errorf("Revoke(unknown) error = %v, want %v", err, domain.ErrAPIKeyNotFound)
+	}
+}
+
+func equal(got []string, want ...string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,15 @@
+package out
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// ImpressionTracker donne accès aux compteurs du microservice impression-tracker.
+type ImpressionTracker interface {
+	// GetTotals retourne, pour chaque publicité, le nombre d'impressions (valides et invalides)
+	// du locataire comptées par le tracker depuis sa création, impressions en attente de
+	// synchronisation comprises : la même période que le compteur de l'adserver.
+	GetTotals(ctx context.Context, tenant string, ids []uuid.UUID) (map[uuid.UUID]int64, error)
+}
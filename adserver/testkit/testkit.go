@@ -0,0 +1,125 @@
+// Package testkit démarre un Ad Server complet pour les tests de bout en bout des autres
+// modules : stockage en mémoire, horloge factice, authentification par clé d'API et serveur
+// gRPC sur une connexion en mémoire. Les paquets internal de l'adserver restent ainsi
+// inaccessibles hors du module.
+package testkit
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"adserver/generated/ad_service"
+	"adserver/internal/adapters/clock"
+	"adserver/internal/adapters/grpc/auth"
+	"adserver/internal/adapters/grpc/handler"
+	"adserver/internal/adapters/healthcheck"
+	"adserver/internal/adapters/memory"
+	"adserver/internal/adapters/tracker"
+	"adserver/internal/application"
+	"adserver/internal/config"
+	"adserver/internal/domain"
+	"adserver/internal/ports/in"
+	"shared/generated/impression_service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Options configure l'Ad Server démarré par Start.
+type Options struct {
+	Now time.Time // Heure initiale de l'horloge factice
+	// TrackerDialer ouvre les connexions vers l'impression-tracker, par exemple sur une
+	// connexion en mémoire ; l'adresse reçue est sans objet
+	TrackerDialer func(ctx context.Context, addr string) (net.Conn, error)
+	Logger        *slog.Logger // Logs de l'Ad Server, ignorés par défaut
+}
+
+// AdServer est un Ad Server en mémoire relié à un impression-tracker. Les délais, nouvelles
+// tentatives et disjoncteur des appels au tracker sont ceux de la configuration par défaut.
+type AdServer struct {
+	lis   *bufconn.Listener
+	clock *clock.Fake
+	keys  in.APIKeyService
+}
+
+// Start démarre un Ad Server arrêté à la fin du test
+func Start(t testing.TB, opts Options) *AdServer {
+	t.Helper()
+	if opts.Logger == nil {
+		opts.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	trackerConn, err := grpc.NewClient("passthrough:///impression-tracker",
+		grpc.WithContextDialer(opts.TrackerDialer),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("connect to impression-tracker: %v", err)
+	}
+	t.Cleanup(func() { trackerConn.Close() })
+
+	fake := clock.NewFake(opts.Now)
+	cfg := config.Default()
+	defaults := cfg.Tracker
+	impressionClient := tracker.NewResilientClient(impression_service.NewImpressionServiceClient(trackerConn), tracker.ResilienceConfig{
+		CallTimeout:      defaults.CallTimeout,
+		RetryAttempts:    defaults.RetryAttempts,
+		RetryBackoff:     defaults.RetryBackoff,
+		BreakerThreshold: defaults.BreakerFailures,
+		BreakerCooldown:  defaults.BreakerCooldown,
+	}, fake, opts.Logger)
+
+	repo := memory.NewAdRepository(fake, 0)
+	keys := application.NewAPIKeyService(memory.NewAPIKeyRepository(), fake, 0, opts.Logger)
+	adService := application.NewAdService(repo, fake, opts.Logger)
+	reconciler := application.NewReconciler(repo, tracker.NewImpressionTracker(impressionClient, opts.Logger), opts.Logger)
+
+	authenticator := auth.NewAuthenticator(keys, auth.AdServicePolicy(), opts.Logger, ad_service.AdService_ServiceDesc.ServiceName)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(authenticator.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(authenticator.StreamServerInterceptor()),
+	)
+
+	// Santé : grpc.health.v1 et DeepHealth suivent l'état du impression-tracker
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	checker := healthcheck.NewChecker(healthServer, cfg.Health.Interval, cfg.Health.Timeout, opts.Logger, ad_service.AdService_ServiceDesc.ServiceName)
+	checker.Register("impression-tracker", tracker.HealthCheck(trackerConn, impressionClient))
+	checker.Start()
+	t.Cleanup(checker.Stop)
+
+	ad_service.RegisterAdServiceServer(server, handler.NewAdHandler(adService, reconciler, impressionClient, checker, keys, false, fake, opts.Logger))
+	lis := bufconn.Listen(1 << 20)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return &AdServer{lis: lis, clock: fake, keys: keys}
+}
+
+// Dial ouvre une connexion à l'Ad Server, à passer à grpc.WithContextDialer
+func (s *AdServer) Dial(ctx context.Context, _ string) (net.Conn, error) {
+	return s.lis.DialContext(ctx)
+}
+
+// Advance avance l'horloge de l'Ad Server
+func (s *AdServer) Advance(d time.Duration) {
+	s.clock.Advance(d)
+}
+
+// As retourne un contexte authentifié par une nouvelle clé d'API du locataire, avec le rôle
+// role ("admin", "advertiser" ou "reader")
+func (s *AdServer) As(t testing.TB, tenant, role string) context.Context {
+	t.Helper()
+	_, secret, err := s.keys.CreateKey(context.Background(), tenant+"-"+role, tenant, domain.Role(role))
+	if err != nil {
+		t.Fatalf("CreateKey() error: %v", err)
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), auth.APIKeyHeader, secret)
+}
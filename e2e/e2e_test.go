@@ -0,0 +1,190 @@
+package e2e
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"adserver/generated/ad_service"
+	adserver "adserver/testkit"
+	tracker "impression-tracker/testkit"
+	"shared/generated/impression_service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// syncInterval est la période de synchronisation du tracker
+const syncInterval = time.Minute
+
+// testStart est l'heure initiale des horloges factices des deux services
+var testStart = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// stack relie un Ad Server et un impression-tracker en mémoire partageant la même heure
+type stack struct {
+	adserver *adserver.AdServer
+	tracker  *tracker.Tracker
+	ads      ad_service.AdServiceClient
+	counts   impression_service.ImpressionServiceClient
+}
+
+func newStack(t *testing.T) *stack {
+	t.Helper()
+	tr := tracker.Start(t, tracker.Options{Now: testStart, SyncInterval: syncInterval})
+	as := adserver.Start(t, adserver.Options{Now: testStart, TrackerDialer: tr.Dial})
+	return &stack{
+		adserver: as,
+		tracker:  tr,
+		ads:      ad_service.NewAdServiceClient(dial(t, as.Dial)),
+		counts:   impression_service.NewImpressionServiceClient(dial(t, tr.Dial)),
+	}
+}
+
+// dial ouvre une connexion gRPC fermée à la fin du test
+func dial(t *testing.T, dialer func(context.Context, string) (net.Conn, error)) *grpc.ClientConn {
+	t.Helper()
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(dialer),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// advance avance l'heure des deux services
+func (s *stack) advance(d time.Duration) {
+	s.adserver.Advance(d)
+	s.tracker.Advance(d)
+}
+
+// eventually attend que cond soit vraie : la synchronisation du tracker est asynchrone
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServedImpressionsAreSyncedAndReconciled(t *testing.T) {
+	s := newStack(t)
+	acme := s.adserver.As(t, "acme", "advertiser")
+	acmeAdmin := s.adserver.As(t, "acme", "admin")
+	ctx := context.Background()
+
+	// Création puis diffusion
+	ad, err := s.ads.CreateAd(acme, &ad_service.CreateAdRequest{Title: "acme ad", ExpiresAt: timestamppb.New(testStart.Add(time.Hour))})
+	if err != nil {
+		t.Fatalf("CreateAd() error: %v", err)
+	}
+	for i := int64(1); i <= 3; i++ {
+		served, err := s.ads.ServeAd(acme, &ad_service.ServeAdRequest{Id: ad.Id})
+		if err != nil || served.GetImpressions() != i {
+			t.Fatalf("ServeAd() #%d = %v, %v, want %d impressions", i, served, err, i)
+		}
+	}
+
+	// Le tracker a reçu les impressions sous le locataire de la publicité, en attente de synchronisation
+	pending := func() int64 {
+		resp, err := s.counts.GetImpressionCount(ctx, &impression_service.GetImpressionCountRequest{AdId: ad.Id, Tenant: "acme"})
+		if err != nil {
+			t.Fatalf("GetImpressionCount() error: %v", err)
+		}
+		return resp.GetCount()
+	}
+	if got := pending(); got != 3 {
+		t.Fatalf("tracker pending count = %d, want 3", got)
+	}
+	if resp, err := s.counts.GetImpressionCount(ctx, &impression_service.GetImpressionCountRequest{AdId: ad.Id, Tenant: "globex"}); err != nil || resp.GetCount() != 0 {
+		t.Errorf("tracker count for globex = %v, %v, want 0", resp, err)
+	}
+
+	// Avant la période de synchronisation, rien n'est persisté
+	s.advance(syncInterval - time.Second)
+	if got := pending(); got != 3 {
+		t.Fatalf("tracker pending count before the sync period = %d, want 3", got)
+	}
+
+	// La période écoulée, le cache est vidé dans le stockage persistant
+	s.advance(time.Second)
+	eventually(t, "the tracker sync", func() bool { return pending() == 0 })
+	report, err := s.counts.GetTrafficReport(ctx, &impression_service.GetTrafficReportRequest{AdId: ad.Id, Tenant: "acme"})
+	if err != nil || report.GetValid() != 3 {
+		t.Fatalf("GetTrafficReport() = %v, %v, want 3 valid impressions", report, err)
+	}
+
+	// Comptage : les compteurs de l'adserver et du tracker concordent
+	s.advance(time.Second)
+	count, err := s.ads.GetImpressionCount(acme, &ad_service.GetImpressionCountRequest{AdId: ad.Id})
+	if err != nil || count.GetImpressions() != 3 {
+		t.Fatalf("GetImpressionCount() = %v, %v, want 3", count, err)
+	}
+	reconciled, err := s.ads.ReconcileImpressions(acmeAdmin, &ad_service.ReconcileImpressionsRequest{})
+	if err != nil {
+		t.Fatalf("ReconcileImpressions() error: %v", err)
+	}
+	if reconciled.GetChecked() != 1 || len(reconciled.GetDrifts()) != 0 {
+		t.Errorf("ReconcileImpressions() = %v, want 1 ad checked without drift", reconciled)
+	}
+}
+
+func TestExpiredAdsAreNoLongerServed(t *testing.T) {
+	s := newStack(t)
+	acme := s.adserver.As(t, "acme", "advertiser")
+	acmeAdmin := s.adserver.As(t, "acme", "admin")
+
+	ad, err := s.ads.CreateAd(acme, &ad_service.CreateAdRequest{Title: "acme ad", ExpiresAt: timestamppb.New(testStart.Add(time.Hour))})
+	if err != nil {
+		t.Fatalf("CreateAd() error: %v", err)
+	}
+	if _, err := s.ads.ServeAd(acme, &ad_service.ServeAdRequest{Id: ad.Id}); err != nil {
+		t.Fatalf("ServeAd() before expiry error: %v", err)
+	}
+
+	s.advance(time.Hour + time.Second)
+	if _, err := s.ads.ServeAd(acme, &ad_service.ServeAdRequest{Id: ad.Id}); status.Code(err) == codes.OK {
+		t.Fatal("ServeAd() after expiry succeeded")
+	}
+	deleted, err := s.ads.DeleteExpired(acmeAdmin, &ad_service.DeleteExpiredRequest{})
+	if err != nil || deleted.GetDeletedCount() != 1 {
+		t.Fatalf("DeleteExpired() = %v, %v, want 1 deleted", deleted, err)
+	}
+	if _, err := s.ads.GetAd(acme, &ad_service.GetAdRequest{Id: ad.Id}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetAd() after archiving error = %v, want NotFound", err)
+	}
+
+	// L'impression servie avant l'expiration est synchronisée par le tracker
+	ctx := context.Background()
+	eventually(t, "the tracker sync", func() bool {
+		resp, err := s.counts.GetImpressionCount(ctx, &impression_service.GetImpressionCountRequest{AdId: ad.Id, Tenant: "acme"})
+		return err == nil && resp.GetCount() == 0
+	})
+	report, err := s.counts.GetTrafficReport(ctx, &impression_service.GetTrafficReportRequest{AdId: ad.Id, Tenant: "acme"})
+	if err != nil || report.GetValid() != 1 {
+		t.Errorf("GetTrafficReport() = %v, %v, want 1 valid impression", report, err)
+	}
+}
+
+func TestDeepHealthReportsTheTracker(t *testing.T) {
+	s := newStack(t)
+	reader := s.adserver.As(t, "acme", "reader")
+
+	resp, err := s.ads.DeepHealth(reader, &ad_service.DeepHealthRequest{})
+	if err != nil {
+		t.Fatalf("DeepHealth() error: %v", err)
+	}
+	deps := resp.GetDependencies()
+	if !resp.GetServing() || len(deps) != 1 || deps[0].GetName() != "impression-tracker" || !deps[0].GetHealthy() {
+		t.Errorf("DeepHealth() = %v, want serving with a healthy impression-tracker", resp)
+	}
+}
@@ -0,0 +1,115 @@
+// Commande export : télécharge les impressions brutes d'une période depuis le tracker.
+//
+// Exemple :
+//
+//	go run ./cmd/export -from 2025-05-01T00:00:00Z -to 2025-05-02T00:00:00Z -format csv -out impressions.csv
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"impression-tracker/internal/adapters/tlsconfig"
+	"shared/generated/impression_service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var formats = map[string]impression_service.ExportFormat{
+	"ndjson":  impression_service.ExportFormat_EXPORT_FORMAT_NDJSON,
+	"csv":     impression_service.ExportFormat_EXPORT_FORMAT_CSV,
+	"parquet": impression_service.ExportFormat_EXPORT_FORMAT_PARQUET,
+}
+
+// parseTime convertit une date RFC3339 en Timestamp protobuf (nil si vide)
+func parseTime(name, value string) *timestamppb.Timestamp {
+	if value == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		log.Fatalf("Invalid -%s value %q: %v", name, value, err)
+	}
+	return timestamppb.New(t)
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:50052", "adresse gRPC du impression-tracker")
+	from := flag.String("from", "", "début de la période (RFC3339, inclus)")
+	to := flag.String("to", "", "fin de la période (RFC3339, exclue), maintenant par défaut")
+	format := flag.String("format", "ndjson", "format d'export : ndjson, csv ou parquet")
+	outPath := flag.String("out", "", "fichier de sortie, sortie standard par défaut")
+	tenant := flag.String("tenant", "", "locataire exporté, locataire par défaut si vide")
+	var tlsCfg tlsconfig.Config
+	flag.StringVar(&tlsCfg.CAFile, "tls-ca", "", "autorité du serveur (active TLS)")
+	flag.StringVar(&tlsCfg.CertFile, "tls-cert", "", "certificat client pour le mTLS")
+	flag.StringVar(&tlsCfg.KeyFile, "tls-key", "", "clé du certificat client")
+	flag.StringVar(&tlsCfg.ServerName, "tls-server-name", "", "nom attendu dans le certificat du serveur")
+	flag.Parse()
+
+	exportFormat, ok := formats[*format]
+	if !ok {
+		log.Fatalf("Unsupported format %q (expected ndjson, csv or parquet)", *format)
+	}
+
+	req := &impression_service.ExportImpressionsRequest{
+		From:   parseTime("from", *from),
+		To:     parseTime("to", *to),
+		Format: exportFormat,
+		Tenant: *tenant,
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", *outPath, err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	creds, err := tlsconfig.ClientCredentials(tlsCfg, slog.Default())
+	if err != nil {
+		log.Fatalf("Failed to load TLS files: %v", err)
+	}
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		log.Fatalf("Failed to connect to impression-tracker %s: %v", *addr, err)
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	stream, err := impression_service.NewImpressionServiceClient(conn).ExportImpressions(ctx, req)
+	if err != nil {
+		log.Fatalf("Failed to start export: %v", err)
+	}
+
+	var written int64
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalf("Export failed after %d bytes: %v", written, err)
+		}
+		n, err := out.Write(chunk.GetData())
+		if err != nil {
+			log.Fatalf("Failed to write export: %v", err)
+		}
+		written += int64(n)
+	}
+
+	log.Printf("Export completed: %d bytes", written)
+}
@@ -2,97 +2,419 @@ package main
 
 import (
 	"context"
-	"impression-tracker/generated/impression_service"
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"impression-tracker/internal/adapters/clock"
 	"impression-tracker/internal/adapters/dragonfly"
+	"impression-tracker/internal/adapters/gateway"
+	"impression-tracker/internal/adapters/grpc/auth"
 	"impression-tracker/internal/adapters/grpc/handler"
+	"impression-tracker/internal/adapters/healthcheck"
+	"impression-tracker/internal/adapters/ivt"
+	"impression-tracker/internal/adapters/logging"
+	"impression-tracker/internal/adapters/memory"
+	"impression-tracker/internal/adapters/metrics"
 	"impression-tracker/internal/adapters/mongodb"
+	"impression-tracker/internal/adapters/ratelimit"
+	"impression-tracker/internal/adapters/tlsconfig"
+	"impression-tracker/internal/adapters/tracing"
 	"impression-tracker/internal/application"
+	"impression-tracker/internal/config"
+	"impression-tracker/internal/domain"
+	"impression-tracker/internal/ports/out"
 	"log"
+	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"runtime"
+	"shared/dragonflyclient"
+	"shared/generated/impression_service"
+	"shared/mongomigrate"
 	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
-func loadEnvFile() {
-	if err := godotenv.Load("/app/.env"); err != nil {
-		log.Printf("Warning: could not load .env file: %v", err)
-	} else {
-		log.Println("Loaded environment variables from .env")
+// storage regroupe les adaptateurs des ports de stockage : MongoDB et Dragonfly, ou la
+// mémoire du processus (STORAGE=memory), sans persistance ni partage entre réplicas
+type storage struct {
+	cache   out.CacheRepository   // Compteurs d'impressions
+	store   out.MetricsRepository // Deltas d'impressions
+	counter func(prefix string) out.CacheRepository
+	deltas  func(collection, eventType string) out.MetricsRepository
+	events  func(collection string) out.EventStore
+	lease   out.LeaseRepository
+	dedup   out.EventDeduplicator // Événements d'engagement déjà comptés
+	client  redis.UniversalClient // Connexion de la limitation de débit, nil en mémoire
+	checks  map[string]healthcheck.Check
+	closers []func() error
+}
+
+// newExternalStorage se connecte à Dragonfly et MongoDB
+func newExternalStorage(cfg *config.Config, logger *slog.Logger) *storage {
+	dragonflyOpts := dragonflyclient.Options{
+		Mode:             cfg.Dragonfly.Mode,
+		Addrs:            cfg.Dragonfly.Addrs,
+		MasterName:       cfg.Dragonfly.MasterName,
+		Username:         cfg.Dragonfly.Username,
+		Password:         cfg.Dragonfly.Password,
+		SentinelUsername: cfg.Dragonfly.SentinelUsername,
+		SentinelPassword: cfg.Dragonfly.SentinelPassword,
+		DB:               cfg.Dragonfly.DB,
+		PoolSize:         cfg.Dragonfly.Pool.Size,
+		MinIdleConns:     cfg.Dragonfly.Pool.MinIdleConns,
+		MaxRetries:       cfg.Dragonfly.Pool.MaxRetries,
+		DialTimeout:      cfg.Dragonfly.Pool.DialTimeout,
+		ReadTimeout:      cfg.Dragonfly.Pool.ReadTimeout,
+		WriteTimeout:     cfg.Dragonfly.Pool.WriteTimeout,
+		PoolTimeout:      cfg.Dragonfly.Pool.Timeout,
+	}
+	s := &storage{}
+
+	// TLS optionnel vers Dragonfly, racines du système si aucune autorité n'est fournie
+	if dragonflyTLS := tlsconfig.Config(cfg.Dragonfly.TLS); cfg.Dragonfly.TLSEnabled || dragonflyTLS.Enabled() {
+		dragonflyReloader, err := tlsconfig.NewReloader(dragonflyTLS, cfg.TLS.ReloadInterval, logger)
+		if err != nil {
+			fatal("Failed to load Dragonfly TLS certificates", "error", err)
+		}
+		s.closers = append(s.closers, func() error { dragonflyReloader.Stop(); return nil })
+		dragonflyOpts.TLS = dragonflyReloader.ClientConfig()
+	}
+	logger.Info("Connecting to Dragonfly", "mode", cfg.Dragonfly.Mode, "addresses", cfg.Dragonfly.Addrs, "db", cfg.Dragonfly.DB, "tls", dragonflyOpts.TLS != nil)
+	cacheRepo, err := dragonfly.NewDragonflyRepository(dragonflyOpts)
+	if err != nil {
+		fatal("Failed to connect to Dragonfly", "error", err)
+	}
+	s.closers = append(s.closers, cacheRepo.Close)
+
+	logger.Info("Connecting to MongoDB", "database", cfg.MongoDB.Database)
+	storeRepo, err := mongodb.NewMongoDBRepository(cfg.MongoDB.URI, cfg.MongoDB.Database, cfg.MongoDB.Collection, clock.System)
+	if err != nil {
+		fatal("Failed to connect to MongoDB", "error", err)
+	}
+	s.closers = append(s.closers, storeRepo.Close)
+
+	s.cache, s.store = cacheRepo, storeRepo
+	s.counter = func(prefix string) out.CacheRepository { return cacheRepo.WithPrefix(prefix) }
+	s.deltas = func(collection, eventType string) out.MetricsRepository {
+		repo := storeRepo.WithCollection(collection)
+		if eventType != "" {
+			return repo.WithEventType(eventType)
+		}
+		return repo
+	}
+	s.events = func(collection string) out.EventStore {
+		return mongodb.NewEventRepository(storeRepo, collection)
+	}
+	s.lease = dragonfly.NewLeaseRepository(cacheRepo)
+	s.dedup = dragonfly.NewEventDeduplicator(cacheRepo)
+	s.client = cacheRepo.Client()
+	s.checks = map[string]healthcheck.Check{"mongodb": storeRepo.Ping, "dragonfly": cacheRepo.Ping}
+
+	// Migrations du schéma (index) ; une migration en échec arrête le service plutôt que de le
+	// laisser démarrer sur un schéma incomplet
+	if cfg.MongoDB.Migrate {
+		migrateCtx, migrateCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if _, err := mongomigrate.Migrate(migrateCtx, storeRepo.Database(), mongodb.Migrations(cfg.DeltaCollections(), cfg.EventLog.Collection), logger); err != nil {
+			fatal("Failed to migrate MongoDB schema", "error", err)
+		}
+		migrateCancel()
 	}
+	return s
 }
 
-func getEnvOrDefault(key, fallback string) string {
-	if val := os.Getenv(key); val != "" {
-		log.Printf("Config: %s=%s", key, val)
-		return val
+// newMemoryStorage crée des adaptateurs en mémoire, un par compteur et par collection
+func newMemoryStorage() *storage {
+	counters := make(map[string]*memory.CacheRepository)
+	deltas := make(map[string]*memory.MetricsRepository)
+	events := make(map[string]*memory.EventStore)
+	return &storage{
+		cache: memory.NewCacheRepository(),
+		store: memory.NewMetricsRepository(clock.System),
+		counter: func(prefix string) out.CacheRepository {
+			if counters[prefix] == nil {
+				counters[prefix] = memory.NewCacheRepository()
+			}
+			return counters[prefix]
+		},
+		deltas: func(collection, eventType string) out.MetricsRepository {
+			key := collection + "/" + eventType
+			if deltas[key] == nil {
+				deltas[key] = memory.NewMetricsRepository(clock.System)
+			}
+			return deltas[key]
+		},
+		events: func(collection string) out.EventStore {
+			if events[collection] == nil {
+				events[collection] = memory.NewEventStore()
+			}
+			return events[collection]
+		},
+		lease: memory.NewLeaseRepository(clock.System),
+		dedup: memory.NewEventDeduplicator(clock.System),
+	}
+}
+
+// close ferme les connexions dans l'ordre inverse de leur ouverture
+func (s *storage) close(logger *slog.Logger) {
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		if err := s.closers[i](); err != nil {
+			logger.Error("Error closing storage", "error", err)
+		}
 	}
-	log.Printf("Config: %s=%s (default)", key, fallback)
-	return fallback
+}
+
+// fatal journalise une erreur de démarrage et arrête le processus
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
 
 func main() {
 	start := time.Now()
-	log.Println("Starting Impression Tracker Service...")
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "fichier de configuration YAML, surchargé par les variables d'environnement")
+	envFile := flag.String("env-file", ".env", "fichier .env chargé dans l'environnement s'il existe")
+	printConfig := flag.Bool("print-config", false, "afficher la configuration effective, secrets masqués, puis quitter")
+	flag.Parse()
 
-	log.Printf("Go version: %s | OS: %s | Arch: %s | CPUs: %d",
-		runtime.Version(), runtime.GOOS, runtime.GOARCH, runtime.NumCPU())
+	// Configuration : valeurs par défaut, fichier YAML puis variables d'environnement.
+	// Le .env est journalisé une fois le logger configuré, LOG_LEVEL pouvant en provenir.
+	envErr := godotenv.Load(*envFile)
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		fatal("Failed to load configuration", "error", err)
+	}
+	if *printConfig {
+		if err := config.Print(os.Stdout, cfg); err != nil {
+			fatal("Failed to print configuration", "error", err)
+		}
+		if err := cfg.Validate(); err != nil {
+			fatal("Invalid configuration", "error", err)
+		}
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		fatal("Invalid configuration", "error", err)
+	}
 
-	loadEnvFile()
+	// Logger JSON structuré, également utilisé par le package log standard
+	logger, err := logging.New(os.Stdout, cfg.Log.Level)
+	if err != nil {
+		log.Fatalf("Invalid LOG_LEVEL: %v", err)
+	}
+	slog.SetDefault(logger)
 
-	// Environment variables
-	grpcAddr := getEnvOrDefault("GRPC_ADDR", ":50052")
-	mongoURI := getEnvOrDefault("MONGO_URI", "mongodb://mongodb:27017")
-	mongoDB := getEnvOrDefault("MONGO_DB", "impression_tracker")
-	mongoColl := getEnvOrDefault("MONGO_COLLECTION", "impressions")
-	dragonflyAddr := getEnvOrDefault("DRAGONFLY_ADDR", "localhost:6379")
-	syncIntervalStr := getEnvOrDefault("SYNC_INTERVAL", "1m")
+	logger.Info("Starting Impression Tracker Service")
+	logger.Info("System info", "go_version", runtime.Version(), "os", runtime.GOOS, "arch", runtime.GOARCH, "cpus", runtime.NumCPU())
+	if envErr != nil {
+		logger.Warn("Could not load .env file", "path", *envFile, "error", envErr)
+	} else {
+		logger.Info("Loaded environment variables from .env", "path", *envFile)
+	}
+	logger.Info("Configuration loaded", "file", *configFile)
 
-	syncInterval, err := time.ParseDuration(syncIntervalStr)
+	// Traces OpenTelemetry
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName:  cfg.Service.Name,
+		Environment:  cfg.Service.Environment,
+		Exporter:     cfg.Tracing.Exporter,
+		OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+		FilePath:     cfg.Tracing.File,
+		SampleRatio:  cfg.Tracing.SampleRatio,
+	})
 	if err != nil {
-		log.Printf("Invalid SYNC_INTERVAL format: %v. Using 1m default.", err)
-		syncInterval = time.Minute
+		fatal("Failed to set up tracing", "error", err)
 	}
+	defer func() {
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer flushCancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			logger.Error("Error flushing traces", "error", err)
+		}
+	}()
 
-	// Dragonfly cache repo
-	log.Printf("Connecting to Dragonfly: %s", dragonflyAddr)
-	cacheRepo, err := dragonfly.NewDragonflyRepository(dragonflyAddr)
-	if err != nil {
-		log.Fatalf("Failed to connect to Dragonfly: %v", err)
+	// Stockage : MongoDB et Dragonfly, ou mémoire du processus pour le développement
+	var store *storage
+	if cfg.Storage.Memory() {
+		logger.Warn("In-memory storage: counters are lost on shutdown and not shared between replicas")
+		store = newMemoryStorage()
+	} else {
+		store = newExternalStorage(cfg, logger)
 	}
-	defer cacheRepo.Close()
+	defer store.close(logger)
 
-	// MongoDB repository
-	log.Printf("Connecting to MongoDB: %s", mongoURI)
-	storeRepo, err := mongodb.NewMongoDBRepository(mongoURI, mongoDB, mongoColl)
-	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
+	// Compteurs des événements d'engagement (rendu, visibilité, survol, fermeture),
+	// chacun compté une fois par impression
+	opts := []application.Option{
+		application.WithMetrics(metrics.NewServiceMetrics()),
+		application.WithEventDeduplication(store.dedup, cfg.Events.DedupTTL),
+	}
+	for _, eventType := range domain.EngagementEvents {
+		opts = append(opts, application.WithEngagementCounter(eventType,
+			store.counter("event_"+string(eventType)),
+			store.deltas(cfg.MongoDB.EngagementCollection, string(eventType))))
+	}
+
+	// Journal des impressions brutes (optionnel)
+	if cfg.EventLog.Enabled {
+		logger.Info("Raw impression event log enabled", "collection", cfg.EventLog.Collection)
+		opts = append(opts, application.WithEventStore(store.events(cfg.EventLog.Collection)))
+	}
+
+	// Filtrage du trafic invalide (optionnel)
+	if cfg.IVT.Enabled {
+		filter, err := ivt.NewFilter(ivt.Config{
+			BotUserAgentsFile: cfg.IVT.BotUAFile,
+			IPBlocklistFile:   cfg.IVT.IPBlocklistFile,
+			DataCenterFile:    cfg.IVT.DataCenterFile,
+			RateLimit:         cfg.IVT.RateLimit,
+			RateWindow:        cfg.IVT.RateWindow,
+		})
+		if err != nil {
+			fatal("Failed to load IVT filter", "error", err)
+		}
+		logger.Info("Invalid traffic filtering enabled", "collection", cfg.IVT.Collection)
+		opts = append(opts, application.WithTrafficFilter(filter, store.counter("ivt"), store.deltas(cfg.IVT.Collection, "")))
+	}
+
+	// Élection de leader : une seule instance synchronise les compteurs partagés
+	if cfg.Leader.Enabled {
+		hostname, _ := os.Hostname()
+		holder := fmt.Sprintf("%s-%d", hostname, time.Now().UnixNano())
+		logger.Info("Leader election enabled", "holder", holder, "lease_ttl", cfg.Leader.LeaseTTL)
+		elector := application.NewLeaderElector(store.lease, clock.System, "sync", holder, cfg.Leader.LeaseTTL, logger)
+		opts = append(opts, application.WithLeaderElection(elector))
 	}
-	defer storeRepo.Close()
 
 	// Application service
-	service := application.NewService(cacheRepo, storeRepo, syncInterval)
+	service := application.NewService(store.cache, store.store, clock.System, cfg.Sync.Interval, logger, opts...)
 	service.Start()
 	defer service.Stop()
 
 	// gRPC server setup
-	lis, err := net.Listen("tcp", grpcAddr)
+	lis, err := net.Listen("tcp", cfg.GRPC.Addr)
 	if err != nil {
-		log.Fatalf("Failed to listen on %s: %v", grpcAddr, err)
+		fatal("Failed to listen", "address", cfg.GRPC.Addr, "error", err)
+	}
+	// Seules les requêtes du chemin critique sont échantillonnées
+	logSampler := logging.NewSampler(cfg.Log.SampleEvery,
+		impression_service.ImpressionService_TrackImpression_FullMethodName,
+		impression_service.ImpressionService_TrackEvent_FullMethodName,
+	)
+	// Identité des appelants : le locataire de chaque requête est vérifié contre leur certificat
+	if cfg.Auth.TrustUnauthenticated {
+		logger.Warn("Callers without a client certificate are trusted for every tenant")
+	}
+	authenticator := auth.NewAuthenticator(auth.Config{
+		ServiceClients:       cfg.Auth.ServiceClients,
+		GatewayClient:        cfg.Auth.GatewayClient,
+		TrustUnauthenticated: cfg.Auth.TrustUnauthenticated,
+	}, logger)
+	unaryInterceptors := []grpc.UnaryServerInterceptor{
+		logging.UnaryServerInterceptor(logSampler),
+		metrics.UnaryServerInterceptor(),
+		authenticator.UnaryServerInterceptor(),
 	}
-	grpcServer := grpc.NewServer()
-	impression_service.RegisterImpressionServiceServer(grpcServer, handler.NewServer(service))
 
-	log.Printf("gRPC server listening on %s (Startup: %v)", grpcAddr, time.Since(start))
+	// Limitation de débit et quotas des impressions et événements, par locataire et par IP,
+	// partagés entre réplicas via Dragonfly
+	if cfg.RateLimit.Enabled {
+		tenantLimit := ratelimit.Limit(cfg.RateLimit.Tenant)
+		ipLimit := ratelimit.Limit(cfg.RateLimit.IP)
+		limiter := ratelimit.NewLimiter(store.client, "ratelimit:tracker", clock.System, logger)
+		unaryInterceptors = append(unaryInterceptors, limiter.UnaryServerInterceptor(
+			[]string{
+				impression_service.ImpressionService_TrackImpression_FullMethodName,
+				impression_service.ImpressionService_TrackEvent_FullMethodName,
+			},
+			ratelimit.Rule{Name: "tenant", Key: ratelimit.ByTenant(), Limit: tenantLimit},
+			ratelimit.Rule{Name: "ip", Key: ratelimit.ByPeerIP(cfg.RateLimit.TrustForwarded), Limit: ipLimit},
+		))
+		logger.Info("Rate limiting enabled", "tenant_limit", tenantLimit, "ip_limit", ipLimit)
+	}
+
+	serverOpts := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(logging.StreamServerInterceptor(logSampler), metrics.StreamServerInterceptor(), authenticator.StreamServerInterceptor()),
+	}
+
+	// TLS optionnel, mTLS si une autorité des clients est fournie ; les certificats sont rechargés à chaud
+	var serverTLS *tls.Config
+	if cfg.TLS.Enabled() {
+		tlsReloader, err := tlsconfig.NewReloader(tlsconfig.Config{
+			CertFile: cfg.TLS.CertFile,
+			KeyFile:  cfg.TLS.KeyFile,
+			CAFile:   cfg.TLS.ClientCAFile,
+		}, cfg.TLS.ReloadInterval, logger)
+		if err != nil {
+			fatal("Failed to load TLS certificates", "error", err)
+		}
+		defer tlsReloader.Stop()
+		serverTLS = tlsReloader.ServerConfig()
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(serverTLS)))
+		logger.Info("TLS enabled", "mutual", cfg.TLS.ClientCAFile != "")
+	}
+	grpcServer := grpc.NewServer(serverOpts...)
+	// Activer la réflexion pour grpcurl
+	reflection.Register(grpcServer)
+
+	// Santé : grpc.health.v1 suit les pings MongoDB et Dragonfly
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	checker := healthcheck.NewChecker(healthServer, cfg.Health.Interval, cfg.Health.Timeout, logger,
+		impression_service.ImpressionService_ServiceDesc.ServiceName)
+	for name, check := range store.checks {
+		checker.Register(name, check)
+	}
+	checker.Start()
+
+	impression_service.RegisterImpressionServiceServer(grpcServer, handler.NewServer(service, checker, clock.System, logger))
+
+	logger.Info("gRPC server listening", "address", cfg.GRPC.Addr, "startup", time.Since(start))
+
+	// Endpoint Prometheus
+	metricsServer := metrics.Serve(cfg.Metrics.Addr, logger)
+	logger.Info("Metrics available", "address", cfg.Metrics.Addr, "path", "/metrics")
+
+	// Passerelle REST/JSON et spécification OpenAPI, relayées au serveur gRPC local ; elle
+	// reprend le TLS du serveur gRPC et relaie le certificat de ses clients
+	gatewayCtx, gatewayCancel := context.WithCancel(context.Background())
+	defer gatewayCancel()
+	var gatewayServer *http.Server
+	if cfg.Gateway.Enabled {
+		gatewayCreds := insecure.NewCredentials()
+		gatewayTLS := tlsconfig.Config(cfg.Gateway.TLS)
+		if gatewayTLS.Enabled() {
+			gatewayReloader, err := tlsconfig.NewReloader(gatewayTLS, cfg.TLS.ReloadInterval, logger)
+			if err != nil {
+				fatal("Failed to load gateway TLS certificates", "error", err)
+			}
+			defer gatewayReloader.Stop()
+			gatewayCreds = credentials.NewTLS(gatewayReloader.ClientConfig())
+		}
+		gatewayServer, err = gateway.Serve(gatewayCtx, cfg.Gateway.Addr, cfg.Gateway.GRPCAddr, gatewayCreds, serverTLS, logger)
+		if err != nil {
+			fatal("Failed to start REST gateway", "error", err)
+		}
+		logger.Info("REST gateway available", "address", cfg.Gateway.Addr, "grpc_address", cfg.Gateway.GRPCAddr, "openapi", gateway.OpenAPIPath, "tls", serverTLS != nil)
+	}
 
 	go func() {
 		if err := grpcServer.Serve(lis); err != nil {
-			log.Fatalf("gRPC server error: %v", err)
+			fatal("gRPC server failed", "error", err)
 		}
 	}()
 
@@ -100,15 +422,24 @@ func main() {
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 	sig := <-stop
-	log.Printf("Received signal: %v", sig)
+	logger.Info("Received signal", "signal", sig.String())
 
 	// Ajout de l'attente avec timeout avant l'arrêt
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	log.Println("Shutting down...")
+	logger.Info("Shutting down")
 	shutdownStart := time.Now()
+	checker.Stop() // Passe le service à NOT_SERVING avant l'arrêt
+	if err := metricsServer.Shutdown(ctx); err != nil {
+		logger.Error("Error shutting down metrics server", "error", err)
+	}
+	if gatewayServer != nil {
+		if err := gatewayServer.Shutdown(ctx); err != nil {
+			logger.Error("Error shutting down REST gateway", "error", err)
+		}
+	}
 	grpcServer.GracefulStop() // Arrêt propre du serveur
 	<-ctx.Done()              // Attendre que le contexte expire
-	log.Printf("Server shut down in %v | Total uptime: %v", time.Since(shutdownStart), time.Since(start))
+	logger.Info("Server shut down", "duration", time.Since(shutdownStart), "uptime", time.Since(start))
 }
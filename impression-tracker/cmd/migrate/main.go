@@ -0,0 +1,77 @@
+// Commande migrate : applique les migrations en attente du schéma MongoDB du tracker
+// (index des deltas et du journal), consignées par collection dans schema_migrations.
+// La base et les collections sont celles de la configuration du service (fichier YAML,
+// .env et variables d'environnement).
+//
+// Exemple :
+//
+//	go run ./cmd/migrate          # applique les migrations en attente
+//	go run ./cmd/migrate -status  # liste les migrations, sans rien appliquer
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"time"
+
+	"impression-tracker/internal/adapters/mongodb"
+	"impression-tracker/internal/config"
+	"shared/mongomigrate"
+
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func main() {
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "fichier de configuration YAML, surchargé par les variables d'environnement")
+	envFile := flag.String("env-file", ".env", "fichier .env chargé dans l'environnement s'il existe")
+	status := flag.Bool("status", false, "lister les migrations appliquées et en attente, sans rien appliquer")
+	timeout := flag.Duration("timeout", 5*time.Minute, "délai maximal des migrations")
+	flag.Parse()
+
+	_ = godotenv.Load(*envFile)
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.MongoDB.URI))
+	if err != nil {
+		log.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	defer client.Disconnect(context.Background())
+	db := client.Database(cfg.MongoDB.Database)
+	migrations := mongodb.Migrations(cfg.DeltaCollections(), cfg.EventLog.Collection)
+
+	if *status {
+		applied, err := mongomigrate.AppliedMigrations(ctx, db)
+		if err != nil {
+			log.Fatalf("Failed to read migrations: %v", err)
+		}
+		appliedAt := make(map[mongomigrate.Key]time.Time, len(applied))
+		for _, m := range applied {
+			appliedAt[m.Key] = m.AppliedAt
+		}
+		for _, m := range migrations {
+			state := "pending"
+			if at, ok := appliedAt[m.Key()]; ok {
+				state = "applied " + at.Format(time.RFC3339)
+			}
+			fmt.Printf("%-24s %3d  %-50s %s\n", m.Collection, m.Version, m.Description, state)
+		}
+		return
+	}
+
+	applied, err := mongomigrate.Migrate(ctx, db, migrations, slog.Default())
+	if err != nil {
+		log.Fatalf("Migration failed after %d applied: %v", len(applied), err)
+	}
+	log.Printf("Migrations completed: %d applied, database %s up to date", len(applied), cfg.MongoDB.Database)
+}
@@ -0,0 +1,91 @@
+package clock
+
+import (
+	"sync"
+	"time"
+
+	"impression-tracker/internal/ports/out"
+)
+
+// Fake est une horloge manuelle pour les tests : le temps n'avance que par Set ou Advance,
+// qui déclenchent alors les tickers arrivés à échéance.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+// NewFake crée une horloge manuelle arrêtée à now
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now retourne l'heure courante de l'horloge
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// NewTicker crée un ticker dont le premier tic est dû à Now()+d
+func (f *Fake) NewTicker(d time.Duration) out.Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := &fakeTicker{clock: f, c: make(chan time.Time, 1), period: d, next: f.now.Add(d)}
+	f.tickers = append(f.tickers, t)
+	return t
+}
+
+// Advance avance l'horloge de d
+func (f *Fake) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Set place l'horloge à now puis émet un tic pour chaque ticker arrivé à échéance.
+// Comme pour time.Ticker, les tics d'un lecteur trop lent sont abandonnés.
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+	for _, t := range f.tickers {
+		for !t.next.After(now) {
+			select {
+			case t.c <- t.next:
+			default:
+			}
+			t.next = t.next.Add(t.period)
+		}
+	}
+}
+
+// fakeTicker est un ticker piloté par une horloge Fake
+type fakeTicker struct {
+	clock  *Fake
+	c      chan time.Time
+	period time.Duration
+	next   time.Time
+}
+
+// C retourne le canal des tics
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop retire le ticker de son horloge
+func (t *fakeTicker) Stop() {
+	f := t.clock
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, other := range f.tickers {
+		if other == t {
+			f.tickers = append(f.tickers[:i], f.tickers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Ensure Fake implements the Clock interface
+var _ out.Clock = (*Fake)(nil)
@@ -0,0 +1,180 @@
+package dragonfly
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"impression-tracker/internal/domain"
+	"impression-tracker/internal/ports/out"
+	"shared/dragonflyclient"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// newRepository connecte un repository au serveur miniredis selon opts
+func newRepository(t *testing.T, mr *miniredis.Miniredis, opts dragonflyclient.Options) *DragonflyRepository {
+	t.Helper()
+	opts.Addrs = []string{mr.Addr()}
+	repo, err := NewDragonflyRepository(opts)
+	if err != nil {
+		t.Fatalf("NewDragonflyRepository() error: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestNewDragonflyRepositoryAuthenticates(t *testing.T) {
+	mr := miniredis.RunT(t)
+	mr.RequireUserAuth("tracker", "s3cret")
+
+	for _, opts := range []dragonflyclient.Options{{}, {Username: "tracker", Password: "wrong"}} {
+		opts.Addrs = []string{mr.Addr()}
+		if repo, err := NewDragonflyRepository(opts); err == nil {
+			repo.Close()
+			t.Errorf("NewDragonflyRepository(user %q) succeeded, want an authentication error", opts.Username)
+		}
+	}
+	repo := newRepository(t, mr, dragonflyclient.Options{Username: "tracker", Password: "s3cret"})
+	if _, err := repo.Increment(context.Background(), "ad"); err != nil {
+		t.Errorf("Increment() as the ACL user error: %v", err)
+	}
+}
+
+func TestNewDragonflyRepositorySelectsDB(t *testing.T) {
+	mr := miniredis.RunT(t)
+	repo := newRepository(t, mr, dragonflyclient.Options{DB: 3})
+	if _, err := repo.Increment(domain.WithTenant(context.Background(), "acme"), "ad"); err != nil {
+		t.Fatalf("Increment() error: %v", err)
+	}
+	if got, err := mr.DB(3).Get("impression:acme:ad"); err != nil || got != "1" {
+		t.Errorf("DB 3 counter = %q, %v, want 1", got, err)
+	}
+	if mr.Exists("impression:acme:ad") {
+		t.Error("counter written to DB 0")
+	}
+}
+
+func TestNewDragonflyRepositoryTLS(t *testing.T) {
+	serverCert, roots := newServerCertificate(t)
+	mr, err := miniredis.RunTLS(&tls.Config{Certificates: []tls.Certificate{serverCert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(mr.Close)
+
+	// La configuration partagée avec le gRPC annonce h2, retiré pour le protocole Redis
+	repo := newRepository(t, mr, dragonflyclient.Options{TLS: &tls.Config{RootCAs: roots, ServerName: "localhost", NextProtos: []string{"h2"}}})
+	if err := repo.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() over TLS error: %v", err)
+	}
+
+	opts := dragonflyclient.Options{Addrs: []string{mr.Addr()}, TLS: &tls.Config{RootCAs: x509.NewCertPool(), ServerName: "localhost"}, MaxRetries: -1}
+	if repo, err := NewDragonflyRepository(opts); err == nil {
+		repo.Close()
+		t.Error("NewDragonflyRepository() trusted an unknown server certificate")
+	}
+}
+
+func TestClusterModeCounters(t *testing.T) {
+	mr := miniredis.RunT(t)
+	repo := newRepository(t, mr, dragonflyclient.Options{Mode: dragonflyclient.ModeCluster})
+	ctx := domain.WithTenant(context.Background(), "acme")
+
+	for i := 0; i < 3; i++ {
+		if _, err := repo.Increment(ctx, "ad"); err != nil {
+			t.Fatalf("Increment() error: %v", err)
+		}
+	}
+	keys, err := repo.GetAllKeys(ctx)
+	if err != nil || len(keys) != 1 || keys[0] != (domain.CounterKey{Tenant: "acme", AdID: "ad"}) {
+		t.Fatalf("GetAllKeys() = %v, %v, want the acme counter only", keys, err)
+	}
+	if count, err := repo.Reset(ctx, "ad", 1); err != nil || count != 3 {
+		t.Errorf("Reset() = %d, %v, want 3", count, err)
+	}
+}
+
+func TestFencedReset(t *testing.T) {
+	mr := miniredis.RunT(t)
+	repo := newRepository(t, mr, dragonflyclient.Options{})
+	ctx := context.Background()
+	increment := func(n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			if _, err := repo.Increment(ctx, "ad"); err != nil {
+				t.Fatalf("Increment() error: %v", err)
+			}
+		}
+	}
+
+	increment(3)
+	if count, err := repo.Reset(ctx, "ad", 5); err != nil || count != 3 {
+		t.Fatalf("Reset(token 5) = %d, %v, want 3", count, err)
+	}
+	if ttl := mr.TTL("{impression:ad}:fence"); ttl <= 0 || ttl > fenceTTL {
+		t.Errorf("fence TTL = %v, want at most %v", ttl, fenceTTL)
+	}
+
+	// Un ancien leader ne prélève plus le compteur ; le leader courant si
+	increment(2)
+	if count, err := repo.Reset(ctx, "ad", 4); !errors.Is(err, out.ErrStaleToken) || count != 0 {
+		t.Fatalf("Reset(token 4) = %d, %v, want %v", count, err, out.ErrStaleToken)
+	}
+	if count, err := repo.Get(ctx, "ad"); err != nil || count != 2 {
+		t.Fatalf("Get() after a stale reset = %d, %v, want 2", count, err)
+	}
+	if count, err := repo.Reset(ctx, "ad", 5); err != nil || count != 2 {
+		t.Errorf("Reset(token 5) again = %d, %v, want 2", count, err)
+	}
+
+	// Les clés de fencing ne sont pas des compteurs
+	if keys, err := repo.GetAllKeys(ctx); err != nil || len(keys) != 0 {
+		t.Errorf("GetAllKeys() = %v, %v, want no counter", keys, err)
+	}
+
+	// Sans jeton (élection désactivée), la réinitialisation ignore le fencing
+	increment(1)
+	if count, err := repo.Reset(ctx, "ad", 0); err != nil || count != 1 {
+		t.Errorf("Reset(no token) = %d, %v, want 1", count, err)
+	}
+}
+
+// newServerCertificate génère un certificat auto-signé pour localhost et l'autorité qui le vérifie
+func newServerCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:              []string{"localhost"},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert}, roots
+}
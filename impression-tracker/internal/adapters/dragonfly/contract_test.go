@@ -0,0 +1,31 @@
+package dragonfly
+
+import (
+	"testing"
+
+	"impression-tracker/internal/ports/out"
+	"impression-tracker/internal/ports/out/contract"
+	"shared/dragonflyclient"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestCacheRepositoryContract(t *testing.T) {
+	contract.CacheRepository(t, func(t *testing.T) out.CacheRepository {
+		return newRepository(t, miniredis.RunT(t), dragonflyclient.Options{})
+	})
+}
+
+func TestLeaseRepositoryContract(t *testing.T) {
+	contract.LeaseRepository(t, func(t *testing.T) (out.LeaseRepository, contract.Elapse) {
+		mr := miniredis.RunT(t)
+		return NewLeaseRepository(newRepository(t, mr, dragonflyclient.Options{})), mr.FastForward
+	})
+}
+
+func TestEventDeduplicatorContract(t *testing.T) {
+	contract.EventDeduplicator(t, func(t *testing.T) (out.EventDeduplicator, contract.Elapse) {
+		mr := miniredis.RunT(t)
+		return NewEventDeduplicator(newRepository(t, mr, dragonflyclient.Options{})), mr.FastForward
+	})
+}
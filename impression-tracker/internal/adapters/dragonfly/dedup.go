@@ -0,0 +1,37 @@
+package dragonfly
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"impression-tracker/internal/adapters/metrics"
+	"impression-tracker/internal/adapters/tracing"
+	"impression-tracker/internal/domain"
+	"impression-tracker/internal/ports/out"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// EventDeduplicator implémente l'interface EventDeduplicator sur Dragonfly : chaque événement
+// compté est une clé posée avec SET NX PX, partagée entre les réplicas.
+type EventDeduplicator struct {
+	client redis.UniversalClient
+}
+
+// NewEventDeduplicator crée un dédoublonneur partageant la connexion du DragonflyRepository.
+func NewEventDeduplicator(repo *DragonflyRepository) *EventDeduplicator {
+	return &EventDeduplicator{client: repo.client}
+}
+
+// FirstSeen pose la clé "event_seen:{tenant}:{impressionID}:{eventType}" si elle n'existe pas.
+func (d *EventDeduplicator) FirstSeen(ctx context.Context, impressionID string, eventType domain.EventType, ttl time.Duration) (bool, error) {
+	defer metrics.ObserveRepository("dragonfly", "FirstSeen", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "dragonfly", "FirstSeen")
+	defer span.End()
+	key := fmt.Sprintf("event_seen:%s:%s:%s", domain.TenantFromContext(ctx), impressionID, eventType)
+	return d.client.SetNX(ctx, key, 1, ttl).Result()
+}
+
+// Ensure EventDeduplicator implements the EventDeduplicator interface
+var _ out.EventDeduplicator = (*EventDeduplicator)(nil)
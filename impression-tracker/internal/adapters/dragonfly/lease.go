@@ -0,0 +1,107 @@
+package dragonfly
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"impression-tracker/internal/adapters/metrics"
+	"impression-tracker/internal/adapters/tracing"
+	"impression-tracker/internal/ports/out"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// acquireScript obtient ou prolonge un bail.
+// Le bail est stocké sous la forme "{holder}|{token}" avec SET NX PX ; le jeton de fencing
+// est incrémenté à chaque nouvelle acquisition, pas lors des prolongations.
+// KEYS[1] = clé du bail, KEYS[2] = compteur des jetons, ARGV[1] = holder, ARGV[2] = TTL en ms.
+// Retourne le jeton, ou -1 si le bail est détenu par une autre instance.
+var acquireScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current then
+  local holder, token = string.match(current, '^(.*)|(%d+)$')
+  if holder == ARGV[1] then
+    redis.call('PEXPIRE', KEYS[1], ARGV[2])
+    return tonumber(token)
+  end
+  return -1
+end
+local token = redis.call('INCR', KEYS[2])
+redis.call('SET', KEYS[1], ARGV[1] .. '|' .. token, 'NX', 'PX', ARGV[2])
+return token
+`)
+
+// releaseScript supprime le bail uniquement s'il est détenu par l'instance appelante.
+// KEYS[1] = clé du bail, ARGV[1] = holder.
+var releaseScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current and string.match(current, '^(.*)|%d+$') == ARGV[1] then
+  return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// LeaseRepository implémente l'interface LeaseRepository sur Dragonfly.
+type LeaseRepository struct {
+	client redis.UniversalClient
+}
+
+// NewLeaseRepository crée un gestionnaire de baux partageant la connexion du DragonflyRepository.
+func NewLeaseRepository(repo *DragonflyRepository) *LeaseRepository {
+	return &LeaseRepository{client: repo.client}
+}
+
+// Acquire obtient ou prolonge le bail "lease:{name}" pour holder.
+func (r *LeaseRepository) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (int64, bool, error) {
+	defer metrics.ObserveRepository("dragonfly", "Acquire", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "dragonfly", "Acquire")
+	defer span.End()
+	keys := []string{leaseKey(name), leaseKey(name) + ":token"}
+	token, err := acquireScript.Run(ctx, r.client, keys, holder, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return 0, false, err
+	}
+	if token < 0 {
+		return 0, false, nil
+	}
+	return token, true, nil
+}
+
+// Validate vérifie que le bail en cours porte le jeton donné.
+func (r *LeaseRepository) Validate(ctx context.Context, name string, token int64) (bool, error) {
+	defer metrics.ObserveRepository("dragonfly", "Validate", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "dragonfly", "Validate")
+	defer span.End()
+	current, err := r.client.Get(ctx, leaseKey(name)).Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	i := strings.LastIndexByte(current, '|')
+	if i < 0 {
+		return false, fmt.Errorf("malformed lease value %q", current)
+	}
+	return current[i+1:] == strconv.FormatInt(token, 10), nil
+}
+
+// Release libère le bail s'il est détenu par holder.
+func (r *LeaseRepository) Release(ctx context.Context, name, holder string) error {
+	defer metrics.ObserveRepository("dragonfly", "Release", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "dragonfly", "Release")
+	defer span.End()
+	return releaseScript.Run(ctx, r.client, []string{leaseKey(name)}, holder).Err()
+}
+
+// leaseKey construit la clé d'un bail. Le nom entre accolades place le bail et son
+// compteur de jetons dans le même slot, condition des scripts en mode cluster.
+func leaseKey(name string) string {
+	return fmt.Sprintf("lease:{%s}", name)
+}
+
+// Ensure LeaseRepository implements the LeaseRepository interface
+var _ out.LeaseRepository = (*LeaseRepository)(nil)
@@ -3,10 +3,16 @@ package dragonfly
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 
+	"impression-tracker/internal/adapters/metrics"
+	"impression-tracker/internal/adapters/tracing"
+	"impression-tracker/internal/domain"
 	"impression-tracker/internal/ports/out"
+	"shared/dragonflyclient"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -14,41 +20,93 @@ import (
 // DragonflyRepository implémente l'interface CacheRepository pour stocker les compteurs d'impressions
 // en utilisant Dragonfly (compatible Redis) comme cache.
 type DragonflyRepository struct {
-	client *redis.Client
+	client redis.UniversalClient
+	prefix string // Préfixe des clés de compteurs ("impression" par défaut)
 }
 
 // NewDragonflyRepository crée une nouvelle instance de DragonflyRepository.
-// Elle établit une connexion avec le serveur Dragonfly et vérifie que la connexion fonctionne.
-func NewDragonflyRepository(addr string) (*DragonflyRepository, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Password: "", // no password set
-		DB:       0,  // use default DB
-	})
+// Elle établit une connexion avec le serveur Dragonfly selon opts et vérifie que la connexion fonctionne.
+func NewDragonflyRepository(opts dragonflyclient.Options) (*DragonflyRepository, error) {
+	client, err := dragonflyclient.NewClient(opts)
+	if err != nil {
+		return nil, err
+	}
 
 	// Test the connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
 		return nil, fmt.Errorf("failed to connect to Dragonfly: %w", err)
 	}
 
 	return &DragonflyRepository{
 		client: client,
+		prefix: "impression",
 	}, nil
 }
 
+// WithPrefix retourne un repository partageant la même connexion mais dont les compteurs
+// sont stockés sous les clés "{prefix}:{tenant}:{adID}". Seul le repository d'origine doit être fermé.
+func (r *DragonflyRepository) WithPrefix(prefix string) *DragonflyRepository {
+	return &DragonflyRepository{
+		client: r.client,
+		prefix: prefix,
+	}
+}
+
+// key construit la clé du compteur d'une publicité pour le locataire du contexte.
+// Le locataire par défaut garde les clés "{prefix}:{adID}" antérieures au multi-locataire.
+// Les segments sont échappés : un ':' n'y apparaît jamais, et les identifiants usuels
+// (UUID, noms de locataires) gardent la même clé qu'avant l'échappement.
+func (r *DragonflyRepository) key(ctx context.Context, adID string) string {
+	if tenant := domain.TenantFromContext(ctx); tenant != "" {
+		return fmt.Sprintf("%s:%s:%s", r.prefix, url.QueryEscape(tenant), url.QueryEscape(adID))
+	}
+	return fmt.Sprintf("%s:%s", r.prefix, url.QueryEscape(adID))
+}
+
+// parseKey retrouve le compteur d'une clé construite par key. Les clés d'un autre format
+// sont ignorées.
+func (r *DragonflyRepository) parseKey(key string) (domain.CounterKey, bool) {
+	rest, ok := strings.CutPrefix(key, r.prefix+":")
+	if !ok {
+		return domain.CounterKey{}, false
+	}
+	tenant, adID, ok := strings.Cut(rest, ":")
+	if !ok {
+		tenant, adID = "", rest // Locataire par défaut
+	} else if tenant == "" {
+		return domain.CounterKey{}, false
+	}
+	if strings.Contains(adID, ":") {
+		return domain.CounterKey{}, false
+	}
+	tenant, terr := url.QueryUnescape(tenant)
+	adID, aerr := url.QueryUnescape(adID)
+	if terr != nil || aerr != nil || adID == "" {
+		return domain.CounterKey{}, false
+	}
+	return domain.CounterKey{Tenant: tenant, AdID: adID}, true
+}
+
 // Increment incrémente le compteur d'impressions pour une publicité donnée.
-// La clé est formatée comme "impression:{adID}" pour éviter les collisions.
+// La clé est formatée comme "{prefix}:{tenant}:{adID}" pour éviter les collisions entre locataires.
 func (r *DragonflyRepository) Increment(ctx context.Context, adID string) (int64, error) {
-	key := fmt.Sprintf("impression:%s", adID)
+	defer metrics.ObserveRepository("dragonfly", "Increment", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "dragonfly", "Increment")
+	defer span.End()
+	key := r.key(ctx, adID)
 	return r.client.Incr(ctx, key).Result()
 }
 
 // Get récupère le nombre actuel d'impressions pour une publicité donnée.
 // Retourne 0 si la clé n'existe pas.
 func (r *DragonflyRepository) Get(ctx context.Context, adID string) (int64, error) {
-	key := fmt.Sprintf("impression:%s", adID)
+	defer metrics.ObserveRepository("dragonfly", "Get", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "dragonfly", "Get")
+	defer span.End()
+	key := r.key(ctx, adID)
 	count, err := r.client.Get(ctx, key).Int64()
 	if err == redis.Nil {
 		return 0, nil
@@ -57,54 +115,119 @@ func (r *DragonflyRepository) Get(ctx context.Context, adID string) (int64, erro
 }
 
 // Reset récupère et réinitialise le compteur d'impressions pour une publicité donnée.
-// Utilise une transaction pipeline pour garantir l'atomicité de l'opération.
-func (r *DragonflyRepository) Reset(ctx context.Context, adID string) (int64, error) {
-	key := fmt.Sprintf("impression:%s", adID)
-	pipe := r.client.Pipeline()
-	getCmd := pipe.Get(ctx, key)
-	pipe.Del(ctx, key)
-
-	_, err := pipe.Exec(ctx)
-	if err != nil && err != redis.Nil {
-		return 0, err
+// Utilise GETDEL pour garantir l'atomicité : si plusieurs instances réinitialisent la même clé,
+// une seule récupère le compteur. Avec un jeton de fencing, la vérification du jeton et le
+// GETDEL sont faits par un même script.
+func (r *DragonflyRepository) Reset(ctx context.Context, adID string, token int64) (int64, error) {
+	defer metrics.ObserveRepository("dragonfly", "Reset", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "dragonfly", "Reset")
+	defer span.End()
+	key := r.key(ctx, adID)
+	if token > 0 {
+		count, err := fencedResetScript.Run(ctx, r.client, []string{key, fenceKey(key)}, token, fenceTTL.Milliseconds()).Int64()
+		if err == nil && count < 0 {
+			return 0, out.ErrStaleToken
+		}
+		return count, err
 	}
-
-	count, err := getCmd.Int64()
+	count, err := r.client.GetDel(ctx, key).Int64()
 	if err == redis.Nil {
 		return 0, nil
 	}
 	return count, err
 }
 
-// GetAllKeys récupère toutes les clés d'impressions stockées dans Dragonfly.
+// fenceTTL est la durée de conservation du dernier jeton de fencing d'un compteur
+const fenceTTL = 24 * time.Hour
+
+// fencedResetScript réinitialise un compteur (GETDEL) si le jeton de fencing n'est pas plus
+// ancien que le dernier jeton l'ayant réinitialisé, puis mémorise ce jeton.
+// KEYS[1] = compteur, KEYS[2] = dernier jeton, ARGV[1] = jeton, ARGV[2] = TTL du jeton en ms.
+// Retourne le compteur, ou -1 si le jeton est périmé.
+var fencedResetScript = redis.NewScript(`
+local fence = tonumber(redis.call('GET', KEYS[2]) or '0')
+if tonumber(ARGV[1]) < fence then
+  return -1
+end
+redis.call('SET', KEYS[2], ARGV[1], 'PX', ARGV[2])
+return tonumber(redis.call('GETDEL', KEYS[1]) or '0')
+`)
+
+// fenceKey construit la clé du dernier jeton de fencing d'un compteur. La clé du compteur
+// entre accolades place les deux clés dans le même slot, condition des scripts en mode
+// cluster, et le préfixe "{" les exclut du SCAN des compteurs.
+func fenceKey(key string) string {
+	return fmt.Sprintf("{%s}:fence", key)
+}
+
+// GetAllKeys récupère les compteurs d'impressions de tous les locataires stockés dans Dragonfly.
 // Utilise SCAN pour itérer sur toutes les clés de manière efficace, même avec un grand nombre de clés.
-func (r *DragonflyRepository) GetAllKeys(ctx context.Context) ([]string, error) {
-	// Use SCAN to get all keys matching the pattern
+func (r *DragonflyRepository) GetAllKeys(ctx context.Context) ([]domain.CounterKey, error) {
+	defer metrics.ObserveRepository("dragonfly", "GetAllKeys", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "dragonfly", "GetAllKeys")
+	defer span.End()
+	keys, err := r.scanKeys(ctx, r.prefix+":*")
+	if err != nil {
+		return nil, err
+	}
+
+	counters := make([]domain.CounterKey, 0, len(keys))
+	for _, key := range keys {
+		if counter, ok := r.parseKey(key); ok {
+			counters = append(counters, counter)
+		}
+	}
+
+	return counters, nil
+}
+
+// scanKeys retourne les clés correspondant à pattern. En mode cluster, SCAN ne parcourt
+// qu'un nœud : chaque maître est alors parcouru.
+func (r *DragonflyRepository) scanKeys(ctx context.Context, pattern string) ([]string, error) {
+	cluster, ok := r.client.(*redis.ClusterClient)
+	if !ok {
+		return scanNode(ctx, r.client, pattern)
+	}
+	var mu sync.Mutex
+	var keys []string
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		nodeKeys, err := scanNode(ctx, node, pattern)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		keys = append(keys, nodeKeys...)
+		mu.Unlock()
+		return nil
+	})
+	return keys, err
+}
+
+// scanNode itère SCAN sur un nœud jusqu'à la fin du curseur
+func scanNode(ctx context.Context, client redis.Cmdable, pattern string) ([]string, error) {
 	var cursor uint64
 	var keys []string
 	for {
-		var err error
-		var partialKeys []string
-		partialKeys, cursor, err = r.client.Scan(ctx, cursor, "impression:*", 100).Result()
+		partialKeys, next, err := client.Scan(ctx, cursor, pattern, 100).Result()
 		if err != nil {
 			return nil, err
 		}
 		keys = append(keys, partialKeys...)
-		if cursor == 0 {
-			break
+		if next == 0 {
+			return keys, nil
 		}
+		cursor = next
 	}
+}
 
-	// Extract ad IDs from keys
-	adIDs := make([]string, 0, len(keys))
-	for _, key := range keys {
-		parts := strings.Split(key, ":")
-		if len(parts) == 2 {
-			adIDs = append(adIDs, parts[1])
-		}
-	}
+// Client retourne la connexion Dragonfly, pour les adaptateurs qui la partagent (limitation de débit).
+func (r *DragonflyRepository) Client() redis.UniversalClient {
+	return r.client
+}
 
-	return adIDs, nil
+// Ping vérifie que le serveur Dragonfly répond, pour le suivi de santé du service.
+func (r *DragonflyRepository) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
 }
 
 // Close ferme la connexion avec le serveur Dragonfly.
@@ -114,4 +237,3 @@ func (r *DragonflyRepository) Close() error {
 
 // Ensure DragonflyRepository implements the CacheRepository interface
 var _ out.CacheRepository = (*DragonflyRepository)(nil)
- 
\ No newline at end of file
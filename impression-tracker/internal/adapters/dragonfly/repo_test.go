@@ -0,0 +1,76 @@
+package dragonfly
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"impression-tracker/internal/domain"
+	"shared/dragonflyclient"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestCounterKeysRoundTrip(t *testing.T) {
+	mr := miniredis.RunT(t)
+	repo := newRepository(t, mr, dragonflyclient.Options{})
+
+	// Les séparateurs dans les identifiants ne déplacent pas la frontière locataire/publicité
+	counters := []domain.CounterKey{
+		{AdID: "3f2b9c1e-7d4a-4c6b-9e0f-1a2b3c4d5e6f"},
+		{Tenant: "acme", AdID: "ad-1"},
+		{Tenant: "a:b", AdID: "c"},
+		{Tenant: "a", AdID: "b:c"},
+		{AdID: "acme:ad-1"},
+		{Tenant: "globex corp", AdID: "50%+off"},
+	}
+	for _, c := range counters {
+		if _, err := repo.Increment(domain.WithTenant(context.Background(), c.Tenant), c.AdID); err != nil {
+			t.Fatalf("Increment(%+v) error: %v", c, err)
+		}
+	}
+
+	got, err := repo.GetAllKeys(context.Background())
+	if err != nil {
+		t.Fatalf("GetAllKeys() error: %v", err)
+	}
+	if len(got) != len(counters) {
+		t.Fatalf("GetAllKeys() = %v, want %v", got, counters)
+	}
+	for _, c := range counters {
+		if !slices.Contains(got, c) {
+			t.Errorf("GetAllKeys() = %v, missing %+v", got, c)
+		}
+		ctx := domain.WithTenant(context.Background(), c.Tenant)
+		if count, err := repo.Get(ctx, c.AdID); err != nil || count != 1 {
+			t.Errorf("Get(%+v) = %d, %v, want 1", c, count, err)
+		}
+	}
+}
+
+func TestCounterKeysKeepTheirFormat(t *testing.T) {
+	mr := miniredis.RunT(t)
+	repo := newRepository(t, mr, dragonflyclient.Options{})
+
+	// Les clés des identifiants usuels sont inchangées, y compris celles du locataire par défaut
+	ctx := domain.WithTenant(context.Background(), "acme")
+	if _, err := repo.Increment(ctx, "ad-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Increment(context.Background(), "ad-2"); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"impression:acme:ad-1", "impression:ad-2"} {
+		if !mr.Exists(key) {
+			t.Errorf("key %s does not exist, keys: %v", key, mr.Keys())
+		}
+	}
+
+	// Une clé étrangère au format des compteurs est ignorée
+	mr.Set("impression:a:b:c", "1")
+	mr.Set("impression::ad", "1")
+	keys, err := repo.GetAllKeys(ctx)
+	if err != nil || len(keys) != 2 {
+		t.Errorf("GetAllKeys() = %v, %v, want the two counters", keys, err)
+	}
+}
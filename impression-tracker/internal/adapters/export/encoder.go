@@ -0,0 +1,138 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"impression-tracker/internal/domain"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Format identifie un format d'export des impressions brutes
+type Format string
+
+const (
+	FormatNDJSON  Format = "ndjson"
+	FormatCSV     Format = "csv"
+	FormatParquet Format = "parquet"
+)
+
+// Encoder écrit des impressions dans un flux au format choisi.
+// Close doit être appelé pour finaliser le flux (en-têtes de fin Parquet, flush CSV).
+type Encoder interface {
+	Encode(imp domain.Impression) error
+	Close() error
+}
+
+// NewEncoder crée un encodeur pour le format demandé.
+func NewEncoder(format Format, w io.Writer) (Encoder, error) {
+	switch format {
+	case FormatNDJSON:
+		return &ndjsonEncoder{enc: json.NewEncoder(w)}, nil
+	case FormatCSV:
+		return newCSVEncoder(w), nil
+	case FormatParquet:
+		return &parquetEncoder{writer: parquet.NewGenericWriter[parquetRow](w)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// ndjsonEncoder écrit une impression JSON par ligne
+type ndjsonEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonEncoder) Encode(imp domain.Impression) error {
+	return e.enc.Encode(imp)
+}
+
+func (e *ndjsonEncoder) Close() error {
+	return nil
+}
+
+// csvHeader liste les colonnes des exports CSV. ivt_reason est vide pour le trafic valide,
+// tenant pour le locataire par défaut.
+var csvHeader = []string{"impression_id", "ad_id", "tenant", "timestamp", "user_agent", "ip_address", "referrer", "ivt_reason"}
+
+// csvEncoder écrit les impressions sous forme de lignes CSV précédées d'un en-tête
+type csvEncoder struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func newCSVEncoder(w io.Writer) *csvEncoder {
+	return &csvEncoder{writer: csv.NewWriter(w)}
+}
+
+func (e *csvEncoder) Encode(imp domain.Impression) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	return e.writer.Write([]string{
+		imp.ID,
+		imp.AdID,
+		imp.Tenant,
+		imp.Timestamp.UTC().Format(time.RFC3339Nano),
+		imp.Context.UserAgent,
+		imp.Context.IPAddress,
+		imp.Context.Referrer,
+		string(imp.IVTReason),
+	})
+}
+
+func (e *csvEncoder) Close() error {
+	// Un export vide contient tout de même l'en-tête
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func (e *csvEncoder) writeHeader() error {
+	if e.headerWritten {
+		return nil
+	}
+	e.headerWritten = true
+	return e.writer.Write(csvHeader)
+}
+
+// parquetRow est le schéma des fichiers Parquet exportés
+type parquetRow struct {
+	ImpressionID string    `parquet:"impression_id"`
+	AdID         string    `parquet:"ad_id,dict"`
+	Tenant       string    `parquet:"tenant,optional,dict"`
+	Timestamp    time.Time `parquet:"timestamp,timestamp(millisecond)"`
+	UserAgent    string    `parquet:"user_agent,optional"`
+	IPAddress    string    `parquet:"ip_address,optional"`
+	Referrer     string    `parquet:"referrer,optional"`
+	IVTReason    string    `parquet:"ivt_reason,optional,dict"` // Vide pour le trafic valide
+}
+
+// parquetEncoder écrit les impressions dans un fichier Parquet
+type parquetEncoder struct {
+	writer *parquet.GenericWriter[parquetRow]
+}
+
+func (e *parquetEncoder) Encode(imp domain.Impression) error {
+	_, err := e.writer.Write([]parquetRow{{
+		ImpressionID: imp.ID,
+		AdID:         imp.AdID,
+		Tenant:       imp.Tenant,
+		Timestamp:    imp.Timestamp.UTC(),
+		UserAgent:    imp.Context.UserAgent,
+		IPAddress:    imp.Context.IPAddress,
+		Referrer:     imp.Context.Referrer,
+		IVTReason:    string(imp.IVTReason),
+	}})
+	return err
+}
+
+func (e *parquetEncoder) Close() error {
+	return e.writer.Close()
+}
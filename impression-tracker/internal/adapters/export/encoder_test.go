@@ -0,0 +1,168 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"impression-tracker/internal/domain"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+var testStart = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// impressions retourne une impression complète, une impression sans contexte du locataire
+// par défaut, et une impression de robot dont l'agent contient virgules et guillemets
+func impressions() []domain.Impression {
+	return []domain.Impression{
+		{
+			ID: "imp-1", AdID: "ad-1", Tenant: "acme", Timestamp: testStart,
+			Context: domain.ImpressionContext{UserAgent: "Mozilla/5.0", IPAddress: "198.51.100.7", Referrer: "https://example.com/"},
+		},
+		{ID: "imp-2", AdID: "ad-2", Timestamp: testStart.Add(1500 * time.Millisecond)},
+		{
+			ID: "imp-3", AdID: "ad-1", Tenant: "acme", Timestamp: testStart.Add(time.Minute),
+			Context:   domain.ImpressionContext{UserAgent: `Mozilla/5.0 (compatible; "Googlebot", +http://www.google.com/bot.html)`, IPAddress: "66.249.66.1"},
+			IVTReason: domain.IVTKnownBot,
+		},
+	}
+}
+
+// encode écrit les impressions au format demandé
+func encode(t *testing.T, format Format, imps []domain.Impression) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	enc, err := NewEncoder(format, &buf)
+	if err != nil {
+		t.Fatalf("NewEncoder(%s) error: %v", format, err)
+	}
+	for _, imp := range imps {
+		if err := enc.Encode(imp); err != nil {
+			t.Fatalf("Encode() error: %v", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	data := encode(t, FormatCSV, impressions())
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("csv.ReadAll() error: %v\n%s", err, data)
+	}
+
+	want := [][]string{
+		{"impression_id", "ad_id", "tenant", "timestamp", "user_agent", "ip_address", "referrer", "ivt_reason"},
+		{"imp-1", "ad-1", "acme", "2026-01-01T12:00:00Z", "Mozilla/5.0", "198.51.100.7", "https://example.com/", ""},
+		{"imp-2", "ad-2", "", "2026-01-01T12:00:01.5Z", "", "", "", ""},
+		{"imp-3", "ad-1", "acme", "2026-01-01T12:01:00Z", `Mozilla/5.0 (compatible; "Googlebot", +http://www.google.com/bot.html)`, "66.249.66.1", "", "known_bot"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("read %d records, want %d:\n%s", len(records), len(want), data)
+	}
+	for i := range want {
+		if strings.Join(records[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("record %d = %q, want %q", i, records[i], want[i])
+		}
+	}
+	// L'agent est cité, ses guillemets doublés
+	if !strings.Contains(string(data), `"Mozilla/5.0 (compatible; ""Googlebot"", +http://www.google.com/bot.html)"`) {
+		t.Errorf("user agent not quoted:\n%s", data)
+	}
+}
+
+func TestCSVEmptyExportHasHeader(t *testing.T) {
+	data := encode(t, FormatCSV, nil)
+	if got, want := string(data), strings.Join(csvHeader, ",")+"\n"; got != want {
+		t.Errorf("empty export = %q, want %q", got, want)
+	}
+}
+
+func TestParquetRoundTrip(t *testing.T) {
+	data := encode(t, FormatParquet, impressions())
+
+	file, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("parquet.OpenFile() error: %v", err)
+	}
+	var columns []string
+	for _, field := range file.Schema().Fields() {
+		columns = append(columns, field.Name())
+		optional := field.Name() != "impression_id" && field.Name() != "ad_id" && field.Name() != "timestamp"
+		if field.Optional() != optional {
+			t.Errorf("column %s optional = %v, want %v", field.Name(), field.Optional(), optional)
+		}
+	}
+	if got, want := strings.Join(columns, ","), strings.Join(csvHeader, ","); got != want {
+		t.Errorf("columns = %s, want %s", got, want)
+	}
+
+	rows, err := parquet.Read[parquetRow](bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("parquet.Read() error: %v", err)
+	}
+	imps := impressions()
+	if len(rows) != len(imps) {
+		t.Fatalf("read %d rows, want %d", len(rows), len(imps))
+	}
+	for i, imp := range imps {
+		want := parquetRow{
+			ImpressionID: imp.ID,
+			AdID:         imp.AdID,
+			Tenant:       imp.Tenant,
+			Timestamp:    imp.Timestamp,
+			UserAgent:    imp.Context.UserAgent,
+			IPAddress:    imp.Context.IPAddress,
+			Referrer:     imp.Context.Referrer,
+			IVTReason:    string(imp.IVTReason),
+		}
+		got := rows[i]
+		if !got.Timestamp.Equal(want.Timestamp) {
+			t.Errorf("row %d timestamp = %v, want %v", i, got.Timestamp, want.Timestamp)
+		}
+		got.Timestamp = want.Timestamp
+		if got != want {
+			t.Errorf("row %d = %+v, want %+v", i, got, want)
+		}
+	}
+
+	// Les champs vides sont écrits comme nuls
+	index, err := file.RowGroups()[0].ColumnChunks()[6].ColumnIndex() // referrer
+	if err != nil {
+		t.Fatalf("ColumnIndex() error: %v", err)
+	}
+	if nulls := index.NullCount(0); nulls != 2 {
+		t.Errorf("referrer null count = %d, want 2", nulls)
+	}
+}
+
+func TestNDJSONRoundTrip(t *testing.T) {
+	data := encode(t, FormatNDJSON, impressions())
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	imps := impressions()
+	if len(lines) != len(imps) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(imps))
+	}
+	for i, line := range lines {
+		var got domain.Impression
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("line %d: %v", i, err)
+		}
+		if got.ID != imps[i].ID || got.Tenant != imps[i].Tenant || got.IVTReason != imps[i].IVTReason || !got.Timestamp.Equal(imps[i].Timestamp) {
+			t.Errorf("line %d = %+v, want %+v", i, got, imps[i])
+		}
+	}
+}
+
+func TestNewEncoderRejectsUnknownFormat(t *testing.T) {
+	if _, err := NewEncoder("xml", &bytes.Buffer{}); err == nil {
+		t.Error("NewEncoder(xml) succeeded, want an error")
+	}
+}
@@ -0,0 +1,120 @@
+package gateway
+
+import (
+	"context"
+	"crypto/tls"
+	_ "embed"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"impression-tracker/internal/adapters/grpc/auth"
+	"impression-tracker/internal/adapters/ratelimit"
+	"shared/generated/impression_service"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+// OpenAPIPath est le chemin de la spécification OpenAPI générée depuis les protos
+const OpenAPIPath = "/openapi.json"
+
+//go:embed openapi/impression_service.swagger.json
+var openAPISpec []byte
+
+// Serve démarre la passerelle REST/JSON sur addr. Chaque requête est relayée au serveur
+// gRPC grpcAddr, dont elle traverse les intercepteurs (authentification, journalisation,
+// limitation de débit). Avec serverTLS, celui du serveur gRPC, la passerelle sert en HTTPS
+// aux mêmes conditions et relaie le certificat client vérifié, qui identifie l'appelant ;
+// sans, elle sert en HTTP. La connexion au serveur est fermée à l'annulation de ctx.
+func Serve(ctx context.Context, addr, grpcAddr string, creds credentials.TransportCredentials, serverTLS *tls.Config, logger *slog.Logger) (*http.Server, error) {
+	logger = logger.With("component", "Gateway")
+
+	gwMux := runtime.NewServeMux(
+		runtime.WithOutgoingHeaderMatcher(outgoingHeader),
+		runtime.WithIncomingHeaderMatcher(incomingHeader),
+		runtime.WithMetadata(forwardClientCert),
+	)
+	err := impression_service.RegisterImpressionServiceHandlerFromEndpoint(ctx, gwMux, grpcAddr, []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(OpenAPIPath, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(openAPISpec)
+	})
+	mux.Handle("/", gwMux)
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	if serverTLS != nil {
+		server.TLSConfig = httpTLS(serverTLS)
+	}
+
+	go func() {
+		var err error
+		if server.TLSConfig != nil {
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Gateway server failed", "addr", addr, "error", err)
+		}
+	}()
+	return server, nil
+}
+
+// outgoingHeader expose la métadonnée retry-after en en-tête HTTP Retry-After ; les autres
+// métadonnées gardent le préfixe Grpc-Metadata-
+func outgoingHeader(key string) (string, bool) {
+	if key == ratelimit.RetryAfterHeader {
+		return "Retry-After", true
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
+
+// incomingHeader relaie les en-têtes par défaut, sauf un certificat relayé fourni par le
+// client HTTP lui-même
+func incomingHeader(key string) (string, bool) {
+	if strings.EqualFold(key, runtime.MetadataHeaderPrefix+auth.ForwardedCertHeader) {
+		return "", false
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// forwardClientCert relaie au serveur gRPC le certificat client vérifié de la requête HTTPS
+func forwardClientCert(_ context.Context, r *http.Request) metadata.MD {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return metadata.Pairs(auth.ForwardedCertHeader, auth.EncodeCertificate(r.TLS.VerifiedChains[0][0]))
+}
+
+// httpTLS adapte la configuration TLS du serveur gRPC à HTTP/1.1, y compris celle choisie
+// à chaque poignée de main pour suivre l'autorité des clients rechargée
+func httpTLS(grpcTLS *tls.Config) *tls.Config {
+	protos := []string{"h2", "http/1.1"}
+	cfg := grpcTLS.Clone()
+	cfg.NextProtos = protos
+	if next := grpcTLS.GetConfigForClient; next != nil {
+		cfg.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			c, err := next(hello)
+			if err != nil || c == nil {
+				return c, err
+			}
+			c = c.Clone()
+			c.NextProtos = protos
+			return c, nil
+		}
+	}
+	return cfg
+}
@@ -0,0 +1,73 @@
+package gateway
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http/httptest"
+	"testing"
+
+	"impression-tracker/internal/adapters/grpc/auth"
+)
+
+func TestIncomingHeaderDropsForwardedCertificate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{header: "Grpc-Metadata-X-Forwarded-Client-Cert", want: false},
+		{header: "grpc-metadata-x-forwarded-client-cert", want: false},
+		{header: "Grpc-Metadata-X-Request-Id", want: true},
+		{header: "X-Forwarded-Client-Cert", want: false},
+	}
+	for _, tt := range tests {
+		if _, ok := incomingHeader(tt.header); ok != tt.want {
+			t.Errorf("incomingHeader(%q) forwarded = %v, want %v", tt.header, ok, tt.want)
+		}
+	}
+}
+
+func TestForwardClientCert(t *testing.T) {
+	cert := &x509.Certificate{Raw: []byte("der")}
+	r := httptest.NewRequest("GET", "/v1/ads/ad/impressions/count", nil)
+	if md := forwardClientCert(context.Background(), r); len(md) != 0 {
+		t.Errorf("forwardClientCert() over HTTP = %v, want no metadata", md)
+	}
+
+	r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	if md := forwardClientCert(context.Background(), r); len(md) != 0 {
+		t.Errorf("forwardClientCert() with an unverified certificate = %v, want no metadata", md)
+	}
+
+	r.TLS.VerifiedChains = [][]*x509.Certificate{{cert}}
+	md := forwardClientCert(context.Background(), r)
+	if got := md.Get(auth.ForwardedCertHeader); len(got) != 1 || got[0] != auth.EncodeCertificate(cert) {
+		t.Errorf("forwarded certificate = %v, want the verified leaf", got)
+	}
+}
+
+func TestHTTPTLSKeepsClientAuthentication(t *testing.T) {
+	grpcTLS := &tls.Config{
+		NextProtos: []string{"h2"},
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return &tls.Config{NextProtos: []string{"h2"}, ClientAuth: tls.RequireAndVerifyClientCert}, nil
+		},
+	}
+	cfg := httpTLS(grpcTLS)
+	perHandshake, err := cfg.GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []*tls.Config{cfg, perHandshake} {
+		if len(c.NextProtos) != 2 || c.NextProtos[1] != "http/1.1" {
+			t.Errorf("NextProtos = %v, want h2 and http/1.1", c.NextProtos)
+		}
+		if c.ClientAuth != tls.RequireAndVerifyClientCert {
+			t.Errorf("ClientAuth = %v, want the gRPC server's RequireAndVerifyClientCert", c.ClientAuth)
+		}
+	}
+	if len(grpcTLS.NextProtos) != 1 {
+		t.Errorf("gRPC NextProtos = %v, want unchanged", grpcTLS.NextProtos)
+	}
+}
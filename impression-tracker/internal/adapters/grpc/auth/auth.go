@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+
+	"impression-tracker/internal/domain"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ForwardedCertHeader est la clé de métadonnée gRPC par laquelle la passerelle REST relaie
+// le certificat client (DER en base64) vérifié lors de la poignée de main HTTPS
+const ForwardedCertHeader = "x-forwarded-client-cert"
+
+// Caller est l'appelant authentifié par son certificat client
+type Caller struct {
+	Name    string // Nom commun du certificat, vide pour un appelant sans certificat
+	Tenant  string // Locataire du certificat (première organisation du sujet)
+	Service bool   // Service de confiance (adserver) pouvant agir pour tout locataire
+}
+
+// Config identifie les appelants par le nom commun de leur certificat
+type Config struct {
+	ServiceClients []string // Services de confiance pouvant agir pour tout locataire
+	GatewayClient  string   // Passerelle REST, seule autorisée à relayer le certificat de son client
+	// TrustUnauthenticated traite un appelant sans certificat comme un service de confiance :
+	// réservé au développement, sans mTLS
+	TrustUnauthenticated bool
+}
+
+type contextKey struct{}
+
+// WithCaller associe l'appelant authentifié au contexte
+func WithCaller(ctx context.Context, c *Caller) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// CallerFromContext retourne l'appelant authentifié de l'appel, ou nil
+func CallerFromContext(ctx context.Context) *Caller {
+	c, _ := ctx.Value(contextKey{}).(*Caller)
+	return c
+}
+
+// Tenant retourne le locataire au nom duquel l'appelant agit : le locataire demandé pour un
+// service de confiance, celui de son certificat sinon. Un locataire demandé différent de
+// celui du certificat est refusé ; vide, il désigne le locataire de l'appelant. Un
+// certificat sans locataire, ou dont le locataire est inutilisable, ne donne aucun droit.
+func Tenant(ctx context.Context, requested string) (string, error) {
+	if err := domain.ValidateTenant(requested); err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+	c := CallerFromContext(ctx)
+	switch {
+	case c == nil || (c.Name == "" && !c.Service):
+		return "", status.Error(codes.Unauthenticated, "client certificate required")
+	case c.Service:
+		return requested, nil
+	case c.Tenant == "":
+		return "", status.Errorf(codes.PermissionDenied, "client certificate %q carries no tenant", c.Name)
+	case domain.ValidateTenant(c.Tenant) != nil:
+		return "", status.Errorf(codes.PermissionDenied, "client certificate %q carries an invalid tenant %q", c.Name, c.Tenant)
+	case requested != "" && requested != c.Tenant:
+		return "", status.Errorf(codes.PermissionDenied, "client %q may not act for tenant %q", c.Name, requested)
+	}
+	return c.Tenant, nil
+}
+
+// Authenticator identifie l'appelant de chaque RPC par le certificat client vérifié par le
+// mTLS, ou par celui que relaie la passerelle REST. Le contrôle du locataire revient aux handlers (Tenant), seuls à lire la requête ;
+// les appels sans certificat passent donc ici.
+type Authenticator struct {
+	services map[string]bool
+	gateway  string
+	trust    bool
+	logger   *slog.Logger
+}
+
+// NewAuthenticator crée un authentificateur
+func NewAuthenticator(cfg Config, logger *slog.Logger) *Authenticator {
+	a := &Authenticator{
+		services: make(map[string]bool, len(cfg.ServiceClients)),
+		gateway:  cfg.GatewayClient,
+		trust:    cfg.TrustUnauthenticated,
+		logger:   logger.With("component", "Authenticator"),
+	}
+	for _, name := range cfg.ServiceClients {
+		a.services[name] = true
+	}
+	return a
+}
+
+// UnaryServerInterceptor identifie l'appelant de chaque appel unaire
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		caller, err := a.identify(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(WithCaller(ctx, caller), req)
+	}
+}
+
+// StreamServerInterceptor identifie l'appelant de chaque appel en flux
+func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		caller, err := a.identify(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &callerStream{ServerStream: ss, ctx: WithCaller(ss.Context(), caller)})
+	}
+}
+
+// identify retourne l'appelant de la connexion. Le certificat relayé n'est lu que si la
+// connexion vient de la passerelle : un autre client ne peut pas usurper une identité.
+func (a *Authenticator) identify(ctx context.Context, method string) (*Caller, error) {
+	cert := peerCertificate(ctx)
+	if cert == nil || a.gateway == "" || cert.Subject.CommonName != a.gateway {
+		return a.caller(cert), nil
+	}
+	// La passerelle n'a aucun droit propre : sans certificat relayé, son client est traité
+	// comme un appelant sans certificat
+	forwarded, err := forwardedCertificate(ctx)
+	if err != nil {
+		a.logger.WarnContext(ctx, "Rejected forwarded client certificate", "method", method, "error", err)
+		return nil, status.Error(codes.Unauthenticated, "invalid forwarded client certificate")
+	}
+	return a.caller(forwarded), nil
+}
+
+// caller associe un certificat client, éventuellement absent, à son appelant
+func (a *Authenticator) caller(cert *x509.Certificate) *Caller {
+	switch {
+	case cert == nil && a.trust:
+		return &Caller{Service: true}
+	case cert == nil:
+		return &Caller{}
+	case a.services[cert.Subject.CommonName]:
+		return &Caller{Name: cert.Subject.CommonName, Service: true}
+	}
+	caller := &Caller{Name: cert.Subject.CommonName}
+	if len(cert.Subject.Organization) > 0 {
+		caller.Tenant = cert.Subject.Organization[0]
+	}
+	return caller
+}
+
+// peerCertificate retourne le certificat client vérifié de la connexion, ou nil
+func peerCertificate(ctx context.Context) *x509.Certificate {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return info.State.VerifiedChains[0][0]
+}
+
+// forwardedCertificate décode le certificat relayé par la passerelle, nil s'il n'y en a pas
+func forwardedCertificate(ctx context.Context) (*x509.Certificate, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(ForwardedCertHeader)
+	switch len(values) {
+	case 0:
+		return nil, nil
+	case 1:
+	default:
+		return nil, errors.New("several forwarded client certificates")
+	}
+	der, err := base64.StdEncoding.DecodeString(values[0])
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+// EncodeCertificate encode un certificat pour la métadonnée ForwardedCertHeader
+func EncodeCertificate(cert *x509.Certificate) string {
+	return base64.StdEncoding.EncodeToString(cert.Raw)
+}
+
+// callerStream substitue le contexte authentifié à celui du flux
+type callerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *callerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"log/slog"
+	"math/big"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// discard est le logger des tests
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// newCertificate crée un certificat autosigné au nom commun name, d'organisations orgs
+func newCertificate(t *testing.T, name string, orgs ...string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name, Organization: orgs},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// withPeer simule une connexion mTLS dont le certificat client vérifié est cert
+func withPeer(ctx context.Context, cert *x509.Certificate) context.Context {
+	return peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{
+		State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+	}})
+}
+
+// tenantOf authentifie l'appelant de ctx puis retourne le locataire pour lequel il agit
+func tenantOf(t *testing.T, a *Authenticator, ctx context.Context, requested string) (string, error) {
+	t.Helper()
+	caller, err := a.identify(ctx, "/impression.ImpressionService/GetImpressionCount")
+	if err != nil {
+		return "", err
+	}
+	return Tenant(WithCaller(ctx, caller), requested)
+}
+
+func TestTenantFromCertificate(t *testing.T) {
+	a := NewAuthenticator(Config{ServiceClients: []string{"adserver"}, GatewayClient: "gateway"}, discard)
+
+	tests := []struct {
+		name      string
+		cert      *x509.Certificate
+		requested string
+		want      string
+		wantCode  codes.Code
+	}{
+		{name: "certificate tenant", cert: newCertificate(t, "acme-portal", "acme"), want: "acme"},
+		{name: "first organization", cert: newCertificate(t, "acme-portal", "acme", "globex"), want: "acme"},
+		{name: "certificate tenant named", cert: newCertificate(t, "acme-portal", "acme"), requested: "acme", want: "acme"},
+		{name: "other tenant", cert: newCertificate(t, "acme-portal", "acme"), requested: "globex", wantCode: codes.PermissionDenied},
+		{name: "no organization", cert: newCertificate(t, "orphan"), wantCode: codes.PermissionDenied},
+		{name: "empty organization", cert: newCertificate(t, "orphan", ""), wantCode: codes.PermissionDenied},
+		// Le locataire du certificat servirait de segment de clé de cache
+		{name: "organization with a colon", cert: newCertificate(t, "mallory", "acme:globex"), wantCode: codes.PermissionDenied},
+		{name: "organization with a colon named", cert: newCertificate(t, "mallory", "acme:globex"), requested: "acme:globex", wantCode: codes.InvalidArgument},
+		{name: "service", cert: newCertificate(t, "adserver"), requested: "globex", want: "globex"},
+		{name: "service ignores its organization", cert: newCertificate(t, "adserver", "a:b"), requested: "acme", want: "acme"},
+		{name: "no certificate", wantCode: codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.cert != nil {
+				ctx = withPeer(ctx, tt.cert)
+			}
+			got, err := tenantOf(t, a, ctx, tt.requested)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Tenant() error = %v, want %v", err, tt.wantCode)
+			}
+			if got != tt.want {
+				t.Errorf("Tenant() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForwardedCertificateTenantIsValidated(t *testing.T) {
+	a := NewAuthenticator(Config{GatewayClient: "gateway"}, discard)
+	gateway := withPeer(context.Background(), newCertificate(t, "gateway"))
+	forwarding := func(cert *x509.Certificate) context.Context {
+		return metadata.NewIncomingContext(gateway, metadata.Pairs(ForwardedCertHeader, EncodeCertificate(cert)))
+	}
+
+	if got, err := tenantOf(t, a, forwarding(newCertificate(t, "acme-portal", "acme")), ""); err != nil || got != "acme" {
+		t.Errorf("Tenant() for a forwarded acme certificate = %q, %v, want acme", got, err)
+	}
+	for _, orgs := range [][]string{nil, {""}, {"acme:globex"}} {
+		if _, err := tenantOf(t, a, forwarding(newCertificate(t, "mallory", orgs...)), ""); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("Tenant() for a forwarded certificate of organizations %q error = %v, want PermissionDenied", orgs, err)
+		}
+	}
+}
+
+func TestTrustUnauthenticated(t *testing.T) {
+	a := NewAuthenticator(Config{TrustUnauthenticated: true}, discard)
+	if got, err := tenantOf(t, a, context.Background(), "acme"); err != nil || got != "acme" {
+		t.Errorf("Tenant() without certificate = %q, %v, want acme", got, err)
+	}
+	// Un certificat présenté reste soumis à la règle de son locataire
+	if _, err := tenantOf(t, a, withPeer(context.Background(), newCertificate(t, "mallory", "a:b")), ""); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Tenant() for an invalid certificate tenant error = %v, want PermissionDenied", err)
+	}
+}
@@ -1,56 +1,388 @@
 package handler
 
 import (
+	"bufio"
 	"context"
-	"log"
+	"errors"
+	"log/slog"
+	"time"
 
-	"impression-tracker/generated/impression_service"
+	"impression-tracker/internal/adapters/export"
+	"impression-tracker/internal/adapters/grpc/auth"
+	"impression-tracker/internal/adapters/healthcheck"
+	"impression-tracker/internal/application"
+	"impression-tracker/internal/domain"
 	"impression-tracker/internal/ports/in"
+	"impression-tracker/internal/ports/out"
+	"shared/generated/impression_service"
 
+	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// maxTotalsAdIDs limite le nombre de publicités par requête GetImpressionTotals
+const maxTotalsAdIDs = 500
+
+// openEnd est la fin des périodes sans borne : les totaux comprennent alors les impressions
+// en attente de synchronisation
+var openEnd = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// exportChunkSize est la taille maximale des morceaux envoyés lors d'un export
+const exportChunkSize = 32 * 1024
+
+// eventTypes associe les types d'événements protobuf aux types du domaine
+var eventTypes = map[impression_service.EventType]domain.EventType{
+	impression_service.EventType_EVENT_TYPE_IMPRESSION: domain.EventImpression,
+	impression_service.EventType_EVENT_TYPE_RENDERED:   domain.EventRendered,
+	impression_service.EventType_EVENT_TYPE_VIEWABLE:   domain.EventViewable,
+	impression_service.EventType_EVENT_TYPE_HOVER:      domain.EventHover,
+	impression_service.EventType_EVENT_TYPE_CLOSE:      domain.EventClose,
+}
+
+// exportFormats associe les formats protobuf aux encodeurs d'export
+var exportFormats = map[impression_service.ExportFormat]export.Format{
+	impression_service.ExportFormat_EXPORT_FORMAT_NDJSON:  export.FormatNDJSON,
+	impression_service.ExportFormat_EXPORT_FORMAT_CSV:     export.FormatCSV,
+	impression_service.ExportFormat_EXPORT_FORMAT_PARQUET: export.FormatParquet,
+}
+
 // Server gère les requêtes gRPC pour les impressions
 type Server struct {
 	impression_service.UnimplementedImpressionServiceServer
 	service in.ImpressionService
+	health  *healthcheck.Checker // Vérification des dépendances pour DeepHealth
+	clock   out.Clock            // Date de réception des impressions et fin par défaut des exports
+	logger  *slog.Logger
 }
 
 // NewServer crée un nouveau serveur gRPC
-func NewServer(service in.ImpressionService) *Server {
-	return &Server{service: service}
+func NewServer(service in.ImpressionService, health *healthcheck.Checker, clock out.Clock, logger *slog.Logger) *Server {
+	return &Server{service: service, health: health, clock: clock, logger: logger.With("component", "ImpressionHandler")}
 }
 
 // TrackImpression enregistre une nouvelle impression pour une publicité
 func (s *Server) TrackImpression(ctx context.Context, req *impression_service.TrackImpressionRequest) (*impression_service.TrackImpressionResponse, error) {
 	adID := req.GetAdId()
-	if adID == "" {
-		return nil, status.Error(codes.InvalidArgument, "ad_id is required")
+	if err := validateAdID(adID); err != nil {
+		return nil, err
+	}
+	ctx, err := withTenant(ctx, req.GetTenant())
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.service.Track(ctx, adID); err != nil {
-		log.Printf("[TrackImpression] service error: %v", err)
+	imp := s.newImpression(adID, req.GetImpressionId(), domain.ImpressionContext{
+		UserAgent: req.GetUserAgent(),
+		IPAddress: req.GetIpAddress(),
+		Referrer:  req.GetReferrer(),
+	})
+
+	if err = s.service.Track(ctx, imp); err != nil {
+		s.logger.ErrorContext(ctx, "TrackImpression service failed", "ad_id", adID, "error", err)
 		return nil, status.Errorf(codes.Internal, "failed to track impression: %v", err)
 	}
 
-	log.Printf("[TrackImpression] adID=%s incremented", adID)
+	s.logger.InfoContext(ctx, "TrackImpression completed", "ad_id", adID, "tenant", domain.TenantFromContext(ctx), "impression_id", imp.ID)
 	return &impression_service.TrackImpressionResponse{Success: true}, nil
 }
 
-// GetCount récupère le nombre d'impressions pour une publicité
-func (s *Server) GetCount(ctx context.Context, req *impression_service.GetImpressionCountRequest) (*impression_service.GetImpressionCountResponse, error) {
+// GetImpressionCount récupère le nombre d'impressions pour une publicité
+func (s *Server) GetImpressionCount(ctx context.Context, req *impression_service.GetImpressionCountRequest) (*impression_service.GetImpressionCountResponse, error) {
 	adID := req.GetAdId()
-	if adID == "" {
-		return nil, status.Error(codes.InvalidArgument, "ad_id is required")
+	if err := validateAdID(adID); err != nil {
+		return nil, err
+	}
+	ctx, err := withTenant(ctx, req.GetTenant())
+	if err != nil {
+		return nil, err
 	}
 
 	count, err := s.service.GetCount(ctx, adID)
 	if err != nil {
-		log.Printf("[GetCount] service error: %v", err)
+		s.logger.ErrorContext(ctx, "GetImpressionCount service failed", "ad_id", adID, "error", err)
 		return nil, status.Errorf(codes.Internal, "failed to get count: %v", err)
 	}
 
-	log.Printf("[GetCount] adID=%s count=%d", adID, count)
+	s.logger.InfoContext(ctx, "GetImpressionCount completed", "ad_id", adID, "count", count)
 	return &impression_service.GetImpressionCountResponse{Count: count}, nil
 }
+
+// TrackEvent enregistre un événement publicitaire.
+// Les impressions suivent le même chemin que TrackImpression (filtrage IVT, journal).
+func (s *Server) TrackEvent(ctx context.Context, req *impression_service.TrackEventRequest) (*impression_service.TrackEventResponse, error) {
+	adID := req.GetAdId()
+	if err := validateAdID(adID); err != nil {
+		return nil, err
+	}
+	eventType, ok := eventTypes[req.GetEventType()]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported event_type %v", req.GetEventType())
+	}
+
+	ctx, err := withTenant(ctx, req.GetTenant())
+	if err != nil {
+		return nil, err
+	}
+
+	clientCtx := domain.ImpressionContext{
+		UserAgent: req.GetUserAgent(),
+		IPAddress: req.GetIpAddress(),
+		Referrer:  req.GetReferrer(),
+	}
+
+	if eventType == domain.EventImpression {
+		err = s.service.Track(ctx, s.newImpression(adID, req.GetImpressionId(), clientCtx))
+	} else {
+		err = s.service.TrackEvent(ctx, domain.Event{
+			AdID:            adID,
+			ImpressionID:    req.GetImpressionId(),
+			Type:            eventType,
+			VisibleRatio:    req.GetVisibleRatio(),
+			VisibleDuration: time.Duration(req.GetVisibleDurationMs()) * time.Millisecond,
+			Context:         clientCtx,
+		})
+	}
+	switch {
+	case errors.Is(err, domain.ErrNotViewable), errors.Is(err, domain.ErrMissingImpressionID):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, application.ErrEventNotTracked):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		s.logger.ErrorContext(ctx, "TrackEvent service failed", "ad_id", adID, "event", eventType, "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to track event: %v", err)
+	}
+
+	s.logger.InfoContext(ctx, "TrackEvent completed", "ad_id", adID, "tenant", domain.TenantFromContext(ctx), "event", eventType)
+	return &impression_service.TrackEventResponse{Success: true}, nil
+}
+
+// GetViewabilityReport récupère les compteurs d'événements et le taux de visibilité d'une publicité
+func (s *Server) GetViewabilityReport(ctx context.Context, req *impression_service.GetViewabilityReportRequest) (*impression_service.GetViewabilityReportResponse, error) {
+	adID := req.GetAdId()
+	if err := validateAdID(adID); err != nil {
+		return nil, err
+	}
+	ctx, err := withTenant(ctx, req.GetTenant())
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.service.GetEngagementReport(ctx, adID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "GetViewabilityReport service failed", "ad_id", adID, "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to get viewability report: %v", err)
+	}
+
+	s.logger.InfoContext(ctx, "GetViewabilityReport completed", "ad_id", adID, "rendered", report.Rendered, "viewable", report.Viewable)
+	return &impression_service.GetViewabilityReportResponse{
+		AdId:            report.AdID,
+		Impressions:     report.Impressions,
+		Rendered:        report.Rendered,
+		Viewable:        report.Viewable,
+		Hovers:          report.Hovers,
+		Closes:          report.Closes,
+		ViewabilityRate: report.ViewabilityRate(),
+	}, nil
+}
+
+// GetTrafficReport récupère le trafic valide et invalide d'une publicité
+func (s *Server) GetTrafficReport(ctx context.Context, req *impression_service.GetTrafficReportRequest) (*impression_service.GetTrafficReportResponse, error) {
+	adID := req.GetAdId()
+	if err := validateAdID(adID); err != nil {
+		return nil, err
+	}
+	ctx, err := withTenant(ctx, req.GetTenant())
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.service.GetTrafficReport(ctx, adID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "GetTrafficReport service failed", "ad_id", adID, "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to get traffic report: %v", err)
+	}
+
+	s.logger.InfoContext(ctx, "GetTrafficReport completed", "ad_id", adID, "valid", report.Valid, "invalid", report.Invalid)
+	return &impression_service.GetTrafficReportResponse{
+		AdId:        report.AdID,
+		Valid:       report.Valid,
+		Invalid:     report.Invalid,
+		InvalidRate: report.InvalidRate(),
+	}, nil
+}
+
+// GetImpressionTotals récupère le trafic de plusieurs publicités sur une période
+func (s *Server) GetImpressionTotals(ctx context.Context, req *impression_service.GetImpressionTotalsRequest) (*impression_service.GetImpressionTotalsResponse, error) {
+	adIDs := req.GetAdIds()
+	if len(adIDs) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "ad_ids is required")
+	}
+	if len(adIDs) > maxTotalsAdIDs {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d ad_ids per request", maxTotalsAdIDs)
+	}
+	for _, adID := range adIDs {
+		if err := validateAdID(adID); err != nil {
+			return nil, err
+		}
+	}
+
+	from, to, err := period(req.GetFrom(), req.GetTo(), openEnd)
+	if err != nil {
+		return nil, err
+	}
+	if ctx, err = withTenant(ctx, req.GetTenant()); err != nil {
+		return nil, err
+	}
+
+	resp := &impression_service.GetImpressionTotalsResponse{}
+	for _, adID := range adIDs {
+		report, err := s.service.GetTrafficBetween(ctx, adID, from, to)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "GetImpressionTotals service failed", "ad_id", adID, "error", err)
+			return nil, status.Errorf(codes.Internal, "failed to get totals for ad %s: %v", adID, err)
+		}
+		resp.Totals = append(resp.Totals, &impression_service.AdImpressionTotal{
+			AdId:    adID,
+			Valid:   report.Valid,
+			Invalid: report.Invalid,
+		})
+	}
+
+	s.logger.InfoContext(ctx, "GetImpressionTotals completed", "ads", len(adIDs), "from", from, "to", to)
+	return resp, nil
+}
+
+// ExportImpressions diffuse les impressions brutes d'une période dans le format demandé
+func (s *Server) ExportImpressions(req *impression_service.ExportImpressionsRequest, stream impression_service.ImpressionService_ExportImpressionsServer) error {
+	format, ok := exportFormats[req.GetFormat()]
+	if !ok {
+		return status.Errorf(codes.InvalidArgument, "unsupported format %v", req.GetFormat())
+	}
+
+	from, to, err := period(req.GetFrom(), req.GetTo(), s.clock.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriterSize(&chunkWriter{stream: stream}, exportChunkSize)
+	enc, err := export.NewEncoder(format, out)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, err := withTenant(stream.Context(), req.GetTenant())
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	err = s.service.ExportImpressions(ctx, from, to, func(imp domain.Impression) error {
+		count++
+		return enc.Encode(imp)
+	})
+	if errors.Is(err, application.ErrEventLogDisabled) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		s.logger.ErrorContext(stream.Context(), "ExportImpressions service failed", "error", err)
+		return status.Errorf(codes.Internal, "failed to export impressions: %v", err)
+	}
+
+	if err := enc.Close(); err != nil {
+		return status.Errorf(codes.Internal, "failed to finalize export: %v", err)
+	}
+	if err := out.Flush(); err != nil {
+		return status.Errorf(codes.Internal, "failed to send export: %v", err)
+	}
+
+	s.logger.InfoContext(stream.Context(), "ExportImpressions completed", "count", count, "from", from, "to", to, "format", format)
+	return nil
+}
+
+// DeepHealth vérifie immédiatement chaque dépendance et retourne le détail.
+// Le statut grpc.health.v1 est mis à jour avec le résultat.
+func (s *Server) DeepHealth(ctx context.Context, req *impression_service.DeepHealthRequest) (*impression_service.DeepHealthResponse, error) {
+	results, serving := s.health.Run(ctx)
+
+	resp := &impression_service.DeepHealthResponse{Serving: serving}
+	for _, r := range results {
+		resp.Dependencies = append(resp.Dependencies, &impression_service.DependencyHealth{
+			Name:      r.Name,
+			Healthy:   r.Healthy,
+			Error:     r.Error,
+			Latency:   durationpb.New(r.Latency),
+			CheckedAt: timestamppb.New(r.CheckedAt),
+		})
+	}
+
+	s.logger.InfoContext(ctx, "DeepHealth completed", "serving", serving)
+	return resp, nil
+}
+
+// withTenant associe au contexte le locataire de la requête, vérifié contre l'identité de
+// l'appelant : un client ne peut agir que pour le locataire de son certificat.
+func withTenant(ctx context.Context, requested string) (context.Context, error) {
+	tenant, err := auth.Tenant(ctx, requested)
+	if err != nil {
+		return ctx, err
+	}
+	return domain.WithTenant(ctx, tenant), nil
+}
+
+// validateAdID vérifie l'identifiant de publicité d'une requête
+func validateAdID(adID string) error {
+	if adID == "" {
+		return status.Error(codes.InvalidArgument, "ad_id is required")
+	}
+	if err := domain.ValidateAdID(adID); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+// period convertit les bornes d'une période : origine des temps et defaultTo par défaut.
+func period(fromTS, toTS *timestamppb.Timestamp, defaultTo time.Time) (time.Time, time.Time, error) {
+	var from time.Time
+	if fromTS != nil {
+		from = fromTS.AsTime()
+	}
+	to := defaultTo
+	if toTS != nil {
+		to = toTS.AsTime()
+	}
+	if !from.Before(to) {
+		return from, to, status.Error(codes.InvalidArgument, "from must be before to")
+	}
+	return from, to, nil
+}
+
+// newImpression construit une impression reçue maintenant, selon l'horloge du serveur.
+// Un identifiant est généré si l'appelant n'en fournit pas.
+func (s *Server) newImpression(adID, impressionID string, clientCtx domain.ImpressionContext) domain.Impression {
+	if impressionID == "" {
+		impressionID = uuid.New().String()
+	}
+	return domain.Impression{
+		ID:        impressionID,
+		AdID:      adID,
+		Timestamp: s.clock.Now().UTC(),
+		Context:   clientCtx,
+	}
+}
+
+// chunkWriter envoie chaque écriture comme un morceau du flux d'export
+type chunkWriter struct {
+	stream impression_service.ImpressionService_ExportImpressionsServer
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	data := make([]byte, len(p))
+	copy(data, p)
+	if err := w.stream.Send(&impression_service.ExportImpressionsChunk{Data: data}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,349 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"impression-tracker/internal/adapters/clock"
+	"impression-tracker/internal/adapters/grpc/auth"
+	"impression-tracker/internal/adapters/memory"
+	"impression-tracker/internal/application"
+	"impression-tracker/internal/domain"
+	"shared/generated/impression_service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// discard est le logger des tests
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// testCA est une autorité auto-signée générée pour le test
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key := newKey(t)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	cert := createCertificate(t, template, template, key, key)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+// issue signe un certificat client au nom commun name et à l'organisation tenant, ou un
+// certificat serveur pour localhost si server est vrai
+func (ca *testCA) issue(t *testing.T, name, tenant string, server bool) tls.Certificate {
+	t.Helper()
+	key := newKey(t)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if tenant != "" {
+		template.Subject.Organization = []string{tenant}
+	}
+	if server {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		template.DNSNames = []string{"localhost"}
+	}
+	cert := createCertificate(t, template, ca.cert, key, ca.key)
+	return tls.Certificate{Certificate: [][]byte{cert.Raw}, PrivateKey: key, Leaf: cert}
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func createCertificate(t *testing.T, template, parent *x509.Certificate, key, signer *ecdsa.PrivateKey) *x509.Certificate {
+	t.Helper()
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// mtlsServer est un tracker en mémoire servi en mTLS sur une connexion en mémoire ; le
+// certificat client y est facultatif pour que le handler voie les appelants anonymes
+type mtlsServer struct {
+	ca    *testCA
+	lis   *bufconn.Listener
+	clock *clock.Fake
+}
+
+func newMTLSServer(t *testing.T) *mtlsServer {
+	t.Helper()
+	ca := newTestCA(t)
+	fake := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	service := application.NewService(memory.NewCacheRepository(), memory.NewMetricsRepository(fake), fake, time.Minute, discard,
+		application.WithEventStore(memory.NewEventStore()))
+
+	authenticator := auth.NewAuthenticator(auth.Config{ServiceClients: []string{"adserver"}, GatewayClient: "gateway"}, discard)
+	server := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{ca.issue(t, "tracker", "", true)},
+			ClientCAs:    ca.pool,
+			ClientAuth:   tls.VerifyClientCertIfGiven,
+		})),
+		grpc.ChainUnaryInterceptor(authenticator.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(authenticator.StreamServerInterceptor()),
+	)
+	impression_service.RegisterImpressionServiceServer(server, NewServer(service, nil, fake, discard))
+	lis := bufconn.Listen(1 << 20)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return &mtlsServer{ca: ca, lis: lis, clock: fake}
+}
+
+// client ouvre une connexion présentant cert, ou aucun certificat si cert est nil
+func (s *mtlsServer) client(t *testing.T, cert *tls.Certificate) impression_service.ImpressionServiceClient {
+	t.Helper()
+	config := &tls.Config{RootCAs: s.ca.pool, ServerName: "localhost"}
+	if cert != nil {
+		config.Certificates = []tls.Certificate{*cert}
+	}
+	conn, err := grpc.NewClient("passthrough:///tracker",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return s.lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(credentials.NewTLS(config)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return impression_service.NewImpressionServiceClient(conn)
+}
+
+func TestTenantIsBoundToClientCertificate(t *testing.T) {
+	s := newMTLSServer(t)
+	acmeCert, globexCert := s.ca.issue(t, "acme-portal", "acme", false), s.ca.issue(t, "globex-portal", "globex", false)
+	adserverCert, orphanCert := s.ca.issue(t, "adserver", "", false), s.ca.issue(t, "orphan", "", false)
+	acme, globex := s.client(t, &acmeCert), s.client(t, &globexCert)
+	adserver, orphan, anonymous := s.client(t, &adserverCert), s.client(t, &orphanCert), s.client(t, nil)
+	ctx := context.Background()
+
+	// Sans locataire explicite, un client agit pour celui de son certificat
+	for _, tenant := range []string{"", "acme"} {
+		if _, err := acme.TrackImpression(ctx, &impression_service.TrackImpressionRequest{AdId: "ad", Tenant: tenant}); err != nil {
+			t.Fatalf("TrackImpression(tenant %q) as acme error: %v", tenant, err)
+		}
+	}
+	if _, err := adserver.TrackImpression(ctx, &impression_service.TrackImpressionRequest{AdId: "ad", Tenant: "globex"}); err != nil {
+		t.Fatalf("TrackImpression(globex) as adserver error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		client    impression_service.ImpressionServiceClient
+		tenant    string
+		wantCode  codes.Code
+		wantCount int64
+	}{
+		{name: "own tenant", client: acme, wantCount: 2},
+		{name: "own tenant named", client: acme, tenant: "acme", wantCount: 2},
+		{name: "other tenant", client: acme, tenant: "globex", wantCode: codes.PermissionDenied},
+		{name: "other tenant's own view", client: globex, wantCount: 1},
+		{name: "service for acme", client: adserver, tenant: "acme", wantCount: 2},
+		{name: "service for globex", client: adserver, tenant: "globex", wantCount: 1},
+		{name: "service for default tenant", client: adserver, wantCount: 0},
+		{name: "certificate without tenant", client: orphan, wantCode: codes.PermissionDenied},
+		{name: "no certificate", client: anonymous, tenant: "acme", wantCode: codes.Unauthenticated},
+		{name: "invalid tenant", client: adserver, tenant: "acme:globex", wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := tt.client.GetImpressionCount(ctx, &impression_service.GetImpressionCountRequest{AdId: "ad", Tenant: tt.tenant})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("GetImpressionCount() error = %v, want %v", err, tt.wantCode)
+			}
+			if err == nil && resp.GetCount() != tt.wantCount {
+				t.Errorf("GetImpressionCount() = %d, want %d", resp.GetCount(), tt.wantCount)
+			}
+		})
+	}
+
+	// Les écritures et les flux suivent la même règle
+	if _, err := globex.TrackImpression(ctx, &impression_service.TrackImpressionRequest{AdId: "ad", Tenant: "acme"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("TrackImpression(acme) as globex error = %v, want PermissionDenied", err)
+	}
+	stream, err := acme.ExportImpressions(ctx, &impression_service.ExportImpressionsRequest{Tenant: "globex", Format: impression_service.ExportFormat_EXPORT_FORMAT_NDJSON})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("ExportImpressions(globex) as acme error = %v, want PermissionDenied", err)
+	}
+}
+
+func TestGatewayForwardsClientCertificate(t *testing.T) {
+	s := newMTLSServer(t)
+	gatewayCert, acmeCert := s.ca.issue(t, "gateway", "", false), s.ca.issue(t, "acme-portal", "acme", false)
+	adserverCert := s.ca.issue(t, "adserver", "", false)
+	gateway, acme := s.client(t, &gatewayCert), s.client(t, &acmeCert)
+	adserver := s.client(t, &adserverCert)
+	forwarding := func(cert tls.Certificate) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), auth.ForwardedCertHeader, auth.EncodeCertificate(cert.Leaf))
+	}
+
+	if _, err := adserver.TrackImpression(context.Background(), &impression_service.TrackImpressionRequest{AdId: "ad", Tenant: "globex"}); err != nil {
+		t.Fatalf("TrackImpression(globex) as adserver error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		client   impression_service.ImpressionServiceClient
+		ctx      context.Context
+		tenant   string
+		wantCode codes.Code
+	}{
+		{name: "forwarded client's tenant", client: gateway, ctx: forwarding(acmeCert), tenant: "acme"},
+		{name: "forwarded client for another tenant", client: gateway, ctx: forwarding(acmeCert), tenant: "globex", wantCode: codes.PermissionDenied},
+		{name: "gateway without forwarded certificate", client: gateway, ctx: context.Background(), tenant: "globex", wantCode: codes.Unauthenticated},
+		{name: "invalid forwarded certificate", client: gateway, ctx: metadata.AppendToOutgoingContext(context.Background(), auth.ForwardedCertHeader, "bm90IGEgY2VydA=="), wantCode: codes.Unauthenticated},
+		// Seule la passerelle peut relayer un certificat : celui d'un service est ignoré
+		{name: "spoofed forwarded certificate", client: acme, ctx: forwarding(adserverCert), tenant: "globex", wantCode: codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.client.GetImpressionCount(tt.ctx, &impression_service.GetImpressionCountRequest{AdId: "ad", Tenant: tt.tenant})
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("GetImpressionCount() error = %v, want %v", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestImpressionsFollowServerClock(t *testing.T) {
+	s := newMTLSServer(t)
+	adserverCert := s.ca.issue(t, "adserver", "", false)
+	adserver := s.client(t, &adserverCert)
+	ctx := context.Background()
+	received := s.clock.Now()
+
+	if _, err := adserver.TrackImpression(ctx, &impression_service.TrackImpressionRequest{AdId: "ad", Tenant: "acme", ImpressionId: "imp"}); err != nil {
+		t.Fatalf("TrackImpression() error: %v", err)
+	}
+	export := func() []domain.Impression {
+		t.Helper()
+		stream, err := adserver.ExportImpressions(ctx, &impression_service.ExportImpressionsRequest{Tenant: "acme", Format: impression_service.ExportFormat_EXPORT_FORMAT_NDJSON})
+		if err != nil {
+			t.Fatalf("ExportImpressions() error: %v", err)
+		}
+		var data bytes.Buffer
+		for {
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Recv() error: %v", err)
+			}
+			data.Write(chunk.GetData())
+		}
+		var imps []domain.Impression
+		for dec := json.NewDecoder(&data); dec.More(); {
+			var imp domain.Impression
+			if err := dec.Decode(&imp); err != nil {
+				t.Fatalf("decode export: %v", err)
+			}
+			imps = append(imps, imp)
+		}
+		return imps
+	}
+
+	// Sans borne, l'export s'arrête à l'heure du serveur, exclue
+	if imps := export(); len(imps) != 0 {
+		t.Fatalf("export at the reception time = %v, want none", imps)
+	}
+	s.clock.Advance(time.Second)
+	imps := export()
+	if len(imps) != 1 || imps[0].ID != "imp" || !imps[0].Timestamp.Equal(received) {
+		t.Errorf("export = %+v, want imp received at %v", imps, received)
+	}
+}
+
+func TestAdIDIsValidated(t *testing.T) {
+	s := newMTLSServer(t)
+	adserverCert := s.ca.issue(t, "adserver", "", false)
+	adserver := s.client(t, &adserverCert)
+	ctx := context.Background()
+
+	for _, adID := range []string{"", "acme:ad"} {
+		calls := map[string]func() error{
+			"TrackImpression": func() error {
+				_, err := adserver.TrackImpression(ctx, &impression_service.TrackImpressionRequest{AdId: adID, Tenant: "acme"})
+				return err
+			},
+			"TrackEvent": func() error {
+				_, err := adserver.TrackEvent(ctx, &impression_service.TrackEventRequest{AdId: adID, Tenant: "acme", ImpressionId: "imp", EventType: impression_service.EventType_EVENT_TYPE_RENDERED})
+				return err
+			},
+			"GetImpressionCount": func() error {
+				_, err := adserver.GetImpressionCount(ctx, &impression_service.GetImpressionCountRequest{AdId: adID, Tenant: "acme"})
+				return err
+			},
+			"GetViewabilityReport": func() error {
+				_, err := adserver.GetViewabilityReport(ctx, &impression_service.GetViewabilityReportRequest{AdId: adID, Tenant: "acme"})
+				return err
+			},
+			"GetTrafficReport": func() error {
+				_, err := adserver.GetTrafficReport(ctx, &impression_service.GetTrafficReportRequest{AdId: adID, Tenant: "acme"})
+				return err
+			},
+			"GetImpressionTotals": func() error {
+				_, err := adserver.GetImpressionTotals(ctx, &impression_service.GetImpressionTotalsRequest{AdIds: []string{"ad", adID}, Tenant: "acme"})
+				return err
+			},
+		}
+		for name, call := range calls {
+			t.Run(fmt.Sprintf("%s(%q)", name, adID), func(t *testing.T) {
+				if err := call(); status.Code(err) != codes.InvalidArgument {
+					t.Errorf("%s(ad_id %q) error = %v, want InvalidArgument", name, adID, err)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package healthcheck
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Check vérifie une dépendance et retourne une erreur si elle est indisponible.
+type Check func(ctx context.Context) error
+
+// Result décrit l'état d'une dépendance lors d'une vérification.
+type Result struct {
+	Name      string
+	Healthy   bool
+	Error     string
+	Latency   time.Duration
+	CheckedAt time.Time
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker vérifie périodiquement les dépendances du service et publie le statut
+// correspondant sur le service standard grpc.health.v1 : SERVING si toutes les
+// dépendances répondent, NOT_SERVING dès que l'une d'elles est perdue.
+type Checker struct {
+	server   *health.Server
+	services []string // Services gRPC dont le statut suit celui des dépendances ("" = serveur entier)
+	interval time.Duration
+	timeout  time.Duration
+	logger   *slog.Logger
+
+	checks []namedCheck
+
+	mu      sync.Mutex
+	serving bool
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewChecker crée un vérificateur publiant sur server le statut des services donnés
+// en plus du statut global. Le statut reste NOT_SERVING jusqu'à la première vérification.
+func NewChecker(server *health.Server, interval, timeout time.Duration, logger *slog.Logger, services ...string) *Checker {
+	c := &Checker{
+		server:   server,
+		services: append([]string{""}, services...),
+		interval: interval,
+		timeout:  timeout,
+		logger:   logger.With("component", "HealthChecker"),
+		stopChan: make(chan struct{}),
+	}
+	c.publish(false)
+	return c
+}
+
+// Register ajoute une dépendance à vérifier. Doit être appelée avant Start.
+func (c *Checker) Register(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Start effectue une première vérification puis lance la vérification périodique.
+func (c *Checker) Start() {
+	c.Run(context.Background())
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.Run(context.Background())
+			case <-c.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop arrête la vérification périodique et passe tous les services à NOT_SERVING,
+// afin que les clients cessent d'envoyer du trafic pendant l'arrêt.
+func (c *Checker) Stop() {
+	close(c.stopChan)
+	c.wg.Wait()
+	c.server.Shutdown()
+}
+
+// Run vérifie immédiatement toutes les dépendances en parallèle, met à jour le statut
+// publié et retourne le détail par dépendance.
+func (c *Checker) Run(ctx context.Context) ([]Result, bool) {
+	results := make([]Result, len(c.checks))
+	var wg sync.WaitGroup
+	for i, nc := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, nc)
+		}()
+	}
+	wg.Wait()
+
+	serving := true
+	for _, r := range results {
+		serving = serving && r.Healthy
+	}
+
+	c.mu.Lock()
+	previous := c.serving
+	c.serving = serving
+	c.mu.Unlock()
+
+	if serving != previous {
+		if serving {
+			c.logger.InfoContext(ctx, "All dependencies are healthy, serving")
+		} else {
+			c.logger.WarnContext(ctx, "Dependency lost, not serving", "dependencies", failing(results))
+		}
+	}
+	c.publish(serving)
+	return results, serving
+}
+
+// run exécute une vérification avec le délai configuré
+func (c *Checker) run(ctx context.Context, nc namedCheck) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := nc.check(ctx)
+	r := Result{
+		Name:      nc.name,
+		Healthy:   err == nil,
+		Latency:   time.Since(start),
+		CheckedAt: start,
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	return r
+}
+
+// publish met à jour le statut de tous les services suivis
+func (c *Checker) publish(serving bool) {
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if serving {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	for _, service := range c.services {
+		c.server.SetServingStatus(service, status)
+	}
+}
+
+// failing retourne les noms des dépendances en échec
+func failing(results []Result) []string {
+	var names []string
+	for _, r := range results {
+		if !r.Healthy {
+			names = append(names, r.Name)
+		}
+	}
+	return names
+}
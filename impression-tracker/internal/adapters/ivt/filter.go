@@ -0,0 +1,256 @@
+package ivt
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"impression-tracker/internal/domain"
+	"impression-tracker/internal/ports/out"
+)
+
+// defaultBotPatterns liste des fragments de User-Agent de robots et clients scriptés connus.
+// La comparaison est faite en minuscules.
+var defaultBotPatterns = []string{
+	"bot", "crawler", "spider", "slurp", "archiver", "facebookexternalhit",
+	"headlesschrome", "phantomjs", "puppeteer", "playwright", "selenium",
+	"curl/", "wget/", "python-requests", "python-urllib", "aiohttp", "go-http-client",
+	"java/", "okhttp", "libwww-perl", "httpclient", "scrapy",
+}
+
+// Config regroupe les sources de données du filtre.
+// Les chemins de fichiers vides sont ignorés.
+type Config struct {
+	BotUserAgentsFile string        // Fragments de User-Agent supplémentaires, un par ligne
+	IPBlocklistFile   string        // Adresses IP ou plages CIDR bloquées, une par ligne
+	DataCenterFile    string        // Plages CIDR des centres de données, une par ligne
+	RateLimit         int           // Nombre maximal d'impressions par IP et par fenêtre (0 = désactivé)
+	RateWindow        time.Duration // Durée de la fenêtre de comptage par IP
+}
+
+// Filter implémente l'interface TrafficFilter en enchaînant plusieurs vérifications :
+// IP bloquée, IP de centre de données, robot connu puis volume anormal par IP.
+type Filter struct {
+	botPatterns []string
+	blocklist   []netip.Prefix
+	dataCenters []netip.Prefix
+	rateLimit   int
+	rateWindow  time.Duration
+
+	mu      sync.Mutex
+	windows map[string]*ipWindow // Compteurs par IP pour la détection d'anomalies de volume
+}
+
+// ipWindow compte les impressions d'une IP sur une fenêtre fixe
+type ipWindow struct {
+	start time.Time
+	count int
+}
+
+// maxTrackedIPs déclenche la purge des fenêtres expirées au-delà de ce nombre d'IP suivies
+const maxTrackedIPs = 100_000
+
+// NewFilter crée un filtre de trafic invalide à partir des fichiers de configuration.
+func NewFilter(cfg Config) (*Filter, error) {
+	f := &Filter{
+		botPatterns: append([]string(nil), defaultBotPatterns...),
+		rateLimit:   cfg.RateLimit,
+		rateWindow:  cfg.RateWindow,
+		windows:     make(map[string]*ipWindow),
+	}
+
+	if cfg.BotUserAgentsFile != "" {
+		patterns, err := readLines(cfg.BotUserAgentsFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range patterns {
+			f.botPatterns = append(f.botPatterns, strings.ToLower(p))
+		}
+	}
+
+	var err error
+	if f.blocklist, err = readPrefixes(cfg.IPBlocklistFile); err != nil {
+		return nil, err
+	}
+	if f.dataCenters, err = readPrefixes(cfg.DataCenterFile); err != nil {
+		return nil, err
+	}
+
+	if f.rateLimit > 0 && f.rateWindow <= 0 {
+		return nil, fmt.Errorf("rate window must be positive when rate limit is set")
+	}
+	return f, nil
+}
+
+// Inspect retourne la raison pour laquelle l'impression est considérée comme invalide,
+// ou domain.IVTNone si elle passe toutes les vérifications.
+func (f *Filter) Inspect(ctx context.Context, imp domain.Impression) domain.IVTReason {
+	addr, hasAddr := parseAddr(imp.Context.IPAddress)
+
+	// Le volume par IP est compté pour toutes les impressions, même déjà invalides,
+	// afin que la fenêtre reflète le trafic réel de l'adresse
+	rateExceeded := hasAddr && f.exceedsRate(addr.String(), imp.Timestamp)
+
+	if reason := f.inspectContext(imp.Context); reason != domain.IVTNone {
+		return reason
+	}
+	if rateExceeded {
+		return domain.IVTRateAnomaly
+	}
+	return domain.IVTNone
+}
+
+// InspectEvent retourne la raison pour laquelle l'événement est considéré comme invalide.
+// Seul le contexte client est vérifié : un affichage légitime produisant plusieurs événements,
+// ceux-ci ne comptent pas dans le volume par IP des impressions.
+func (f *Filter) InspectEvent(ctx context.Context, event domain.Event) domain.IVTReason {
+	return f.inspectContext(event.Context)
+}
+
+// inspectContext vérifie l'IP puis le User-Agent du client
+func (f *Filter) inspectContext(c domain.ImpressionContext) domain.IVTReason {
+	addr, hasAddr := parseAddr(c.IPAddress)
+	switch {
+	case hasAddr && containsAddr(f.blocklist, addr):
+		return domain.IVTBlockedIP
+	case hasAddr && containsAddr(f.dataCenters, addr):
+		return domain.IVTDataCenter
+	case f.isKnownBot(c.UserAgent):
+		return domain.IVTKnownBot
+	}
+	return domain.IVTNone
+}
+
+// isKnownBot vérifie si le User-Agent correspond à un robot connu.
+// Un User-Agent absent n'est pas considéré comme suspect.
+func (f *Filter) isKnownBot(userAgent string) bool {
+	if userAgent == "" {
+		return false
+	}
+	ua := strings.ToLower(userAgent)
+	for _, pattern := range f.botPatterns {
+		if strings.Contains(ua, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// exceedsRate incrémente le compteur de l'IP et indique si la limite de la fenêtre est dépassée.
+func (f *Filter) exceedsRate(ip string, now time.Time) bool {
+	if f.rateLimit <= 0 {
+		return false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.windows) > maxTrackedIPs {
+		f.purge(now)
+	}
+
+	w, ok := f.windows[ip]
+	if !ok || now.Sub(w.start) >= f.rateWindow {
+		w = &ipWindow{start: now}
+		f.windows[ip] = w
+	}
+	w.count++
+	return w.count > f.rateLimit
+}
+
+// purge supprime les fenêtres expirées. Doit être appelée avec f.mu verrouillé.
+func (f *Filter) purge(now time.Time) {
+	for ip, w := range f.windows {
+		if now.Sub(w.start) >= f.rateWindow {
+			delete(f.windows, ip)
+		}
+	}
+}
+
+// parseAddr analyse une adresse IP, en ignorant un éventuel port
+func parseAddr(s string) (netip.Addr, bool) {
+	if s == "" {
+		return netip.Addr{}, false
+	}
+	if ap, err := netip.ParseAddrPort(s); err == nil {
+		return ap.Addr().Unmap(), true
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// containsAddr vérifie si l'adresse appartient à l'une des plages
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// readPrefixes charge une liste d'adresses IP ou de plages CIDR depuis un fichier
+func readPrefixes(path string) ([]netip.Prefix, error) {
+	if path == "" {
+		return nil, nil
+	}
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	prefixes := make([]netip.Prefix, 0, len(lines))
+	for _, line := range lines {
+		if strings.Contains(line, "/") {
+			p, err := netip.ParsePrefix(line)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid CIDR %q: %w", path, line, err)
+			}
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid IP address %q: %w", path, line, err)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes, nil
+}
+
+// readLines lit les lignes non vides d'un fichier, en ignorant les commentaires (#)
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return lines, nil
+}
+
+// Ensure Filter implements the TrafficFilter interface
+var _ out.TrafficFilter = (*Filter)(nil)
@@ -0,0 +1,131 @@
+package ivt
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"impression-tracker/internal/domain"
+)
+
+// writeFile écrit un fichier de configuration du filtre dans un répertoire temporaire
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newTestFilter(t *testing.T) *Filter {
+	t.Helper()
+	f, err := NewFilter(Config{
+		BotUserAgentsFile: writeFile(t, "bots.txt", "# partenaires scriptés\nacme-fetcher\n"),
+		IPBlocklistFile:   writeFile(t, "blocklist.txt", "203.0.113.9\n198.51.100.0/24 # réseau de fraude\n"),
+		DataCenterFile:    writeFile(t, "datacenters.txt", "192.0.2.0/24\n"),
+		RateLimit:         3,
+		RateWindow:        time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func impression(ip, userAgent string, at time.Time) domain.Impression {
+	return domain.Impression{
+		AdID:      "ad",
+		Timestamp: at,
+		Context:   domain.ImpressionContext{IPAddress: ip, UserAgent: userAgent},
+	}
+}
+
+func TestInspect(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	browser := "Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0"
+	tests := []struct {
+		name string
+		imp  domain.Impression
+		want domain.IVTReason
+	}{
+		{name: "valid", imp: impression("8.8.4.4", browser, now), want: domain.IVTNone},
+		{name: "blocked address", imp: impression("203.0.113.9", browser, now), want: domain.IVTBlockedIP},
+		{name: "blocked address with port", imp: impression("203.0.113.9:41000", browser, now), want: domain.IVTBlockedIP},
+		{name: "blocked range", imp: impression("198.51.100.77", browser, now), want: domain.IVTBlockedIP},
+		{name: "IPv4-mapped blocked address", imp: impression("::ffff:203.0.113.9", browser, now), want: domain.IVTBlockedIP},
+		{name: "data center", imp: impression("192.0.2.44", browser, now), want: domain.IVTDataCenter},
+		{name: "default bot pattern", imp: impression("8.8.4.4", "Googlebot/2.1", now), want: domain.IVTKnownBot},
+		{name: "bot pattern from file", imp: impression("8.8.4.4", "ACME-Fetcher/1.0", now), want: domain.IVTKnownBot},
+		{name: "no user agent", imp: impression("8.8.4.4", "", now), want: domain.IVTNone},
+		// L'adresse est celle que l'adserver a retenue ; une liste X-Forwarded-For recopiée
+		// par un client n'est pas une adresse et ne masque pas le robot
+		{name: "forwarded list is not an address", imp: impression("1.1.1.1, 8.8.8.8", "curl/8.5.0", now), want: domain.IVTKnownBot},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newTestFilter(t).Inspect(context.Background(), tt.imp); got != tt.want {
+				t.Errorf("Inspect() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInspectRateAnomaly(t *testing.T) {
+	f := newTestFilter(t)
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	browser := "Mozilla/5.0"
+
+	for i := 0; i < 3; i++ {
+		if got := f.Inspect(context.Background(), impression("8.8.4.4", browser, start.Add(time.Duration(i)*time.Second))); got != domain.IVTNone {
+			t.Fatalf("impression %d: Inspect() = %q, want valid", i, got)
+		}
+	}
+	if got := f.Inspect(context.Background(), impression("8.8.4.4", browser, start.Add(10*time.Second))); got != domain.IVTRateAnomaly {
+		t.Errorf("4th impression in window: Inspect() = %q, want %q", got, domain.IVTRateAnomaly)
+	}
+	if got := f.Inspect(context.Background(), impression("9.9.9.9", browser, start.Add(10*time.Second))); got != domain.IVTNone {
+		t.Errorf("other address: Inspect() = %q, want valid", got)
+	}
+	if got := f.Inspect(context.Background(), impression("8.8.4.4", browser, start.Add(time.Minute))); got != domain.IVTNone {
+		t.Errorf("next window: Inspect() = %q, want valid", got)
+	}
+}
+
+func TestNewFilterRejectsInvalidFiles(t *testing.T) {
+	if _, err := NewFilter(Config{IPBlocklistFile: writeFile(t, "blocklist.txt", "not-an-ip\n")}); err == nil {
+		t.Error("NewFilter() accepted an invalid blocklist entry")
+	}
+	if _, err := NewFilter(Config{DataCenterFile: writeFile(t, "dc.txt", "10.0.0.0/33\n")}); err == nil {
+		t.Error("NewFilter() accepted an invalid CIDR")
+	}
+	if _, err := NewFilter(Config{RateLimit: 10}); err == nil {
+		t.Error("NewFilter() accepted a rate limit without window")
+	}
+}
+
+func TestInspectEvent(t *testing.T) {
+	f := newTestFilter(t)
+	event := func(ip, userAgent string) domain.Event {
+		return domain.Event{AdID: "ad", ImpressionID: "imp", Type: domain.EventViewable, Context: domain.ImpressionContext{IPAddress: ip, UserAgent: userAgent}}
+	}
+
+	if got := f.InspectEvent(context.Background(), event("203.0.113.9", "Mozilla/5.0")); got != domain.IVTBlockedIP {
+		t.Errorf("blocked address: InspectEvent() = %q, want %q", got, domain.IVTBlockedIP)
+	}
+	if got := f.InspectEvent(context.Background(), event("8.8.4.4", "HeadlessChrome/120.0")); got != domain.IVTKnownBot {
+		t.Errorf("headless browser: InspectEvent() = %q, want %q", got, domain.IVTKnownBot)
+	}
+
+	// Les événements d'un affichage légitime ne comptent pas dans le volume par IP
+	for i := 0; i < 10; i++ {
+		if got := f.InspectEvent(context.Background(), event("8.8.4.4", "Mozilla/5.0")); got != domain.IVTNone {
+			t.Fatalf("event %d: InspectEvent() = %q, want valid", i, got)
+		}
+	}
+	if got := f.Inspect(context.Background(), impression("8.8.4.4", "Mozilla/5.0", time.Now())); got != domain.IVTNone {
+		t.Errorf("impression after events: Inspect() = %q, want valid", got)
+	}
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"impression-tracker/internal/adapters/metrics"
+	"impression-tracker/internal/adapters/tracing"
+	"impression-tracker/internal/domain"
+	"impression-tracker/internal/ports/out"
+)
+
+// CacheRepository implémente l'interface CacheRepository en mémoire, pour les tests et le
+// mode développement (STORAGE=memory). Les compteurs sont perdus à l'arrêt du processus.
+type CacheRepository struct {
+	mu       sync.Mutex
+	counters map[domain.CounterKey]int64
+	fences   map[domain.CounterKey]int64 // Plus récent jeton de fencing ayant réinitialisé le compteur
+}
+
+// NewCacheRepository crée un cache de compteurs vide
+func NewCacheRepository() *CacheRepository {
+	return &CacheRepository{counters: make(map[domain.CounterKey]int64), fences: make(map[domain.CounterKey]int64)}
+}
+
+// Increment incrémente le compteur de la publicité pour le locataire du contexte
+func (r *CacheRepository) Increment(ctx context.Context, adID string) (int64, error) {
+	defer metrics.ObserveRepository("memory", "Increment", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "Increment")
+	defer span.End()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := counterKey(ctx, adID)
+	r.counters[key]++
+	return r.counters[key], nil
+}
+
+// Get retourne le compteur de la publicité, 0 s'il n'existe pas
+func (r *CacheRepository) Get(ctx context.Context, adID string) (int64, error) {
+	defer metrics.ObserveRepository("memory", "Get", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "Get")
+	defer span.End()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counters[counterKey(ctx, adID)], nil
+}
+
+// Reset retourne le compteur de la publicité et le supprime, comme GETDEL, sauf si un jeton
+// de fencing plus récent l'a déjà réinitialisé
+func (r *CacheRepository) Reset(ctx context.Context, adID string, token int64) (int64, error) {
+	defer metrics.ObserveRepository("memory", "Reset", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "Reset")
+	defer span.End()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := counterKey(ctx, adID)
+	if token > 0 {
+		if token < r.fences[key] {
+			return 0, out.ErrStaleToken
+		}
+		r.fences[key] = token
+	}
+	count := r.counters[key]
+	delete(r.counters, key)
+	return count, nil
+}
+
+// GetAllKeys retourne les compteurs de tous les locataires
+func (r *CacheRepository) GetAllKeys(ctx context.Context) ([]domain.CounterKey, error) {
+	defer metrics.ObserveRepository("memory", "GetAllKeys", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "GetAllKeys")
+	defer span.End()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keys := make([]domain.CounterKey, 0, len(r.counters))
+	for key := range r.counters {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// counterKey identifie le compteur d'une publicité du locataire du contexte
+func counterKey(ctx context.Context, adID string) domain.CounterKey {
+	return domain.CounterKey{Tenant: domain.TenantFromContext(ctx), AdID: adID}
+}
+
+// Ensure CacheRepository implements the CacheRepository interface
+var _ out.CacheRepository = (*CacheRepository)(nil)
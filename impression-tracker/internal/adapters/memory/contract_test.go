@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"impression-tracker/internal/adapters/clock"
+	"impression-tracker/internal/ports/out"
+	"impression-tracker/internal/ports/out/contract"
+)
+
+func TestCacheRepositoryContract(t *testing.T) {
+	contract.CacheRepository(t, func(*testing.T) out.CacheRepository { return NewCacheRepository() })
+}
+
+func TestLeaseRepositoryContract(t *testing.T) {
+	contract.LeaseRepository(t, func(*testing.T) (out.LeaseRepository, contract.Elapse) {
+		fake := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+		return NewLeaseRepository(fake), fake.Advance
+	})
+}
+
+func TestEventDeduplicatorContract(t *testing.T) {
+	contract.EventDeduplicator(t, func(*testing.T) (out.EventDeduplicator, contract.Elapse) {
+		fake := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+		return NewEventDeduplicator(fake), fake.Advance
+	})
+}
+
+func TestMetricsRepositoryContract(t *testing.T) {
+	contract.MetricsRepository(t, func(_ *testing.T, clock out.Clock) out.MetricsRepository { return NewMetricsRepository(clock) })
+}
+
+func TestEventStoreContract(t *testing.T) {
+	contract.EventStore(t, func(*testing.T) out.EventStore { return NewEventStore() })
+}
@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"impression-tracker/internal/adapters/metrics"
+	"impression-tracker/internal/adapters/tracing"
+	"impression-tracker/internal/domain"
+	"impression-tracker/internal/ports/out"
+)
+
+// maxSeenEvents déclenche la purge des événements expirés au-delà de ce nombre
+const maxSeenEvents = 100_000
+
+// EventDeduplicator implémente l'interface EventDeduplicator en mémoire. Les événements ne
+// sont dédoublonnés qu'au sein du processus : il ne convient qu'à une instance unique.
+type EventDeduplicator struct {
+	mu    sync.Mutex
+	seen  map[seenEvent]time.Time // Expiration de chaque événement marqué
+	clock out.Clock
+}
+
+// seenEvent identifie un événement d'une impression d'un locataire
+type seenEvent struct {
+	tenant       string
+	impressionID string
+	eventType    domain.EventType
+}
+
+// NewEventDeduplicator crée un dédoublonneur vide, dont les marques expirent selon clock
+func NewEventDeduplicator(clock out.Clock) *EventDeduplicator {
+	return &EventDeduplicator{seen: make(map[seenEvent]time.Time), clock: clock}
+}
+
+// FirstSeen marque l'événement s'il n'est pas déjà marqué ou si sa marque a expiré
+func (d *EventDeduplicator) FirstSeen(ctx context.Context, impressionID string, eventType domain.EventType, ttl time.Duration) (bool, error) {
+	defer metrics.ObserveRepository("memory", "FirstSeen", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "FirstSeen")
+	defer span.End()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.clock.Now()
+	if len(d.seen) > maxSeenEvents {
+		for event, expires := range d.seen {
+			if !now.Before(expires) {
+				delete(d.seen, event)
+			}
+		}
+	}
+	event := seenEvent{tenant: domain.TenantFromContext(ctx), impressionID: impressionID, eventType: eventType}
+	if expires, ok := d.seen[event]; ok && now.Before(expires) {
+		return false, nil
+	}
+	d.seen[event] = now.Add(ttl)
+	return true, nil
+}
+
+// Ensure EventDeduplicator implements the EventDeduplicator interface
+var _ out.EventDeduplicator = (*EventDeduplicator)(nil)
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"impression-tracker/internal/adapters/metrics"
+	"impression-tracker/internal/adapters/tracing"
+	"impression-tracker/internal/domain"
+	"impression-tracker/internal/ports/out"
+)
+
+// EventStore implémente l'interface EventStore en mémoire, en mode append-only
+type EventStore struct {
+	mu          sync.Mutex
+	impressions []domain.Impression
+	ids         map[string]bool // Impressions déjà enregistrées
+}
+
+// NewEventStore crée un journal d'impressions vide
+func NewEventStore() *EventStore {
+	return &EventStore{ids: make(map[string]bool)}
+}
+
+// Append ajoute une impression au journal ; un rejeu de la même impression est ignoré
+func (s *EventStore) Append(ctx context.Context, imp domain.Impression) error {
+	defer metrics.ObserveRepository("memory", "Append", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "Append")
+	defer span.End()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ids[imp.ID] {
+		return nil
+	}
+	s.ids[imp.ID] = true
+	s.impressions = append(s.impressions, imp)
+	return nil
+}
+
+// Scan parcourt par ordre chronologique les impressions du locataire du contexte reçues
+// dans [from, to). Le parcours porte sur une copie : fn peut appeler Append.
+func (s *EventStore) Scan(ctx context.Context, from, to time.Time, fn func(domain.Impression) error) error {
+	defer metrics.ObserveRepository("memory", "Scan", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "memory", "Scan")
+	defer span.End()
+	tenant := domain.TenantFromContext(ctx)
+
+	s.mu.Lock()
+	var matched []domain.Impression
+	for _, imp := range s.impressions {
+		if imp.Tenant == tenant && !imp.Timestamp.Before(from) && imp.Timestamp.Before(to) {
+			matched = append(matched, imp)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].Timestamp.Before(matched[j].Timestamp) })
+	for _, imp := range matched {
+		if err := fn(imp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ensure EventStore implements the EventStore interface
+var _ out.EventStore = (*EventStore)(nil)
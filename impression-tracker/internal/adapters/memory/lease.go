@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"impression-tracker/internal/adapters/metrics"
+	"impression-tracker/internal/adapters/tracing"
+	"impression-tracker/internal/ports/out"
+)
+
+// LeaseRepository implémente l'interface LeaseRepository en mémoire. Les baux ne sont
+// partagés qu'au sein du processus : il ne convient qu'à une instance unique.
+type LeaseRepository struct {
+	mu     sync.Mutex
+	leases map[string]lease
+	tokens map[string]int64 // Dernier jeton de fencing émis par bail, conservé après expiration
+	clock  out.Clock        // Horloge des expirations
+}
+
+// lease est un bail détenu jusqu'à son expiration
+type lease struct {
+	holder  string
+	token   int64
+	expires time.Time
+}
+
+// NewLeaseRepository crée un gestionnaire de baux vide dont les baux expirent selon clock
+func NewLeaseRepository(clock out.Clock) *LeaseRepository {
+	return &LeaseRepository{leases: make(map[string]lease), tokens: make(map[string]int64), clock: clock}
+}
+
+// Acquire obtient ou prolonge le bail name pour holder
+func (r *LeaseRepository) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (int64, bool, error) {
+	defer metrics.ObserveRepository("memory", "Acquire", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "Acquire")
+	defer span.End()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.clock.Now()
+	current, held := r.leases[name]
+	if held && now.Before(current.expires) {
+		if current.holder != holder {
+			return 0, false, nil
+		}
+		current.expires = now.Add(ttl)
+		r.leases[name] = current
+		return current.token, true, nil
+	}
+	r.tokens[name]++
+	r.leases[name] = lease{holder: holder, token: r.tokens[name], expires: now.Add(ttl)}
+	return r.tokens[name], true, nil
+}
+
+// Validate vérifie que le bail en cours porte le jeton donné
+func (r *LeaseRepository) Validate(ctx context.Context, name string, token int64) (bool, error) {
+	defer metrics.ObserveRepository("memory", "Validate", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "Validate")
+	defer span.End()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, held := r.leases[name]
+	return held && r.clock.Now().Before(current.expires) && current.token == token, nil
+}
+
+// Release libère le bail s'il est détenu par holder
+func (r *LeaseRepository) Release(ctx context.Context, name, holder string) error {
+	defer metrics.ObserveRepository("memory", "Release", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "Release")
+	defer span.End()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if current, held := r.leases[name]; held && current.holder == holder {
+		delete(r.leases, name)
+	}
+	return nil
+}
+
+// Ensure LeaseRepository implements the LeaseRepository interface
+var _ out.LeaseRepository = (*LeaseRepository)(nil)
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"impression-tracker/internal/adapters/metrics"
+	"impression-tracker/internal/adapters/tracing"
+	"impression-tracker/internal/domain"
+	"impression-tracker/internal/ports/out"
+)
+
+// MetricsRepository implémente l'interface MetricsRepository en mémoire : les deltas sont
+// conservés tels quels, datés à leur écriture comme dans MongoDB.
+type MetricsRepository struct {
+	mu     sync.Mutex
+	deltas []delta
+	clock  out.Clock
+}
+
+// delta est un delta persisté d'une publicité
+type delta struct {
+	key   domain.CounterKey
+	value int64
+	at    time.Time
+}
+
+// NewMetricsRepository crée un stockage de deltas vide, datés selon clock
+func NewMetricsRepository(clock out.Clock) *MetricsRepository {
+	return &MetricsRepository{clock: clock}
+}
+
+// PersistDelta enregistre un delta pour la publicité du locataire du contexte
+func (r *MetricsRepository) PersistDelta(ctx context.Context, adID string, value int64) error {
+	defer metrics.ObserveRepository("memory", "PersistDelta", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "PersistDelta")
+	defer span.End()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deltas = append(r.deltas, delta{key: counterKey(ctx, adID), value: value, at: r.clock.Now()})
+	return nil
+}
+
+// GetTotal retourne la somme des deltas de la publicité
+func (r *MetricsRepository) GetTotal(ctx context.Context, adID string) (int64, error) {
+	defer metrics.ObserveRepository("memory", "GetTotal", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "GetTotal")
+	defer span.End()
+	return r.sum(counterKey(ctx, adID), func(time.Time) bool { return true }), nil
+}
+
+// GetTotalBetween retourne la somme des deltas de la publicité écrits dans [from, to)
+func (r *MetricsRepository) GetTotalBetween(ctx context.Context, adID string, from, to time.Time) (int64, error) {
+	defer metrics.ObserveRepository("memory", "GetTotalBetween", time.Now())
+	_, span := tracing.StartRepository(ctx, "memory", "GetTotalBetween")
+	defer span.End()
+	return r.sum(counterKey(ctx, adID), func(at time.Time) bool {
+		return !at.Before(from) && at.Before(to)
+	}), nil
+}
+
+// sum additionne les deltas de key dont la date est retenue par keep
+func (r *MetricsRepository) sum(key domain.CounterKey, keep func(time.Time) bool) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var total int64
+	for _, d := range r.deltas {
+		if d.key == key && keep(d.at) {
+			total += d.value
+		}
+	}
+	return total
+}
+
+// Ensure MetricsRepository implements the MetricsRepository interface
+var _ out.MetricsRepository = (*MetricsRepository)(nil)
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// namespace préfixe toutes les métriques du service
+const namespace = "impression_tracker"
+
+var (
+	rpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_server_handling_seconds",
+		Help:      "Durée de traitement des appels gRPC, par méthode et code de retour.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	repositoryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "repository_operation_seconds",
+		Help:      "Durée des opérations sur les repositories, par backend et opération.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"repository", "operation"})
+
+	rateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "Appels refusés par la limitation, par méthode, règle (tenant, ip) et raison (rate, quota).",
+	}, []string{"method", "rule", "reason"})
+)
+
+// ObserveRepository enregistre la durée d'une opération de repository démarrée à start.
+// S'utilise en début de méthode : defer metrics.ObserveRepository("mongodb", "PersistDelta", time.Now())
+func ObserveRepository(repository, operation string, start time.Time) {
+	repositoryDuration.WithLabelValues(repository, operation).Observe(time.Since(start).Seconds())
+}
+
+// RateLimited comptabilise un appel refusé par la limitation de débit ou le quota.
+func RateLimited(method, rule, reason string) {
+	rateLimited.WithLabelValues(method, rule, reason).Inc()
+}
+
+// UnaryServerInterceptor mesure la durée des appels gRPC unaires.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		rpcDuration.WithLabelValues(info.FullMethod, status.Code(err).String()).Observe(time.Since(start).Seconds())
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor mesure la durée des appels gRPC en flux.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		rpcDuration.WithLabelValues(info.FullMethod, status.Code(err).String()).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// Serve démarre le listener HTTP exposant /metrics au format Prometheus.
+// Le serveur retourné doit être arrêté avec Shutdown.
+func Serve(addr string, logger *slog.Logger) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Metrics server failed", "addr", addr, "error", err)
+		}
+	}()
+	return server
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// family retourne la métrique name du registre par défaut
+func family(t *testing.T, name string) *dto.MetricFamily {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error: %v", err)
+	}
+	for _, f := range families {
+		if f.GetName() == name {
+			return f
+		}
+	}
+	t.Fatalf("metric %s is not registered", name)
+	return nil
+}
+
+// sample retourne la série de la métrique name portant les labels donnés
+func sample(t *testing.T, name string, labels map[string]string) *dto.Metric {
+	t.Helper()
+	for _, m := range family(t, name).GetMetric() {
+		matched := 0
+		for _, l := range m.GetLabel() {
+			if labels[l.GetName()] == l.GetValue() {
+				matched++
+			}
+		}
+		if matched == len(labels) {
+			return m
+		}
+	}
+	t.Fatalf("metric %s has no series %v", name, labels)
+	return nil
+}
+
+func TestMetricsAreRegistered(t *testing.T) {
+	ObserveRepository("dragonfly", "Increment", time.Now())
+	RateLimited("/impression.ImpressionService/TrackImpression", "tenant", "rate")
+	m := NewServiceMetrics()
+	m.ImpressionTracked(false)
+	m.EventTracked("click")
+	m.DeltaPersisted("impressions", 5)
+	m.SyncError("clicks")
+	m.CacheKeys("impressions", 42)
+	m.SyncCompleted(20 * time.Millisecond)
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		check  func(m *dto.Metric) bool
+	}{
+		{"impression_tracker_repository_operation_seconds", map[string]string{"repository": "dragonfly", "operation": "Increment"}, func(m *dto.Metric) bool { return m.GetHistogram().GetSampleCount() >= 1 }},
+		{"impression_tracker_rate_limited_total", map[string]string{"method": "/impression.ImpressionService/TrackImpression", "rule": "tenant", "reason": "rate"}, func(m *dto.Metric) bool { return m.GetCounter().GetValue() >= 1 }},
+		{"impression_tracker_impressions_tracked_total", map[string]string{"traffic": "invalid"}, func(m *dto.Metric) bool { return m.GetCounter().GetValue() >= 1 }},
+		{"impression_tracker_events_tracked_total", map[string]string{"event_type": "click"}, func(m *dto.Metric) bool { return m.GetCounter().GetValue() >= 1 }},
+		{"impression_tracker_sync_deltas_persisted_total", map[string]string{"counter": "impressions"}, func(m *dto.Metric) bool { return m.GetCounter().GetValue() >= 1 }},
+		{"impression_tracker_sync_delta_sum_total", map[string]string{"counter": "impressions"}, func(m *dto.Metric) bool { return m.GetCounter().GetValue() >= 5 }},
+		{"impression_tracker_sync_errors_total", map[string]string{"counter": "clicks"}, func(m *dto.Metric) bool { return m.GetCounter().GetValue() >= 1 }},
+		{"impression_tracker_cache_keys", map[string]string{"counter": "impressions"}, func(m *dto.Metric) bool { return m.GetGauge().GetValue() == 42 }},
+		{"impression_tracker_sync_duration_seconds", nil, func(m *dto.Metric) bool { return m.GetHistogram().GetSampleCount() >= 1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if m := sample(t, tt.name, tt.labels); !tt.check(m) {
+				t.Errorf("%s%v = %v", tt.name, tt.labels, m)
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptorLabelsStatusCode(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/impression.ImpressionService/GetImpressionCount"}
+	interceptor := UnaryServerInterceptor()
+	for _, err := range []error{nil, status.Error(codes.InvalidArgument, "ad_id is required")} {
+		interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) { return nil, err })
+	}
+
+	for _, code := range []string{"OK", "InvalidArgument"} {
+		m := sample(t, "impression_tracker_grpc_server_handling_seconds", map[string]string{"method": info.FullMethod, "code": code})
+		if m.GetHistogram().GetSampleCount() < 1 {
+			t.Errorf("no %s call observed", code)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"time"
+
+	"impression-tracker/internal/ports/out"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	impressionsTracked = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "impressions_tracked_total",
+		Help:      "Impressions comptées, par validité (valid, invalid).",
+	}, []string{"traffic"})
+
+	eventsTracked = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_tracked_total",
+		Help:      "Événements d'engagement comptés, par type.",
+	}, []string{"event_type"})
+
+	deltasPersisted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sync_deltas_persisted_total",
+		Help:      "Deltas persistés dans MongoDB lors des synchronisations, par compteur.",
+	}, []string{"counter"})
+
+	deltaSumPersisted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sync_delta_sum_total",
+		Help:      "Somme des deltas persistés dans MongoDB, par compteur.",
+	}, []string{"counter"})
+
+	syncDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "sync_duration_seconds",
+		Help:      "Durée des boucles de synchronisation cache vers MongoDB.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	})
+
+	syncErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sync_errors_total",
+		Help:      "Erreurs rencontrées pendant les synchronisations, par compteur.",
+	}, []string{"counter"})
+
+	cacheKeys = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cache_keys",
+		Help:      "Nombre de clés en cache lors de la dernière synchronisation, par compteur.",
+	}, []string{"counter"})
+)
+
+// serviceMetrics implémente l'interface ServiceMetrics avec des collecteurs Prometheus
+type serviceMetrics struct{}
+
+// NewServiceMetrics crée l'enregistreur des métriques métier du tracker
+func NewServiceMetrics() out.ServiceMetrics {
+	return serviceMetrics{}
+}
+
+func (serviceMetrics) ImpressionTracked(valid bool) {
+	traffic := "valid"
+	if !valid {
+		traffic = "invalid"
+	}
+	impressionsTracked.WithLabelValues(traffic).Inc()
+}
+
+func (serviceMetrics) EventTracked(eventType string) {
+	eventsTracked.WithLabelValues(eventType).Inc()
+}
+
+func (serviceMetrics) DeltaPersisted(counter string, delta int64) {
+	deltasPersisted.WithLabelValues(counter).Inc()
+	deltaSumPersisted.WithLabelValues(counter).Add(float64(delta))
+}
+
+func (serviceMetrics) SyncError(counter string) {
+	syncErrors.WithLabelValues(counter).Inc()
+}
+
+func (serviceMetrics) CacheKeys(counter string, count int) {
+	cacheKeys.WithLabelValues(counter).Set(float64(count))
+}
+
+func (serviceMetrics) SyncCompleted(duration time.Duration) {
+	syncDuration.Observe(duration.Seconds())
+}
@@ -0,0 +1,41 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"impression-tracker/internal/ports/out"
+	"impression-tracker/internal/ports/out/contract"
+)
+
+// newRepository connecte un repository à une base propre au test, supprimée à la fin du test.
+// Sans MONGO_TEST_URI, le test est ignoré.
+func newRepository(t *testing.T, clock out.Clock) *MongoDBRepository {
+	t.Helper()
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI not set")
+	}
+	repo, err := NewMongoDBRepository(uri, fmt.Sprintf("contract_%d", time.Now().UnixNano()), "impressions", clock)
+	if err != nil {
+		t.Fatalf("NewMongoDBRepository() error: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = repo.Database().Drop(context.Background())
+		repo.Close()
+	})
+	return repo
+}
+
+func TestMetricsRepositoryContract(t *testing.T) {
+	contract.MetricsRepository(t, func(t *testing.T, clock out.Clock) out.MetricsRepository { return newRepository(t, clock) })
+}
+
+func TestEventStoreContract(t *testing.T) {
+	contract.EventStore(t, func(t *testing.T) out.EventStore {
+		return NewEventRepository(newRepository(t, nil), "impression_events")
+	})
+}
@@ -0,0 +1,72 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"impression-tracker/internal/adapters/metrics"
+	"impression-tracker/internal/adapters/tracing"
+	"impression-tracker/internal/domain"
+	"impression-tracker/internal/ports/out"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EventRepository implémente l'interface EventStore en stockant chaque impression brute
+// dans une collection MongoDB dédiée. Les documents ne sont jamais modifiés ni supprimés.
+type EventRepository struct {
+	collection *mongo.Collection
+}
+
+// NewEventRepository crée un journal d'impressions partageant la connexion du MongoDBRepository.
+func NewEventRepository(repo *MongoDBRepository, collection string) *EventRepository {
+	return &EventRepository{
+		collection: repo.client.Database(repo.database).Collection(collection),
+	}
+}
+
+// Append ajoute une impression au journal.
+// L'ID de l'impression sert de clé primaire : un rejeu de la même impression est ignoré.
+func (r *EventRepository) Append(ctx context.Context, imp domain.Impression) error {
+	defer metrics.ObserveRepository("mongodb", "Append", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "mongodb", "Append")
+	defer span.End()
+	_, err := r.collection.InsertOne(ctx, imp)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+// Scan parcourt les impressions du locataire du contexte reçues dans l'intervalle [from, to)
+// par ordre chronologique.
+// Le parcours s'arrête à la première erreur retournée par fn.
+func (r *EventRepository) Scan(ctx context.Context, from, to time.Time, fn func(domain.Impression) error) error {
+	defer metrics.ObserveRepository("mongodb", "Scan", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "mongodb", "Scan")
+	defer span.End()
+	filter := bson.M{"tenant": tenantFilter(ctx), "timestamp": bson.M{"$gte": from, "$lt": to}}
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var imp domain.Impression
+		if err := cursor.Decode(&imp); err != nil {
+			return err
+		}
+		if err := fn(imp); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// Ensure EventRepository implements the EventStore interface
+var _ out.EventStore = (*EventRepository)(nil)
@@ -0,0 +1,47 @@
+package mongodb
+
+import (
+	"shared/mongomigrate"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Migrations retourne les migrations du schéma du tracker, appliquées par
+// mongomigrate.Migrate : index des totaux sur chaque collection de deltas (impressions,
+// engagement, trafic invalide) et index des exports sur le journal des impressions brutes.
+// Les collections sont celles de la configuration, fonctionnalités désactivées comprises ;
+// chaque étape est consignée par collection, si bien qu'une collection renommée après coup
+// reçoit ses index au prochain démarrage.
+// Une version publiée ne doit plus changer : toute évolution passe par une nouvelle version.
+func Migrations(deltaCollections []string, eventsCollection string) []mongomigrate.Migration {
+	totals := mongomigrate.CreateIndexes(mongo.IndexModel{Keys: bson.D{
+		{Key: "tenant", Value: 1},
+		{Key: "ad_id", Value: 1},
+		{Key: "event_type", Value: 1},
+		{Key: "date_time", Value: 1},
+	}})
+	var migrations []mongomigrate.Migration
+	seen := make(map[string]bool, len(deltaCollections))
+	for _, collection := range deltaCollections {
+		// Deux compteurs peuvent partager une collection
+		if seen[collection] {
+			continue
+		}
+		seen[collection] = true
+		migrations = append(migrations, mongomigrate.Migration{
+			Collection:  collection,
+			Version:     1,
+			Description: "deltas: index by tenant, ad, event type and date",
+			Up:          totals,
+		})
+	}
+	return append(migrations, mongomigrate.Migration{
+		Collection:  eventsCollection,
+		Version:     1,
+		Description: "events: index by tenant and timestamp",
+		Up: mongomigrate.CreateIndexes(mongo.IndexModel{
+			Keys: bson.D{{Key: "tenant", Value: 1}, {Key: "timestamp", Value: 1}},
+		}),
+	})
+}
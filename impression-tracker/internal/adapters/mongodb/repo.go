@@ -5,8 +5,12 @@ import (
 	"fmt"
 	"time"
 
+	"impression-tracker/internal/adapters/metrics"
+	"impression-tracker/internal/adapters/tracing"
+	"impression-tracker/internal/domain"
 	"impression-tracker/internal/ports/out"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -17,18 +21,23 @@ type MongoDBRepository struct {
 	client     *mongo.Client
 	database   string
 	collection string
+	eventType  string    // Type d'événement des deltas, vide pour les impressions
+	clock      out.Clock // Horloge datant les deltas
 }
 
 // impressionDelta représente un document MongoDB stockant les informations sur un delta d'impressions.
 type impressionDelta struct {
-	AdID     string    `bson:"ad_id"`     // Identifiant de la publicité
-	Delta    int64     `bson:"delta"`     // Nombre d'impressions à synchroniser
-	DateTime time.Time `bson:"date_time"` // Date et heure de la synchronisation
+	AdID      string    `bson:"ad_id"`                // Identifiant de la publicité
+	Tenant    string    `bson:"tenant,omitempty"`     // Locataire, absent pour le locataire par défaut
+	Delta     int64     `bson:"delta"`                // Nombre d'impressions à synchroniser
+	DateTime  time.Time `bson:"date_time"`            // Date et heure de la synchronisation
+	EventType string    `bson:"event_type,omitempty"` // Type d'événement, absent pour les impressions
 }
 
 // NewMongoDBRepository crée une nouvelle instance de MongoDBRepository.
 // Elle établit une connexion avec le serveur MongoDB et vérifie que la connexion fonctionne.
-func NewMongoDBRepository(uri, database, collection string) (*MongoDBRepository, error) {
+// Les deltas sont datés selon clock.
+func NewMongoDBRepository(uri, database, collection string, clock out.Clock) (*MongoDBRepository, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -46,24 +55,127 @@ func NewMongoDBRepository(uri, database, collection string) (*MongoDBRepository,
 		client:     client,
 		database:   database,
 		collection: collection,
+		clock:      clock,
 	}, nil
 }
 
+// WithCollection retourne un repository partageant la même connexion mais stockant
+// ses deltas dans une autre collection. Seul le repository d'origine doit être fermé.
+func (r *MongoDBRepository) WithCollection(collection string) *MongoDBRepository {
+	return &MongoDBRepository{
+		client:     r.client,
+		database:   r.database,
+		collection: collection,
+		clock:      r.clock,
+	}
+}
+
+// WithEventType retourne un repository partageant la même connexion et la même collection
+// dont les deltas sont marqués du type d'événement donné. Les totaux ne portent alors
+// que sur ce type d'événement.
+func (r *MongoDBRepository) WithEventType(eventType string) *MongoDBRepository {
+	return &MongoDBRepository{
+		client:     r.client,
+		database:   r.database,
+		collection: r.collection,
+		eventType:  eventType,
+		clock:      r.clock,
+	}
+}
+
 // PersistDelta enregistre un delta d'impressions dans MongoDB.
-// Le document contient l'ID de la publicité, le nombre d'impressions et la date/heure.
+// Le document contient l'ID de la publicité, son locataire, le nombre d'impressions et la date/heure.
 func (r *MongoDBRepository) PersistDelta(ctx context.Context, adID string, delta int64) error {
+	defer metrics.ObserveRepository("mongodb", "PersistDelta", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "mongodb", "PersistDelta")
+	defer span.End()
 	collection := r.client.Database(r.database).Collection(r.collection)
 
 	doc := impressionDelta{
-		AdID:     adID,
-		Delta:    delta,
-		DateTime: time.Now(),
+		AdID:      adID,
+		Tenant:    domain.TenantFromContext(ctx),
+		Delta:     delta,
+		DateTime:  r.clock.Now(),
+		EventType: r.eventType,
 	}
 
 	_, err := collection.InsertOne(ctx, doc)
 	return err
 }
 
+// GetTotal calcule la somme des deltas persistés pour une publicité donnée.
+func (r *MongoDBRepository) GetTotal(ctx context.Context, adID string) (int64, error) {
+	defer metrics.ObserveRepository("mongodb", "GetTotal", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "mongodb", "GetTotal")
+	defer span.End()
+	return r.sum(ctx, r.filter(ctx, adID))
+}
+
+// GetTotalBetween calcule la somme des deltas synchronisés dans l'intervalle [from, to).
+// La précision est celle de l'intervalle de synchronisation, les deltas étant datés à leur écriture.
+func (r *MongoDBRepository) GetTotalBetween(ctx context.Context, adID string, from, to time.Time) (int64, error) {
+	defer metrics.ObserveRepository("mongodb", "GetTotalBetween", time.Now())
+	ctx, span := tracing.StartRepository(ctx, "mongodb", "GetTotalBetween")
+	defer span.End()
+	filter := r.filter(ctx, adID)
+	filter["date_time"] = bson.M{"$gte": from, "$lt": to}
+	return r.sum(ctx, filter)
+}
+
+// sum additionne les deltas des documents correspondant au filtre
+func (r *MongoDBRepository) sum(ctx context.Context, filter bson.M) (int64, error) {
+	collection := r.client.Database(r.database).Collection(r.collection)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "total": bson.M{"$sum": "$delta"}}}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result struct {
+		Total int64 `bson:"total"`
+	}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&result); err != nil {
+			return 0, err
+		}
+	}
+	return result.Total, cursor.Err()
+}
+
+// filter construit le filtre des deltas d'une publicité du locataire du contexte
+// pour le type d'événement du repository
+func (r *MongoDBRepository) filter(ctx context.Context, adID string) bson.M {
+	filter := bson.M{"ad_id": adID, "tenant": tenantFilter(ctx)}
+	if r.eventType != "" {
+		filter["event_type"] = r.eventType
+	}
+	return filter
+}
+
+// tenantFilter retourne la valeur du champ "tenant" à filtrer pour le locataire du contexte.
+// Les documents du locataire par défaut n'ont pas de champ "tenant", que la valeur nil sélectionne.
+func tenantFilter(ctx context.Context) interface{} {
+	if tenant := domain.TenantFromContext(ctx); tenant != "" {
+		return tenant
+	}
+	return nil
+}
+
+// Database retourne la base des deltas, pour les migrations du schéma.
+func (r *MongoDBRepository) Database() *mongo.Database {
+	return r.client.Database(r.database)
+}
+
+// Ping vérifie que le serveur MongoDB répond, pour le suivi de santé du service.
+func (r *MongoDBRepository) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx, nil)
+}
+
 // Close ferme la connexion avec le serveur MongoDB.
 func (r *MongoDBRepository) Close() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"impression-tracker/internal/adapters/clock"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// discard est le logger des tests
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestAllowFollowsClock(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	fake := clock.NewFake(time.Date(2026, 1, 1, 23, 0, 0, 0, time.UTC))
+	l := NewLimiter(client, "ratelimit:tracker", fake, discard)
+	allow := func(limit Limit) Decision {
+		t.Helper()
+		d, err := l.Allow(context.Background(), "tenant", "acme", limit)
+		if err != nil {
+			t.Fatalf("Allow() error: %v", err)
+		}
+		return d
+	}
+
+	// Un jeton par seconde : le seau vide se recharge quand l'horloge avance
+	rate := Limit{Rate: 1, Burst: 1}
+	if d := allow(rate); !d.Allowed {
+		t.Fatalf("first Allow() = %+v, want allowed", d)
+	}
+	if d := allow(rate); d.Allowed || d.RetryAfter != time.Second {
+		t.Fatalf("Allow() with an empty bucket = %+v, want a refusal for 1s", d)
+	}
+	fake.Advance(time.Second)
+	if d := allow(rate); !d.Allowed {
+		t.Errorf("Allow() after the refill = %+v, want allowed", d)
+	}
+
+	// Le quota journalier se renouvelle à minuit UTC
+	quota := Limit{Daily: 1}
+	if d := allow(quota); !d.Allowed {
+		t.Fatalf("first Allow() of the day = %+v, want allowed", d)
+	}
+	want := time.Hour - time.Second
+	if d := allow(quota); d.Allowed || d.Reason != ReasonQuota || d.RetryAfter != want {
+		t.Fatalf("Allow() over quota = %+v, want a quota refusal for %v", d, want)
+	}
+	fake.Advance(time.Hour)
+	if d := allow(quota); !d.Allowed {
+		t.Errorf("Allow() the next day = %+v, want allowed", d)
+	}
+}
@@ -0,0 +1,240 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Config désigne les fichiers PEM d'une identité TLS.
+//
+// Côté serveur, CertFile/KeyFile sont le certificat présenté aux clients et CAFile,
+// s'il est renseigné, l'autorité des certificats clients : le mTLS est alors exigé.
+// Côté client, CertFile/KeyFile sont le certificat présenté au serveur (mTLS) et
+// CAFile l'autorité du serveur (racines du système si vide).
+type Config struct {
+	CertFile   string
+	KeyFile    string
+	CAFile     string
+	ServerName string // Nom attendu dans le certificat du serveur (client uniquement, adresse appelée par défaut)
+}
+
+// Enabled indique si au moins un fichier est configuré
+func (c Config) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != "" || c.CAFile != ""
+}
+
+// Reloader garde en mémoire le certificat et l'autorité de Config, et les recharge
+// lorsque les fichiers changent sur disque : les nouvelles connexions utilisent
+// les nouveaux fichiers sans redémarrage. Un rechargement invalide (paire
+// incomplète pendant une rotation par exemple) conserve les fichiers précédents.
+type Reloader struct {
+	cfg    Config
+	logger *slog.Logger
+
+	cert atomic.Pointer[tls.Certificate]
+	pool atomic.Pointer[x509.CertPool]
+
+	modTimes map[string]time.Time // Dates de modification des fichiers chargés
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewReloader charge les fichiers de cfg et, si interval > 0, surveille leurs
+// modifications à cet intervalle.
+func NewReloader(cfg Config, interval time.Duration, logger *slog.Logger) (*Reloader, error) {
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return nil, errors.New("certificate and key files must be set together")
+	}
+	r := &Reloader{
+		cfg:      cfg,
+		logger:   logger.With("component", "TLSReloader"),
+		stopChan: make(chan struct{}),
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	if interval > 0 {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					r.reloadIfChanged()
+				case <-r.stopChan:
+					return
+				}
+			}
+		}()
+	}
+	return r, nil
+}
+
+// Stop arrête la surveillance des fichiers
+func (r *Reloader) Stop() {
+	close(r.stopChan)
+	r.wg.Wait()
+}
+
+// ServerConfig retourne la configuration TLS d'un serveur. Le certificat client
+// est exigé et vérifié dès qu'une autorité est configurée.
+func (r *Reloader) ServerConfig() *tls.Config {
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2"},
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return r.cert.Load(), nil
+		},
+	}
+	if r.cfg.CAFile == "" {
+		return base
+	}
+
+	base.ClientAuth = tls.RequireAndVerifyClientCert
+	cfg := base.Clone()
+	// Chaque poignée de main lit l'autorité courante, éventuellement rechargée
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		c := base.Clone()
+		c.ClientCAs = r.pool.Load()
+		return c, nil
+	}
+	return cfg
+}
+
+// ClientConfig retourne la configuration TLS d'un client, présentant son
+// certificat si un certificat est configuré.
+func (r *Reloader) ClientConfig() *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2"},
+		ServerName: r.cfg.ServerName,
+	}
+	if r.cfg.CertFile != "" {
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.cert.Load(), nil
+		}
+	}
+	if r.cfg.CAFile != "" {
+		// La vérification standard figerait l'autorité : elle est refaite dans
+		// VerifyConnection avec l'autorité courante, nom du serveur compris.
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = r.verifyServer
+	}
+	return cfg
+}
+
+// ClientCredentials retourne les identifiants gRPC d'un client ponctuel (commandes) :
+// TLS sans rechargement si des fichiers sont configurés, texte clair sinon.
+func ClientCredentials(cfg Config, logger *slog.Logger) (credentials.TransportCredentials, error) {
+	if !cfg.Enabled() {
+		return insecure.NewCredentials(), nil
+	}
+	r, err := NewReloader(cfg, 0, logger)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(r.ClientConfig()), nil
+}
+
+// verifyServer vérifie la chaîne et le nom du serveur avec l'autorité courante
+func (r *Reloader) verifyServer(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("server presented no certificate")
+	}
+	opts := x509.VerifyOptions{
+		Roots:         r.pool.Load(),
+		DNSName:       cs.ServerName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+// load lit tous les fichiers configurés et remplace l'identité courante
+func (r *Reloader) load() error {
+	modTimes := make(map[string]time.Time)
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	var cert *tls.Certificate
+	if r.cfg.CertFile != "" {
+		pair, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+		if err != nil {
+			return fmt.Errorf("load certificate %s: %w", r.cfg.CertFile, err)
+		}
+		cert = &pair
+	}
+
+	var pool *x509.CertPool
+	if r.cfg.CAFile != "" {
+		pem, err := os.ReadFile(r.cfg.CAFile)
+		if err != nil {
+			return fmt.Errorf("read CA %s: %w", r.cfg.CAFile, err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no PEM certificate found in %s", r.cfg.CAFile)
+		}
+	}
+
+	r.cert.Store(cert)
+	r.pool.Store(pool)
+	r.modTimes = modTimes
+	return nil
+}
+
+// reloadIfChanged recharge les fichiers si l'un d'eux a été modifié
+func (r *Reloader) reloadIfChanged() {
+	changed := false
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			r.logger.Error("TLS file unavailable, keeping current certificates", "file", file, "error", err)
+			return
+		}
+		if !info.ModTime().Equal(r.modTimes[file]) {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	if err := r.load(); err != nil {
+		r.logger.Error("TLS reload failed, keeping current certificates", "error", err)
+		return
+	}
+	r.logger.Info("TLS certificates reloaded")
+}
+
+// files retourne les fichiers configurés
+func (r *Reloader) files() []string {
+	var files []string
+	for _, file := range []string{r.cfg.CertFile, r.cfg.KeyFile, r.cfg.CAFile} {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
@@ -0,0 +1,247 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// discard est le logger des tests
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// testCA est une autorité auto-signée générée pour le test
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	file string // Certificat PEM de l'autorité
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key := newKey(t)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := &testCA{cert: cert, key: key, file: filepath.Join(t.TempDir(), name+".pem")}
+	writePEM(t, ca.file, "CERTIFICATE", der)
+	return ca
+}
+
+// issue signe un certificat serveur pour localhost, ou client si client est vrai, et
+// l'écrit avec sa clé dans dir
+func (ca *testCA) issue(t *testing.T, dir, name string, client bool) (certFile, keyFile string) {
+	t.Helper()
+	key := newKey(t)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost"},
+	}
+	if client {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+		template.DNSNames = nil
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "PRIVATE KEY", keyDER)
+	return certFile, keyFile
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newReloader(t *testing.T, cfg Config) *Reloader {
+	t.Helper()
+	r, err := NewReloader(cfg, 0, discard)
+	if err != nil {
+		t.Fatalf("NewReloader() error: %v", err)
+	}
+	t.Cleanup(r.Stop)
+	return r
+}
+
+// handshake établit une connexion TLS locale et retourne l'erreur du client ou du serveur
+func handshake(t *testing.T, server, client *tls.Config) error {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer conn.Close()
+		serverErr <- tls.Server(conn, server).Handshake()
+	}()
+
+	conn, err := tls.Dial("tcp", lis.Addr().String(), client)
+	if err == nil {
+		// En TLS 1.3, le certificat client est vérifié après la fin de la poignée de main côté client
+		_, err = conn.Read(make([]byte, 1))
+		conn.Close()
+	}
+	if err := <-serverErr; err != nil {
+		return err
+	}
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+func TestTLS(t *testing.T) {
+	ca, other := newTestCA(t, "ca"), newTestCA(t, "other-ca")
+	dir := t.TempDir()
+	certFile, keyFile := ca.issue(t, dir, "server", false)
+	server := newReloader(t, Config{CertFile: certFile, KeyFile: keyFile})
+
+	tests := []struct {
+		name    string
+		client  Config
+		wantErr bool
+	}{
+		{name: "trusted server", client: Config{CAFile: ca.file, ServerName: "localhost"}},
+		{name: "unknown authority", client: Config{CAFile: other.file, ServerName: "localhost"}, wantErr: true},
+		{name: "wrong server name", client: Config{CAFile: ca.file, ServerName: "tracker.example.com"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := handshake(t, server.ServerConfig(), newReloader(t, tt.client).ClientConfig())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("handshake error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca, other := newTestCA(t, "ca"), newTestCA(t, "other-ca")
+	dir := t.TempDir()
+	serverCert, serverKey := ca.issue(t, dir, "server", false)
+	clientCert, clientKey := ca.issue(t, dir, "adserver", true)
+	rogueCert, rogueKey := other.issue(t, dir, "rogue", true)
+	server := newReloader(t, Config{CertFile: serverCert, KeyFile: serverKey, CAFile: ca.file})
+
+	tests := []struct {
+		name    string
+		client  Config
+		wantErr bool
+	}{
+		{name: "client certificate", client: Config{CertFile: clientCert, KeyFile: clientKey, CAFile: ca.file, ServerName: "localhost"}},
+		{name: "no client certificate", client: Config{CAFile: ca.file, ServerName: "localhost"}, wantErr: true},
+		{name: "client certificate from another authority", client: Config{CertFile: rogueCert, KeyFile: rogueKey, CAFile: ca.file, ServerName: "localhost"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := handshake(t, server.ServerConfig(), newReloader(t, tt.client).ClientConfig())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("handshake error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	oldCA, newCA := newTestCA(t, "old-ca"), newTestCA(t, "new-ca")
+	dir := t.TempDir()
+	certFile, keyFile := oldCA.issue(t, dir, "server", false)
+	server := newReloader(t, Config{CertFile: certFile, KeyFile: keyFile})
+	client := newReloader(t, Config{CAFile: newCA.file, ServerName: "localhost"})
+
+	if err := handshake(t, server.ServerConfig(), client.ClientConfig()); err == nil {
+		t.Fatal("handshake succeeded before the rotation, want an unknown authority error")
+	}
+
+	// Rotation : le certificat signé par la nouvelle autorité remplace l'ancien sur disque
+	newCA.issue(t, dir, "server", false)
+	touch(t, certFile, keyFile)
+	server.reloadIfChanged()
+	if err := handshake(t, server.ServerConfig(), client.ClientConfig()); err != nil {
+		t.Fatalf("handshake after reload error: %v", err)
+	}
+
+	// Une clé illisible en cours de rotation conserve le certificat chargé
+	if err := os.WriteFile(keyFile, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	touch(t, keyFile)
+	server.reloadIfChanged()
+	if err := handshake(t, server.ServerConfig(), client.ClientConfig()); err != nil {
+		t.Errorf("handshake after a failed reload error: %v", err)
+	}
+}
+
+func TestNewReloaderRejectsIncompletePair(t *testing.T) {
+	certFile, _ := newTestCA(t, "ca").issue(t, t.TempDir(), "server", false)
+	if _, err := NewReloader(Config{CertFile: certFile}, 0, discard); err == nil {
+		t.Error("NewReloader() accepted a certificate without key")
+	}
+}
+
+// touch avance la date de modification des fichiers pour que le rechargement les voie
+func touch(t *testing.T, files ...string) {
+	t.Helper()
+	at := time.Now().Add(time.Minute)
+	for _, file := range files {
+		if err := os.Chtimes(file, at, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
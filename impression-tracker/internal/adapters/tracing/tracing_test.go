@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetupRejectsUnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), Config{Exporter: "jaeger"}); err == nil {
+		t.Error("Setup(jaeger) succeeded")
+	}
+}
+
+func TestSetupWithoutExport(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterNone})
+	if err != nil {
+		t.Fatalf("Setup() error: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() error: %v", err)
+	}
+}
+
+func TestFileExporterWritesRepositorySpans(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := Setup(context.Background(), Config{ServiceName: "impression-tracker", Exporter: ExporterFile, FilePath: path, SampleRatio: 1})
+	if err != nil {
+		t.Fatalf("Setup() error: %v", err)
+	}
+
+	_, span := StartRepository(context.Background(), "dragonfly", "Increment")
+	span.End()
+	// L'arrêt vide les spans en attente dans le fichier
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown() error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"Name":"dragonfly.Increment"`, `"db.system"`, `"impression-tracker"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("trace file does not contain %s:\n%s", want, data)
+		}
+	}
+}
+
+func TestSetupFailsOnUnwritableFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "traces.json")
+	if _, err := Setup(context.Background(), Config{Exporter: ExporterFile, FilePath: path}); err == nil {
+		t.Error("Setup() with an unwritable trace file succeeded")
+	}
+}
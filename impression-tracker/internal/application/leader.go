@@ -0,0 +1,125 @@
+package application
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"impression-tracker/internal/ports/out"
+)
+
+// LeaderElector élit, parmi les instances du tracker, celle qui synchronise les compteurs.
+// Le leader prolonge son bail à intervalle régulier ; si elle disparaît, une instance
+// en attente prend le relais au plus tard à l'expiration du bail.
+type LeaderElector struct {
+	lease  out.LeaseRepository
+	clock  out.Clock     // Cadence des renouvellements
+	name   string        // Nom du bail partagé par les instances
+	holder string        // Identifiant de l'instance courante
+	ttl    time.Duration // Durée de validité du bail
+
+	mu    sync.RWMutex
+	token int64 // Jeton de fencing du bail détenu, 0 si l'instance n'est pas leader
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+	logger   *slog.Logger
+}
+
+// NewLeaderElector crée un électeur pour le bail name, identifié par holder, renouvelant
+// son bail selon clock.
+func NewLeaderElector(lease out.LeaseRepository, clock out.Clock, name, holder string, ttl time.Duration, logger *slog.Logger) *LeaderElector {
+	return &LeaderElector{
+		lease:    lease,
+		clock:    clock,
+		name:     name,
+		holder:   holder,
+		ttl:      ttl,
+		stopChan: make(chan struct{}),
+		logger:   logger.With("component", "LeaderElector", "lease", name, "holder", holder),
+	}
+}
+
+// Start lance la tentative d'acquisition puis le renouvellement périodique du bail.
+// Le bail est renouvelé trois fois par période pour tolérer un renouvellement manqué.
+func (e *LeaderElector) Start() {
+	e.campaign()
+
+	// Le ticker est créé avant la goroutine : la cadence part de l'acquisition
+	ticker := e.clock.NewTicker(e.ttl / 3)
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C():
+				e.campaign()
+			case <-e.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop arrête le renouvellement et libère le bail pour accélérer la reprise par une autre instance.
+func (e *LeaderElector) Stop() {
+	close(e.stopChan)
+	e.wg.Wait()
+
+	if _, leader := e.Token(); !leader {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), e.ttl)
+	defer cancel()
+	if err := e.lease.Release(ctx, e.name, e.holder); err != nil {
+		e.logger.ErrorContext(ctx, "Failed to release lease", "error", err)
+	}
+	e.setToken(0)
+}
+
+// Token retourne le jeton de fencing du bail si l'instance est leader.
+func (e *LeaderElector) Token() (int64, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.token, e.token > 0
+}
+
+// Validate vérifie auprès du stockage partagé que le jeton est toujours celui du bail en cours,
+// afin qu'un ancien leader n'agisse pas après avoir perdu son bail.
+func (e *LeaderElector) Validate(ctx context.Context, token int64) (bool, error) {
+	return e.lease.Validate(ctx, e.name, token)
+}
+
+// campaign tente d'obtenir ou de prolonger le bail.
+// En cas d'erreur, l'instance renonce au rôle de leader par prudence.
+func (e *LeaderElector) campaign() {
+	ctx, cancel := context.WithTimeout(context.Background(), e.ttl/3)
+	defer cancel()
+
+	token, acquired, err := e.lease.Acquire(ctx, e.name, e.holder, e.ttl)
+	if err != nil {
+		e.logger.ErrorContext(ctx, "Failed to acquire lease", "error", err)
+		acquired = false
+	}
+
+	previous, wasLeader := e.Token()
+	switch {
+	case acquired && (!wasLeader || previous != token):
+		e.logger.InfoContext(ctx, "Instance is now leader", "token", token)
+	case !acquired && wasLeader:
+		e.logger.WarnContext(ctx, "Instance lost leadership")
+	}
+
+	if !acquired {
+		token = 0
+	}
+	e.setToken(token)
+}
+
+func (e *LeaderElector) setToken(token int64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.token = token
+}
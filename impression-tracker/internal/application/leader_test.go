@@ -0,0 +1,175 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"impression-tracker/internal/adapters/clock"
+	"impression-tracker/internal/adapters/memory"
+	"impression-tracker/internal/ports/out"
+)
+
+// staleLease valide tous les jetons : elle reproduit un leader dont le bail expire juste
+// après la vérification de son jeton, avant la réinitialisation du cache
+type staleLease struct {
+	out.LeaseRepository
+}
+
+func (staleLease) Validate(context.Context, string, int64) (bool, error) {
+	return true, nil
+}
+
+// instance est une instance du tracker partageant le cache et le stockage des autres
+type instance struct {
+	service *Service
+	elector *LeaderElector
+}
+
+func newInstance(holder string, lease out.LeaseRepository, cache out.CacheRepository, store out.MetricsRepository, fake *clock.Fake) instance {
+	elector := NewLeaderElector(lease, fake, "sync", holder, time.Minute, discard)
+	return instance{
+		service: NewService(cache, store, fake, time.Minute, discard, WithLeaderElection(elector)),
+		elector: elector,
+	}
+}
+
+func mustTrack(t *testing.T, s *Service, adID string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := s.TrackImpression(context.Background(), adID); err != nil {
+			t.Fatalf("TrackImpression() error: %v", err)
+		}
+	}
+}
+
+func persisted(t *testing.T, store out.MetricsRepository, adID string) int64 {
+	t.Helper()
+	total, err := store.GetTotal(context.Background(), adID)
+	if err != nil {
+		t.Fatalf("GetTotal() error: %v", err)
+	}
+	return total
+}
+
+func TestSyncRejectsStaleFencingToken(t *testing.T) {
+	fake := clock.NewFake(testStart)
+	lease := memory.NewLeaseRepository(fake)
+	cache, store := memory.NewCacheRepository(), memory.NewMetricsRepository(fake)
+	old := newInstance("old", staleLease{lease}, cache, store, fake)
+	successor := newInstance("successor", lease, cache, store, fake)
+
+	// L'ancien leader perd son bail sans s'en apercevoir ; son successeur est élu et synchronise
+	old.elector.campaign()
+	if err := lease.Release(context.Background(), "sync", "old"); err != nil {
+		t.Fatal(err)
+	}
+	successor.elector.campaign()
+	if _, leader := successor.elector.Token(); !leader {
+		t.Fatal("successor did not acquire the lease")
+	}
+	mustTrack(t, old.service, "ad", 3)
+	successor.service.sync()
+
+	// Le jeton de l'ancien leader passe la vérification mais pas la réinitialisation
+	mustTrack(t, old.service, "ad", 2)
+	old.service.sync()
+	if got := persisted(t, store, "ad"); got != 3 {
+		t.Fatalf("persisted = %d after the stale sync, want 3", got)
+	}
+	if count, err := old.service.GetCount(context.Background(), "ad"); err != nil || count != 2 {
+		t.Fatalf("GetCount() = %d, %v, want 2 (pending impressions kept in cache)", count, err)
+	}
+
+	successor.service.sync()
+	if got := persisted(t, store, "ad"); got != 5 {
+		t.Errorf("persisted = %d, want 5", got)
+	}
+}
+
+func TestConcurrentInstancesSyncEachImpressionOnce(t *testing.T) {
+	fake := clock.NewFake(testStart)
+	lease := memory.NewLeaseRepository(fake)
+	cache, store := memory.NewCacheRepository(), memory.NewMetricsRepository(fake)
+	instances := []instance{
+		newInstance("a", staleLease{lease}, cache, store, fake),
+		newInstance("b", staleLease{lease}, cache, store, fake),
+		newInstance("c", staleLease{lease}, cache, store, fake),
+	}
+
+	// Le bail change de main à chaque tour pendant que chaque instance sert et synchronise ;
+	// les anciens leaders gardent leur jeton et tentent encore de synchroniser
+	var wg sync.WaitGroup
+	for round := 0; round < 20; round++ {
+		holder := instances[round%len(instances)]
+		if err := lease.Release(context.Background(), "sync", instances[(round+2)%len(instances)].elector.holder); err != nil {
+			t.Fatal(err)
+		}
+		holder.elector.campaign()
+		for _, inst := range instances {
+			wg.Add(1)
+			go func(inst instance) {
+				defer wg.Done()
+				for i := 0; i < 10; i++ {
+					if err := inst.service.TrackImpression(context.Background(), "ad"); err != nil {
+						t.Errorf("TrackImpression() error: %v", err)
+					}
+				}
+				inst.service.sync()
+			}(inst)
+		}
+		wg.Wait()
+	}
+
+	// Le dernier leader élu vide le cache : rien n'est perdu ni compté deux fois
+	final := newInstance("final", lease, cache, store, fake)
+	for _, inst := range instances {
+		if err := lease.Release(context.Background(), "sync", inst.elector.holder); err != nil {
+			t.Fatal(err)
+		}
+	}
+	final.elector.campaign()
+	final.service.sync()
+	if got := persisted(t, store, "ad"); got != 20*3*10 {
+		t.Errorf("persisted = %d, want %d", got, 20*3*10)
+	}
+}
+
+func TestStandbyTakesOverWhenLeaseExpires(t *testing.T) {
+	fake := clock.NewFake(testStart)
+	lease := memory.NewLeaseRepository(fake)
+	// Le leader ne renouvelle pas son bail : il s'est arrêté sans le libérer
+	crashed := NewLeaderElector(lease, fake, "sync", "crashed", time.Minute, discard)
+	crashed.campaign()
+	token, _ := crashed.Token()
+
+	standby := NewLeaderElector(lease, fake, "sync", "standby", time.Minute, discard)
+	standby.Start()
+	defer standby.Stop()
+	if _, leader := standby.Token(); leader {
+		t.Fatal("standby is leader while the lease is held")
+	}
+
+	// Les renouvellements ont lieu tous les tiers de bail ; le bail expire à la troisième échéance
+	for i := 0; i < 2; i++ {
+		fake.Advance(20 * time.Second)
+	}
+	if _, leader := standby.Token(); leader {
+		t.Fatal("standby took over before the lease expired")
+	}
+	fake.Advance(20*time.Second + time.Millisecond)
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, leader := standby.Token(); leader {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("standby did not take over the expired lease")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if valid, err := crashed.Validate(context.Background(), token); err != nil || valid {
+		t.Errorf("Validate(crashed token) = %v, %v, want invalid", valid, err)
+	}
+}
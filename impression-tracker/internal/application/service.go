@@ -2,42 +2,145 @@ package application
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"sync"
 	"time"
 
+	"impression-tracker/internal/domain"
 	"impression-tracker/internal/ports/out"
 )
 
+var (
+	// ErrEventLogDisabled est retournée lorsqu'un export est demandé sans journal d'impressions configuré.
+	ErrEventLogDisabled = errors.New("impression event log is disabled")
+
+	// ErrEventNotTracked est retournée pour un type d'événement dont le comptage n'est pas activé.
+	ErrEventNotTracked = errors.New("event type is not tracked")
+)
+
 // Service implémente la logique métier du suivi d'impressions.
 // Il gère la synchronisation périodique entre le cache (Dragonfly) et le stockage persistant (MongoDB).
 type Service struct {
 	cacheRepo  out.CacheRepository   // Repository pour le cache (Dragonfly)
 	storeRepo  out.MetricsRepository // Repository pour le stockage persistant (MongoDB)
-	syncTicker *time.Ticker          // Timer pour la synchronisation périodique
+	clock      out.Clock             // Horloge des fenêtres de synchronisation
+	syncTicker out.Ticker            // Timer pour la synchronisation périodique
 	stopChan   chan struct{}         // Canal pour arrêter la synchronisation
 	wg         sync.WaitGroup        // WaitGroup pour gérer la goroutine de synchronisation
+
+	eventStore out.EventStore // Journal optionnel des impressions brutes
+
+	// Filtrage du trafic invalide (IVT), optionnel : les impressions signalées
+	// sont comptées à part plutôt que rejetées
+	trafficFilter out.TrafficFilter
+	invalid       counter
+
+	// Compteurs des événements d'engagement (rendu, visibilité, survol, fermeture),
+	// dédoublonnés par impression et par type pendant dedupTTL
+	engagement map[domain.EventType]counter
+	dedup      out.EventDeduplicator
+	dedupTTL   time.Duration
+
+	// Élection de leader, optionnelle : seule l'instance leader synchronise les compteurs
+	elector *LeaderElector
+
+	metrics out.ServiceMetrics // Métriques métier (aucune par défaut)
+	logger  *slog.Logger
+}
+
+// counter associe un compteur en cache au stockage persistant de ses deltas
+type counter struct {
+	cache out.CacheRepository
+	store out.MetricsRepository
+}
+
+// Option configure les dépendances optionnelles du Service.
+type Option func(*Service)
+
+// WithEventStore active l'enregistrement des impressions brutes dans le journal fourni.
+func WithEventStore(store out.EventStore) Option {
+	return func(s *Service) {
+		s.eventStore = store
+	}
+}
+
+// WithTrafficFilter active le filtrage du trafic invalide.
+// Les impressions signalées par le filtre sont comptées dans invalidCache puis
+// synchronisées vers invalidStore, séparément du trafic valide.
+func WithTrafficFilter(filter out.TrafficFilter, invalidCache out.CacheRepository, invalidStore out.MetricsRepository) Option {
+	return func(s *Service) {
+		s.trafficFilter = filter
+		s.invalid = counter{cache: invalidCache, store: invalidStore}
+	}
+}
+
+// WithEngagementCounter active le comptage d'un type d'événement d'engagement.
+func WithEngagementCounter(eventType domain.EventType, cache out.CacheRepository, store out.MetricsRepository) Option {
+	return func(s *Service) {
+		if s.engagement == nil {
+			s.engagement = make(map[domain.EventType]counter)
+		}
+		s.engagement[eventType] = counter{cache: cache, store: store}
+	}
+}
+
+// WithEventDeduplication ne compte qu'une fois chaque type d'événement d'une impression
+// reçu dans les ttl suivant le premier, même renvoyé par le client ou reçu par une autre instance.
+func WithEventDeduplication(dedup out.EventDeduplicator, ttl time.Duration) Option {
+	return func(s *Service) {
+		s.dedup = dedup
+		s.dedupTTL = ttl
+	}
+}
+
+// WithLeaderElection réserve la synchronisation à l'instance détenant le bail de l'électeur,
+// afin que plusieurs réplicas puissent partager le même cache.
+func WithLeaderElection(elector *LeaderElector) Option {
+	return func(s *Service) {
+		s.elector = elector
+	}
+}
+
+// WithMetrics enregistre les métriques métier (impressions, synchronisations) via metrics.
+func WithMetrics(metrics out.ServiceMetrics) Option {
+	return func(s *Service) {
+		s.metrics = metrics
+	}
 }
 
 // NewService crée une nouvelle instance de Service.
-// Elle initialise les repositories et configure la synchronisation périodique.
-func NewService(cacheRepo out.CacheRepository, storeRepo out.MetricsRepository, syncInterval time.Duration) *Service {
-	return &Service{
+// Elle initialise les repositories et configure la synchronisation périodique,
+// cadencée par clock.
+func NewService(cacheRepo out.CacheRepository, storeRepo out.MetricsRepository, clock out.Clock, syncInterval time.Duration, logger *slog.Logger, opts ...Option) *Service {
+	s := &Service{
 		cacheRepo:  cacheRepo,
 		storeRepo:  storeRepo,
-		syncTicker: time.NewTicker(syncInterval),
+		clock:      clock,
+		syncTicker: clock.NewTicker(syncInterval),
 		stopChan:   make(chan struct{}),
+		metrics:    nopMetrics{},
+		logger:     logger.With("component", "Service"),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
-// Start démarre la goroutine de synchronisation périodique.
+// Start démarre l'élection de leader (si activée) puis la goroutine de synchronisation périodique.
 func (s *Service) Start() {
+	if s.elector != nil {
+		s.elector.Start()
+	}
+
 	s.wg.Add(1)
 	go func() {
 		defer s.wg.Done()
 		for {
 			select {
-			case <-s.syncTicker.C:
+			case <-s.syncTicker.C():
 				s.sync()
 			case <-s.stopChan:
 				return
@@ -46,11 +149,15 @@ func (s *Service) Start() {
 	}()
 }
 
-// Stop arrête la goroutine de synchronisation et attend sa terminaison.
+// Stop arrête la goroutine de synchronisation, attend sa terminaison puis libère le bail.
 func (s *Service) Stop() {
 	close(s.stopChan)
 	s.wg.Wait()
 	s.syncTicker.Stop()
+
+	if s.elector != nil {
+		s.elector.Stop()
+	}
 }
 
 // TrackImpression incrémente le compteur d'impressions pour une publicité donnée.
@@ -64,10 +171,35 @@ func (s *Service) GetImpressionCount(ctx context.Context, adID string) (int64, e
 	return s.cacheRepo.Get(ctx, adID)
 }
 
-// Track incrémente le compteur d'impressions pour une publicité donnée.
+// Track fait passer l'impression par le filtre de trafic invalide (s'il est activé),
+// incrémente le compteur correspondant (valide ou invalide) du locataire du contexte
+// puis, si le journal est activé, y ajoute l'impression brute.
+// Un échec du journal n'annule pas le comptage : il est seulement loggé.
 // Implémente l'interface in.ImpressionService.
-func (s *Service) Track(ctx context.Context, adID string) error {
-	return s.TrackImpression(ctx, adID)
+func (s *Service) Track(ctx context.Context, imp domain.Impression) error {
+	imp.Tenant = domain.TenantFromContext(ctx)
+	if s.trafficFilter != nil {
+		imp.IVTReason = s.trafficFilter.Inspect(ctx, imp)
+	}
+
+	if imp.Valid() {
+		if err := s.TrackImpression(ctx, imp.AdID); err != nil {
+			return err
+		}
+	} else {
+		if _, err := s.invalid.cache.Increment(ctx, imp.AdID); err != nil {
+			return err
+		}
+		s.logger.InfoContext(ctx, "Invalid traffic", "ad_id", imp.AdID, "tenant", imp.Tenant, "reason", imp.IVTReason, "ip", imp.Context.IPAddress)
+	}
+	s.metrics.ImpressionTracked(imp.Valid())
+
+	if s.eventStore != nil {
+		if err := s.eventStore.Append(ctx, imp); err != nil {
+			s.logger.WarnContext(ctx, "Failed to append impression to event log", "impression_id", imp.ID, "ad_id", imp.AdID, "error", err)
+		}
+	}
+	return nil
 }
 
 // GetCount récupère le nombre actuel d'impressions pour une publicité donnée.
@@ -76,36 +208,243 @@ func (s *Service) GetCount(ctx context.Context, adID string) (int64, error) {
 	return s.GetImpressionCount(ctx, adID)
 }
 
-// sync synchronise les compteurs d'impressions entre le cache et le stockage persistant.
-// Pour chaque publicité :
-// 1. Récupère et réinitialise le compteur dans le cache
-// 2. Si le compteur est > 0, persiste le delta dans MongoDB
+// TrackEvent valide puis compte un événement d'engagement pour une publicité.
+// Comme les impressions, l'événement passe par le filtre de trafic invalide (s'il est activé) :
+// un événement signalé est ignoré, afin que robots et IP suspectes ne faussent pas le taux
+// de visibilité. Un événement déjà compté pour la même impression est ignoré de même.
+// Implémente l'interface in.ImpressionService.
+func (s *Service) TrackEvent(ctx context.Context, event domain.Event) error {
+	if err := event.Validate(); err != nil {
+		return err
+	}
+
+	c, ok := s.engagement[event.Type]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrEventNotTracked, event.Type)
+	}
+	if s.trafficFilter != nil {
+		if reason := s.trafficFilter.InspectEvent(ctx, event); reason != domain.IVTNone {
+			s.logger.InfoContext(ctx, "Invalid traffic event ignored", "ad_id", event.AdID, "impression_id", event.ImpressionID, "event", event.Type, "reason", reason, "ip", event.Context.IPAddress)
+			return nil
+		}
+	}
+	if s.dedup != nil {
+		first, err := s.dedup.FirstSeen(ctx, event.ImpressionID, event.Type, s.dedupTTL)
+		if err != nil {
+			return err
+		}
+		if !first {
+			s.logger.DebugContext(ctx, "Duplicate event ignored", "ad_id", event.AdID, "impression_id", event.ImpressionID, "event", event.Type)
+			return nil
+		}
+	}
+	if _, err := c.cache.Increment(ctx, event.AdID); err != nil {
+		return err
+	}
+	s.metrics.EventTracked(string(event.Type))
+	return nil
+}
+
+// GetEngagementReport retourne les compteurs d'impressions et d'événements d'engagement
+// d'une publicité, à partir desquels est calculé son taux de visibilité.
+// Implémente l'interface in.ImpressionService.
+func (s *Service) GetEngagementReport(ctx context.Context, adID string) (domain.EngagementReport, error) {
+	report := domain.EngagementReport{AdID: adID}
+
+	totals := map[domain.EventType]*int64{
+		domain.EventRendered: &report.Rendered,
+		domain.EventViewable: &report.Viewable,
+		domain.EventHover:    &report.Hovers,
+		domain.EventClose:    &report.Closes,
+	}
+
+	var err error
+	if report.Impressions, err = s.impressions().total(ctx, adID); err != nil {
+		return report, err
+	}
+	for eventType, c := range s.engagement {
+		dst, ok := totals[eventType]
+		if !ok {
+			continue
+		}
+		if *dst, err = c.total(ctx, adID); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// GetTrafficReport retourne le trafic valide et invalide d'une publicité :
+// total déjà persisté augmenté des compteurs en cache non encore synchronisés.
+// Implémente l'interface in.ImpressionService.
+func (s *Service) GetTrafficReport(ctx context.Context, adID string) (domain.TrafficReport, error) {
+	report := domain.TrafficReport{AdID: adID}
+
+	var err error
+	if report.Valid, err = s.impressions().total(ctx, adID); err != nil {
+		return report, err
+	}
+	if s.trafficFilter != nil {
+		if report.Invalid, err = s.invalid.total(ctx, adID); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// impressions retourne le compteur des impressions valides
+func (s *Service) impressions() counter {
+	return counter{cache: s.cacheRepo, store: s.storeRepo}
+}
+
+// total additionne le total persisté et le compteur en cache d'une publicité
+func (c counter) total(ctx context.Context, adID string) (int64, error) {
+	persisted, err := c.store.GetTotal(ctx, adID)
+	if err != nil {
+		return 0, err
+	}
+	pending, err := c.cache.Get(ctx, adID)
+	if err != nil {
+		return 0, err
+	}
+	return persisted + pending, nil
+}
+
+// GetTrafficBetween retourne le trafic valide et invalide d'une publicité synchronisé dans [from, to).
+// Si la période inclut l'instant présent, les compteurs en cache non encore synchronisés sont ajoutés.
+// Implémente l'interface in.ImpressionService.
+func (s *Service) GetTrafficBetween(ctx context.Context, adID string, from, to time.Time) (domain.TrafficReport, error) {
+	report := domain.TrafficReport{AdID: adID}
+	now := s.clock.Now()
+	includePending := !now.Before(from) && now.Before(to)
+
+	var err error
+	if report.Valid, err = s.impressions().totalBetween(ctx, adID, from, to, includePending); err != nil {
+		return report, err
+	}
+	if s.trafficFilter != nil {
+		if report.Invalid, err = s.invalid.totalBetween(ctx, adID, from, to, includePending); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// totalBetween additionne les deltas synchronisés dans [from, to) et, si demandé,
+// le compteur en cache d'une publicité
+func (c counter) totalBetween(ctx context.Context, adID string, from, to time.Time, includePending bool) (int64, error) {
+	persisted, err := c.store.GetTotalBetween(ctx, adID, from, to)
+	if err != nil || !includePending {
+		return persisted, err
+	}
+	pending, err := c.cache.Get(ctx, adID)
+	if err != nil {
+		return 0, err
+	}
+	return persisted + pending, nil
+}
+
+// ExportImpressions parcourt les impressions brutes reçues dans [from, to).
+// Implémente l'interface in.ImpressionService.
+func (s *Service) ExportImpressions(ctx context.Context, from, to time.Time, fn func(domain.Impression) error) error {
+	if s.eventStore == nil {
+		return ErrEventLogDisabled
+	}
+	return s.eventStore.Scan(ctx, from, to, fn)
+}
+
+// sync synchronise les compteurs entre le cache et le stockage persistant :
+// trafic valide, trafic invalide si le filtrage est activé, puis événements d'engagement.
+// Avec l'élection de leader, seule l'instance leader synchronise. Son jeton de fencing est
+// revérifié avant chaque compteur pour s'arrêter dès la perte du bail, et accompagne chaque
+// réinitialisation du cache : un ancien leader ne peut plus prélever un compteur déjà
+// réinitialisé par son successeur, même si le bail expire pendant la synchronisation.
 func (s *Service) sync() {
 	ctx := context.Background()
+	start := s.clock.Now()
+	defer func() { s.metrics.SyncCompleted(s.clock.Now().Sub(start)) }()
+
+	var token int64
+	if s.elector != nil {
+		var leader bool
+		if token, leader = s.elector.Token(); !leader {
+			return
+		}
+	}
 
-	// Get all ad IDs from cache
-	adIDs, err := s.cacheRepo.GetAllKeys(ctx)
+	counters := map[string]counter{"impressions": s.impressions()}
+	if s.trafficFilter != nil {
+		counters["invalid impressions"] = s.invalid
+	}
+	for eventType, c := range s.engagement {
+		counters[string(eventType)+" events"] = c
+	}
+
+	for label, c := range counters {
+		if s.elector != nil {
+			valid, err := s.elector.Validate(ctx, token)
+			if err != nil || !valid {
+				s.logger.WarnContext(ctx, "Sync aborted: fencing token is no longer valid", "token", token, "error", err)
+				return
+			}
+		}
+		if !c.sync(ctx, label, token, s.metrics, s.logger) {
+			return
+		}
+	}
+}
+
+// sync synchronise le compteur en cache vers son stockage persistant.
+// Pour chaque publicité de chaque locataire :
+// 1. Récupère et réinitialise le compteur dans le cache sous le jeton de fencing (0 sans élection)
+// 2. Si le compteur est > 0, persiste le delta dans MongoDB sous le même locataire
+// Retourne false si le jeton est périmé : la synchronisation doit alors s'arrêter.
+func (c counter) sync(ctx context.Context, label string, token int64, metrics out.ServiceMetrics, logger *slog.Logger) bool {
+	// Get all counters from cache
+	keys, err := c.cache.GetAllKeys(ctx)
 	if err != nil {
-		log.Printf("Error getting keys from cache: %v", err)
-		return
+		logger.ErrorContext(ctx, "Failed to get keys from cache", "counter", label, "error", err)
+		metrics.SyncError(label)
+		return true
 	}
+	metrics.CacheKeys(label, len(keys))
+
+	// Process each counter in its tenant
+	for _, key := range keys {
+		ctx := domain.WithTenant(ctx, key.Tenant)
 
-	// Process each ad ID
-	for _, adID := range adIDs {
 		// Get and reset the count in cache
-		count, err := s.cacheRepo.Reset(ctx, adID)
+		count, err := c.cache.Reset(ctx, key.AdID, token)
+		if errors.Is(err, out.ErrStaleToken) {
+			logger.WarnContext(ctx, "Sync aborted: counter already reset under a newer fencing token", "counter", label, "token", token)
+			return false
+		}
 		if err != nil {
-			log.Printf("Error resetting count for ad %s: %v", adID, err)
+			logger.ErrorContext(ctx, "Failed to reset count", "counter", label, "tenant", key.Tenant, "ad_id", key.AdID, "error", err)
+			metrics.SyncError(label)
 			continue
 		}
 
 		// If there were impressions, persist the delta
 		if count > 0 {
-			if err := s.storeRepo.PersistDelta(ctx, adID, count); err != nil {
-				log.Printf("Error persisting delta for ad %s: %v", adID, err)
+			if err := c.store.PersistDelta(ctx, key.AdID, count); err != nil {
+				logger.ErrorContext(ctx, "Failed to persist delta", "counter", label, "tenant", key.Tenant, "ad_id", key.AdID, "error", err)
+				metrics.SyncError(label)
 				continue
 			}
-			log.Printf("Synced %d impressions for ad %s", count, adID)
+			metrics.DeltaPersisted(label, count)
+			logger.DebugContext(ctx, "Synced", "counter", label, "tenant", key.Tenant, "ad_id", key.AdID, "count", count)
 		}
 	}
+	return true
 }
+
+// nopMetrics ignore les métriques lorsqu'aucun enregistreur n'est configuré
+type nopMetrics struct{}
+
+func (nopMetrics) ImpressionTracked(bool)       {}
+func (nopMetrics) EventTracked(string)          {}
+func (nopMetrics) DeltaPersisted(string, int64) {}
+func (nopMetrics) SyncError(string)             {}
+func (nopMetrics) CacheKeys(string, int)        {}
+func (nopMetrics) SyncCompleted(time.Duration)  {}
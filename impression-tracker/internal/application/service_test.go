@@ -0,0 +1,178 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"impression-tracker/internal/adapters/clock"
+	"impression-tracker/internal/adapters/memory"
+	"impression-tracker/internal/domain"
+	"impression-tracker/internal/ports/out"
+)
+
+var testStart = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// discard est le logger des tests
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// uaFilter signale le trafic dont le User-Agent vaut botUA
+type uaFilter struct {
+	botUA string
+}
+
+func (f uaFilter) Inspect(_ context.Context, imp domain.Impression) domain.IVTReason {
+	return f.inspect(imp.Context)
+}
+
+func (f uaFilter) InspectEvent(_ context.Context, event domain.Event) domain.IVTReason {
+	return f.inspect(event.Context)
+}
+
+func (f uaFilter) inspect(c domain.ImpressionContext) domain.IVTReason {
+	if c.UserAgent == f.botUA {
+		return domain.IVTKnownBot
+	}
+	return domain.IVTNone
+}
+
+// newEngagementService crée un service en mémoire comptant les événements d'engagement,
+// dédoublonnés pendant une heure, avec le filtre de trafic invalide uaFilter{"bot"}
+func newEngagementService(t *testing.T) (*Service, *clock.Fake) {
+	t.Helper()
+	fake := clock.NewFake(testStart)
+	opts := []Option{
+		WithEventDeduplication(memory.NewEventDeduplicator(fake), time.Hour),
+		WithTrafficFilter(uaFilter{botUA: "bot"}, memory.NewCacheRepository(), memory.NewMetricsRepository(fake)),
+	}
+	for _, eventType := range domain.EngagementEvents {
+		opts = append(opts, WithEngagementCounter(eventType, memory.NewCacheRepository(), memory.NewMetricsRepository(fake)))
+	}
+	return NewService(memory.NewCacheRepository(), memory.NewMetricsRepository(fake), fake, time.Minute, discard, opts...), fake
+}
+
+func viewable(adID, impressionID string) domain.Event {
+	return domain.Event{
+		AdID:            adID,
+		ImpressionID:    impressionID,
+		Type:            domain.EventViewable,
+		VisibleRatio:    0.8,
+		VisibleDuration: 2 * time.Second,
+	}
+}
+
+func rendered(adID, impressionID string) domain.Event {
+	return domain.Event{AdID: adID, ImpressionID: impressionID, Type: domain.EventRendered}
+}
+
+func mustTrackEvent(t *testing.T, s *Service, ctx context.Context, events ...domain.Event) {
+	t.Helper()
+	for _, e := range events {
+		if err := s.TrackEvent(ctx, e); err != nil {
+			t.Fatalf("TrackEvent(%s, %s) error: %v", e.Type, e.ImpressionID, err)
+		}
+	}
+}
+
+func engagementReport(t *testing.T, s *Service, ctx context.Context, adID string) domain.EngagementReport {
+	t.Helper()
+	report, err := s.GetEngagementReport(ctx, adID)
+	if err != nil {
+		t.Fatalf("GetEngagementReport() error: %v", err)
+	}
+	return report
+}
+
+func TestTrackEventCountsEachEventOncePerImpression(t *testing.T) {
+	s, _ := newEngagementService(t)
+	ctx := context.Background()
+
+	mustTrackEvent(t, s, ctx,
+		rendered("ad", "imp-1"), viewable("ad", "imp-1"),
+		viewable("ad", "imp-1"), viewable("ad", "imp-1"), rendered("ad", "imp-1"),
+		rendered("ad", "imp-2"),
+	)
+
+	report := engagementReport(t, s, ctx, "ad")
+	if report.Rendered != 2 || report.Viewable != 1 {
+		t.Errorf("Rendered, Viewable = %d, %d, want 2, 1", report.Rendered, report.Viewable)
+	}
+	if rate := report.ViewabilityRate(); rate != 0.5 {
+		t.Errorf("ViewabilityRate() = %v, want 0.5", rate)
+	}
+}
+
+func TestTrackEventDeduplicationExpires(t *testing.T) {
+	s, fake := newEngagementService(t)
+	ctx := context.Background()
+
+	mustTrackEvent(t, s, ctx, rendered("ad", "imp-1"))
+	fake.Advance(59 * time.Minute)
+	mustTrackEvent(t, s, ctx, rendered("ad", "imp-1"))
+	fake.Advance(time.Minute)
+	mustTrackEvent(t, s, ctx, rendered("ad", "imp-1"))
+
+	if got := engagementReport(t, s, ctx, "ad").Rendered; got != 2 {
+		t.Errorf("Rendered = %d, want 2 (second event inside the window, third after it)", got)
+	}
+}
+
+func TestTrackEventDeduplicationIsPerTenant(t *testing.T) {
+	s, _ := newEngagementService(t)
+	acme := domain.WithTenant(context.Background(), "acme")
+	globex := domain.WithTenant(context.Background(), "globex")
+
+	mustTrackEvent(t, s, acme, rendered("ad", "imp-1"))
+	mustTrackEvent(t, s, globex, rendered("ad", "imp-1"))
+
+	for _, ctx := range []context.Context{acme, globex} {
+		if got := engagementReport(t, s, ctx, "ad").Rendered; got != 1 {
+			t.Errorf("tenant %q: Rendered = %d, want 1", domain.TenantFromContext(ctx), got)
+		}
+	}
+}
+
+func TestTrackEventIgnoresInvalidTraffic(t *testing.T) {
+	s, _ := newEngagementService(t)
+	ctx := context.Background()
+
+	bot := viewable("ad", "imp-1")
+	bot.Context.UserAgent = "bot"
+	mustTrackEvent(t, s, ctx, bot)
+	if got := engagementReport(t, s, ctx, "ad").Viewable; got != 0 {
+		t.Fatalf("Viewable = %d after a bot event, want 0", got)
+	}
+
+	// L'événement écarté ne consomme pas la déduplication de l'impression
+	mustTrackEvent(t, s, ctx, viewable("ad", "imp-1"))
+	if got := engagementReport(t, s, ctx, "ad").Viewable; got != 1 {
+		t.Errorf("Viewable = %d, want 1", got)
+	}
+}
+
+func TestTrackEventRejectsInvalidEvents(t *testing.T) {
+	s, _ := newEngagementService(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		event domain.Event
+		want  error
+	}{
+		{name: "missing impression", event: rendered("ad", ""), want: domain.ErrMissingImpressionID},
+		{name: "below MRC threshold", event: domain.Event{AdID: "ad", ImpressionID: "imp-1", Type: domain.EventViewable, VisibleRatio: 0.4, VisibleDuration: time.Second}, want: domain.ErrNotViewable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.TrackEvent(ctx, tt.event); !errors.Is(err, tt.want) {
+				t.Errorf("TrackEvent() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// Ensure uaFilter implements the TrafficFilter interface
+var _ out.TrafficFilter = uaFilter{}
@@ -0,0 +1,342 @@
+package config
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// Config regroupe toute la configuration de l'impression-tracker. Chaque champ porte sa clé YAML
+// et la variable d'environnement qui le surcharge ; sur une structure imbriquée, le tag env
+// préfixe les variables de ses champs. Les secrets sont masqués à l'affichage.
+type Config struct {
+	Service   ServiceConfig   `yaml:"service"`
+	Storage   StorageConfig   `yaml:"storage"`
+	Log       LogConfig       `yaml:"log"`
+	GRPC      GRPCConfig      `yaml:"grpc"`
+	TLS       TLSConfig       `yaml:"tls"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Dragonfly DragonflyConfig `yaml:"dragonfly"`
+	MongoDB   MongoDBConfig   `yaml:"mongodb"`
+	Sync      SyncConfig      `yaml:"sync"`
+	Leader    LeaderConfig    `yaml:"leader"`
+	EventLog  EventLogConfig  `yaml:"event_log"`
+	Events    EventsConfig    `yaml:"events"`
+	IVT       IVTConfig       `yaml:"ivt"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Gateway   GatewayConfig   `yaml:"gateway"`
+	Health    HealthConfig    `yaml:"health"`
+	Metrics   MetricsConfig   `yaml:"metrics"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+}
+
+// ServiceConfig identifie l'instance dans les traces
+type ServiceConfig struct {
+	Name        string `yaml:"name" env:"SERVICE_NAME"`
+	Environment string `yaml:"environment" env:"ENVIRONMENT"`
+}
+
+// StorageConfig choisit les adaptateurs de stockage
+type StorageConfig struct {
+	Backend string `yaml:"backend" env:"STORAGE"` // external (MongoDB et Dragonfly) ou memory
+}
+
+// Memory indique si les compteurs sont gardés en mémoire, sans MongoDB ni Dragonfly
+func (c StorageConfig) Memory() bool {
+	return c.Backend == "memory"
+}
+
+// LogConfig règle les logs JSON
+type LogConfig struct {
+	Level       string `yaml:"level" env:"LOG_LEVEL"`               // debug, info, warn ou error
+	SampleEvery int    `yaml:"sample_every" env:"LOG_SAMPLE_EVERY"` // Une impression journalisée sur N sous le niveau warn
+}
+
+// GRPCConfig est l'adresse d'écoute du serveur gRPC
+type GRPCConfig struct {
+	Addr string `yaml:"addr" env:"GRPC_ADDR"`
+}
+
+// TLSConfig active le TLS du serveur gRPC, et le mTLS si une autorité des clients est fournie
+type TLSConfig struct {
+	CertFile       string        `yaml:"cert_file" env:"TLS_CERT_FILE"`
+	KeyFile        string        `yaml:"key_file" env:"TLS_KEY_FILE"`
+	ClientCAFile   string        `yaml:"client_ca_file" env:"TLS_CLIENT_CA_FILE"`
+	ReloadInterval time.Duration `yaml:"reload_interval" env:"TLS_RELOAD_INTERVAL"` // 0 : jamais
+}
+
+// Enabled indique si le serveur gRPC chiffre ses connexions
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.ClientCAFile != ""
+}
+
+// AuthConfig identifie les appelants par le nom commun de leur certificat client : un service
+// de confiance agit pour tout locataire, tout autre client pour l'organisation de son certificat
+type AuthConfig struct {
+	ServiceClients       []string `yaml:"service_clients" env:"AUTH_SERVICE_CLIENTS"`             // Séparés par des virgules dans la variable
+	GatewayClient        string   `yaml:"gateway_client" env:"AUTH_GATEWAY_CLIENT"`               // Certificat de la passerelle REST (GATEWAY_TLS_CERT_FILE)
+	TrustUnauthenticated bool     `yaml:"trust_unauthenticated" env:"AUTH_TRUST_UNAUTHENTICATED"` // Développement sans mTLS uniquement
+}
+
+// ClientTLSConfig est le TLS d'une connexion sortante : certificat pour le mTLS, autorité du serveur
+type ClientTLSConfig struct {
+	CertFile   string `yaml:"cert_file" env:"CERT_FILE"`
+	KeyFile    string `yaml:"key_file" env:"KEY_FILE"`
+	CAFile     string `yaml:"ca_file" env:"CA_FILE"`
+	ServerName string `yaml:"server_name" env:"SERVER_NAME"`
+}
+
+// DragonflyConfig est la connexion au cache des compteurs : un nœud, des sentinelles ou un cluster
+type DragonflyConfig struct {
+	Mode             string          `yaml:"mode" env:"DRAGONFLY_MODE"`  // standalone, sentinel ou cluster
+	Addrs            []string        `yaml:"addrs" env:"DRAGONFLY_ADDR"` // Séparées par des virgules dans la variable
+	MasterName       string          `yaml:"master_name" env:"DRAGONFLY_MASTER_NAME"`
+	Username         string          `yaml:"username" env:"DRAGONFLY_USERNAME"`
+	Password         string          `yaml:"password" env:"DRAGONFLY_PASSWORD" secret:"true"`
+	SentinelUsername string          `yaml:"sentinel_username" env:"DRAGONFLY_SENTINEL_USERNAME"`
+	SentinelPassword string          `yaml:"sentinel_password" env:"DRAGONFLY_SENTINEL_PASSWORD" secret:"true"`
+	DB               int             `yaml:"db" env:"DRAGONFLY_DB"`
+	TLSEnabled       bool            `yaml:"tls_enabled" env:"DRAGONFLY_TLS_ENABLED"` // Implicite si un fichier TLS est fourni
+	TLS              ClientTLSConfig `yaml:"tls" env:"DRAGONFLY_TLS_"`
+	Pool             PoolConfig      `yaml:"pool" env:"DRAGONFLY_"`
+}
+
+// PoolConfig règle le pool de connexions et les délais d'un client Dragonfly
+type PoolConfig struct {
+	Size         int           `yaml:"size" env:"POOL_SIZE"` // Connexions par nœud, 0 : 10 par CPU
+	MinIdleConns int           `yaml:"min_idle_conns" env:"MIN_IDLE_CONNS"`
+	MaxRetries   int           `yaml:"max_retries" env:"MAX_RETRIES"` // -1 : aucune nouvelle tentative
+	DialTimeout  time.Duration `yaml:"dial_timeout" env:"DIAL_TIMEOUT"`
+	ReadTimeout  time.Duration `yaml:"read_timeout" env:"READ_TIMEOUT"`
+	WriteTimeout time.Duration `yaml:"write_timeout" env:"WRITE_TIMEOUT"`
+	Timeout      time.Duration `yaml:"timeout" env:"POOL_TIMEOUT"` // Attente d'une connexion libre
+}
+
+// MongoDBConfig est la base des compteurs persistés
+type MongoDBConfig struct {
+	URI                  string `yaml:"uri" env:"MONGO_URI" secret:"url"`
+	Database             string `yaml:"database" env:"MONGO_DB"`
+	Collection           string `yaml:"collection" env:"MONGO_COLLECTION"`                 // Deltas d'impressions
+	EngagementCollection string `yaml:"engagement_collection" env:"ENGAGEMENT_COLLECTION"` // Deltas des événements d'engagement
+	Migrate              bool   `yaml:"migrate" env:"MONGO_MIGRATE"`                       // Migrations du schéma au démarrage
+}
+
+// SyncConfig règle la synchronisation des compteurs vers MongoDB
+type SyncConfig struct {
+	Interval time.Duration `yaml:"interval" env:"SYNC_INTERVAL"`
+}
+
+// LeaderConfig règle l'élection de la réplica qui synchronise
+type LeaderConfig struct {
+	Enabled  bool          `yaml:"enabled" env:"LEADER_ELECTION_ENABLED"`
+	LeaseTTL time.Duration `yaml:"lease_ttl" env:"LEADER_LEASE_TTL"`
+}
+
+// EventLogConfig règle le journal des impressions brutes
+type EventLogConfig struct {
+	Enabled    bool   `yaml:"enabled" env:"EVENT_LOG_ENABLED"`
+	Collection string `yaml:"collection" env:"EVENT_LOG_COLLECTION"`
+}
+
+// EventsConfig règle le comptage des événements d'engagement
+type EventsConfig struct {
+	DedupTTL time.Duration `yaml:"dedup_ttl" env:"EVENT_DEDUP_TTL"` // Durée pendant laquelle un événement d'une impression n'est compté qu'une fois
+}
+
+// IVTConfig règle le filtrage du trafic invalide
+type IVTConfig struct {
+	Enabled         bool          `yaml:"enabled" env:"IVT_ENABLED"`
+	Collection      string        `yaml:"collection" env:"IVT_COLLECTION"`
+	BotUAFile       string        `yaml:"bot_ua_file" env:"IVT_BOT_UA_FILE"`
+	IPBlocklistFile string        `yaml:"ip_blocklist_file" env:"IVT_IP_BLOCKLIST_FILE"`
+	DataCenterFile  string        `yaml:"datacenter_file" env:"IVT_DATACENTER_FILE"`
+	RateLimit       int           `yaml:"rate_limit" env:"IVT_RATE_LIMIT"` // Impressions par IP et par fenêtre, 0 : aucune limite
+	RateWindow      time.Duration `yaml:"rate_window" env:"IVT_RATE_WINDOW"`
+}
+
+// Limit est une limite de débit : jetons par seconde, capacité du seau et quota journalier
+type Limit struct {
+	Rate  float64 `yaml:"rate" env:"RATE"`   // 0 : débit illimité
+	Burst int     `yaml:"burst" env:"BURST"` // 0 : Rate arrondi au supérieur
+	Daily int64   `yaml:"daily" env:"DAILY"` // 0 : aucun quota
+}
+
+// RateLimitConfig règle la limitation de débit de TrackImpression et TrackEvent
+type RateLimitConfig struct {
+	Enabled        bool  `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	Tenant         Limit `yaml:"tenant" env:"RATE_LIMIT_TENANT_"`
+	IP             Limit `yaml:"ip" env:"RATE_LIMIT_IP_"`
+	TrustForwarded bool  `yaml:"trust_forwarded" env:"RATE_LIMIT_TRUST_FORWARDED"`
+}
+
+// GatewayConfig règle la passerelle REST/JSON
+type GatewayConfig struct {
+	Enabled  bool            `yaml:"enabled" env:"GATEWAY_ENABLED"`
+	Addr     string          `yaml:"addr" env:"GATEWAY_ADDR"`
+	GRPCAddr string          `yaml:"grpc_addr" env:"GATEWAY_GRPC_ADDR"`
+	TLS      ClientTLSConfig `yaml:"tls" env:"GATEWAY_TLS_"` // Connexion au serveur gRPC
+}
+
+// HealthConfig règle la vérification périodique des dépendances
+type HealthConfig struct {
+	Interval time.Duration `yaml:"interval" env:"HEALTH_CHECK_INTERVAL"`
+	Timeout  time.Duration `yaml:"timeout" env:"HEALTH_CHECK_TIMEOUT"`
+}
+
+// MetricsConfig est l'adresse de l'endpoint Prometheus
+type MetricsConfig struct {
+	Addr string `yaml:"addr" env:"METRICS_ADDR"`
+}
+
+// TracingConfig règle l'export des traces OpenTelemetry
+type TracingConfig struct {
+	Exporter     string  `yaml:"exporter" env:"TRACING_EXPORTER"` // none, otlp, stdout ou file
+	OTLPEndpoint string  `yaml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT"`
+	File         string  `yaml:"file" env:"TRACING_FILE"`
+	SampleRatio  float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+// DeltaCollections retourne les collections de deltas : impressions, événements
+// d'engagement et trafic invalide, que ce dernier soit activé ou non
+func (c *Config) DeltaCollections() []string {
+	return []string{c.MongoDB.Collection, c.MongoDB.EngagementCollection, c.IVT.Collection}
+}
+
+// Default retourne la configuration par défaut
+func Default() *Config {
+	return &Config{
+		Service: ServiceConfig{Name: "impression-tracker", Environment: "development"},
+		Storage: StorageConfig{Backend: "external"},
+		Log:     LogConfig{Level: "info", SampleEvery: 1},
+		GRPC:    GRPCConfig{Addr: ":50052"},
+		TLS:     TLSConfig{ReloadInterval: 30 * time.Second},
+		Auth:    AuthConfig{ServiceClients: []string{"adserver"}, GatewayClient: "impression-tracker-gateway"},
+		Dragonfly: DragonflyConfig{
+			Mode:  "standalone",
+			Addrs: []string{"localhost:6379"},
+			Pool: PoolConfig{
+				MaxRetries:   3,
+				DialTimeout:  5 * time.Second,
+				ReadTimeout:  3 * time.Second,
+				WriteTimeout: 3 * time.Second,
+				Timeout:      4 * time.Second,
+			},
+		},
+		MongoDB: MongoDBConfig{
+			URI:                  "mongodb://mongodb:27017",
+			Database:             "impression_tracker",
+			Collection:           "impressions",
+			EngagementCollection: "ad_events",
+			Migrate:              true,
+		},
+		Sync:      SyncConfig{Interval: time.Minute},
+		Leader:    LeaderConfig{Enabled: true, LeaseTTL: 15 * time.Second},
+		EventLog:  EventLogConfig{Collection: "impression_events"},
+		Events:    EventsConfig{DedupTTL: 24 * time.Hour},
+		IVT:       IVTConfig{Collection: "invalid_impressions", RateWindow: time.Minute},
+		RateLimit: RateLimitConfig{Tenant: Limit{Rate: 500, Burst: 1000}},
+		Gateway:   GatewayConfig{Enabled: true, Addr: ":8080", GRPCAddr: "localhost:50052"},
+		Health:    HealthConfig{Interval: 10 * time.Second, Timeout: 2 * time.Second},
+		Metrics:   MetricsConfig{Addr: ":9090"},
+		Tracing:   TracingConfig{Exporter: "none", OTLPEndpoint: "otel-collector:4317", File: "/app/traces.json", SampleRatio: 1},
+	}
+}
+
+// Validate vérifie toute la configuration et rapporte chaque valeur invalide
+func (c *Config) Validate() error {
+	var v validator
+
+	var level slog.Level
+	v.check(level.UnmarshalText([]byte(c.Log.Level)) == nil, "LOG_LEVEL must be debug, info, warn or error")
+	v.check(c.Log.SampleEvery >= 1, "LOG_SAMPLE_EVERY must be a positive integer")
+	v.check(c.Service.Name != "", "SERVICE_NAME must not be empty")
+	v.check(c.Storage.Backend == "external" || c.Storage.Memory(), "STORAGE must be external or memory")
+	v.check(!c.Storage.Memory() || !c.RateLimit.Enabled, "RATE_LIMIT_ENABLED requires STORAGE=external: buckets live in Dragonfly")
+	v.check(c.GRPC.Addr != "", "GRPC_ADDR must not be empty")
+
+	v.check(c.TLS.ReloadInterval >= 0, "TLS_RELOAD_INTERVAL must be a non-negative duration")
+	v.check(c.TLS.ClientCAFile == "" || c.TLS.CertFile != "", "TLS_CLIENT_CA_FILE requires TLS_CERT_FILE and TLS_KEY_FILE")
+	v.check((c.TLS.CertFile == "") == (c.TLS.KeyFile == ""), "TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	v.check(c.TLS.ClientCAFile != "" || c.Auth.TrustUnauthenticated, "TLS_CLIENT_CA_FILE is required to authenticate callers (AUTH_TRUST_UNAUTHENTICATED=true in development only)")
+	v.check(!slices.Contains(c.Auth.ServiceClients, c.Auth.GatewayClient), "AUTH_GATEWAY_CLIENT must not be one of AUTH_SERVICE_CLIENTS")
+	v.check(!c.Gateway.Enabled || c.TLS.ClientCAFile == "" || c.Gateway.TLS.CertFile != "", "GATEWAY_TLS_CERT_FILE is required when TLS_CLIENT_CA_FILE enforces mutual TLS")
+	v.check((c.Gateway.TLS.CertFile == "") == (c.Gateway.TLS.KeyFile == ""), "GATEWAY_TLS_CERT_FILE and GATEWAY_TLS_KEY_FILE must be set together")
+
+	v.dragonfly(c.Dragonfly)
+	v.check(c.MongoDB.URI != "", "MONGO_URI must not be empty")
+	v.check(c.MongoDB.Database != "", "MONGO_DB must not be empty")
+	v.check(c.MongoDB.Collection != "", "MONGO_COLLECTION must not be empty")
+	v.check(c.MongoDB.EngagementCollection != "", "ENGAGEMENT_COLLECTION must not be empty")
+
+	v.check(c.Sync.Interval > 0, "SYNC_INTERVAL must be a positive duration")
+	v.check(!c.Leader.Enabled || c.Leader.LeaseTTL > 0, "LEADER_LEASE_TTL must be a positive duration")
+	v.check(!c.EventLog.Enabled || c.EventLog.Collection != "", "EVENT_LOG_COLLECTION must not be empty")
+	v.check(c.Events.DedupTTL > 0, "EVENT_DEDUP_TTL must be a positive duration")
+	v.check(!c.IVT.Enabled || c.IVT.Collection != "", "IVT_COLLECTION must not be empty")
+	v.check(c.IVT.RateLimit >= 0, "IVT_RATE_LIMIT must be a non-negative integer")
+	v.check(c.IVT.RateWindow > 0, "IVT_RATE_WINDOW must be a positive duration")
+
+	v.limit("RATE_LIMIT_TENANT", c.RateLimit.Tenant)
+	v.limit("RATE_LIMIT_IP", c.RateLimit.IP)
+
+	v.check(!c.Gateway.Enabled || (c.Gateway.Addr != "" && c.Gateway.GRPCAddr != ""), "GATEWAY_ADDR and GATEWAY_GRPC_ADDR must not be empty")
+	v.check(c.Health.Interval > 0, "HEALTH_CHECK_INTERVAL must be a positive duration")
+	v.check(c.Health.Timeout > 0, "HEALTH_CHECK_TIMEOUT must be a positive duration")
+	v.check(c.Metrics.Addr != "", "METRICS_ADDR must not be empty")
+
+	switch c.Tracing.Exporter {
+	case "none", "otlp", "stdout", "file":
+	default:
+		v.check(false, "TRACING_EXPORTER must be none, otlp, stdout or file")
+	}
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "TRACING_SAMPLE_RATIO must be between 0 and 1")
+	return v.err()
+}
+
+// validator accumule les erreurs de validation
+type validator struct {
+	errs []error
+}
+
+func (v *validator) check(ok bool, msg string) {
+	if !ok {
+		v.errs = append(v.errs, errors.New(msg))
+	}
+}
+
+// limit vérifie la limite {name}_RATE, {name}_BURST et {name}_DAILY
+func (v *validator) limit(name string, l Limit) {
+	v.check(l.Rate >= 0, name+"_RATE must be a non-negative number")
+	v.check(l.Burst >= 0, name+"_BURST must be a non-negative integer")
+	v.check(l.Daily >= 0, name+"_DAILY must be a non-negative integer")
+}
+
+// dragonfly vérifie la connexion à Dragonfly selon son mode
+func (v *validator) dragonfly(c DragonflyConfig) {
+	switch c.Mode {
+	case "standalone":
+		v.check(len(c.Addrs) == 1, "DRAGONFLY_ADDR must be a single address in standalone mode")
+	case "sentinel":
+		v.check(len(c.Addrs) > 0, "DRAGONFLY_ADDR must list the sentinel addresses")
+		v.check(c.MasterName != "", "DRAGONFLY_MASTER_NAME is required in sentinel mode")
+	case "cluster":
+		v.check(len(c.Addrs) > 0, "DRAGONFLY_ADDR must list the cluster seed addresses")
+		v.check(c.DB == 0, "DRAGONFLY_DB must be 0 in cluster mode")
+	default:
+		v.check(false, "DRAGONFLY_MODE must be standalone, sentinel or cluster")
+	}
+	v.check(c.DB >= 0, "DRAGONFLY_DB must be a non-negative integer")
+	v.check((c.TLS.CertFile == "") == (c.TLS.KeyFile == ""), "DRAGONFLY_TLS_CERT_FILE and DRAGONFLY_TLS_KEY_FILE must be set together")
+	v.check(c.Pool.Size >= 0, "DRAGONFLY_POOL_SIZE must be a non-negative integer")
+	v.check(c.Pool.MinIdleConns >= 0, "DRAGONFLY_MIN_IDLE_CONNS must be a non-negative integer")
+	v.check(c.Pool.MaxRetries >= -1, "DRAGONFLY_MAX_RETRIES must be -1 or more")
+	v.check(c.Pool.DialTimeout > 0, "DRAGONFLY_DIAL_TIMEOUT must be a positive duration")
+	v.check(c.Pool.ReadTimeout > 0, "DRAGONFLY_READ_TIMEOUT must be a positive duration")
+	v.check(c.Pool.WriteTimeout > 0, "DRAGONFLY_WRITE_TIMEOUT must be a positive duration")
+	v.check(c.Pool.Timeout > 0, "DRAGONFLY_POOL_TIMEOUT must be a positive duration")
+}
+
+func (v *validator) err() error {
+	return errors.Join(v.errs...)
+}
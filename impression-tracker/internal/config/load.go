@@ -0,0 +1,180 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted remplace la valeur d'un secret à l'affichage
+const redacted = "REDACTED"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Load construit la configuration : valeurs par défaut, puis fichier YAML si path est
+// fourni, puis variables d'environnement, qui l'emportent. Une clé YAML inconnue ou une
+// valeur illisible est une erreur ; la cohérence des valeurs est vérifiée par Validate.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read config file: %w", err)
+		}
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("parse config file %s: %w", path, err)
+		}
+	}
+	if err := applyEnv(reflect.ValueOf(cfg).Elem(), ""); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyEnv surcharge les champs dont la variable d'environnement est définie et non vide
+func applyEnv(v reflect.Value, prefix string) error {
+	var errs []error
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		key := prefix + field.Tag.Get("env")
+		if field.Type.Kind() == reflect.Struct {
+			errs = append(errs, applyEnv(value, key))
+			continue
+		}
+		raw := os.Getenv(key)
+		if key == prefix || raw == "" {
+			continue
+		}
+		if err := setValue(value, raw); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// setValue convertit raw dans le type du champ
+func setValue(v reflect.Value, raw string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("must be true or false, got %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("must be an integer, got %q", raw)
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("must be a number, got %q", raw)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		// Liste séparée par des virgules
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// Print écrit la configuration en YAML, secrets masqués, chaque valeur annotée
+// de la variable d'environnement qui la surcharge
+func Print(w io.Writer, cfg *Config) error {
+	node, err := toNode(reflect.ValueOf(cfg).Elem(), "")
+	if err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// toNode construit le document YAML de la structure v
+func toNode(v reflect.Value, prefix string) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		key := prefix + field.Tag.Get("env")
+		name := &yaml.Node{Kind: yaml.ScalarNode, Value: field.Tag.Get("yaml")}
+
+		if field.Type.Kind() == reflect.Struct {
+			child, err := toNode(value, key)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, name, child)
+			continue
+		}
+
+		var out any = value.Interface()
+		if s, ok := out.(string); ok && s != "" {
+			switch field.Tag.Get("secret") {
+			case "true":
+				out = redacted
+			case "url":
+				out = redactURL(s)
+			}
+		}
+		child := &yaml.Node{}
+		if err := child.Encode(out); err != nil {
+			return nil, err
+		}
+		if child.Kind == yaml.SequenceNode {
+			child.Style = yaml.FlowStyle // Sur une ligne, pour porter le commentaire
+		}
+		if key != prefix {
+			child.LineComment = key
+		}
+		node.Content = append(node.Content, name, child)
+	}
+	return node, nil
+}
+
+// redactURL masque le mot de passe d'une URI de connexion
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redacted
+	}
+	return u.Redacted()
+}
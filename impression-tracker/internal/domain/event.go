@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EventType identifie le type d'un événement publicitaire.
+type EventType string
+
+const (
+	EventImpression EventType = "impression" // Publicité servie
+	EventRendered   EventType = "rendered"   // Publicité affichée dans la page (mesurable)
+	EventViewable   EventType = "viewable"   // Publicité vue selon le standard MRC
+	EventHover      EventType = "hover"      // Survol de la publicité
+	EventClose      EventType = "close"      // Fermeture de la publicité par l'utilisateur
+)
+
+// EngagementEvents liste les événements comptés en plus des impressions.
+var EngagementEvents = []EventType{EventRendered, EventViewable, EventHover, EventClose}
+
+// Seuils du standard MRC pour une publicité display : 50 % des pixels visibles pendant au moins 1 seconde.
+const (
+	MRCMinVisibleRatio    = 0.5
+	MRCMinVisibleDuration = time.Second
+)
+
+// ErrNotViewable est retournée lorsqu'un événement "viewable" ne respecte pas les seuils MRC.
+var ErrNotViewable = errors.New("event does not meet MRC viewability thresholds (50% for 1s)")
+
+// ErrMissingImpressionID est retournée pour un événement d'engagement sans impression :
+// les événements sont dédoublonnés par impression et par type.
+var ErrMissingImpressionID = errors.New("impression_id is required for engagement events")
+
+// Event représente un événement d'engagement sur une publicité déjà servie.
+type Event struct {
+	AdID            string
+	ImpressionID    string
+	Type            EventType
+	VisibleRatio    float64       // Part des pixels visibles (0 à 1), pour les événements "viewable"
+	VisibleDuration time.Duration // Durée de visibilité continue, pour les événements "viewable"
+	Context         ImpressionContext
+}
+
+// Validate vérifie la cohérence de l'événement, notamment les seuils MRC des événements "viewable"
+// et la présence de l'impression à laquelle il se rapporte.
+func (e Event) Validate() error {
+	switch e.Type {
+	case EventRendered, EventHover, EventClose:
+	case EventViewable:
+		if e.VisibleRatio < MRCMinVisibleRatio || e.VisibleDuration < MRCMinVisibleDuration {
+			return ErrNotViewable
+		}
+	default:
+		return fmt.Errorf("unsupported event type %q", e.Type)
+	}
+	if e.ImpressionID == "" {
+		return ErrMissingImpressionID
+	}
+	return nil
+}
+
+// EngagementReport regroupe les compteurs d'événements d'une publicité.
+type EngagementReport struct {
+	AdID        string
+	Impressions int64
+	Rendered    int64
+	Viewable    int64
+	Hovers      int64
+	Closes      int64
+}
+
+// ViewabilityRate retourne la part des impressions mesurables (rendues) qui ont été vues
+// selon le standard MRC (0 si aucune impression mesurable).
+func (r EngagementReport) ViewabilityRate() float64 {
+	if r.Rendered == 0 {
+		return 0
+	}
+	return float64(r.Viewable) / float64(r.Rendered)
+}
@@ -1 +1,27 @@
-package domain
\ No newline at end of file
+package domain
+
+import "time"
+
+// Impression représente un événement brut d'impression tel que reçu par le tracker.
+// Contrairement aux deltas agrégés, chaque impression est conservée individuellement
+// afin de pouvoir être auditée ou rejouée.
+type Impression struct {
+	ID        string            `bson:"_id" json:"impression_id"`                         // Identifiant unique de l'impression
+	AdID      string            `bson:"ad_id" json:"ad_id"`                               // Identifiant de la publicité
+	Tenant    string            `bson:"tenant,omitempty" json:"tenant,omitempty"`         // Locataire de la publicité, vide pour le locataire par défaut
+	Timestamp time.Time         `bson:"timestamp" json:"timestamp"`                       // Date et heure de réception
+	Context   ImpressionContext `bson:"context" json:"context"`                           // Contexte de l'affichage
+	IVTReason IVTReason         `bson:"ivt_reason,omitempty" json:"ivt_reason,omitempty"` // Raison du classement en trafic invalide
+}
+
+// Valid indique si l'impression a passé le filtre de trafic invalide.
+func (i Impression) Valid() bool {
+	return i.IVTReason == IVTNone
+}
+
+// ImpressionContext regroupe les informations sur le client ayant affiché la publicité.
+type ImpressionContext struct {
+	UserAgent string `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+	IPAddress string `bson:"ip_address,omitempty" json:"ip_address,omitempty"`
+	Referrer  string `bson:"referrer,omitempty" json:"referrer,omitempty"`
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidTenant est retournée pour un identifiant de locataire inutilisable dans les clés de cache.
+var ErrInvalidTenant = errors.New("tenant must not contain ':'")
+
+// ErrInvalidAdID est retournée pour un identifiant de publicité inutilisable dans les clés de cache.
+var ErrInvalidAdID = errors.New("ad_id must not contain ':'")
+
+// tenantKey est la clé de contexte du locataire
+type tenantKey struct{}
+
+// WithTenant associe un locataire au contexte. Les compteurs et les requêtes des
+// repositories sont cloisonnés par locataire ; une chaîne vide désigne le locataire
+// par défaut, celui des données antérieures au multi-locataire.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext retourne le locataire du contexte, ou une chaîne vide
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// ValidateTenant vérifie que le locataire peut servir de segment de clé de cache
+func ValidateTenant(tenant string) error {
+	if strings.Contains(tenant, ":") {
+		return ErrInvalidTenant
+	}
+	return nil
+}
+
+// ValidateAdID vérifie que l'identifiant de publicité peut servir de segment de clé de cache
+func ValidateAdID(adID string) error {
+	if strings.Contains(adID, ":") {
+		return ErrInvalidAdID
+	}
+	return nil
+}
+
+// CounterKey identifie un compteur en cache : une publicité d'un locataire.
+type CounterKey struct {
+	Tenant string
+	AdID   string
+}
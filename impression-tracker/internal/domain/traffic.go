@@ -0,0 +1,28 @@
+package domain
+
+// IVTReason identifie la vérification ayant classé une impression en trafic invalide (IVT).
+type IVTReason string
+
+const (
+	IVTNone        IVTReason = ""             // Trafic valide
+	IVTKnownBot    IVTReason = "known_bot"    // User-Agent d'un robot connu
+	IVTBlockedIP   IVTReason = "blocked_ip"   // Adresse IP en liste noire
+	IVTDataCenter  IVTReason = "data_center"  // Adresse IP d'un centre de données
+	IVTRateAnomaly IVTReason = "rate_anomaly" // Volume anormal d'impressions pour une même IP
+)
+
+// TrafficReport présente côte à côte le trafic valide et invalide d'une publicité.
+type TrafficReport struct {
+	AdID    string
+	Valid   int64
+	Invalid int64
+}
+
+// InvalidRate retourne la part de trafic invalide dans le total (0 si aucun trafic).
+func (r TrafficReport) InvalidRate() float64 {
+	total := r.Valid + r.Invalid
+	if total == 0 {
+		return 0
+	}
+	return float64(r.Invalid) / float64(total)
+}
@@ -1,13 +1,33 @@
 package in
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"impression-tracker/internal/domain"
+)
 
 // ImpressionService définit les cas d'usage du suivi d'impressions.
 // C'est le port d'entrée (primary port) de l'application.
 type ImpressionService interface {
 	// Track enregistre une nouvelle impression pour une publicité donnée
-	Track(ctx context.Context, adID string) error
+	Track(ctx context.Context, imp domain.Impression) error
 
 	// GetCount récupère le nombre d'impressions pour une publicité donnée
 	GetCount(ctx context.Context, adID string) (int64, error)
+
+	// TrackEvent enregistre un événement d'engagement (rendu, visibilité, survol, fermeture)
+	TrackEvent(ctx context.Context, event domain.Event) error
+
+	// GetEngagementReport récupère les compteurs d'événements et le taux de visibilité d'une publicité
+	GetEngagementReport(ctx context.Context, adID string) (domain.EngagementReport, error)
+
+	// GetTrafficReport récupère le trafic valide et invalide d'une publicité
+	GetTrafficReport(ctx context.Context, adID string) (domain.TrafficReport, error)
+
+	// GetTrafficBetween récupère le trafic valide et invalide d'une publicité sur une période
+	GetTrafficBetween(ctx context.Context, adID string, from, to time.Time) (domain.TrafficReport, error)
+
+	// ExportImpressions parcourt les impressions brutes reçues dans [from, to)
+	ExportImpressions(ctx context.Context, from, to time.Time, fn func(domain.Impression) error) error
 }
@@ -1,11 +1,26 @@
 package out
 
-import "context"
+import (
+	"context"
+	"errors"
 
-// CacheRepository gère le compteur en cache (Dragonfly)
+	"impression-tracker/internal/domain"
+)
+
+// ErrStaleToken signale une écriture refusée car faite sous un jeton de fencing périmé
+var ErrStaleToken = errors.New("fencing token is stale")
+
+// CacheRepository gère le compteur en cache (Dragonfly).
+// Les compteurs sont cloisonnés par le locataire du contexte (domain.TenantFromContext).
 type CacheRepository interface {
 	Increment(ctx context.Context, adID string) (int64, error)
 	Get(ctx context.Context, adID string) (int64, error)
-	Reset(ctx context.Context, adID string) (int64, error)
-	GetAllKeys(ctx context.Context) ([]string, error)
+
+	// Reset retourne le compteur et le supprime. Avec un jeton de fencing non nul, la
+	// lecture-suppression est refusée (ErrStaleToken) si le compteur a déjà été réinitialisé
+	// sous un jeton plus récent, c'est-à-dire par un leader élu après l'appelant.
+	Reset(ctx context.Context, adID string, token int64) (int64, error)
+
+	// GetAllKeys retourne les compteurs en cache de tous les locataires
+	GetAllKeys(ctx context.Context) ([]domain.CounterKey, error)
 }
@@ -0,0 +1,22 @@
+package out
+
+import "time"
+
+// Clock fournit l'heure courante et les tickers au domaine, afin que les règles dépendant
+// du temps (expiration, fenêtres de synchronisation) puissent être pilotées en test.
+type Clock interface {
+	// Now retourne l'heure courante.
+	Now() time.Time
+
+	// NewTicker crée un ticker émettant toutes les d (d > 0).
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker émet l'heure à intervalle régulier, comme time.Ticker.
+type Ticker interface {
+	// C retourne le canal sur lequel les tics sont émis.
+	C() <-chan time.Time
+
+	// Stop arrête le ticker ; aucun tic n'est émis ensuite.
+	Stop()
+}
@@ -0,0 +1,330 @@
+// Package contract regroupe les suites de tests que chaque adaptateur d'un port de sortie
+// doit passer, quel que soit son stockage : mémoire, Dragonfly ou MongoDB.
+package contract
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"impression-tracker/internal/adapters/clock"
+	"impression-tracker/internal/domain"
+	"impression-tracker/internal/ports/out"
+)
+
+// Elapse avance le temps vu par l'adaptateur testé : horloge factice, ou FastForward de miniredis
+type Elapse func(d time.Duration)
+
+// start est l'heure de départ des horloges factices des suites, à la milliseconde comme MongoDB
+var start = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+var (
+	acme   = domain.WithTenant(context.Background(), "acme")
+	globex = domain.WithTenant(context.Background(), "globex")
+)
+
+// CacheRepository vérifie un out.CacheRepository ; newRepo retourne un cache vide
+func CacheRepository(t *testing.T, newRepo func(t *testing.T) out.CacheRepository) {
+	t.Run("counts per tenant", func(t *testing.T) {
+		repo := newRepo(t)
+		increment(t, repo, acme, "ad", 2)
+		increment(t, repo, globex, "ad", 1)
+		increment(t, repo, context.Background(), "ad", 3)
+		for _, tt := range []struct {
+			ctx  context.Context
+			adID string
+			want int64
+		}{
+			{acme, "ad", 2}, {globex, "ad", 1}, {context.Background(), "ad", 3}, {acme, "missing", 0},
+		} {
+			if got, err := repo.Get(tt.ctx, tt.adID); err != nil || got != tt.want {
+				t.Errorf("Get(%s, %s) = %d, %v, want %d", domain.TenantFromContext(tt.ctx), tt.adID, got, err, tt.want)
+			}
+		}
+
+		keys, err := repo.GetAllKeys(context.Background())
+		if err != nil {
+			t.Fatalf("GetAllKeys() error: %v", err)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i].Tenant < keys[j].Tenant })
+		want := []domain.CounterKey{{AdID: "ad"}, {Tenant: "acme", AdID: "ad"}, {Tenant: "globex", AdID: "ad"}}
+		if len(keys) != len(want) || keys[0] != want[0] || keys[1] != want[1] || keys[2] != want[2] {
+			t.Errorf("GetAllKeys() = %v, want %v", keys, want)
+		}
+	})
+
+	t.Run("reset returns and clears the counter", func(t *testing.T) {
+		repo := newRepo(t)
+		increment(t, repo, acme, "ad", 4)
+		increment(t, repo, globex, "ad", 1)
+		if got, err := repo.Reset(acme, "ad", 0); err != nil || got != 4 {
+			t.Fatalf("Reset() = %d, %v, want 4", got, err)
+		}
+		if got, err := repo.Get(acme, "ad"); err != nil || got != 0 {
+			t.Errorf("Get() after Reset = %d, %v, want 0", got, err)
+		}
+		if got, err := repo.Get(globex, "ad"); err != nil || got != 1 {
+			t.Errorf("Get(globex) after the acme Reset = %d, %v, want 1", got, err)
+		}
+		if got, err := repo.Reset(acme, "missing", 0); err != nil || got != 0 {
+			t.Errorf("Reset(missing) = %d, %v, want 0", got, err)
+		}
+	})
+
+	t.Run("stale fencing token", func(t *testing.T) {
+		repo := newRepo(t)
+		increment(t, repo, acme, "ad", 3)
+		if got, err := repo.Reset(acme, "ad", 2); err != nil || got != 3 {
+			t.Fatalf("Reset(token 2) = %d, %v, want 3", got, err)
+		}
+		increment(t, repo, acme, "ad", 1)
+		if _, err := repo.Reset(acme, "ad", 1); !errors.Is(err, out.ErrStaleToken) {
+			t.Fatalf("Reset(token 1) error = %v, want %v", err, out.ErrStaleToken)
+		}
+		if got, err := repo.Get(acme, "ad"); err != nil || got != 1 {
+			t.Errorf("Get() after a stale Reset = %d, %v, want 1", got, err)
+		}
+		if got, err := repo.Reset(acme, "ad", 3); err != nil || got != 1 {
+			t.Errorf("Reset(token 3) = %d, %v, want 1", got, err)
+		}
+	})
+
+	t.Run("concurrent increments", func(t *testing.T) {
+		repo := newRepo(t)
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					if _, err := repo.Increment(acme, "ad"); err != nil {
+						t.Errorf("Increment() error: %v", err)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		if got, err := repo.Get(acme, "ad"); err != nil || got != 500 {
+			t.Errorf("Get() = %d, %v, want 500", got, err)
+		}
+	})
+}
+
+func increment(t *testing.T, repo out.CacheRepository, ctx context.Context, adID string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if _, err := repo.Increment(ctx, adID); err != nil {
+			t.Fatalf("Increment() error: %v", err)
+		}
+	}
+}
+
+// LeaseRepository vérifie un out.LeaseRepository ; newRepo retourne un gestionnaire sans bail
+// et de quoi faire expirer ses baux
+func LeaseRepository(t *testing.T, newRepo func(t *testing.T) (out.LeaseRepository, Elapse)) {
+	ctx := context.Background()
+	repo, elapse := newRepo(t)
+
+	token, ok, err := repo.Acquire(ctx, "sync", "a", time.Minute)
+	if err != nil || !ok || token <= 0 {
+		t.Fatalf("Acquire(a) = %d, %v, %v, want a positive token", token, ok, err)
+	}
+	if renewed, ok, err := repo.Acquire(ctx, "sync", "a", time.Minute); err != nil || !ok || renewed != token {
+		t.Errorf("Acquire(a) renewal = %d, %v, %v, want the same token %d", renewed, ok, err, token)
+	}
+	if _, ok, err := repo.Acquire(ctx, "sync", "b", time.Minute); err != nil || ok {
+		t.Errorf("Acquire(b) = %v, %v while a holds the lease, want refused", ok, err)
+	}
+	if other, ok, err := repo.Acquire(ctx, "other", "b", time.Minute); err != nil || !ok || other <= 0 {
+		t.Errorf("Acquire(other lease) = %d, %v, %v, want acquired", other, ok, err)
+	}
+	if valid, err := repo.Validate(ctx, "sync", token); err != nil || !valid {
+		t.Errorf("Validate(token) = %v, %v, want valid", valid, err)
+	}
+
+	// Seul le détenteur libère le bail ; le suivant reçoit un jeton plus grand
+	if err := repo.Release(ctx, "sync", "b"); err != nil {
+		t.Fatalf("Release(b) error: %v", err)
+	}
+	if valid, err := repo.Validate(ctx, "sync", token); err != nil || !valid {
+		t.Errorf("Validate(token) after a release by another holder = %v, %v, want valid", valid, err)
+	}
+	if err := repo.Release(ctx, "sync", "a"); err != nil {
+		t.Fatalf("Release(a) error: %v", err)
+	}
+	next, ok, err := repo.Acquire(ctx, "sync", "b", time.Minute)
+	if err != nil || !ok || next <= token {
+		t.Fatalf("Acquire(b) after release = %d, %v, %v, want a token above %d", next, ok, err, token)
+	}
+	if valid, err := repo.Validate(ctx, "sync", token); err != nil || valid {
+		t.Errorf("Validate(old token) = %v, %v, want invalid", valid, err)
+	}
+
+	// Un bail renouvelé court à nouveau ; non renouvelé, il expire et passe à un autre détenteur
+	elapse(40 * time.Second)
+	if renewed, ok, err := repo.Acquire(ctx, "sync", "b", time.Minute); err != nil || !ok || renewed != next {
+		t.Fatalf("Acquire(b) renewal = %d, %v, %v, want the token %d", renewed, ok, err, next)
+	}
+	elapse(40 * time.Second)
+	if valid, err := repo.Validate(ctx, "sync", next); err != nil || !valid {
+		t.Errorf("Validate() of a renewed lease = %v, %v, want valid", valid, err)
+	}
+	if _, ok, err := repo.Acquire(ctx, "sync", "a", time.Minute); err != nil || ok {
+		t.Errorf("Acquire(a) = %v, %v while b's renewed lease runs, want refused", ok, err)
+	}
+	elapse(21 * time.Second)
+	if valid, err := repo.Validate(ctx, "sync", next); err != nil || valid {
+		t.Errorf("Validate() of an expired lease = %v, %v, want invalid", valid, err)
+	}
+	if taken, ok, err := repo.Acquire(ctx, "sync", "a", time.Minute); err != nil || !ok || taken <= next {
+		t.Errorf("Acquire(a) after expiry = %d, %v, %v, want a token above %d", taken, ok, err, next)
+	}
+}
+
+// EventDeduplicator vérifie un out.EventDeduplicator ; newDedup retourne un dédoublonneur
+// vide et de quoi faire expirer ses marques
+func EventDeduplicator(t *testing.T, newDedup func(t *testing.T) (out.EventDeduplicator, Elapse)) {
+	dedup, elapse := newDedup(t)
+	firstSeen := func(ctx context.Context, impressionID string, eventType domain.EventType) bool {
+		t.Helper()
+		first, err := dedup.FirstSeen(ctx, impressionID, eventType, time.Hour)
+		if err != nil {
+			t.Fatalf("FirstSeen() error: %v", err)
+		}
+		return first
+	}
+
+	if !firstSeen(acme, "imp", domain.EventViewable) {
+		t.Error("FirstSeen() = false for a new event")
+	}
+	if firstSeen(acme, "imp", domain.EventViewable) {
+		t.Error("FirstSeen() = true for a repeated event")
+	}
+	if !firstSeen(acme, "imp", domain.EventRendered) {
+		t.Error("FirstSeen() = false for another event type")
+	}
+	if !firstSeen(globex, "imp", domain.EventViewable) {
+		t.Error("FirstSeen() = false for another tenant's impression")
+	}
+
+	elapse(time.Hour + time.Second)
+	if !firstSeen(acme, "imp", domain.EventViewable) {
+		t.Error("FirstSeen() = false once the mark expired")
+	}
+}
+
+// MetricsRepository vérifie un out.MetricsRepository ; newRepo retourne un stockage vide
+// datant ses deltas selon clock
+func MetricsRepository(t *testing.T, newRepo func(t *testing.T, clock out.Clock) out.MetricsRepository) {
+	fake := clock.NewFake(start)
+	repo := newRepo(t, fake)
+	persist := func(ctx context.Context, adID string, delta int64) {
+		t.Helper()
+		if err := repo.PersistDelta(ctx, adID, delta); err != nil {
+			t.Fatalf("PersistDelta() error: %v", err)
+		}
+	}
+
+	persist(acme, "ad", 3)
+	persist(globex, "ad", 7)
+	fake.Advance(time.Hour)
+	persist(acme, "ad", 2)
+	persist(context.Background(), "ad", 1)
+
+	for _, tt := range []struct {
+		ctx  context.Context
+		adID string
+		want int64
+	}{
+		{acme, "ad", 5}, {globex, "ad", 7}, {context.Background(), "ad", 1}, {acme, "missing", 0},
+	} {
+		if got, err := repo.GetTotal(tt.ctx, tt.adID); err != nil || got != tt.want {
+			t.Errorf("GetTotal(%s, %s) = %d, %v, want %d", domain.TenantFromContext(tt.ctx), tt.adID, got, err, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		name     string
+		from, to time.Time
+		want     int64
+	}{
+		{name: "first hour", from: start, to: start.Add(time.Hour), want: 3},
+		{name: "second hour", from: start.Add(time.Hour), to: start.Add(2 * time.Hour), want: 2},
+		{name: "whole range", from: start.Add(-time.Hour), to: start.Add(2 * time.Hour), want: 5},
+		{name: "before any delta", from: start.Add(-time.Hour), to: start, want: 0},
+	} {
+		if got, err := repo.GetTotalBetween(acme, "ad", tt.from, tt.to); err != nil || got != tt.want {
+			t.Errorf("GetTotalBetween(%s) = %d, %v, want %d", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+// EventStore vérifie un out.EventStore ; newStore retourne un journal vide
+func EventStore(t *testing.T, newStore func(t *testing.T) out.EventStore) {
+	store := newStore(t)
+	impression := func(id, tenant string, at time.Duration) domain.Impression {
+		return domain.Impression{ID: id, AdID: "ad", Tenant: tenant, Timestamp: start.Add(at), Context: domain.ImpressionContext{IPAddress: "192.0.2.1"}}
+	}
+	for _, imp := range []domain.Impression{
+		impression("late", "acme", 2*time.Minute),
+		impression("early", "acme", 0),
+		impression("middle", "acme", time.Minute),
+		impression("replayed", "acme", 3*time.Minute),
+		impression("other", "globex", time.Minute),
+	} {
+		if err := store.Append(context.Background(), imp); err != nil {
+			t.Fatalf("Append(%s) error: %v", imp.ID, err)
+		}
+	}
+	// Un rejeu de la même impression est ignoré
+	if err := store.Append(context.Background(), impression("replayed", "acme", 3*time.Minute)); err != nil {
+		t.Fatalf("Append(replayed) again error: %v", err)
+	}
+
+	scan := func(ctx context.Context, from, to time.Time) []string {
+		t.Helper()
+		var ids []string
+		err := store.Scan(ctx, from, to, func(imp domain.Impression) error {
+			ids = append(ids, imp.ID)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Scan() error: %v", err)
+		}
+		return ids
+	}
+	if got := scan(acme, start, start.Add(time.Hour)); !equal(got, "early", "middle", "late", "replayed") {
+		t.Errorf("Scan(acme) = %v, want the four acme impressions in order", got)
+	}
+	if got := scan(acme, start.Add(time.Minute), start.Add(2*time.Minute)); !equal(got, "middle") {
+		t.Errorf("Scan([1m, 2m)) = %v, want [middle]", got)
+	}
+	if got := scan(globex, start, start.Add(time.Hour)); !equal(got, "other") {
+		t.Errorf("Scan(globex) = %v, want [other]", got)
+	}
+
+	stop := errors.New("stop")
+	var seen int
+	err := store.Scan(acme, start, start.Add(time.Hour), func(domain.Impression) error {
+		seen++
+		return stop
+	})
+	if !errors.Is(err, stop) || seen != 1 {
+		t.Errorf("Scan() with a failing callback = %v after %d impressions, want %v after 1", err, seen, stop)
+	}
+}
+
+func equal(got []string, want ...string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
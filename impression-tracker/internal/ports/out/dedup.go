@@ -0,0 +1,17 @@
+package out
+
+import (
+	"context"
+	"time"
+
+	"impression-tracker/internal/domain"
+)
+
+// EventDeduplicator retient les événements d'engagement déjà comptés, afin qu'un même
+// événement d'une impression ne soit compté qu'une fois, quelle que soit l'instance qui le reçoit.
+// Les événements sont cloisonnés par le locataire du contexte (domain.TenantFromContext).
+type EventDeduplicator interface {
+	// FirstSeen marque l'événement eventType de l'impression pendant ttl et indique
+	// s'il n'était pas déjà marqué.
+	FirstSeen(ctx context.Context, impressionID string, eventType domain.EventType, ttl time.Duration) (bool, error)
+}
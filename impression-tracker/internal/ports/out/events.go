@@ -0,0 +1,18 @@
+package out
+
+import (
+	"context"
+	"time"
+
+	"impression-tracker/internal/domain"
+)
+
+// EventStore conserve les impressions brutes en mode append-only
+type EventStore interface {
+	// Append ajoute une impression au journal. Une impression déjà enregistrée est ignorée.
+	Append(ctx context.Context, imp domain.Impression) error
+
+	// Scan parcourt, par ordre chronologique, les impressions reçues dans [from, to),
+	// pour le locataire du contexte.
+	Scan(ctx context.Context, from, to time.Time, fn func(domain.Impression) error) error
+}
@@ -0,0 +1,17 @@
+package out
+
+import (
+	"context"
+
+	"impression-tracker/internal/domain"
+)
+
+// TrafficFilter détecte le trafic invalide (robots, IP suspectes) avant le comptage
+type TrafficFilter interface {
+	// Inspect retourne la raison du classement en trafic invalide, ou domain.IVTNone
+	Inspect(ctx context.Context, imp domain.Impression) domain.IVTReason
+
+	// InspectEvent applique à un événement d'engagement les vérifications de son contexte
+	// client (IP, User-Agent), sans le compter dans le volume par IP des impressions
+	InspectEvent(ctx context.Context, event domain.Event) domain.IVTReason
+}
@@ -0,0 +1,20 @@
+package out
+
+import (
+	"context"
+	"time"
+)
+
+// LeaseRepository gère un bail exclusif partagé entre les instances du tracker (élection de leader).
+// Chaque nouvelle acquisition du bail produit un jeton de fencing strictement croissant.
+type LeaseRepository interface {
+	// Acquire obtient le bail pour holder, ou le prolonge s'il le détient déjà.
+	// Retourne le jeton de fencing et true si le bail est détenu par holder.
+	Acquire(ctx context.Context, name, holder string, ttl time.Duration) (token int64, acquired bool, err error)
+
+	// Validate vérifie que le jeton correspond toujours au bail en cours.
+	Validate(ctx context.Context, name string, token int64) (bool, error)
+
+	// Release libère le bail s'il est détenu par holder.
+	Release(ctx context.Context, name, holder string) error
+}
@@ -0,0 +1,24 @@
+package out
+
+import "time"
+
+// ServiceMetrics enregistre les métriques métier du suivi d'impressions
+type ServiceMetrics interface {
+	// ImpressionTracked compte une impression, valide ou classée en trafic invalide
+	ImpressionTracked(valid bool)
+
+	// EventTracked compte un événement d'engagement
+	EventTracked(eventType string)
+
+	// DeltaPersisted compte un delta persisté lors d'une synchronisation
+	DeltaPersisted(counter string, delta int64)
+
+	// SyncError compte une erreur de synchronisation
+	SyncError(counter string)
+
+	// CacheKeys enregistre le nombre de clés en cache d'un compteur
+	CacheKeys(counter string, count int)
+
+	// SyncCompleted enregistre la durée d'une boucle de synchronisation
+	SyncCompleted(duration time.Duration)
+}
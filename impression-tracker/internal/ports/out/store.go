@@ -1,8 +1,18 @@
 package out
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
-// MetricsRepository persiste les deltas d'impressions en base
+// MetricsRepository persiste les deltas d'impressions en base.
+// Les deltas sont cloisonnés par le locataire du contexte (domain.TenantFromContext).
 type MetricsRepository interface {
 	PersistDelta(ctx context.Context, adID string, delta int64) error
+
+	// GetTotal retourne la somme des deltas persistés pour une publicité
+	GetTotal(ctx context.Context, adID string) (int64, error)
+
+	// GetTotalBetween retourne la somme des deltas synchronisés dans [from, to)
+	GetTotalBetween(ctx context.Context, adID string, from, to time.Time) (int64, error)
 }
@@ -0,0 +1,89 @@
+// Package testkit démarre un impression-tracker complet pour les tests de bout en bout des
+// autres modules : stockage en mémoire, horloge factice et serveur gRPC sur une connexion en
+// mémoire. Les paquets internal du tracker restent ainsi inaccessibles hors du module.
+package testkit
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"impression-tracker/internal/adapters/clock"
+	"impression-tracker/internal/adapters/grpc/auth"
+	"impression-tracker/internal/adapters/grpc/handler"
+	"impression-tracker/internal/adapters/healthcheck"
+	"impression-tracker/internal/adapters/memory"
+	"impression-tracker/internal/application"
+	"impression-tracker/internal/config"
+	"shared/generated/impression_service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Options configure le tracker démarré par Start.
+type Options struct {
+	Now          time.Time     // Heure initiale de l'horloge factice
+	SyncInterval time.Duration // Période de synchronisation du cache, 1 minute par défaut
+	Logger       *slog.Logger  // Logs du tracker, ignorés par défaut
+}
+
+// Tracker est un impression-tracker en mémoire. Les appelants sans certificat sont acceptés
+// pour tous les locataires, comme AUTH_TRUST_UNAUTHENTICATED en développement.
+type Tracker struct {
+	lis   *bufconn.Listener
+	clock *clock.Fake
+}
+
+// Start démarre un tracker arrêté à la fin du test
+func Start(t testing.TB, opts Options) *Tracker {
+	t.Helper()
+	if opts.SyncInterval <= 0 {
+		opts.SyncInterval = time.Minute
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	fake := clock.NewFake(opts.Now)
+	service := application.NewService(memory.NewCacheRepository(), memory.NewMetricsRepository(fake), fake, opts.SyncInterval, opts.Logger,
+		application.WithEventStore(memory.NewEventStore()))
+	service.Start()
+	t.Cleanup(service.Stop)
+
+	authenticator := auth.NewAuthenticator(auth.Config{TrustUnauthenticated: true}, opts.Logger)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(authenticator.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(authenticator.StreamServerInterceptor()),
+	)
+
+	// Santé : sans dépendance externe, grpc.health.v1 est SERVING dès la première vérification
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	defaults := config.Default().Health
+	checker := healthcheck.NewChecker(healthServer, defaults.Interval, defaults.Timeout, opts.Logger,
+		impression_service.ImpressionService_ServiceDesc.ServiceName)
+	checker.Start()
+	t.Cleanup(checker.Stop)
+
+	impression_service.RegisterImpressionServiceServer(server, handler.NewServer(service, checker, fake, opts.Logger))
+	lis := bufconn.Listen(1 << 20)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return &Tracker{lis: lis, clock: fake}
+}
+
+// Dial ouvre une connexion au tracker, à passer à grpc.WithContextDialer
+func (tr *Tracker) Dial(ctx context.Context, _ string) (net.Conn, error) {
+	return tr.lis.DialContext(ctx)
+}
+
+// Advance avance l'horloge du tracker ; la synchronisation part à chaque période écoulée
+func (tr *Tracker) Advance(d time.Duration) {
+	tr.clock.Advance(d)
+}
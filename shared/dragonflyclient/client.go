@@ -0,0 +1,93 @@
+// Package dragonflyclient crée les clients go-redis de Dragonfly (ou de tout serveur
+// compatible Redis) : nœud unique, sentinelles ou cluster, authentification, TLS et pool.
+// Il est partagé par l'adserver (cache des publicités, limitation de débit) et le
+// impression-tracker (compteurs).
+package dragonflyclient
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Modes de connexion à Dragonfly
+const (
+	ModeStandalone = "standalone" // Un seul nœud
+	ModeSentinel   = "sentinel"   // Maître découvert via Redis Sentinel, bascule automatique
+	ModeCluster    = "cluster"    // Redis Cluster, clés réparties entre les maîtres
+)
+
+// Options décrit la connexion à Dragonfly ou à tout serveur compatible Redis.
+// Les valeurs nulles gardent les défauts de go-redis.
+type Options struct {
+	Mode             string   // ModeStandalone (défaut), ModeSentinel ou ModeCluster
+	Addrs            []string // Nœud, sentinelles ou nœuds d'amorçage du cluster
+	MasterName       string   // Maître surveillé par les sentinelles (ModeSentinel)
+	Username         string   // Utilisateur ACL, vide pour l'utilisateur par défaut
+	Password         string   // Vide : pas d'authentification
+	SentinelUsername string
+	SentinelPassword string
+	DB               int         // Base sélectionnée, toujours 0 en mode cluster
+	TLS              *tls.Config // nil : connexion en clair
+
+	PoolSize     int // Connexions par nœud, 0 : 10 par CPU
+	MinIdleConns int
+	MaxRetries   int // -1 : aucune nouvelle tentative
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	PoolTimeout  time.Duration // Attente d'une connexion libre du pool
+}
+
+// NewClient crée le client go-redis correspondant au mode : nœud unique, bascule
+// via Sentinel ou cluster. Les adaptateurs n'en voient que l'interface UniversalClient.
+func NewClient(opts Options) (redis.UniversalClient, error) {
+	if len(opts.Addrs) == 0 {
+		return nil, errors.New("no Dragonfly address")
+	}
+	var tlsConfig *tls.Config
+	if opts.TLS != nil {
+		// L'ALPN h2 de la configuration partagée sert au gRPC, pas au protocole Redis
+		tlsConfig = opts.TLS.Clone()
+		tlsConfig.NextProtos = nil
+	}
+	universal := &redis.UniversalOptions{
+		Addrs:            opts.Addrs,
+		MasterName:       opts.MasterName,
+		Username:         opts.Username,
+		Password:         opts.Password,
+		SentinelUsername: opts.SentinelUsername,
+		SentinelPassword: opts.SentinelPassword,
+		DB:               opts.DB,
+		TLSConfig:        tlsConfig,
+		PoolSize:         opts.PoolSize,
+		MinIdleConns:     opts.MinIdleConns,
+		MaxRetries:       opts.MaxRetries,
+		DialTimeout:      opts.DialTimeout,
+		ReadTimeout:      opts.ReadTimeout,
+		WriteTimeout:     opts.WriteTimeout,
+		PoolTimeout:      opts.PoolTimeout,
+	}
+
+	switch opts.Mode {
+	case "", ModeStandalone:
+		if len(opts.Addrs) > 1 {
+			return nil, fmt.Errorf("standalone mode takes a single address, got %d", len(opts.Addrs))
+		}
+		return redis.NewClient(universal.Simple()), nil
+	case ModeSentinel:
+		if opts.MasterName == "" {
+			return nil, errors.New("sentinel mode requires a master name")
+		}
+		return redis.NewFailoverClient(universal.Failover()), nil
+	case ModeCluster:
+		if opts.DB != 0 {
+			return nil, errors.New("cluster mode only supports database 0")
+		}
+		return redis.NewClusterClient(universal.Cluster()), nil
+	}
+	return nil, fmt.Errorf("unknown Dragonfly mode %q", opts.Mode)
+}
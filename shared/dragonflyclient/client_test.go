@@ -0,0 +1,67 @@
+package dragonflyclient
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestNewClientRejectsInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "no address"},
+		{name: "several standalone addresses", opts: Options{Addrs: []string{"a:6379", "b:6379"}}},
+		{name: "sentinel without master", opts: Options{Mode: ModeSentinel, Addrs: []string{"s:26379"}}},
+		{name: "cluster database", opts: Options{Mode: ModeCluster, Addrs: []string{"c:6379"}, DB: 1}},
+		{name: "unknown mode", opts: Options{Mode: "ring", Addrs: []string{"a:6379"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if client, err := NewClient(tt.opts); err == nil {
+				client.Close()
+				t.Error("NewClient() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestNewClientSelectsTheMode(t *testing.T) {
+	shared := &tls.Config{ServerName: "dragonfly", NextProtos: []string{"h2"}}
+	tests := []struct {
+		name string
+		opts Options
+		want func(redis.UniversalClient) bool
+	}{
+		{name: "default", opts: Options{Addrs: []string{"a:6379"}}, want: func(c redis.UniversalClient) bool { _, ok := c.(*redis.Client); return ok }},
+		{name: "standalone", opts: Options{Mode: ModeStandalone, Addrs: []string{"a:6379"}, DB: 2}, want: func(c redis.UniversalClient) bool {
+			rc, ok := c.(*redis.Client)
+			return ok && rc.Options().DB == 2
+		}},
+		{name: "sentinel", opts: Options{Mode: ModeSentinel, Addrs: []string{"s:26379"}, MasterName: "main"}, want: func(c redis.UniversalClient) bool { _, ok := c.(*redis.Client); return ok }},
+		{name: "cluster", opts: Options{Mode: ModeCluster, Addrs: []string{"c1:6379", "c2:6379"}}, want: func(c redis.UniversalClient) bool { _, ok := c.(*redis.ClusterClient); return ok }},
+		// L'ALPN h2 de la configuration partagée n'est pas transmis au serveur Redis
+		{name: "tls", opts: Options{Addrs: []string{"a:6379"}, TLS: shared, Username: "adserver", Password: "s3cret", PoolSize: 20}, want: func(c redis.UniversalClient) bool {
+			o := c.(*redis.Client).Options()
+			return o.TLSConfig != nil && o.TLSConfig.ServerName == "dragonfly" && len(o.TLSConfig.NextProtos) == 0 &&
+				o.Username == "adserver" && o.Password == "s3cret" && o.PoolSize == 20
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.opts)
+			if err != nil {
+				t.Fatalf("NewClient() error: %v", err)
+			}
+			defer client.Close()
+			if !tt.want(client) {
+				t.Errorf("NewClient(%+v) = %T with unexpected options", tt.opts, client)
+			}
+		})
+	}
+	if len(shared.NextProtos) != 1 {
+		t.Errorf("NewClient() modified the shared TLS configuration: %v", shared.NextProtos)
+	}
+}
@@ -0,0 +1,1446 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.21.12
+// source: proto/impression_service.proto
+
+package impression_service
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Type d'événement publicitaire
+type EventType int32
+
+const (
+	EventType_EVENT_TYPE_UNSPECIFIED EventType = 0
+	EventType_EVENT_TYPE_IMPRESSION  EventType = 1
+	EventType_EVENT_TYPE_RENDERED    EventType = 2
+	EventType_EVENT_TYPE_VIEWABLE    EventType = 3 // 50 % des pixels visibles pendant au moins 1 seconde (MRC)
+	EventType_EVENT_TYPE_HOVER       EventType = 4
+	EventType_EVENT_TYPE_CLOSE       EventType = 5
+)
+
+// Enum value maps for EventType.
+var (
+	EventType_name = map[int32]string{
+		0: "EVENT_TYPE_UNSPECIFIED",
+		1: "EVENT_TYPE_IMPRESSION",
+		2: "EVENT_TYPE_RENDERED",
+		3: "EVENT_TYPE_VIEWABLE",
+		4: "EVENT_TYPE_HOVER",
+		5: "EVENT_TYPE_CLOSE",
+	}
+	EventType_value = map[string]int32{
+		"EVENT_TYPE_UNSPECIFIED": 0,
+		"EVENT_TYPE_IMPRESSION":  1,
+		"EVENT_TYPE_RENDERED":    2,
+		"EVENT_TYPE_VIEWABLE":    3,
+		"EVENT_TYPE_HOVER":       4,
+		"EVENT_TYPE_CLOSE":       5,
+	}
+)
+
+func (x EventType) Enum() *EventType {
+	p := new(EventType)
+	*p = x
+	return p
+}
+
+func (x EventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_impression_service_proto_enumTypes[0].Descriptor()
+}
+
+func (EventType) Type() protoreflect.EnumType {
+	return &file_proto_impression_service_proto_enumTypes[0]
+}
+
+func (x EventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EventType.Descriptor instead.
+func (EventType) EnumDescriptor() ([]byte, []int) {
+	return file_proto_impression_service_proto_rawDescGZIP(), []int{0}
+}
+
+// Format d'export des impressions brutes
+type ExportFormat int32
+
+const (
+	ExportFormat_EXPORT_FORMAT_NDJSON  ExportFormat = 0
+	ExportFormat_EXPORT_FORMAT_CSV     ExportFormat = 1
+	ExportFormat_EXPORT_FORMAT_PARQUET ExportFormat = 2
+)
+
+// Enum value maps for ExportFormat.
+var (
+	ExportFormat_name = map[int32]string{
+		0: "EXPORT_FORMAT_NDJSON",
+		1: "EXPORT_FORMAT_CSV",
+		2: "EXPORT_FORMAT_PARQUET",
+	}
+	ExportFormat_value = map[string]int32{
+		"EXPORT_FORMAT_NDJSON":  0,
+		"EXPORT_FORMAT_CSV":     1,
+		"EXPORT_FORMAT_PARQUET": 2,
+	}
+)
+
+func (x ExportFormat) Enum() *ExportFormat {
+	p := new(ExportFormat)
+	*p = x
+	return p
+}
+
+func (x ExportFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ExportFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_impression_service_proto_enumTypes[1].Descriptor()
+}
+
+func (ExportFormat) Type() protoreflect.EnumType {
+	return &file_proto_impression_service_proto_enumTypes[1]
+}
+
+func (x ExportFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ExportFormat.Descriptor instead.
+func (ExportFormat) EnumDescriptor() ([]byte, []int) {
+	return file_proto_impression_service_proto_rawDescGZIP(), []int{1}
+}
+
+// Requête pour enregistrer une impression
+type TrackImpressionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AdId          string                 `protobuf:"bytes,1,opt,name=ad_id,json=adId,proto3" json:"ad_id,omitempty"`
+	ImpressionId  string                 `protobuf:"bytes,2,opt,name=impression_id,json=impressionId,proto3" json:"impression_id,omitempty"`
+	UserAgent     string                 `protobuf:"bytes,3,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"` // User-Agent du client ayant affiché la publicité
+	IpAddress     string                 `protobuf:"bytes,4,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"` // Adresse IP du client
+	Referrer      string                 `protobuf:"bytes,5,opt,name=referrer,proto3" json:"referrer,omitempty"`                    // Page sur laquelle la publicité a été affichée
+	Tenant        string                 `protobuf:"bytes,6,opt,name=tenant,proto3" json:"tenant,omitempty"`                        // Locataire de la publicité, vide pour le locataire par défaut
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrackImpressionRequest) Reset() {
+	*x = TrackImpressionRequest{}
+	mi := &file_proto_impression_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrackImpressionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackImpressionRequest) ProtoMessage() {}
+
+func (x *TrackImpressionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impression_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackImpressionRequest.ProtoReflect.Descriptor instead.
+func (*TrackImpressionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_impression_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TrackImpressionRequest) GetAdId() string {
+	if x != nil {
+		return x.AdId
+	}
+	return ""
+}
+
+func (x *TrackImpressionRequest) GetImpressionId() string {
+	if x != nil {
+		return x.ImpressionId
+	}
+	return ""
+}
+
+func (x *TrackImpressionRequest) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+func (x *TrackImpressionRequest) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *TrackImpressionRequest) GetReferrer() string {
+	if x != nil {
+		return x.Referrer
+	}
+	return ""
+}
+
+func (x *TrackImpressionRequest) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+// Réponse après l'enregistrement d'une impression
+type TrackImpressionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrackImpressionResponse) Reset() {
+	*x = TrackImpressionResponse{}
+	mi := &file_proto_impression_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrackImpressionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackImpressionResponse) ProtoMessage() {}
+
+func (x *TrackImpressionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impression_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackImpressionResponse.ProtoReflect.Descriptor instead.
+func (*TrackImpressionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_impression_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TrackImpressionResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// Requête pour obtenir le nombre d'impressions
+type GetImpressionCountRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AdId          string                 `protobuf:"bytes,1,opt,name=ad_id,json=adId,proto3" json:"ad_id,omitempty"`
+	Tenant        string                 `protobuf:"bytes,2,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetImpressionCountRequest) Reset() {
+	*x = GetImpressionCountRequest{}
+	mi := &file_proto_impression_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetImpressionCountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetImpressionCountRequest) ProtoMessage() {}
+
+func (x *GetImpressionCountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impression_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetImpressionCountRequest.ProtoReflect.Descriptor instead.
+func (*GetImpressionCountRequest) Descriptor() ([]byte, []int) {
+	return file_proto_impression_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetImpressionCountRequest) GetAdId() string {
+	if x != nil {
+		return x.AdId
+	}
+	return ""
+}
+
+func (x *GetImpressionCountRequest) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+// Réponse avec le nombre d'impressions
+type GetImpressionCountResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Count         int64                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetImpressionCountResponse) Reset() {
+	*x = GetImpressionCountResponse{}
+	mi := &file_proto_impression_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetImpressionCountResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetImpressionCountResponse) ProtoMessage() {}
+
+func (x *GetImpressionCountResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impression_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetImpressionCountResponse.ProtoReflect.Descriptor instead.
+func (*GetImpressionCountResponse) Descriptor() ([]byte, []int) {
+	return file_proto_impression_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetImpressionCountResponse) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// Requête pour enregistrer un événement publicitaire
+type TrackEventRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	AdId              string                 `protobuf:"bytes,1,opt,name=ad_id,json=adId,proto3" json:"ad_id,omitempty"`
+	ImpressionId      string                 `protobuf:"bytes,2,opt,name=impression_id,json=impressionId,proto3" json:"impression_id,omitempty"`
+	EventType         EventType              `protobuf:"varint,3,opt,name=event_type,json=eventType,proto3,enum=impression.EventType" json:"event_type,omitempty"`
+	VisibleRatio      float64                `protobuf:"fixed64,4,opt,name=visible_ratio,json=visibleRatio,proto3" json:"visible_ratio,omitempty"`                 // Part des pixels visibles (0 à 1), requis pour VIEWABLE
+	VisibleDurationMs int64                  `protobuf:"varint,5,opt,name=visible_duration_ms,json=visibleDurationMs,proto3" json:"visible_duration_ms,omitempty"` // Durée de visibilité continue, requise pour VIEWABLE
+	UserAgent         string                 `protobuf:"bytes,6,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	IpAddress         string                 `protobuf:"bytes,7,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	Referrer          string                 `protobuf:"bytes,8,opt,name=referrer,proto3" json:"referrer,omitempty"`
+	Tenant            string                 `protobuf:"bytes,9,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *TrackEventRequest) Reset() {
+	*x = TrackEventRequest{}
+	mi := &file_proto_impression_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrackEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackEventRequest) ProtoMessage() {}
+
+func (x *TrackEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impression_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackEventRequest.ProtoReflect.Descriptor instead.
+func (*TrackEventRequest) Descriptor() ([]byte, []int) {
+	return file_proto_impression_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TrackEventRequest) GetAdId() string {
+	if x != nil {
+		return x.AdId
+	}
+	return ""
+}
+
+func (x *TrackEventRequest) GetImpressionId() string {
+	if x != nil {
+		return x.ImpressionId
+	}
+	return ""
+}
+
+func (x *TrackEventRequest) GetEventType() EventType {
+	if x != nil {
+		return x.EventType
+	}
+	return EventType_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *TrackEventRequest) GetVisibleRatio() float64 {
+	if x != nil {
+		return x.VisibleRatio
+	}
+	return 0
+}
+
+func (x *TrackEventRequest) GetVisibleDurationMs() int64 {
+	if x != nil {
+		return x.VisibleDurationMs
+	}
+	return 0
+}
+
+func (x *TrackEventRequest) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+func (x *TrackEventRequest) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *TrackEventRequest) GetReferrer() string {
+	if x != nil {
+		return x.Referrer
+	}
+	return ""
+}
+
+func (x *TrackEventRequest) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+// Réponse après l'enregistrement d'un événement
+type TrackEventResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrackEventResponse) Reset() {
+	*x = TrackEventResponse{}
+	mi := &file_proto_impression_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrackEventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackEventResponse) ProtoMessage() {}
+
+func (x *TrackEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impression_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackEventResponse.ProtoReflect.Descriptor instead.
+func (*TrackEventResponse) Descriptor() ([]byte, []int) {
+	return file_proto_impression_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TrackEventResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// Requête pour obtenir le rapport de visibilité d'une publicité
+type GetViewabilityReportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AdId          string                 `protobuf:"bytes,1,opt,name=ad_id,json=adId,proto3" json:"ad_id,omitempty"`
+	Tenant        string                 `protobuf:"bytes,2,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetViewabilityReportRequest) Reset() {
+	*x = GetViewabilityReportRequest{}
+	mi := &file_proto_impression_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetViewabilityReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetViewabilityReportRequest) ProtoMessage() {}
+
+func (x *GetViewabilityReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impression_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetViewabilityReportRequest.ProtoReflect.Descriptor instead.
+func (*GetViewabilityReportRequest) Descriptor() ([]byte, []int) {
+	return file_proto_impression_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetViewabilityReportRequest) GetAdId() string {
+	if x != nil {
+		return x.AdId
+	}
+	return ""
+}
+
+func (x *GetViewabilityReportRequest) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+// Rapport de visibilité et d'engagement d'une publicité
+type GetViewabilityReportResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	AdId            string                 `protobuf:"bytes,1,opt,name=ad_id,json=adId,proto3" json:"ad_id,omitempty"`
+	Impressions     int64                  `protobuf:"varint,2,opt,name=impressions,proto3" json:"impressions,omitempty"`
+	Rendered        int64                  `protobuf:"varint,3,opt,name=rendered,proto3" json:"rendered,omitempty"`
+	Viewable        int64                  `protobuf:"varint,4,opt,name=viewable,proto3" json:"viewable,omitempty"`
+	Hovers          int64                  `protobuf:"varint,5,opt,name=hovers,proto3" json:"hovers,omitempty"`
+	Closes          int64                  `protobuf:"varint,6,opt,name=closes,proto3" json:"closes,omitempty"`
+	ViewabilityRate float64                `protobuf:"fixed64,7,opt,name=viewability_rate,json=viewabilityRate,proto3" json:"viewability_rate,omitempty"` // viewable / rendered (0 à 1)
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GetViewabilityReportResponse) Reset() {
+	*x = GetViewabilityReportResponse{}
+	mi := &file_proto_impression_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetViewabilityReportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetViewabilityReportResponse) ProtoMessage() {}
+
+func (x *GetViewabilityReportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impression_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetViewabilityReportResponse.ProtoReflect.Descriptor instead.
+func (*GetViewabilityReportResponse) Descriptor() ([]byte, []int) {
+	return file_proto_impression_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetViewabilityReportResponse) GetAdId() string {
+	if x != nil {
+		return x.AdId
+	}
+	return ""
+}
+
+func (x *GetViewabilityReportResponse) GetImpressions() int64 {
+	if x != nil {
+		return x.Impressions
+	}
+	return 0
+}
+
+func (x *GetViewabilityReportResponse) GetRendered() int64 {
+	if x != nil {
+		return x.Rendered
+	}
+	return 0
+}
+
+func (x *GetViewabilityReportResponse) GetViewable() int64 {
+	if x != nil {
+		return x.Viewable
+	}
+	return 0
+}
+
+func (x *GetViewabilityReportResponse) GetHovers() int64 {
+	if x != nil {
+		return x.Hovers
+	}
+	return 0
+}
+
+func (x *GetViewabilityReportResponse) GetCloses() int64 {
+	if x != nil {
+		return x.Closes
+	}
+	return 0
+}
+
+func (x *GetViewabilityReportResponse) GetViewabilityRate() float64 {
+	if x != nil {
+		return x.ViewabilityRate
+	}
+	return 0
+}
+
+// Requête pour obtenir le rapport de trafic d'une publicité
+type GetTrafficReportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AdId          string                 `protobuf:"bytes,1,opt,name=ad_id,json=adId,proto3" json:"ad_id,omitempty"`
+	Tenant        string                 `protobuf:"bytes,2,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTrafficReportRequest) Reset() {
+	*x = GetTrafficReportRequest{}
+	mi := &file_proto_impression_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTrafficReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTrafficReportRequest) ProtoMessage() {}
+
+func (x *GetTrafficReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impression_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTrafficReportRequest.ProtoReflect.Descriptor instead.
+func (*GetTrafficReportRequest) Descriptor() ([]byte, []int) {
+	return file_proto_impression_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetTrafficReportRequest) GetAdId() string {
+	if x != nil {
+		return x.AdId
+	}
+	return ""
+}
+
+func (x *GetTrafficReportRequest) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+// Rapport de trafic : impressions valides et invalides côte à côte
+type GetTrafficReportResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AdId          string                 `protobuf:"bytes,1,opt,name=ad_id,json=adId,proto3" json:"ad_id,omitempty"`
+	Valid         int64                  `protobuf:"varint,2,opt,name=valid,proto3" json:"valid,omitempty"`
+	Invalid       int64                  `protobuf:"varint,3,opt,name=invalid,proto3" json:"invalid,omitempty"`
+	InvalidRate   float64                `protobuf:"fixed64,4,opt,name=invalid_rate,json=invalidRate,proto3" json:"invalid_rate,omitempty"` // Part du trafic invalide dans le total (0 à 1)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTrafficReportResponse) Reset() {
+	*x = GetTrafficReportResponse{}
+	mi := &file_proto_impression_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTrafficReportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTrafficReportResponse) ProtoMessage() {}
+
+func (x *GetTrafficReportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impression_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTrafficReportResponse.ProtoReflect.Descriptor instead.
+func (*GetTrafficReportResponse) Descriptor() ([]byte, []int) {
+	return file_proto_impression_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetTrafficReportResponse) GetAdId() string {
+	if x != nil {
+		return x.AdId
+	}
+	return ""
+}
+
+func (x *GetTrafficReportResponse) GetValid() int64 {
+	if x != nil {
+		return x.Valid
+	}
+	return 0
+}
+
+func (x *GetTrafficReportResponse) GetInvalid() int64 {
+	if x != nil {
+		return x.Invalid
+	}
+	return 0
+}
+
+func (x *GetTrafficReportResponse) GetInvalidRate() float64 {
+	if x != nil {
+		return x.InvalidRate
+	}
+	return 0
+}
+
+// Requête pour obtenir le trafic de plusieurs publicités
+type GetImpressionTotalsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AdIds         []string               `protobuf:"bytes,1,rep,name=ad_ids,json=adIds,proto3" json:"ad_ids,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`     // Début de la période (inclus), origine par défaut
+	To            *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`         // Fin de la période (exclue), sans limite par défaut (impressions en attente comprises)
+	Tenant        string                 `protobuf:"bytes,4,opt,name=tenant,proto3" json:"tenant,omitempty"` // Locataire des publicités
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetImpressionTotalsRequest) Reset() {
+	*x = GetImpressionTotalsRequest{}
+	mi := &file_proto_impression_service_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetImpressionTotalsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetImpressionTotalsRequest) ProtoMessage() {}
+
+func (x *GetImpressionTotalsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impression_service_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetImpressionTotalsRequest.ProtoReflect.Descriptor instead.
+func (*GetImpressionTotalsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_impression_service_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetImpressionTotalsRequest) GetAdIds() []string {
+	if x != nil {
+		return x.AdIds
+	}
+	return nil
+}
+
+func (x *GetImpressionTotalsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *GetImpressionTotalsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *GetImpressionTotalsRequest) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+// Trafic d'une publicité sur la période demandée
+type AdImpressionTotal struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AdId          string                 `protobuf:"bytes,1,opt,name=ad_id,json=adId,proto3" json:"ad_id,omitempty"`
+	Valid         int64                  `protobuf:"varint,2,opt,name=valid,proto3" json:"valid,omitempty"`
+	Invalid       int64                  `protobuf:"varint,3,opt,name=invalid,proto3" json:"invalid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdImpressionTotal) Reset() {
+	*x = AdImpressionTotal{}
+	mi := &file_proto_impression_service_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdImpressionTotal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdImpressionTotal) ProtoMessage() {}
+
+func (x *AdImpressionTotal) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impression_service_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdImpressionTotal.ProtoReflect.Descriptor instead.
+func (*AdImpressionTotal) Descriptor() ([]byte, []int) {
+	return file_proto_impression_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AdImpressionTotal) GetAdId() string {
+	if x != nil {
+		return x.AdId
+	}
+	return ""
+}
+
+func (x *AdImpressionTotal) GetValid() int64 {
+	if x != nil {
+		return x.Valid
+	}
+	return 0
+}
+
+func (x *AdImpressionTotal) GetInvalid() int64 {
+	if x != nil {
+		return x.Invalid
+	}
+	return 0
+}
+
+// Réponse avec le trafic de chaque publicité demandée
+type GetImpressionTotalsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Totals        []*AdImpressionTotal   `protobuf:"bytes,1,rep,name=totals,proto3" json:"totals,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetImpressionTotalsResponse) Reset() {
+	*x = GetImpressionTotalsResponse{}
+	mi := &file_proto_impression_service_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetImpressionTotalsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetImpressionTotalsResponse) ProtoMessage() {}
+
+func (x *GetImpressionTotalsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impression_service_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetImpressionTotalsResponse.ProtoReflect.Descriptor instead.
+func (*GetImpressionTotalsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_impression_service_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetImpressionTotalsResponse) GetTotals() []*AdImpressionTotal {
+	if x != nil {
+		return x.Totals
+	}
+	return nil
+}
+
+// Requête pour exporter les impressions brutes
+type ExportImpressionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"` // Début de la période (inclus)
+	To            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`     // Fin de la période (exclue), maintenant par défaut
+	Format        ExportFormat           `protobuf:"varint,3,opt,name=format,proto3,enum=impression.ExportFormat" json:"format,omitempty"`
+	Tenant        string                 `protobuf:"bytes,4,opt,name=tenant,proto3" json:"tenant,omitempty"` // Seules les impressions de ce locataire sont exportées
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportImpressionsRequest) Reset() {
+	*x = ExportImpressionsRequest{}
+	mi := &file_proto_impression_service_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportImpressionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportImpressionsRequest) ProtoMessage() {}
+
+func (x *ExportImpressionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impression_service_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportImpressionsRequest.ProtoReflect.Descriptor instead.
+func (*ExportImpressionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_impression_service_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ExportImpressionsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ExportImpressionsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *ExportImpressionsRequest) GetFormat() ExportFormat {
+	if x != nil {
+		return x.Format
+	}
+	return ExportFormat_EXPORT_FORMAT_NDJSON
+}
+
+func (x *ExportImpressionsRequest) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+// Morceau du fichier exporté
+type ExportImpressionsChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportImpressionsChunk) Reset() {
+	*x = ExportImpressionsChunk{}
+	mi := &file_proto_impression_service_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportImpressionsChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportImpressionsChunk) ProtoMessage() {}
+
+func (x *ExportImpressionsChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impression_service_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportImpressionsChunk.ProtoReflect.Descriptor instead.
+func (*ExportImpressionsChunk) Descriptor() ([]byte, []int) {
+	return file_proto_impression_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ExportImpressionsChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// Requête de vérification détaillée de la santé du service
+type DeepHealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeepHealthRequest) Reset() {
+	*x = DeepHealthRequest{}
+	mi := &file_proto_impression_service_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeepHealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeepHealthRequest) ProtoMessage() {}
+
+func (x *DeepHealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impression_service_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeepHealthRequest.ProtoReflect.Descriptor instead.
+func (*DeepHealthRequest) Descriptor() ([]byte, []int) {
+	return file_proto_impression_service_proto_rawDescGZIP(), []int{15}
+}
+
+// État d'une dépendance lors de la vérification
+type DependencyHealth struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Healthy       bool                   `protobuf:"varint,2,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`     // Cause de l'échec, vide si la dépendance répond
+	Latency       *durationpb.Duration   `protobuf:"bytes,4,opt,name=latency,proto3" json:"latency,omitempty"` // Durée de la vérification
+	CheckedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=checked_at,json=checkedAt,proto3" json:"checked_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DependencyHealth) Reset() {
+	*x = DependencyHealth{}
+	mi := &file_proto_impression_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DependencyHealth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DependencyHealth) ProtoMessage() {}
+
+func (x *DependencyHealth) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impression_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DependencyHealth.ProtoReflect.Descriptor instead.
+func (*DependencyHealth) Descriptor() ([]byte, []int) {
+	return file_proto_impression_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *DependencyHealth) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DependencyHealth) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *DependencyHealth) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *DependencyHealth) GetLatency() *durationpb.Duration {
+	if x != nil {
+		return x.Latency
+	}
+	return nil
+}
+
+func (x *DependencyHealth) GetCheckedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CheckedAt
+	}
+	return nil
+}
+
+// Réponse avec l'état global et le détail par dépendance
+type DeepHealthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Serving       bool                   `protobuf:"varint,1,opt,name=serving,proto3" json:"serving,omitempty"` // Vrai si toutes les dépendances répondent
+	Dependencies  []*DependencyHealth    `protobuf:"bytes,2,rep,name=dependencies,proto3" json:"dependencies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeepHealthResponse) Reset() {
+	*x = DeepHealthResponse{}
+	mi := &file_proto_impression_service_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeepHealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeepHealthResponse) ProtoMessage() {}
+
+func (x *DeepHealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impression_service_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeepHealthResponse.ProtoReflect.Descriptor instead.
+func (*DeepHealthResponse) Descriptor() ([]byte, []int) {
+	return file_proto_impression_service_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *DeepHealthResponse) GetServing() bool {
+	if x != nil {
+		return x.Serving
+	}
+	return false
+}
+
+func (x *DeepHealthResponse) GetDependencies() []*DependencyHealth {
+	if x != nil {
+		return x.Dependencies
+	}
+	return nil
+}
+
+var File_proto_impression_service_proto protoreflect.FileDescriptor
+
+const file_proto_impression_service_proto_rawDesc = "" +
+	"\n" +
+	"\x1eproto/impression_service.proto\x12\n" +
+	"impression\x1a\x1cgoogle/api/annotations.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc4\x01\n" +
+	"\x16TrackImpressionRequest\x12\x13\n" +
+	"\x05ad_id\x18\x01 \x01(\tR\x04adId\x12#\n" +
+	"\rimpression_id\x18\x02 \x01(\tR\fimpressionId\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x03 \x01(\tR\tuserAgent\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x04 \x01(\tR\tipAddress\x12\x1a\n" +
+	"\breferrer\x18\x05 \x01(\tR\breferrer\x12\x16\n" +
+	"\x06tenant\x18\x06 \x01(\tR\x06tenant\"3\n" +
+	"\x17TrackImpressionResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"H\n" +
+	"\x19GetImpressionCountRequest\x12\x13\n" +
+	"\x05ad_id\x18\x01 \x01(\tR\x04adId\x12\x16\n" +
+	"\x06tenant\x18\x02 \x01(\tR\x06tenant\"2\n" +
+	"\x1aGetImpressionCountResponse\x12\x14\n" +
+	"\x05count\x18\x01 \x01(\x03R\x05count\"\xca\x02\n" +
+	"\x11TrackEventRequest\x12\x13\n" +
+	"\x05ad_id\x18\x01 \x01(\tR\x04adId\x12#\n" +
+	"\rimpression_id\x18\x02 \x01(\tR\fimpressionId\x124\n" +
+	"\n" +
+	"event_type\x18\x03 \x01(\x0e2\x15.impression.EventTypeR\teventType\x12#\n" +
+	"\rvisible_ratio\x18\x04 \x01(\x01R\fvisibleRatio\x12.\n" +
+	"\x13visible_duration_ms\x18\x05 \x01(\x03R\x11visibleDurationMs\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x06 \x01(\tR\tuserAgent\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\a \x01(\tR\tipAddress\x12\x1a\n" +
+	"\breferrer\x18\b \x01(\tR\breferrer\x12\x16\n" +
+	"\x06tenant\x18\t \x01(\tR\x06tenant\".\n" +
+	"\x12TrackEventResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"J\n" +
+	"\x1bGetViewabilityReportRequest\x12\x13\n" +
+	"\x05ad_id\x18\x01 \x01(\tR\x04adId\x12\x16\n" +
+	"\x06tenant\x18\x02 \x01(\tR\x06tenant\"\xe8\x01\n" +
+	"\x1cGetViewabilityReportResponse\x12\x13\n" +
+	"\x05ad_id\x18\x01 \x01(\tR\x04adId\x12 \n" +
+	"\vimpressions\x18\x02 \x01(\x03R\vimpressions\x12\x1a\n" +
+	"\brendered\x18\x03 \x01(\x03R\brendered\x12\x1a\n" +
+	"\bviewable\x18\x04 \x01(\x03R\bviewable\x12\x16\n" +
+	"\x06hovers\x18\x05 \x01(\x03R\x06hovers\x12\x16\n" +
+	"\x06closes\x18\x06 \x01(\x03R\x06closes\x12)\n" +
+	"\x10viewability_rate\x18\a \x01(\x01R\x0fviewabilityRate\"F\n" +
+	"\x17GetTrafficReportRequest\x12\x13\n" +
+	"\x05ad_id\x18\x01 \x01(\tR\x04adId\x12\x16\n" +
+	"\x06tenant\x18\x02 \x01(\tR\x06tenant\"\x82\x01\n" +
+	"\x18GetTrafficReportResponse\x12\x13\n" +
+	"\x05ad_id\x18\x01 \x01(\tR\x04adId\x12\x14\n" +
+	"\x05valid\x18\x02 \x01(\x03R\x05valid\x12\x18\n" +
+	"\ainvalid\x18\x03 \x01(\x03R\ainvalid\x12!\n" +
+	"\finvalid_rate\x18\x04 \x01(\x01R\vinvalidRate\"\xa7\x01\n" +
+	"\x1aGetImpressionTotalsRequest\x12\x15\n" +
+	"\x06ad_ids\x18\x01 \x03(\tR\x05adIds\x12.\n" +
+	"\x04from\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x16\n" +
+	"\x06tenant\x18\x04 \x01(\tR\x06tenant\"X\n" +
+	"\x11AdImpressionTotal\x12\x13\n" +
+	"\x05ad_id\x18\x01 \x01(\tR\x04adId\x12\x14\n" +
+	"\x05valid\x18\x02 \x01(\x03R\x05valid\x12\x18\n" +
+	"\ainvalid\x18\x03 \x01(\x03R\ainvalid\"T\n" +
+	"\x1bGetImpressionTotalsResponse\x125\n" +
+	"\x06totals\x18\x01 \x03(\v2\x1d.impression.AdImpressionTotalR\x06totals\"\xc0\x01\n" +
+	"\x18ExportImpressionsRequest\x12.\n" +
+	"\x04from\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x120\n" +
+	"\x06format\x18\x03 \x01(\x0e2\x18.impression.ExportFormatR\x06format\x12\x16\n" +
+	"\x06tenant\x18\x04 \x01(\tR\x06tenant\",\n" +
+	"\x16ExportImpressionsChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"\x13\n" +
+	"\x11DeepHealthRequest\"\xc6\x01\n" +
+	"\x10DependencyHealth\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\ahealthy\x18\x02 \x01(\bR\ahealthy\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x123\n" +
+	"\alatency\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\alatency\x129\n" +
+	"\n" +
+	"checked_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcheckedAt\"p\n" +
+	"\x12DeepHealthResponse\x12\x18\n" +
+	"\aserving\x18\x01 \x01(\bR\aserving\x12@\n" +
+	"\fdependencies\x18\x02 \x03(\v2\x1c.impression.DependencyHealthR\fdependencies*\xa0\x01\n" +
+	"\tEventType\x12\x1a\n" +
+	"\x16EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15EVENT_TYPE_IMPRESSION\x10\x01\x12\x17\n" +
+	"\x13EVENT_TYPE_RENDERED\x10\x02\x12\x17\n" +
+	"\x13EVENT_TYPE_VIEWABLE\x10\x03\x12\x14\n" +
+	"\x10EVENT_TYPE_HOVER\x10\x04\x12\x14\n" +
+	"\x10EVENT_TYPE_CLOSE\x10\x05*Z\n" +
+	"\fExportFormat\x12\x18\n" +
+	"\x14EXPORT_FORMAT_NDJSON\x10\x00\x12\x15\n" +
+	"\x11EXPORT_FORMAT_CSV\x10\x01\x12\x19\n" +
+	"\x15EXPORT_FORMAT_PARQUET\x10\x022\x95\b\n" +
+	"\x11ImpressionService\x12\x82\x01\n" +
+	"\x0fTrackImpression\x12\".impression.TrackImpressionRequest\x1a#.impression.TrackImpressionResponse\"&\x82\xd3\xe4\x93\x02 :\x01*\"\x1b/v1/ads/{ad_id}/impressions\x12\x8e\x01\n" +
+	"\x12GetImpressionCount\x12%.impression.GetImpressionCountRequest\x1a&.impression.GetImpressionCountResponse\")\x82\xd3\xe4\x93\x02#\x12!/v1/ads/{ad_id}/impressions/count\x12n\n" +
+	"\n" +
+	"TrackEvent\x12\x1d.impression.TrackEventRequest\x1a\x1e.impression.TrackEventResponse\"!\x82\xd3\xe4\x93\x02\x1b:\x01*\"\x16/v1/ads/{ad_id}/events\x12\x8e\x01\n" +
+	"\x14GetViewabilityReport\x12'.impression.GetViewabilityReportRequest\x1a(.impression.GetViewabilityReportResponse\"#\x82\xd3\xe4\x93\x02\x1d\x12\x1b/v1/ads/{ad_id}/viewability\x12~\n" +
+	"\x10GetTrafficReport\x12#.impression.GetTrafficReportRequest\x1a$.impression.GetTrafficReportResponse\"\x1f\x82\xd3\xe4\x93\x02\x19\x12\x17/v1/ads/{ad_id}/traffic\x12\x86\x01\n" +
+	"\x13GetImpressionTotals\x12&.impression.GetImpressionTotalsRequest\x1a'.impression.GetImpressionTotalsResponse\"\x1e\x82\xd3\xe4\x93\x02\x18\x12\x16/v1/impressions/totals\x12\x7f\n" +
+	"\x11ExportImpressions\x12$.impression.ExportImpressionsRequest\x1a\".impression.ExportImpressionsChunk\"\x1e\x82\xd3\xe4\x93\x02\x18\x12\x16/v1/impressions:export0\x01\x12_\n" +
+	"\n" +
+	"DeepHealth\x12\x1d.impression.DeepHealthRequest\x1a\x1e.impression.DeepHealthResponse\"\x12\x82\xd3\xe4\x93\x02\f\x12\n" +
+	"/v1/healthB%Z#shared/generated/impression_serviceb\x06proto3"
+
+var (
+	file_proto_impression_service_proto_rawDescOnce sync.Once
+	file_proto_impression_service_proto_rawDescData []byte
+)
+
+func file_proto_impression_service_proto_rawDescGZIP() []byte {
+	file_proto_impression_service_proto_rawDescOnce.Do(func() {
+		file_proto_impression_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_impression_service_proto_rawDesc), len(file_proto_impression_service_proto_rawDesc)))
+	})
+	return file_proto_impression_service_proto_rawDescData
+}
+
+var file_proto_impression_service_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_proto_impression_service_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_proto_impression_service_proto_goTypes = []any{
+	(EventType)(0),                       // 0: impression.EventType
+	(ExportFormat)(0),                    // 1: impression.ExportFormat
+	(*TrackImpressionRequest)(nil),       // 2: impression.TrackImpressionRequest
+	(*TrackImpressionResponse)(nil),      // 3: impression.TrackImpressionResponse
+	(*GetImpressionCountRequest)(nil),    // 4: impression.GetImpressionCountRequest
+	(*GetImpressionCountResponse)(nil),   // 5: impression.GetImpressionCountResponse
+	(*TrackEventRequest)(nil),            // 6: impression.TrackEventRequest
+	(*TrackEventResponse)(nil),           // 7: impression.TrackEventResponse
+	(*GetViewabilityReportRequest)(nil),  // 8: impression.GetViewabilityReportRequest
+	(*GetViewabilityReportResponse)(nil), // 9: impression.GetViewabilityReportResponse
+	(*GetTrafficReportRequest)(nil),      // 10: impression.GetTrafficReportRequest
+	(*GetTrafficReportResponse)(nil),     // 11: impression.GetTrafficReportResponse
+	(*GetImpressionTotalsRequest)(nil),   // 12: impression.GetImpressionTotalsRequest
+	(*AdImpressionTotal)(nil),            // 13: impression.AdImpressionTotal
+	(*GetImpressionTotalsResponse)(nil),  // 14: impression.GetImpressionTotalsResponse
+	(*ExportImpressionsRequest)(nil),     // 15: impression.ExportImpressionsRequest
+	(*ExportImpressionsChunk)(nil),       // 16: impression.ExportImpressionsChunk
+	(*DeepHealthRequest)(nil),            // 17: impression.DeepHealthRequest
+	(*DependencyHealth)(nil),             // 18: impression.DependencyHealth
+	(*DeepHealthResponse)(nil),           // 19: impression.DeepHealthResponse
+	(*timestamppb.Timestamp)(nil),        // 20: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),          // 21: google.protobuf.Duration
+}
+var file_proto_impression_service_proto_depIdxs = []int32{
+	0,  // 0: impression.TrackEventRequest.event_type:type_name -> impression.EventType
+	20, // 1: impression.GetImpressionTotalsRequest.from:type_name -> google.protobuf.Timestamp
+	20, // 2: impression.GetImpressionTotalsRequest.to:type_name -> google.protobuf.Timestamp
+	13, // 3: impression.GetImpressionTotalsResponse.totals:type_name -> impression.AdImpressionTotal
+	20, // 4: impression.ExportImpressionsRequest.from:type_name -> google.protobuf.Timestamp
+	20, // 5: impression.ExportImpressionsRequest.to:type_name -> google.protobuf.Timestamp
+	1,  // 6: impression.ExportImpressionsRequest.format:type_name -> impression.ExportFormat
+	21, // 7: impression.DependencyHealth.latency:type_name -> google.protobuf.Duration
+	20, // 8: impression.DependencyHealth.checked_at:type_name -> google.protobuf.Timestamp
+	18, // 9: impression.DeepHealthResponse.dependencies:type_name -> impression.DependencyHealth
+	2,  // 10: impression.ImpressionService.TrackImpression:input_type -> impression.TrackImpressionRequest
+	4,  // 11: impression.ImpressionService.GetImpressionCount:input_type -> impression.GetImpressionCountRequest
+	6,  // 12: impression.ImpressionService.TrackEvent:input_type -> impression.TrackEventRequest
+	8,  // 13: impression.ImpressionService.GetViewabilityReport:input_type -> impression.GetViewabilityReportRequest
+	10, // 14: impression.ImpressionService.GetTrafficReport:input_type -> impression.GetTrafficReportRequest
+	12, // 15: impression.ImpressionService.GetImpressionTotals:input_type -> impression.GetImpressionTotalsRequest
+	15, // 16: impression.ImpressionService.ExportImpressions:input_type -> impression.ExportImpressionsRequest
+	17, // 17: impression.ImpressionService.DeepHealth:input_type -> impression.DeepHealthRequest
+	3,  // 18: impression.ImpressionService.TrackImpression:output_type -> impression.TrackImpressionResponse
+	5,  // 19: impression.ImpressionService.GetImpressionCount:output_type -> impression.GetImpressionCountResponse
+	7,  // 20: impression.ImpressionService.TrackEvent:output_type -> impression.TrackEventResponse
+	9,  // 21: impression.ImpressionService.GetViewabilityReport:output_type -> impression.GetViewabilityReportResponse
+	11, // 22: impression.ImpressionService.GetTrafficReport:output_type -> impression.GetTrafficReportResponse
+	14, // 23: impression.ImpressionService.GetImpressionTotals:output_type -> impression.GetImpressionTotalsResponse
+	16, // 24: impression.ImpressionService.ExportImpressions:output_type -> impression.ExportImpressionsChunk
+	19, // 25: impression.ImpressionService.DeepHealth:output_type -> impression.DeepHealthResponse
+	18, // [18:26] is the sub-list for method output_type
+	10, // [10:18] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_proto_impression_service_proto_init() }
+func file_proto_impression_service_proto_init() {
+	if File_proto_impression_service_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_impression_service_proto_rawDesc), len(file_proto_impression_service_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   18,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_impression_service_proto_goTypes,
+		DependencyIndexes: file_proto_impression_service_proto_depIdxs,
+		EnumInfos:         file_proto_impression_service_proto_enumTypes,
+		MessageInfos:      file_proto_impression_service_proto_msgTypes,
+	}.Build()
+	File_proto_impression_service_proto = out.File
+	file_proto_impression_service_proto_goTypes = nil
+	file_proto_impression_service_proto_depIdxs = nil
+}
@@ -0,0 +1,413 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v3.21.12
+// source: proto/impression_service.proto
+
+package impression_service
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ImpressionService_TrackImpression_FullMethodName      = "/impression.ImpressionService/TrackImpression"
+	ImpressionService_GetImpressionCount_FullMethodName   = "/impression.ImpressionService/GetImpressionCount"
+	ImpressionService_TrackEvent_FullMethodName           = "/impression.ImpressionService/TrackEvent"
+	ImpressionService_GetViewabilityReport_FullMethodName = "/impression.ImpressionService/GetViewabilityReport"
+	ImpressionService_GetTrafficReport_FullMethodName     = "/impression.ImpressionService/GetTrafficReport"
+	ImpressionService_GetImpressionTotals_FullMethodName  = "/impression.ImpressionService/GetImpressionTotals"
+	ImpressionService_ExportImpressions_FullMethodName    = "/impression.ImpressionService/ExportImpressions"
+	ImpressionService_DeepHealth_FullMethodName           = "/impression.ImpressionService/DeepHealth"
+)
+
+// ImpressionServiceClient is the client API for ImpressionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Service de suivi des impressions.
+// Les options google.api.http exposent chaque méthode en REST/JSON via la passerelle.
+type ImpressionServiceClient interface {
+	// Enregistrer une nouvelle impression
+	TrackImpression(ctx context.Context, in *TrackImpressionRequest, opts ...grpc.CallOption) (*TrackImpressionResponse, error)
+	// Obtenir le nombre d'impressions pour une publicité
+	GetImpressionCount(ctx context.Context, in *GetImpressionCountRequest, opts ...grpc.CallOption) (*GetImpressionCountResponse, error)
+	// Enregistrer un événement publicitaire (impression, rendu, visibilité, survol, fermeture)
+	TrackEvent(ctx context.Context, in *TrackEventRequest, opts ...grpc.CallOption) (*TrackEventResponse, error)
+	// Obtenir les compteurs d'événements et le taux de visibilité d'une publicité
+	GetViewabilityReport(ctx context.Context, in *GetViewabilityReportRequest, opts ...grpc.CallOption) (*GetViewabilityReportResponse, error)
+	// Obtenir le trafic valide et invalide (IVT) d'une publicité
+	GetTrafficReport(ctx context.Context, in *GetTrafficReportRequest, opts ...grpc.CallOption) (*GetTrafficReportResponse, error)
+	// Obtenir le trafic de plusieurs publicités sur une période (réconciliation)
+	GetImpressionTotals(ctx context.Context, in *GetImpressionTotalsRequest, opts ...grpc.CallOption) (*GetImpressionTotalsResponse, error)
+	// Exporter les impressions brutes sur une période donnée
+	ExportImpressions(ctx context.Context, in *ExportImpressionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportImpressionsChunk], error)
+	// Vérifier chaque dépendance du service (MongoDB, Dragonfly) et obtenir le détail
+	DeepHealth(ctx context.Context, in *DeepHealthRequest, opts ...grpc.CallOption) (*DeepHealthResponse, error)
+}
+
+type impressionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewImpressionServiceClient(cc grpc.ClientConnInterface) ImpressionServiceClient {
+	return &impressionServiceClient{cc}
+}
+
+func (c *impressionServiceClient) TrackImpression(ctx context.Context, in *TrackImpressionRequest, opts ...grpc.CallOption) (*TrackImpressionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TrackImpressionResponse)
+	err := c.cc.Invoke(ctx, ImpressionService_TrackImpression_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *impressionServiceClient) GetImpressionCount(ctx context.Context, in *GetImpressionCountRequest, opts ...grpc.CallOption) (*GetImpressionCountResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetImpressionCountResponse)
+	err := c.cc.Invoke(ctx, ImpressionService_GetImpressionCount_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *impressionServiceClient) TrackEvent(ctx context.Context, in *TrackEventRequest, opts ...grpc.CallOption) (*TrackEventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TrackEventResponse)
+	err := c.cc.Invoke(ctx, ImpressionService_TrackEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *impressionServiceClient) GetViewabilityReport(ctx context.Context, in *GetViewabilityReportRequest, opts ...grpc.CallOption) (*GetViewabilityReportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetViewabilityReportResponse)
+	err := c.cc.Invoke(ctx, ImpressionService_GetViewabilityReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *impressionServiceClient) GetTrafficReport(ctx context.Context, in *GetTrafficReportRequest, opts ...grpc.CallOption) (*GetTrafficReportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTrafficReportResponse)
+	err := c.cc.Invoke(ctx, ImpressionService_GetTrafficReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *impressionServiceClient) GetImpressionTotals(ctx context.Context, in *GetImpressionTotalsRequest, opts ...grpc.CallOption) (*GetImpressionTotalsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetImpressionTotalsResponse)
+	err := c.cc.Invoke(ctx, ImpressionService_GetImpressionTotals_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *impressionServiceClient) ExportImpressions(ctx context.Context, in *ExportImpressionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportImpressionsChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ImpressionService_ServiceDesc.Streams[0], ImpressionService_ExportImpressions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportImpressionsRequest, ExportImpressionsChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ImpressionService_ExportImpressionsClient = grpc.ServerStreamingClient[ExportImpressionsChunk]
+
+func (c *impressionServiceClient) DeepHealth(ctx context.Context, in *DeepHealthRequest, opts ...grpc.CallOption) (*DeepHealthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeepHealthResponse)
+	err := c.cc.Invoke(ctx, ImpressionService_DeepHealth_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ImpressionServiceServer is the server API for ImpressionService service.
+// All implementations must embed UnimplementedImpressionServiceServer
+// for forward compatibility.
+//
+// Service de suivi des impressions.
+// Les options google.api.http exposent chaque méthode en REST/JSON via la passerelle.
+type ImpressionServiceServer interface {
+	// Enregistrer une nouvelle impression
+	TrackImpression(context.Context, *TrackImpressionRequest) (*TrackImpressionResponse, error)
+	// Obtenir le nombre d'impressions pour une publicité
+	GetImpressionCount(context.Context, *GetImpressionCountRequest) (*GetImpressionCountResponse, error)
+	// Enregistrer un événement publicitaire (impression, rendu, visibilité, survol, fermeture)
+	TrackEvent(context.Context, *TrackEventRequest) (*TrackEventResponse, error)
+	// Obtenir les compteurs d'événements et le taux de visibilité d'une publicité
+	GetViewabilityReport(context.Context, *GetViewabilityReportRequest) (*GetViewabilityReportResponse, error)
+	// Obtenir le trafic valide et invalide (IVT) d'une publicité
+	GetTrafficReport(context.Context, *GetTrafficReportRequest) (*GetTrafficReportResponse, error)
+	// Obtenir le trafic de plusieurs publicités sur une période (réconciliation)
+	GetImpressionTotals(context.Context, *GetImpressionTotalsRequest) (*GetImpressionTotalsResponse, error)
+	// Exporter les impressions brutes sur une période donnée
+	ExportImpressions(*ExportImpressionsRequest, grpc.ServerStreamingServer[ExportImpressionsChunk]) error
+	// Vérifier chaque dépendance du service (MongoDB, Dragonfly) et obtenir le détail
+	DeepHealth(context.Context, *DeepHealthRequest) (*DeepHealthResponse, error)
+	mustEmbedUnimplementedImpressionServiceServer()
+}
+
+// UnimplementedImpressionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedImpressionServiceServer struct{}
+
+func (UnimplementedImpressionServiceServer) TrackImpression(context.Context, *TrackImpressionRequest) (*TrackImpressionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TrackImpression not implemented")
+}
+func (UnimplementedImpressionServiceServer) GetImpressionCount(context.Context, *GetImpressionCountRequest) (*GetImpressionCountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetImpressionCount not implemented")
+}
+func (UnimplementedImpressionServiceServer) TrackEvent(context.Context, *TrackEventRequest) (*TrackEventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TrackEvent not implemented")
+}
+func (UnimplementedImpressionServiceServer) GetViewabilityReport(context.Context, *GetViewabilityReportRequest) (*GetViewabilityReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetViewabilityReport not implemented")
+}
+func (UnimplementedImpressionServiceServer) GetTrafficReport(context.Context, *GetTrafficReportRequest) (*GetTrafficReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTrafficReport not implemented")
+}
+func (UnimplementedImpressionServiceServer) GetImpressionTotals(context.Context, *GetImpressionTotalsRequest) (*GetImpressionTotalsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetImpressionTotals not implemented")
+}
+func (UnimplementedImpressionServiceServer) ExportImpressions(*ExportImpressionsRequest, grpc.ServerStreamingServer[ExportImpressionsChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportImpressions not implemented")
+}
+func (UnimplementedImpressionServiceServer) DeepHealth(context.Context, *DeepHealthRequest) (*DeepHealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeepHealth not implemented")
+}
+func (UnimplementedImpressionServiceServer) mustEmbedUnimplementedImpressionServiceServer() {}
+func (UnimplementedImpressionServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeImpressionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ImpressionServiceServer will
+// result in compilation errors.
+type UnsafeImpressionServiceServer interface {
+	mustEmbedUnimplementedImpressionServiceServer()
+}
+
+func RegisterImpressionServiceServer(s grpc.ServiceRegistrar, srv ImpressionServiceServer) {
+	// If the following call pancis, it indicates UnimplementedImpressionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ImpressionService_ServiceDesc, srv)
+}
+
+func _ImpressionService_TrackImpression_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TrackImpressionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImpressionServiceServer).TrackImpression(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImpressionService_TrackImpression_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImpressionServiceServer).TrackImpression(ctx, req.(*TrackImpressionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImpressionService_GetImpressionCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetImpressionCountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImpressionServiceServer).GetImpressionCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImpressionService_GetImpressionCount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImpressionServiceServer).GetImpressionCount(ctx, req.(*GetImpressionCountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImpressionService_TrackEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TrackEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImpressionServiceServer).TrackEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImpressionService_TrackEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImpressionServiceServer).TrackEvent(ctx, req.(*TrackEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImpressionService_GetViewabilityReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetViewabilityReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImpressionServiceServer).GetViewabilityReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImpressionService_GetViewabilityReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImpressionServiceServer).GetViewabilityReport(ctx, req.(*GetViewabilityReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImpressionService_GetTrafficReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTrafficReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImpressionServiceServer).GetTrafficReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImpressionService_GetTrafficReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImpressionServiceServer).GetTrafficReport(ctx, req.(*GetTrafficReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImpressionService_GetImpressionTotals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetImpressionTotalsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImpressionServiceServer).GetImpressionTotals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImpressionService_GetImpressionTotals_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImpressionServiceServer).GetImpressionTotals(ctx, req.(*GetImpressionTotalsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImpressionService_ExportImpressions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportImpressionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ImpressionServiceServer).ExportImpressions(m, &grpc.GenericServerStream[ExportImpressionsRequest, ExportImpressionsChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ImpressionService_ExportImpressionsServer = grpc.ServerStreamingServer[ExportImpressionsChunk]
+
+func _ImpressionService_DeepHealth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeepHealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImpressionServiceServer).DeepHealth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImpressionService_DeepHealth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImpressionServiceServer).DeepHealth(ctx, req.(*DeepHealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ImpressionService_ServiceDesc is the grpc.ServiceDesc for ImpressionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ImpressionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "impression.ImpressionService",
+	HandlerType: (*ImpressionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "TrackImpression",
+			Handler:    _ImpressionService_TrackImpression_Handler,
+		},
+		{
+			MethodName: "GetImpressionCount",
+			Handler:    _ImpressionService_GetImpressionCount_Handler,
+		},
+		{
+			MethodName: "TrackEvent",
+			Handler:    _ImpressionService_TrackEvent_Handler,
+		},
+		{
+			MethodName: "GetViewabilityReport",
+			Handler:    _ImpressionService_GetViewabilityReport_Handler,
+		},
+		{
+			MethodName: "GetTrafficReport",
+			Handler:    _ImpressionService_GetTrafficReport_Handler,
+		},
+		{
+			MethodName: "GetImpressionTotals",
+			Handler:    _ImpressionService_GetImpressionTotals_Handler,
+		},
+		{
+			MethodName: "DeepHealth",
+			Handler:    _ImpressionService_DeepHealth_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportImpressions",
+			Handler:       _ImpressionService_ExportImpressions_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/impression_service.proto",
+}
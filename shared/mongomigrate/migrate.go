@@ -0,0 +1,148 @@
+// Package mongomigrate applique les migrations versionnées d'un schéma MongoDB (index) et
+// les consigne dans la collection schema_migrations. Il est partagé par l'adserver et le
+// impression-tracker.
+//
+// Chaque étape cible une collection et est consignée par couple (collection, version) :
+// une collection renommée dans la configuration est une nouvelle cible, dont toutes les
+// étapes sont appliquées au prochain démarrage.
+package mongomigrate
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collection est la collection où sont consignées les migrations appliquées
+const Collection = "schema_migrations"
+
+// Migration est une étape versionnée du schéma d'une collection. Les versions se suivent
+// par collection. Up doit être idempotente : deux réplicas démarrant ensemble peuvent
+// l'exécuter toutes les deux avant que l'étape soit consignée.
+type Migration struct {
+	Collection  string
+	Version     int
+	Description string
+	Up          func(ctx context.Context, collection *mongo.Collection) error
+}
+
+// Key identifie une étape consignée
+type Key struct {
+	Collection string `bson:"collection"`
+	Version    int    `bson:"version"`
+}
+
+// Key retourne l'identifiant sous lequel l'étape est consignée
+func (m Migration) Key() Key {
+	return Key{Collection: m.Collection, Version: m.Version}
+}
+
+// Applied est une étape consignée dans schema_migrations
+type Applied struct {
+	Key         Key       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// CreateIndexes retourne une étape créant des index ; la création d'un index existant à
+// l'identique est sans effet
+func CreateIndexes(models ...mongo.IndexModel) func(context.Context, *mongo.Collection) error {
+	return func(ctx context.Context, collection *mongo.Collection) error {
+		_, err := collection.Indexes().CreateMany(ctx, models)
+		return err
+	}
+}
+
+// Migrate applique, par version croissante, les étapes absentes de schema_migrations et
+// retourne celles qui ont été appliquées. Elle s'arrête à la première erreur ; les étapes
+// suivantes seront tentées à la prochaine exécution.
+func Migrate(ctx context.Context, db *mongo.Database, migrations []Migration, logger *slog.Logger) ([]Migration, error) {
+	logger = logger.With("component", "Migrations")
+	pending, err := pendingMigrations(ctx, db, migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, m := range pending {
+		start := time.Now()
+		if err := m.Up(ctx, db.Collection(m.Collection)); err != nil {
+			return applied, fmt.Errorf("migration %s %d (%s): %w", m.Collection, m.Version, m.Description, err)
+		}
+		_, err := db.Collection(Collection).InsertOne(ctx, Applied{
+			Key:         m.Key(),
+			Description: m.Description,
+			AppliedAt:   time.Now().UTC(),
+		})
+		// Une autre réplica a consigné la même étape entre-temps
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return applied, fmt.Errorf("record migration %s %d: %w", m.Collection, m.Version, err)
+		}
+		logger.InfoContext(ctx, "Migration applied", "collection", m.Collection, "version", m.Version, "description", m.Description, "duration", time.Since(start))
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// Validate vérifie que chaque étape a une collection, une version strictement positive et
+// que le couple (collection, version) est unique
+func Validate(migrations []Migration) error {
+	seen := make(map[Key]bool, len(migrations))
+	for _, m := range migrations {
+		switch {
+		case m.Collection == "":
+			return fmt.Errorf("migration %d (%s) has no collection", m.Version, m.Description)
+		case m.Version <= 0 || seen[m.Key()]:
+			return fmt.Errorf("invalid or duplicate migration version %s %d", m.Collection, m.Version)
+		}
+		seen[m.Key()] = true
+	}
+	return nil
+}
+
+// pendingMigrations retourne les étapes non consignées, triées par version
+func pendingMigrations(ctx context.Context, db *mongo.Database, migrations []Migration) ([]Migration, error) {
+	if err := Validate(migrations); err != nil {
+		return nil, err
+	}
+	done, err := AppliedMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[Key]bool, len(done))
+	for _, m := range done {
+		applied[m.Key] = true
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if !applied[m.Key()] {
+			pending = append(pending, m)
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool { return pending[i].Version < pending[j].Version })
+	return pending, nil
+}
+
+// AppliedMigrations retourne les étapes consignées, par collection et version croissante.
+// Les entrées consignées par numéro de version seul, sans collection, sont ignorées :
+// leurs étapes, idempotentes, sont rejouées une fois sous leur nouvel identifiant.
+func AppliedMigrations(ctx context.Context, db *mongo.Database) ([]Applied, error) {
+	filter := bson.M{"_id": bson.M{"$type": "object"}}
+	sortBy := bson.D{{Key: "_id.collection", Value: 1}, {Key: "_id.version", Value: 1}}
+	cursor, err := db.Collection(Collection).Find(ctx, filter, options.Find().SetSort(sortBy))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", Collection, err)
+	}
+	var applied []Applied
+	if err := cursor.All(ctx, &applied); err != nil {
+		return nil, fmt.Errorf("read %s: %w", Collection, err)
+	}
+	return applied, nil
+}
@@ -0,0 +1,161 @@
+package mongomigrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// discard est le logger des tests
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// newDatabase retourne une base propre au test, supprimée à la fin du test.
+// Sans MONGO_TEST_URI, le test est ignoré.
+func newDatabase(t *testing.T) *mongo.Database {
+	t.Helper()
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI not set")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("mongo.Connect() error: %v", err)
+	}
+	db := client.Database(fmt.Sprintf("migrations_%d", time.Now().UnixNano()))
+	t.Cleanup(func() {
+		_ = db.Drop(context.Background())
+		_ = client.Disconnect(context.Background())
+	})
+	return db
+}
+
+// indexStep retourne une étape indexant field dans collection
+func indexStep(collection string, version int, field string) Migration {
+	return Migration{
+		Collection:  collection,
+		Version:     version,
+		Description: "index by " + field,
+		Up:          CreateIndexes(mongo.IndexModel{Keys: bson.D{{Key: field, Value: 1}}}),
+	}
+}
+
+// indexNames retourne les noms des index de collection
+func indexNames(t *testing.T, collection *mongo.Collection) []string {
+	t.Helper()
+	specs, err := collection.Indexes().ListSpecifications(context.Background())
+	if err != nil {
+		t.Fatalf("ListSpecifications(%s) error: %v", collection.Name(), err)
+	}
+	var names []string
+	for _, s := range specs {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		migrations []Migration
+		want       string // Extrait du message attendu, vide si les étapes sont valides
+	}{
+		{name: "valid", migrations: []Migration{indexStep("ads", 1, "tenant"), indexStep("ads", 2, "expires_at"), indexStep("api_keys", 1, "hash")}},
+		{name: "same version on two collections", migrations: []Migration{indexStep("clicks", 1, "tenant"), indexStep("impressions", 1, "tenant")}},
+		{name: "no collection", migrations: []Migration{indexStep("", 1, "tenant")}, want: "has no collection"},
+		{name: "zero version", migrations: []Migration{indexStep("ads", 0, "tenant")}, want: "invalid or duplicate migration version ads 0"},
+		{name: "duplicate version", migrations: []Migration{indexStep("ads", 1, "tenant"), indexStep("ads", 1, "expires_at")}, want: "invalid or duplicate migration version ads 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.migrations)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("Validate() error: %v", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("Validate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateRejectsInvalidMigrations(t *testing.T) {
+	// Les étapes sont validées avant tout accès à la base
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1").SetServerSelectionTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Disconnect(context.Background())
+	_, err = Migrate(context.Background(), client.Database("migrations"), []Migration{indexStep("", 1, "tenant")}, discard)
+	if err == nil || !strings.Contains(err.Error(), "has no collection") {
+		t.Errorf("Migrate() error = %v, want the validation error", err)
+	}
+}
+
+func TestMigrateRecordsEachCollection(t *testing.T) {
+	db := newDatabase(t)
+	ctx := context.Background()
+
+	steps := func(collection string) []Migration {
+		return []Migration{indexStep(collection, 1, "tenant"), indexStep(collection, 2, "expires_at")}
+	}
+	applied, err := Migrate(ctx, db, steps("ads"), discard)
+	if err != nil || len(applied) != 2 {
+		t.Fatalf("Migrate() = %d applied, %v, want 2", len(applied), err)
+	}
+	if applied, err := Migrate(ctx, db, steps("ads"), discard); err != nil || len(applied) != 0 {
+		t.Fatalf("second Migrate() = %d applied, %v, want none", len(applied), err)
+	}
+
+	// La collection renommée reçoit les mêmes index, sous ses propres entrées
+	applied, err = Migrate(ctx, db, steps("ads_v2"), discard)
+	if err != nil || len(applied) != 2 {
+		t.Fatalf("Migrate() after a rename = %d applied, %v, want 2", len(applied), err)
+	}
+	for _, name := range []string{"tenant_1", "expires_at_1"} {
+		if got := indexNames(t, db.Collection("ads_v2")); !slices.Contains(got, name) {
+			t.Errorf("ads_v2 indexes = %v, missing %s", got, name)
+		}
+	}
+
+	done, err := AppliedMigrations(ctx, db)
+	if err != nil {
+		t.Fatalf("AppliedMigrations() error: %v", err)
+	}
+	var keys []Key
+	for _, m := range done {
+		keys = append(keys, m.Key)
+	}
+	want := []Key{{"ads", 1}, {"ads", 2}, {"ads_v2", 1}, {"ads_v2", 2}}
+	if !slices.Equal(keys, want) {
+		t.Errorf("AppliedMigrations() = %v, want %v", keys, want)
+	}
+}
+
+func TestMigrateReplaysLegacyRecords(t *testing.T) {
+	db := newDatabase(t)
+	ctx := context.Background()
+
+	// Entrée consignée par numéro de version seul
+	if _, err := db.Collection(Collection).InsertOne(ctx, bson.M{"_id": 1, "description": "ads: index by tenant"}); err != nil {
+		t.Fatal(err)
+	}
+	applied, err := Migrate(ctx, db, []Migration{indexStep("ads", 1, "tenant")}, discard)
+	if err != nil || len(applied) != 1 {
+		t.Fatalf("Migrate() = %d applied, %v, want the step replayed", len(applied), err)
+	}
+	if got := indexNames(t, db.Collection("ads")); !slices.Contains(got, "tenant_1") {
+		t.Errorf("ads indexes = %v, missing tenant_1", got)
+	}
+}